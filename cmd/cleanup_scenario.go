@@ -77,14 +77,23 @@ func (c *scenarioCleaner) run(ctx context.Context) error {
 		hostname = host
 	}
 
+	// Clean based on task queue to avoid relying on search attributes and reducing the
+	// requirements of this framework, but also by run ID search attribute if registered since
+	// workflows tagged with it may run on other task queues
+	query := fmt.Sprintf("TaskQueue = %q", taskQueue)
+	tagged, err := loadgen.RunIDSearchAttributeRegistered(ctx, client.OperatorService(), c.clientOptions.Namespace)
+	if err != nil {
+		c.logger.Warnf("Not cleaning up by run ID search attribute: %v", err)
+	} else if tagged {
+		query = fmt.Sprintf("%v OR %v = %q", query, loadgen.RunIDSearchAttribute, c.runID)
+	}
+
 	// Start
-	_, err := client.WorkflowService().StartBatchOperation(ctx, &workflowservice.StartBatchOperationRequest{
-		Namespace: c.clientOptions.Namespace,
-		JobId:     jobID,
-		Reason:    "omes cleanup",
-		// Clean based on task queue to avoid relying on search attributes and
-		// reducing the requirements of this framework
-		VisibilityQuery: fmt.Sprintf("TaskQueue = %q", taskQueue),
+	_, err = client.WorkflowService().StartBatchOperation(ctx, &workflowservice.StartBatchOperationRequest{
+		Namespace:       c.clientOptions.Namespace,
+		JobId:           jobID,
+		Reason:          "omes cleanup",
+		VisibilityQuery: query,
 		Operation: &workflowservice.StartBatchOperationRequest_DeletionOperation{
 			DeletionOperation: &batch.BatchOperationDeletion{Identity: username + "@" + hostname},
 		},
@@ -7,13 +7,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/spf13/pflag"
 	"go.temporal.io/api/common/v1"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/interceptor"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 const AUTH_HEADER_ENV_VAR = "TEMPORAL_OMES_AUTH_HEADER"
@@ -32,6 +35,10 @@ func newHeadersProvider(headers map[string]string) headersProvider {
 type ClientOptions struct {
 	// Address of Temporal server to connect to
 	Address string
+	// Addresses of frontend endpoints scenario iterations are distributed across round-robin, each
+	// with its own client, see loadgen.ScenarioInfo.Endpoints. Address is still used for calls
+	// outside iterations and by workers.
+	Endpoints []string
 	// Temporal namespace
 	Namespace string
 	// Enable TLS
@@ -42,6 +49,31 @@ type ClientOptions struct {
 	ClientKeyPath string
 	// Authorization header value
 	AuthHeader string
+	// gRPC interceptors to install on the client, applied in order with the first outermost. Not
+	// settable by flag, see loadgen.HasClientInterceptors.
+	UnaryInterceptors []grpc.UnaryClientInterceptor
+	// Interval of keepalive pings on idle connections, disabled if zero. The SDK raises values
+	// below 10s to 10s.
+	KeepAliveTime time.Duration
+	// Time to wait for a keepalive ping acknowledgement before closing the connection.
+	KeepAliveTimeout time.Duration
+	// Send keepalive pings even without active RPCs.
+	KeepAlivePermitWithoutStream bool
+	// Number of gRPC connections calls are spread across round-robin. Default is one.
+	Connections int
+	// Wraps the metrics handler the SDK emits metrics through if set. Not settable by flag, see
+	// loadgen.HasSDKMetricsCapture.
+	WrapMetricsHandler func(client.MetricsHandler) client.MetricsHandler
+	// Codecs payloads are encoded with after conversion, e.g. for compression or encryption, applied
+	// in order when encoding and in reverse when decoding. Not settable by flag, see
+	// loadgen.HasPayloadCodecs.
+	PayloadCodecs []converter.PayloadCodec
+	// Converter of values to payloads, before PayloadCodecs, replacing the default converter that
+	// also passes raw payloads through. Not settable by flag, see loadgen.HasDataConverter.
+	DataConverter converter.DataConverter
+	// SDK client interceptors, applied in order with the first outermost. Not settable by flag, see
+	// TracingOptions.ClientInterceptors.
+	Interceptors []interceptor.ClientInterceptor
 }
 
 // loadTLSConfig inits a TLS config from the provided cert and key files.
@@ -82,9 +114,22 @@ func (c *ClientOptions) Dial(metrics *Metrics, logger *zap.SugaredLogger) (clien
 	var clientOptions client.Options
 	clientOptions.HostPort = c.Address
 	clientOptions.Namespace = c.Namespace
-	clientOptions.ConnectionOptions.TLS = tlsCfg
+	clientOptions.ConnectionOptions = c.connectionOptions(tlsCfg)
+	clientOptions.Interceptors = c.Interceptors
+	var pool *connectionPool
+	if c.Connections > 1 {
+		if pool, err = dialConnectionPool(c.Address, c.Connections, clientOptions.ConnectionOptions); err != nil {
+			return nil, err
+		}
+		// Innermost, sending each call on a pooled connection instead of the client's own
+		clientOptions.ConnectionOptions.DialOptions = append(clientOptions.ConnectionOptions.DialOptions,
+			grpc.WithChainUnaryInterceptor(pool.interceptor))
+	}
 	clientOptions.Logger = NewZapAdapter(logger.Desugar())
 	clientOptions.MetricsHandler = metrics.NewHandler()
+	if c.WrapMetricsHandler != nil {
+		clientOptions.MetricsHandler = c.WrapMetricsHandler(clientOptions.MetricsHandler)
+	}
 
 	var authHeader string
 	if c.AuthHeader == "" {
@@ -98,36 +143,79 @@ func (c *ClientOptions) Dial(metrics *Metrics, logger *zap.SugaredLogger) (clien
 		})
 	}
 
-	dataConverter := converter.NewCompositeDataConverter(
-		converter.NewNilPayloadConverter(),
-		converter.NewByteSlicePayloadConverter(),
-		&PassThroughPayloadConverter{},
-		converter.NewProtoJSONPayloadConverter(),
-		converter.NewProtoPayloadConverter(),
-		converter.NewJSONPayloadConverter(),
-	)
-	clientOptions.DataConverter = dataConverter
+	clientOptions.DataConverter = c.ClientDataConverter()
 
 	client, err := client.Dial(clientOptions)
 	if err != nil {
+		if pool != nil {
+			pool.Close()
+		}
 		return nil, fmt.Errorf("failed to dial: %w", err)
 	}
 	logger.Infof("Client connected to %s, namespace: %s", c.Address, c.Namespace)
+	if pool != nil {
+		return &pooledClient{Client: client, pool: pool}, nil
+	}
 	return client, nil
 }
 
+// ClientDataConverter returns the data converter of dialed clients, DataConverter or the default
+// converter that also passes raw payloads through, encoding with PayloadCodecs if any.
+func (c *ClientOptions) ClientDataConverter() converter.DataConverter {
+	dataConverter := c.DataConverter
+	if dataConverter == nil {
+		dataConverter = converter.NewCompositeDataConverter(
+			converter.NewNilPayloadConverter(),
+			converter.NewByteSlicePayloadConverter(),
+			&PassThroughPayloadConverter{},
+			converter.NewProtoJSONPayloadConverter(),
+			converter.NewProtoPayloadConverter(),
+			converter.NewJSONPayloadConverter(),
+		)
+	}
+	if len(c.PayloadCodecs) > 0 {
+		return converter.NewCodecDataConverter(dataConverter, c.PayloadCodecs...)
+	}
+	return dataConverter
+}
+
+// connectionOptions translates these options to SDK connection options with the given TLS config.
+func (c *ClientOptions) connectionOptions(tlsCfg *tls.Config) client.ConnectionOptions {
+	options := client.ConnectionOptions{
+		TLS:                          tlsCfg,
+		EnableKeepAliveCheck:         c.KeepAliveTime > 0,
+		KeepAliveTime:                c.KeepAliveTime,
+		KeepAliveTimeout:             c.KeepAliveTimeout,
+		KeepAlivePermitWithoutStream: c.KeepAlivePermitWithoutStream,
+	}
+	if len(c.UnaryInterceptors) > 0 {
+		options.DialOptions = append(options.DialOptions, grpc.WithChainUnaryInterceptor(c.UnaryInterceptors...))
+	}
+	return options
+}
+
 // AddCLIFlags adds the relevant flags to populate the options struct.
 func (c *ClientOptions) AddCLIFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.Address, "server-address", client.DefaultHostPort, "Address of Temporal server")
+	fs.StringSliceVar(&c.Endpoints, "server-endpoints", nil,
+		"Addresses of frontend endpoints to distribute scenario iterations across round-robin (comma-separated)")
 	fs.StringVar(&c.Namespace, "namespace", client.DefaultNamespace, "Namespace to connect to")
 	fs.BoolVar(&c.EnableTLS, "tls", false, "Enable TLS")
 	fs.StringVar(&c.ClientCertPath, "tls-cert-path", "", "Path to client TLS certificate")
 	fs.StringVar(&c.ClientKeyPath, "tls-key-path", "", "Path to client private key")
 	fs.StringVar(&c.AuthHeader, "auth-header", "",
 		fmt.Sprintf("Authorization header value (can also be set via %s env var)", AUTH_HEADER_ENV_VAR))
+	fs.DurationVar(&c.KeepAliveTime, "grpc-keepalive-time", 0,
+		"Interval of keepalive pings on idle gRPC connections (disabled if zero, minimum 10s)")
+	fs.DurationVar(&c.KeepAliveTimeout, "grpc-keepalive-timeout", 0,
+		"Time to wait for a keepalive ping acknowledgement before closing the connection")
+	fs.BoolVar(&c.KeepAlivePermitWithoutStream, "grpc-keepalive-permit-without-stream", false,
+		"Send keepalive pings even without active RPCs")
+	fs.IntVar(&c.Connections, "grpc-connections", 1, "Number of gRPC connections to spread client calls across")
 }
 
-// ToFlags converts these options to string flags.
+// ToFlags converts these options to string flags. gRPC connection options are not included since
+// not all workers accept them.
 func (c *ClientOptions) ToFlags() (flags []string) {
 	if c.Address != "" {
 		flags = append(flags, "--server-address", c.Address)
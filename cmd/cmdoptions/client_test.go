@@ -0,0 +1,277 @@
+package cmdoptions
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+type systemInfoServer struct {
+	workflowservice.UnimplementedWorkflowServiceServer
+}
+
+func (*systemInfoServer) GetSystemInfo(
+	context.Context,
+	*workflowservice.GetSystemInfoRequest,
+) (*workflowservice.GetSystemInfoResponse, error) {
+	return &workflowservice.GetSystemInfoResponse{}, nil
+}
+
+func TestDialInstallsUnaryInterceptorsInOrder(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	workflowservice.RegisterWorkflowServiceServer(server, &systemInfoServer{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	var lock sync.Mutex
+	var invoked []string
+	recordingInterceptor := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			lock.Lock()
+			invoked = append(invoked, name+" "+method)
+			lock.Unlock()
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+	options := ClientOptions{
+		Address:   listener.Addr().String(),
+		Namespace: "default",
+		UnaryInterceptors: []grpc.UnaryClientInterceptor{
+			recordingInterceptor("outer"),
+			recordingInterceptor("inner"),
+		},
+	}
+	logger := zap.NewNop().Sugar()
+	c, err := options.Dial((&MetricsOptions{}).MustCreateMetrics(logger), logger)
+	require.NoError(t, err)
+	defer c.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, []string{
+		"outer /temporal.api.workflowservice.v1.WorkflowService/GetSystemInfo",
+		"inner /temporal.api.workflowservice.v1.WorkflowService/GetSystemInfo",
+	}, invoked)
+}
+
+func TestDialCapturesSDKMetrics(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	workflowservice.RegisterWorkflowServiceServer(server, &systemInfoServer{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	var sdkMetrics *loadgen.CapturingMetricsHandler
+	options := ClientOptions{
+		Address:   listener.Addr().String(),
+		Namespace: "default",
+		WrapMetricsHandler: func(handler client.MetricsHandler) client.MetricsHandler {
+			sdkMetrics = loadgen.NewCapturingMetricsHandler(handler)
+			return sdkMetrics
+		},
+	}
+	logger := zap.NewNop().Sugar()
+	c, err := options.Dial((&MetricsOptions{}).MustCreateMetrics(logger), logger)
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.DescribeTaskQueue(context.Background(), "some-queue", enums.TASK_QUEUE_TYPE_WORKFLOW)
+	require.Error(t, err)
+
+	require.NotNil(t, sdkMetrics)
+	// The call when dialing succeeded, the unimplemented one failed
+	require.NoError(t, sdkMetrics.AssertCounterAtLeast("temporal_request", map[string]string{"operation": "GetSystemInfo"}, 1))
+	require.Equal(t, int64(1), sdkMetrics.CounterTotal("temporal_request_failure",
+		map[string]string{"operation": "DescribeTaskQueue"}))
+	require.Positive(t, sdkMetrics.TimerCount("temporal_request_latency", map[string]string{"operation": "GetSystemInfo"}))
+	require.Error(t, sdkMetrics.AssertCounterAtLeast("temporal_request_failure", map[string]string{"operation": "GetSystemInfo"}, 1))
+	series := sdkMetrics.Find("temporal_request_failure", nil)
+	require.Len(t, series, 1)
+	require.Equal(t, "default", series[0].Tags["namespace"])
+}
+
+// reversingCodec is a reversible codec reversing payload data.
+type reversingCodec struct{}
+
+func reversed(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out
+}
+
+func (reversingCodec) Encode(payloads []*common.Payload) ([]*common.Payload, error) {
+	encoded := make([]*common.Payload, len(payloads))
+	for i, p := range payloads {
+		encoded[i] = &common.Payload{
+			Metadata: map[string][]byte{"encoding": []byte("binary/reversed"), "reversed-encoding": p.Metadata["encoding"]},
+			Data:     reversed(p.Data),
+		}
+	}
+	return encoded, nil
+}
+
+func (reversingCodec) Decode(payloads []*common.Payload) ([]*common.Payload, error) {
+	decoded := make([]*common.Payload, len(payloads))
+	for i, p := range payloads {
+		decoded[i] = &common.Payload{
+			Metadata: map[string][]byte{"encoding": p.Metadata["reversed-encoding"]},
+			Data:     reversed(p.Data),
+		}
+	}
+	return decoded, nil
+}
+
+// plainTextConverter converts strings to payloads of their raw bytes.
+type plainTextConverter struct{}
+
+func (plainTextConverter) ToPayload(value interface{}) (*common.Payload, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, nil
+	}
+	return &common.Payload{Metadata: map[string][]byte{"encoding": []byte("text/plain")}, Data: []byte(s)}, nil
+}
+
+func (plainTextConverter) FromPayload(payload *common.Payload, valuePtr interface{}) error {
+	s, ok := valuePtr.(*string)
+	if !ok {
+		return fmt.Errorf("cannot decode text into %T", valuePtr)
+	}
+	*s = string(payload.Data)
+	return nil
+}
+
+func (plainTextConverter) ToString(payload *common.Payload) string {
+	return string(payload.Data)
+}
+
+func (plainTextConverter) Encoding() string {
+	return "text/plain"
+}
+
+// echoServer completes every started workflow with its input as result.
+type echoServer struct {
+	systemInfoServer
+	lock   sync.Mutex
+	input  *common.Payloads
+	header *common.Header
+}
+
+func (s *echoServer) StartWorkflowExecution(
+	ctx context.Context,
+	request *workflowservice.StartWorkflowExecutionRequest,
+) (*workflowservice.StartWorkflowExecutionResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.input = request.Input
+	s.header = request.Header
+	return &workflowservice.StartWorkflowExecutionResponse{RunId: "run"}, nil
+}
+
+func (s *echoServer) GetWorkflowExecutionHistory(
+	ctx context.Context,
+	request *workflowservice.GetWorkflowExecutionHistoryRequest,
+) (*workflowservice.GetWorkflowExecutionHistoryResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return &workflowservice.GetWorkflowExecutionHistoryResponse{History: &history.History{Events: []*history.HistoryEvent{{
+		EventId:   1,
+		EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED,
+		Attributes: &history.HistoryEvent_WorkflowExecutionCompletedEventAttributes{
+			WorkflowExecutionCompletedEventAttributes: &history.WorkflowExecutionCompletedEventAttributes{Result: s.input},
+		},
+	}}}}, nil
+}
+
+func TestDialEncodesPayloadsWithCodecs(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	echo := &echoServer{}
+	workflowservice.RegisterWorkflowServiceServer(server, echo)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	options := ClientOptions{
+		Address:       listener.Addr().String(),
+		Namespace:     "default",
+		PayloadCodecs: []converter.PayloadCodec{reversingCodec{}},
+	}
+	logger := zap.NewNop().Sugar()
+	c, err := options.Dial((&MetricsOptions{}).MustCreateMetrics(logger), logger)
+	require.NoError(t, err)
+	defer c.Close()
+
+	run, err := c.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{TaskQueue: "tq"}, "wf", "hello")
+	require.NoError(t, err)
+	// The server only sees the encoded argument
+	echo.lock.Lock()
+	sent := echo.input.GetPayloads()[0]
+	echo.lock.Unlock()
+	require.Equal(t, "binary/reversed", string(sent.Metadata["encoding"]))
+	require.Equal(t, `"olleh"`, string(sent.Data))
+	// And the result is decoded
+	var result string
+	require.NoError(t, run.Get(context.Background(), &result))
+	require.Equal(t, "hello", result)
+}
+
+func TestDialConvertsPayloadsWithDataConverter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	echo := &echoServer{}
+	workflowservice.RegisterWorkflowServiceServer(server, echo)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	execute := func(codecs ...converter.PayloadCodec) *common.Payload {
+		options := ClientOptions{
+			Address:       listener.Addr().String(),
+			Namespace:     "default",
+			DataConverter: converter.NewCompositeDataConverter(plainTextConverter{}),
+			PayloadCodecs: codecs,
+		}
+		logger := zap.NewNop().Sugar()
+		c, err := options.Dial((&MetricsOptions{}).MustCreateMetrics(logger), logger)
+		require.NoError(t, err)
+		defer c.Close()
+		run, err := c.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{TaskQueue: "tq"}, "wf", "hello")
+		require.NoError(t, err)
+		var result string
+		require.NoError(t, run.Get(context.Background(), &result))
+		require.Equal(t, "hello", result)
+		echo.lock.Lock()
+		defer echo.lock.Unlock()
+		return echo.input.GetPayloads()[0]
+	}
+
+	// Arguments are converted by the custom converter instead of as JSON
+	sent := execute()
+	require.Equal(t, "text/plain", string(sent.Metadata["encoding"]))
+	require.Equal(t, "hello", string(sent.Data))
+
+	// Codecs encode the custom converter's payloads
+	sent = execute(reversingCodec{})
+	require.Equal(t, "binary/reversed", string(sent.Metadata["encoding"]))
+	require.Equal(t, "text/plain", string(sent.Metadata["reversed-encoding"]))
+	require.Equal(t, "olleh", string(sent.Data))
+}
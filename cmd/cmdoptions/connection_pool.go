@@ -0,0 +1,76 @@
+package cmdoptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"go.temporal.io/sdk/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// connectionPool is a set of gRPC connections to the server that unary calls are spread across
+// round-robin, so that a single connection does not bottleneck a client under high concurrency.
+type connectionPool struct {
+	conns []*grpc.ClientConn
+	next  uint64
+}
+
+// dialConnectionPool dials the given number of connections with the TLS and keepalive settings of
+// the connection options.
+func dialConnectionPool(address string, size int, options client.ConnectionOptions) (*connectionPool, error) {
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if options.TLS != nil {
+		dialOptions[0] = grpc.WithTransportCredentials(credentials.NewTLS(options.TLS))
+	}
+	if options.EnableKeepAliveCheck {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                options.KeepAliveTime,
+			Timeout:             options.KeepAliveTimeout,
+			PermitWithoutStream: options.KeepAlivePermitWithoutStream,
+		}))
+	}
+	pool := &connectionPool{}
+	for i := 0; i < size; i++ {
+		conn, err := grpc.Dial(address, dialOptions...)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed dialing pooled connection: %w", err)
+		}
+		pool.conns = append(pool.conns, conn)
+	}
+	return pool, nil
+}
+
+// interceptor sends the call on the next pooled connection instead of the one it was made on.
+func (p *connectionPool) interceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	conn := p.conns[atomic.AddUint64(&p.next, 1)%uint64(len(p.conns))]
+	return conn.Invoke(ctx, method, req, reply, opts...)
+}
+
+// Close closes all pooled connections.
+func (p *connectionPool) Close() error {
+	var errs []error
+	for _, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pooledClient closes its connection pool along with the client.
+type pooledClient struct {
+	client.Client
+	pool *connectionPool
+}
+
+func (c *pooledClient) Close() {
+	c.Client.Close()
+	c.pool.Close()
+}
@@ -0,0 +1,85 @@
+package cmdoptions
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+func TestConnectionOptions(t *testing.T) {
+	tlsCfg := &tls.Config{}
+	options := (&ClientOptions{
+		KeepAliveTime:                30 * time.Second,
+		KeepAliveTimeout:             5 * time.Second,
+		KeepAlivePermitWithoutStream: true,
+	}).connectionOptions(tlsCfg)
+	require.Same(t, tlsCfg, options.TLS)
+	require.True(t, options.EnableKeepAliveCheck)
+	require.Equal(t, 30*time.Second, options.KeepAliveTime)
+	require.Equal(t, 5*time.Second, options.KeepAliveTimeout)
+	require.True(t, options.KeepAlivePermitWithoutStream)
+	require.Empty(t, options.DialOptions)
+
+	options = (&ClientOptions{}).connectionOptions(nil)
+	require.False(t, options.EnableKeepAliveCheck)
+}
+
+// peerRecordingServer records the remote address of every GetSystemInfo call.
+type peerRecordingServer struct {
+	workflowservice.UnimplementedWorkflowServiceServer
+	lock  sync.Mutex
+	peers []string
+}
+
+func (s *peerRecordingServer) GetSystemInfo(
+	ctx context.Context,
+	_ *workflowservice.GetSystemInfoRequest,
+) (*workflowservice.GetSystemInfoResponse, error) {
+	p, _ := peer.FromContext(ctx)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.peers = append(s.peers, p.Addr.String())
+	return &workflowservice.GetSystemInfoResponse{}, nil
+}
+
+func TestDialSpreadsCallsAcrossConnections(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	recorder := &peerRecordingServer{}
+	workflowservice.RegisterWorkflowServiceServer(server, recorder)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	options := ClientOptions{Address: listener.Addr().String(), Namespace: "default", Connections: 3}
+	logger := zap.NewNop().Sugar()
+	c, err := options.Dial((&MetricsOptions{}).MustCreateMetrics(logger), logger)
+	require.NoError(t, err)
+	defer c.Close()
+	for i := 0; i < 5; i++ {
+		_, err := c.WorkflowService().GetSystemInfo(context.Background(), &workflowservice.GetSystemInfoRequest{})
+		require.NoError(t, err)
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	// One call when dialing and five more, round-robin over three connections
+	require.Len(t, recorder.peers, 6)
+	counts := map[string]int{}
+	for _, p := range recorder.peers {
+		counts[p]++
+	}
+	require.Len(t, counts, 3)
+	for _, count := range counts {
+		require.Equal(t, 2, count)
+	}
+}
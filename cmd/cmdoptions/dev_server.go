@@ -0,0 +1,54 @@
+package cmdoptions
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/spf13/pflag"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/zap"
+)
+
+// DevServerOptions for running a scenario against a local dev server started for the run, using
+// the Temporal CLI's "server start-dev".
+type DevServerOptions struct {
+	// Start a dev server and connect to it instead of the client address
+	Enabled bool
+	// Path of the Temporal CLI binary (downloaded if unset)
+	BinaryPath string
+	// Frontend port (a free one if unset)
+	Port int
+}
+
+// AddCLIFlags adds the relevant flags to populate the options struct.
+func (d *DevServerOptions) AddCLIFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&d.Enabled, "dev-server", false,
+		"Start a local dev server with the Temporal CLI for the run, connect to it, and stop it after")
+	fs.StringVar(&d.BinaryPath, "dev-server-path", "",
+		"Path of the Temporal CLI binary for --dev-server (default downloaded)")
+	fs.IntVar(&d.Port, "dev-server-port", 0, "Frontend port of the --dev-server (default a free port)")
+}
+
+// Start starts a dev server with the given namespace registered and waits until it accepts
+// connections, see testsuite.StartDevServer.
+func (d *DevServerOptions) Start(ctx context.Context, namespace string, logger *zap.SugaredLogger) (*testsuite.DevServer, error) {
+	clientOptions := &client.Options{Namespace: namespace, Logger: NewZapAdapter(logger.Desugar())}
+	if d.Port != 0 {
+		clientOptions.HostPort = net.JoinHostPort("127.0.0.1", strconv.Itoa(d.Port))
+	}
+	server, err := testsuite.StartDevServer(ctx, testsuite.DevServerOptions{
+		ExistingPath:  d.BinaryPath,
+		ClientOptions: clientOptions,
+		LogLevel:      "error",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed starting dev server: %w", err)
+	}
+	// The run dials its own client
+	server.Client().Close()
+	logger.Infof("Started dev server at %v", server.FrontendHostPort())
+	return server, nil
+}
@@ -0,0 +1,16 @@
+package cmdoptions
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDevServerBinaryAbsent(t *testing.T) {
+	options := DevServerOptions{BinaryPath: filepath.Join(t.TempDir(), "temporal")}
+	_, err := options.Start(context.Background(), "default", zap.NewNop().Sugar())
+	require.ErrorContains(t, err, "failed starting dev server")
+}
@@ -0,0 +1,62 @@
+package cmdoptions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/temporalio/omes/loadgen"
+	"google.golang.org/grpc/codes"
+)
+
+// FaultInjectionOptions for injecting synthetic faults into the scenario client's RPCs.
+type FaultInjectionOptions struct {
+	// RPC method names to inject faults into (all if unset)
+	Methods []string
+	// Probability of failing a call
+	ErrorRate float64
+	// gRPC codes of injected errors, e.g. UNAVAILABLE
+	ErrorCodes []string
+	// Probability of delaying a call
+	LatencyRate float64
+	// Delay of delayed calls
+	Latency time.Duration
+	// Seed of the fault decisions
+	Seed int64
+}
+
+// FaultInjection converts these options to the loadgen fault injection config.
+func (f *FaultInjectionOptions) FaultInjection() (loadgen.FaultInjection, error) {
+	if f.ErrorRate < 0 || f.ErrorRate > 1 || f.LatencyRate < 0 || f.LatencyRate > 1 {
+		return loadgen.FaultInjection{}, fmt.Errorf("fault rates must be between 0 and 1")
+	}
+	faults := loadgen.FaultInjection{
+		Methods:     f.Methods,
+		ErrorRate:   f.ErrorRate,
+		LatencyRate: f.LatencyRate,
+		Latency:     f.Latency,
+		Seed:        f.Seed,
+	}
+	for _, name := range f.ErrorCodes {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(name)))); err != nil {
+			return loadgen.FaultInjection{}, fmt.Errorf("invalid fault error code %q", name)
+		}
+		faults.ErrorCodes = append(faults.ErrorCodes, code)
+	}
+	return faults, nil
+}
+
+// AddCLIFlags adds the relevant flags to populate the options struct.
+func (f *FaultInjectionOptions) AddCLIFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&f.Methods, "fault-methods", nil,
+		"RPC method names to inject faults into, e.g. StartWorkflowExecution (all if unset)")
+	fs.Float64Var(&f.ErrorRate, "fault-error-rate", 0, "Probability of failing a client RPC with a synthetic error")
+	fs.StringSliceVar(&f.ErrorCodes, "fault-error-codes", nil,
+		"gRPC codes of synthetic errors (default UNAVAILABLE,DEADLINE_EXCEEDED)")
+	fs.Float64Var(&f.LatencyRate, "fault-latency-rate", 0, "Probability of delaying a client RPC by --fault-latency")
+	fs.DurationVar(&f.Latency, "fault-latency", 0, "Latency added to delayed client RPCs")
+	fs.Int64Var(&f.Seed, "fault-seed", 0, "Seed of the fault injection decisions")
+}
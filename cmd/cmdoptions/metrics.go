@@ -34,31 +34,33 @@ func (h *metricsHandler) WithTags(tags map[string]string) client.MetricsHandler
 	return &metricsHandler{registry: h.registry, tags: mergedTags}
 }
 
-func (h *metricsHandler) mustRegisterIgnoreDuplicate(c prometheus.Collector) {
+// mustRegisterIgnoreDuplicate registers the collector, returning the already registered one
+// instead if there is one with the same name and tags, so that updates are not lost.
+func (h *metricsHandler) mustRegisterIgnoreDuplicate(c prometheus.Collector) prometheus.Collector {
 	err := h.registry.Register(c)
 	var alreadyRegisteredError prometheus.AlreadyRegisteredError
-	if err != nil && !errors.As(err, &alreadyRegisteredError) {
+	if errors.As(err, &alreadyRegisteredError) {
+		return alreadyRegisteredError.ExistingCollector
+	} else if err != nil {
 		panic(err)
 	}
+	return c
 }
 
 func (h *metricsHandler) Counter(name string) client.MetricsCounter {
 	ctr := prometheus.NewCounter(prometheus.CounterOpts{Name: name, ConstLabels: prometheus.Labels(h.tags)})
-	h.mustRegisterIgnoreDuplicate(ctr)
-	return metricsCounter{ctr}
+	return metricsCounter{h.mustRegisterIgnoreDuplicate(ctr).(prometheus.Counter)}
 }
 
 func (h *metricsHandler) Gauge(name string) client.MetricsGauge {
 	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, ConstLabels: prometheus.Labels(h.tags)})
-	h.mustRegisterIgnoreDuplicate(gauge)
-	return metricsGauge{gauge}
+	return metricsGauge{h.mustRegisterIgnoreDuplicate(gauge).(prometheus.Gauge)}
 }
 
 func (h *metricsHandler) Timer(name string) client.MetricsTimer {
 	// TODO: buckets
 	timer := prometheus.NewHistogram(prometheus.HistogramOpts{Name: name, ConstLabels: prometheus.Labels(h.tags)})
-	h.mustRegisterIgnoreDuplicate(timer)
-	return metricsTimer{timer}
+	return metricsTimer{h.mustRegisterIgnoreDuplicate(timer).(prometheus.Histogram)}
 }
 
 type metricsCounter struct {
@@ -101,22 +103,31 @@ type MetricsOptions struct {
 // Metrics is a component for insrumenting an application with Promethues metrics.
 type Metrics struct {
 	server   *http.Server
+	address  string
 	registry *prometheus.Registry
 }
 
 // MustCreateMetrics sets up Prometheus based metrics and starts an HTTP server
 // for serving metrics.
 func (m *MetricsOptions) MustCreateMetrics(logger *zap.SugaredLogger) *Metrics {
-	registry := prometheus.NewRegistry()
-	var server *http.Server
+	metrics := &Metrics{registry: prometheus.NewRegistry()}
 	if m.PrometheusListenAddress != "" {
-		registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-		server = m.mustInitPrometheusServer(logger, registry)
-	}
-	return &Metrics{
-		server:   server,
-		registry: registry,
+		metrics.registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		m.mustInitPrometheusServer(logger, metrics)
 	}
+	return metrics
+}
+
+// Handler returns an HTTP handler serving all metrics recorded so far in the Prometheus
+// exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Address returns the address the Prometheus HTTP listener is bound to, or empty if no listen
+// address was provided.
+func (m *Metrics) Address() string {
+	return m.address
 }
 
 // Handler returns a new Temporal-client-compatible metrics handler.
@@ -136,7 +147,7 @@ func (m *Metrics) Shutdown(ctx context.Context) error {
 	return m.server.Shutdown(ctx)
 }
 
-func (m *MetricsOptions) mustInitPrometheusServer(logger *zap.SugaredLogger, registry *prometheus.Registry) *http.Server {
+func (m *MetricsOptions) mustInitPrometheusServer(logger *zap.SugaredLogger, metrics *Metrics) {
 	address := m.PrometheusListenAddress
 	handlerPath := m.PrometheusHandlerPath
 	if handlerPath == "" {
@@ -144,13 +155,16 @@ func (m *MetricsOptions) mustInitPrometheusServer(logger *zap.SugaredLogger, reg
 	}
 
 	handler := http.NewServeMux()
-	handler.Handle(handlerPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	handler.Handle(handlerPath, metrics.Handler())
 
 	server := &http.Server{Addr: address, Handler: handler}
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
 		logger.Fatalf("Failed to initialize Prometheus HTTP listener on %s: %v", address, err)
 	}
+	metrics.server = server
+	metrics.address = listener.Addr().String()
+	logger.Infof("Serving Prometheus metrics on http://%s%s", metrics.address, handlerPath)
 
 	go func() {
 		err := server.Serve(listener)
@@ -158,8 +172,6 @@ func (m *MetricsOptions) mustInitPrometheusServer(logger *zap.SugaredLogger, reg
 			logger.Fatalf("Fatal error in Prometheus HTTP server: %v", err)
 		}
 	}()
-
-	return server
 }
 
 // AddCLIFlags adds the relevant flags to populate the options struct.
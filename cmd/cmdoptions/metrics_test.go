@@ -0,0 +1,58 @@
+package cmdoptions
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen"
+	"go.uber.org/zap"
+)
+
+func scrape(t *testing.T, url string) string {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricsHandlerServesRecordedMetrics(t *testing.T) {
+	metrics := (&MetricsOptions{}).MustCreateMetrics(zap.NewNop().Sugar())
+	info := loadgen.ScenarioInfo{ScenarioName: "test", MetricsHandler: metrics.NewHandler()}
+	info.RecordCounter("omes_test_counter", nil, 2)
+	info.RecordCounter("omes_test_counter", nil, 3)
+	info.RecordGauge("omes_test_gauge", map[string]string{"queue": "q"}, 7)
+	info.RecordTimer("omes_test_timer", nil, time.Second)
+
+	server := httptest.NewServer(metrics.Handler())
+	defer server.Close()
+	exposition := scrape(t, server.URL)
+	require.Contains(t, exposition, `omes_test_counter{scenario="test"} 5`)
+	require.Contains(t, exposition, `omes_test_gauge{queue="q",scenario="test"} 7`)
+	require.Contains(t, exposition, `omes_test_timer_count{scenario="test"} 1`)
+	require.Contains(t, exposition, `omes_test_timer_sum{scenario="test"} 1`)
+}
+
+func TestMetricsListenerServesUntilShutdown(t *testing.T) {
+	metrics := (&MetricsOptions{
+		PrometheusListenAddress: "127.0.0.1:0",
+		PrometheusHandlerPath:   "/metrics",
+	}).MustCreateMetrics(zap.NewNop().Sugar())
+	metrics.NewHandler().Counter("omes_test_counter").Inc(1)
+
+	url := "http://" + metrics.Address() + "/metrics"
+	exposition := scrape(t, url)
+	require.Contains(t, exposition, "omes_test_counter 1")
+	require.Contains(t, exposition, "process_cpu_seconds_total")
+
+	require.NoError(t, metrics.Shutdown(context.Background()))
+	_, err := http.Get(url)
+	require.Error(t, err)
+}
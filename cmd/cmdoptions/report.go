@@ -0,0 +1,66 @@
+package cmdoptions
+
+import (
+	"os"
+
+	"github.com/spf13/pflag"
+	"github.com/temporalio/omes/loadgen"
+)
+
+// ReportOptions for selecting where the end-of-run report is written.
+type ReportOptions struct {
+	// Path of a file to write the report to
+	FilePath string
+	// Write the report to stdout
+	Stdout bool
+	// URL to POST the report to
+	URL string
+	// Path of a SQLite database to append the report to
+	SQLitePath string
+	// Report format (json csv)
+	Format string
+	// Path of a CSV file to export per-iteration latency samples to
+	SamplesFilePath string
+	// Path of a file to export per-iteration segment breakdowns to in folded stack format
+	SegmentBreakdownFilePath string
+	// Path of a file to append every iteration failure to as JSON lines
+	ErrorLogFilePath string
+}
+
+// Sinks builds the configured report sinks.
+func (r *ReportOptions) Sinks() ([]loadgen.ReportSink, error) {
+	format, err := loadgen.ParseReportFormat(r.Format)
+	if err != nil {
+		return nil, err
+	}
+	var sinks []loadgen.ReportSink
+	if r.FilePath != "" {
+		sinks = append(sinks, &loadgen.FileReportSink{Path: r.FilePath, Format: format})
+	}
+	if r.Stdout {
+		sinks = append(sinks, &loadgen.WriterReportSink{Writer: os.Stdout, Format: format})
+	}
+	if r.URL != "" {
+		sinks = append(sinks, &loadgen.HTTPReportSink{URL: r.URL, Format: format})
+	}
+	if r.SQLitePath != "" {
+		sinks = append(sinks, &loadgen.SQLiteReportSink{Path: r.SQLitePath})
+	}
+	return sinks, nil
+}
+
+// AddCLIFlags adds the relevant flags to populate the options struct.
+func (r *ReportOptions) AddCLIFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&r.FilePath, "report-file", "", "Write the end-of-run report to this file")
+	fs.BoolVar(&r.Stdout, "report-stdout", false, "Write the end-of-run report to stdout")
+	fs.StringVar(&r.URL, "report-url", "", "POST the end-of-run report to this URL")
+	fs.StringVar(&r.SQLitePath, "report-sqlite", "",
+		"Append the end-of-run report to this SQLite database, created if absent (requires the sqlite3 tool)")
+	fs.StringVar(&r.Format, "report-format", "json", "Format of the end-of-run report (json csv)")
+	fs.StringVar(&r.SamplesFilePath, "latency-samples-file", "",
+		"Stream every iteration's latency, start time and outcome to this CSV file")
+	fs.StringVar(&r.SegmentBreakdownFilePath, "segment-breakdown-file", "",
+		"Stream every iteration's segment breakdown to this file in folded stack format for flame graphs")
+	fs.StringVar(&r.ErrorLogFilePath, "error-log-file", "",
+		"Append every iteration failure (iteration, workflow ID, category and error) to this file as JSON lines")
+}
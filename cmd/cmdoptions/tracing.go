@@ -0,0 +1,194 @@
+package cmdoptions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"github.com/temporalio/omes/loadgen"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/interceptor"
+)
+
+// tracingHeaderKey is the Temporal header key the span context is written to, the default of the
+// SDK's OpenTelemetry tracing interceptor so workers using it continue the iteration traces.
+const tracingHeaderKey = "_tracer-data"
+
+// TracingOptions for exporting a span per iteration to an OpenTelemetry collector over OTLP.
+type TracingOptions struct {
+	// OTLP gRPC endpoint of the collector, as host:port (no tracing if unset)
+	Endpoint string
+	// Connect to the endpoint without TLS
+	Insecure bool
+	// Fraction of iterations traced
+	SampleRatio float64
+}
+
+// AddCLIFlags adds the relevant flags to populate the options struct.
+func (t *TracingOptions) AddCLIFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&t.Endpoint, "tracing-otlp-endpoint", "",
+		"OTLP gRPC endpoint (host:port) of an OpenTelemetry collector to export a span per iteration to (no tracing if unset)")
+	fs.BoolVar(&t.Insecure, "tracing-insecure", false, "Connect to --tracing-otlp-endpoint without TLS")
+	fs.Float64Var(&t.SampleRatio, "tracing-sample-ratio", 1, "Fraction of iterations traced")
+}
+
+// Tracer creates the iteration tracer exporting to the endpoint, nil if there is none. The returned
+// shutdown function exports the remaining spans and must be called once the run ends.
+func (t *TracingOptions) Tracer(ctx context.Context) (loadgen.IterationTracer, func(context.Context) error, error) {
+	if t.Endpoint == "" {
+		return nil, func(context.Context) error { return nil }, nil
+	}
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		return nil, nil, fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+	exporterOptions := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(t.Endpoint)}
+	if t.Insecure {
+		exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed creating OTLP trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "omes"))),
+	)
+	return newIterationTracer(provider), provider.Shutdown, nil
+}
+
+// ClientInterceptors returns the SDK client interceptors propagating the span context of iterations
+// to the workflows they start, signal, query and update, none if there is no tracing.
+func (t *TracingOptions) ClientInterceptors() []interceptor.ClientInterceptor {
+	if t.Endpoint == "" {
+		return nil
+	}
+	return []interceptor.ClientInterceptor{&tracePropagationInterceptor{}}
+}
+
+// tracePropagationInterceptor writes the span context of calls to their Temporal header like the
+// SDK's OpenTelemetry tracing interceptor, as a JSON W3C trace context map, without opening spans
+// of its own.
+type tracePropagationInterceptor struct {
+	interceptor.ClientInterceptorBase
+}
+
+func (t *tracePropagationInterceptor) InterceptClient(
+	next interceptor.ClientOutboundInterceptor,
+) interceptor.ClientOutboundInterceptor {
+	i := &tracePropagationClientOutboundInterceptor{}
+	i.Next = next
+	return i
+}
+
+type tracePropagationClientOutboundInterceptor struct {
+	interceptor.ClientOutboundInterceptorBase
+}
+
+func (t *tracePropagationClientOutboundInterceptor) ExecuteWorkflow(
+	ctx context.Context,
+	in *interceptor.ClientExecuteWorkflowInput,
+) (client.WorkflowRun, error) {
+	if err := writeSpanContextToHeader(ctx); err != nil {
+		return nil, err
+	}
+	return t.Next.ExecuteWorkflow(ctx, in)
+}
+
+func (t *tracePropagationClientOutboundInterceptor) SignalWorkflow(
+	ctx context.Context,
+	in *interceptor.ClientSignalWorkflowInput,
+) error {
+	if err := writeSpanContextToHeader(ctx); err != nil {
+		return err
+	}
+	return t.Next.SignalWorkflow(ctx, in)
+}
+
+func (t *tracePropagationClientOutboundInterceptor) SignalWithStartWorkflow(
+	ctx context.Context,
+	in *interceptor.ClientSignalWithStartWorkflowInput,
+) (client.WorkflowRun, error) {
+	if err := writeSpanContextToHeader(ctx); err != nil {
+		return nil, err
+	}
+	return t.Next.SignalWithStartWorkflow(ctx, in)
+}
+
+func (t *tracePropagationClientOutboundInterceptor) QueryWorkflow(
+	ctx context.Context,
+	in *interceptor.ClientQueryWorkflowInput,
+) (converter.EncodedValue, error) {
+	if err := writeSpanContextToHeader(ctx); err != nil {
+		return nil, err
+	}
+	return t.Next.QueryWorkflow(ctx, in)
+}
+
+func (t *tracePropagationClientOutboundInterceptor) UpdateWorkflow(
+	ctx context.Context,
+	in *interceptor.ClientUpdateWorkflowInput,
+) (client.WorkflowUpdateHandle, error) {
+	if err := writeSpanContextToHeader(ctx); err != nil {
+		return nil, err
+	}
+	return t.Next.UpdateWorkflow(ctx, in)
+}
+
+// writeSpanContextToHeader writes the span context of ctx, if any, to the Temporal header of the
+// call.
+func writeSpanContextToHeader(ctx context.Context) error {
+	header := interceptor.Header(ctx)
+	if header == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	payload, err := converter.GetDefaultDataConverter().ToPayload(map[string]string(carrier))
+	if err != nil {
+		return fmt.Errorf("failed encoding span context: %w", err)
+	}
+	header[tracingHeaderKey] = payload
+	return nil
+}
+
+// iterationTracer is a loadgen.IterationTracer opening an OpenTelemetry span per iteration.
+type iterationTracer struct {
+	tracer trace.Tracer
+}
+
+func newIterationTracer(provider trace.TracerProvider) *iterationTracer {
+	return &iterationTracer{tracer: provider.Tracer("github.com/temporalio/omes")}
+}
+
+func (i *iterationTracer) StartIteration(
+	ctx context.Context,
+	attributes map[string]string,
+) (context.Context, func(error)) {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	spanAttributes := make([]attribute.KeyValue, 0, len(names))
+	for _, name := range names {
+		spanAttributes = append(spanAttributes, attribute.String(name, attributes[name]))
+	}
+	ctx, span := i.tracer.Start(ctx, "iteration", trace.WithAttributes(spanAttributes...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,100 @@
+package cmdoptions
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestIterationTracerSpanPerIteration(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	info := loadgen.NewTestScenarioInfo(&loadgen.FakeClient{}, loadgen.RunConfiguration{Iterations: 3, MaxConcurrent: 1})
+	info.Tracer = newIterationTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	err := (&loadgen.GenericExecutor{
+		Execute: func(ctx context.Context, run *loadgen.Run) error {
+			if run.Iteration == 3 {
+				return errors.New("deliberate fail from test")
+			}
+			return nil
+		},
+	}).Run(context.Background(), info)
+	require.ErrorContains(t, err, "deliberate fail from test")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for i, span := range spans {
+		require.Equal(t, "iteration", span.Name())
+		require.Contains(t, span.Attributes(), attribute.String("scenario", "test"))
+		require.Contains(t, span.Attributes(), attribute.String("iteration", []string{"1", "2", "3"}[i]))
+		require.Contains(t, span.Attributes(), attribute.String("workflow_id", "w-test-run-"+[]string{"1", "2", "3"}[i]))
+	}
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Equal(t, codes.Error, spans[2].Status().Code)
+	require.Len(t, spans[2].Events(), 1)
+}
+
+func TestTracingDisabledWithoutEndpoint(t *testing.T) {
+	tracer, shutdown, err := (&TracingOptions{}).Tracer(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, tracer)
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestTracingPropagatesIterationSpanToWorkflow(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	echo := &echoServer{}
+	workflowservice.RegisterWorkflowServiceServer(server, echo)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	tracing := &TracingOptions{Endpoint: "collector:4317"}
+	options := ClientOptions{
+		Address:      listener.Addr().String(),
+		Namespace:    "default",
+		Interceptors: tracing.ClientInterceptors(),
+	}
+	logger := zap.NewNop().Sugar()
+	c, err := options.Dial((&MetricsOptions{}).MustCreateMetrics(logger), logger)
+	require.NoError(t, err)
+	defer c.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	info := loadgen.NewTestScenarioInfo(c, loadgen.RunConfiguration{Iterations: 1})
+	info.Tracer = newIterationTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	err = (&loadgen.GenericExecutor{
+		Execute: func(ctx context.Context, run *loadgen.Run) error {
+			_, err := run.Client.ExecuteWorkflow(ctx, run.DefaultStartWorkflowOptions(), "wf")
+			return err
+		},
+	}).Run(context.Background(), info)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	echo.lock.Lock()
+	payload := echo.header.GetFields()[tracingHeaderKey]
+	echo.lock.Unlock()
+	require.NotNil(t, payload)
+	var carrier map[string]string
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayload(payload, &carrier))
+	spanContext := spans[0].SpanContext()
+	require.Equal(t, "00-"+spanContext.TraceID().String()+"-"+spanContext.SpanID().String()+"-01", carrier["traceparent"])
+}
+
+func TestTracingClientInterceptorsDisabledWithoutEndpoint(t *testing.T) {
+	require.Empty(t, (&TracingOptions{}).ClientInterceptors())
+}
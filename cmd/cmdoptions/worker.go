@@ -1,7 +1,10 @@
 package cmdoptions
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -12,6 +15,13 @@ type WorkerOptions struct {
 	MaxConcurrentWorkflowPollers int
 	MaxConcurrentActivities      int
 	MaxConcurrentWorkflowTasks   int
+	BuildID                      string
+	// TaskQueueActivitiesPerSecond is the server-side activity dispatch rate limit the worker
+	// requests for its task queues. Zero leaves the task queue unlimited.
+	TaskQueueActivitiesPerSecond float64
+	// StickyScheduleToStartTimeout is how long a workflow task waits on the worker's sticky queue
+	// before the server retries it on the normal task queue. Zero leaves the SDK default.
+	StickyScheduleToStartTimeout time.Duration
 }
 
 // AddCLIFlags adds the relevant flags to populate the options struct.
@@ -20,10 +30,37 @@ func (m *WorkerOptions) AddCLIFlags(fs *pflag.FlagSet, prefix string) {
 	fs.IntVar(&m.MaxConcurrentWorkflowPollers, prefix+"max-concurrent-workflow-pollers", 0, "Max concurrent workflow pollers")
 	fs.IntVar(&m.MaxConcurrentActivities, prefix+"max-concurrent-activities", 0, "Max concurrent activities")
 	fs.IntVar(&m.MaxConcurrentWorkflowTasks, prefix+"max-concurrent-workflow-tasks", 0, "Max concurrent workflow tasks")
+	fs.StringVar(&m.BuildID, prefix+"build-id", "",
+		"Build ID to version the worker with (unversioned if unset, Go worker only)")
+	fs.Float64Var(&m.TaskQueueActivitiesPerSecond, prefix+"task-queue-activities-per-second", 0,
+		"Server-side activity dispatch rate limit per task queue (unlimited if unset)")
+	fs.DurationVar(&m.StickyScheduleToStartTimeout, prefix+"sticky-schedule-to-start-timeout", 0,
+		"Timeout of workflow tasks on the sticky queue before retrying them on the normal task queue (Go worker only)")
 }
 
-// ToFlags converts these options to string flags.
-func (m *WorkerOptions) ToFlags() (flags []string) {
+// goOnlyWorkerOptions returns the names of the set options only the Go worker supports.
+func (m *WorkerOptions) goOnlyWorkerOptions() (names []string) {
+	if m.BuildID != "" {
+		names = append(names, "build ID")
+	}
+	if m.StickyScheduleToStartTimeout != 0 {
+		names = append(names, "sticky schedule to start timeout")
+	}
+	return
+}
+
+// CheckLanguage returns an error if options are set that the worker of the given language does not
+// support.
+func (m *WorkerOptions) CheckLanguage(language string) error {
+	if names := m.goOnlyWorkerOptions(); language != "go" && len(names) > 0 {
+		return fmt.Errorf("%v only supported by the Go worker, not %v", strings.Join(names, ", "), language)
+	}
+	return nil
+}
+
+// ToFlags converts these options to string flags for the worker of the given language, leaving out
+// options it does not support, see CheckLanguage.
+func (m *WorkerOptions) ToFlags(language string) (flags []string) {
 	if m.MaxConcurrentActivityPollers != 0 {
 		flags = append(flags, "--max-concurrent-activity-pollers", strconv.Itoa(m.MaxConcurrentActivityPollers))
 	}
@@ -36,5 +73,15 @@ func (m *WorkerOptions) ToFlags() (flags []string) {
 	if m.MaxConcurrentWorkflowTasks != 0 {
 		flags = append(flags, "--max-concurrent-workflow-tasks", strconv.Itoa(m.MaxConcurrentWorkflowTasks))
 	}
+	if m.BuildID != "" && language == "go" {
+		flags = append(flags, "--build-id", m.BuildID)
+	}
+	if m.TaskQueueActivitiesPerSecond != 0 {
+		flags = append(flags, "--task-queue-activities-per-second",
+			strconv.FormatFloat(m.TaskQueueActivitiesPerSecond, 'f', -1, 64))
+	}
+	if m.StickyScheduleToStartTimeout != 0 && language == "go" {
+		flags = append(flags, "--sticky-schedule-to-start-timeout", m.StickyScheduleToStartTimeout.String())
+	}
 	return
 }
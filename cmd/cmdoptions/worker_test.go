@@ -0,0 +1,41 @@
+package cmdoptions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerOptionsFlagsRoundTrip(t *testing.T) {
+	options := WorkerOptions{
+		MaxConcurrentActivityPollers: 1,
+		MaxConcurrentWorkflowPollers: 2,
+		MaxConcurrentActivities:      3,
+		MaxConcurrentWorkflowTasks:   4,
+		BuildID:                      "build",
+		TaskQueueActivitiesPerSecond: 2.5,
+		StickyScheduleToStartTimeout: 3 * time.Second,
+	}
+	var parsed WorkerOptions
+	fs := pflag.NewFlagSet("worker", pflag.ContinueOnError)
+	parsed.AddCLIFlags(fs, "")
+	require.NoError(t, fs.Parse(options.ToFlags("go")))
+	require.Equal(t, options, parsed)
+
+	require.Empty(t, (&WorkerOptions{}).ToFlags("go"))
+}
+
+func TestWorkerOptionsFlagsPerLanguage(t *testing.T) {
+	options := WorkerOptions{MaxConcurrentActivities: 3, BuildID: "build", StickyScheduleToStartTimeout: time.Second}
+	require.Equal(t, []string{"--max-concurrent-activities", "3", "--build-id", "build",
+		"--sticky-schedule-to-start-timeout", "1s"}, options.ToFlags("go"))
+	require.NoError(t, options.CheckLanguage("go"))
+	for _, language := range []string{"java", "python"} {
+		require.Equal(t, []string{"--max-concurrent-activities", "3"}, options.ToFlags(language))
+		require.EqualError(t, options.CheckLanguage(language),
+			"build ID, sticky schedule to start timeout only supported by the Go worker, not "+language)
+	}
+	require.NoError(t, (&WorkerOptions{MaxConcurrentActivities: 3}).CheckLanguage("java"))
+}
@@ -18,8 +18,7 @@ func listScenariosCmd() *cobra.Command {
 			for name, scen := range loadgen.GetScenarios() {
 				var defaultConfigDesc string
 				if iface, _ := scen.Executor.(loadgen.HasDefaultConfiguration); iface != nil {
-					config := iface.GetDefaultConfiguration()
-					config.ApplyDefaults()
+					config := loadgen.EffectiveRunConfiguration(iface.GetDefaultConfiguration(), loadgen.RunConfiguration{})
 					defaultConfigDesc = "\n    Default configuration:"
 					if config.Iterations != 0 {
 						defaultConfigDesc += fmt.Sprintf("\n        Iterations: %v", config.Iterations)
@@ -30,6 +29,16 @@ func listScenariosCmd() *cobra.Command {
 					if config.MaxConcurrent != 0 {
 						defaultConfigDesc += fmt.Sprintf("\n        Max concurrent: %v", config.MaxConcurrent)
 					}
+					if config.MaxIterationsPerSecond != 0 {
+						defaultConfigDesc += fmt.Sprintf("\n        Max iterations per second: %v", config.MaxIterationsPerSecond)
+					}
+					for _, phase := range config.Phases {
+						defaultConfigDesc += fmt.Sprintf("\n        Phase %v: duration %v, max concurrent %v",
+							phase.Name, phase.Duration, phase.MaxConcurrent)
+						if phase.MaxIterationsPerSecond != 0 {
+							defaultConfigDesc += fmt.Sprintf(", max iterations per second %v", phase.MaxIterationsPerSecond)
+						}
+					}
 				}
 				descs = append(descs, fmt.Sprintf("Scenario: %v\n    Description: %v%v\n",
 					name, scen.Description, defaultConfigDesc))
@@ -17,6 +17,7 @@ func main() {
 	rootCmd.AddCommand(buildWorkerImageCmd())
 	rootCmd.AddCommand(cleanupScenarioCmd())
 	rootCmd.AddCommand(listScenariosCmd())
+	rootCmd.AddCommand(mergeResultsCmd())
 	rootCmd.AddCommand(prepareWorkerCmd())
 	rootCmd.AddCommand(runScenarioCmd())
 	rootCmd.AddCommand(runScenarioWithWorkerCmd())
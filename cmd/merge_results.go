@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/temporalio/omes/cmd/cmdoptions"
+	"github.com/temporalio/omes/loadgen"
+	"go.uber.org/zap"
+)
+
+func mergeResultsCmd() *cobra.Command {
+	var m resultMerger
+	cmd := &cobra.Command{
+		Use:   "merge-results <report.json>...",
+		Short: "Combine JSON run reports of several omes instances into one",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := m.run(args); err != nil {
+				m.logger.Fatal(err)
+			}
+		},
+	}
+	m.addCLIFlags(cmd.Flags())
+	return cmd
+}
+
+type resultMerger struct {
+	logger         *zap.SugaredLogger
+	format         string
+	outputPath     string
+	loggingOptions cmdoptions.LoggingOptions
+}
+
+func (m *resultMerger) addCLIFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&m.format, "report-format", "json", "Format of the combined report: json or csv")
+	fs.StringVar(&m.outputPath, "output", "", "File to write the combined report to (default stdout)")
+	m.loggingOptions.AddCLIFlags(fs)
+}
+
+func (m *resultMerger) run(paths []string) error {
+	m.logger = m.loggingOptions.MustCreateLogger()
+	format, err := loadgen.ParseReportFormat(m.format)
+	if err != nil {
+		return err
+	}
+	results := make([]*loadgen.RunResult, len(paths))
+	for i, path := range paths {
+		if results[i], err = loadgen.ReadRunResult(path); err != nil {
+			return err
+		}
+	}
+	merged, err := loadgen.MergeRunResults(results...)
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if m.outputPath != "" {
+		if out, err = os.Create(m.outputPath); err != nil {
+			return fmt.Errorf("failed creating combined report: %w", err)
+		}
+		defer out.Close()
+	}
+	return format.Encode(out, merged)
+}
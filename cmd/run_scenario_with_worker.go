@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/temporalio/omes/cmd/cmdoptions"
 	"github.com/temporalio/omes/cmd/scenariorunner"
+	"github.com/temporalio/omes/loadgen"
 )
 
 func runScenarioWithWorkerCmd() *cobra.Command {
@@ -32,20 +33,95 @@ func runScenarioWithWorkerCmd() *cobra.Command {
 
 type workerWithScenarioRunner struct {
 	workerRunner
-	iterations      int
-	duration        time.Duration
-	maxConcurrent   int
-	scenarioOptions []string
-	metricsOptions  cmdoptions.MetricsOptions
+	idPrefix                  string
+	iterations                int
+	duration                  time.Duration
+	maxConcurrent             int
+	skipLateIterations        bool
+	deadlineTolerance         time.Duration
+	shuffleIterations         bool
+	gracePeriod               time.Duration
+	progressInterval          time.Duration
+	throughputWindow          time.Duration
+	throughputGauge           bool
+	maxBacklog                int64
+	batchSize                 int
+	batchPause                time.Duration
+	batchTimeout              time.Duration
+	iterationRetries          int
+	retryableErrors           []string
+	arrivalTrace              string
+	maxConcurrentAwaits       int
+	maxTotalStarts            int
+	awaitPollersTimeout       time.Duration
+	maxScheduleToStartLatency time.Duration
+	taskQueueStatsInterval    time.Duration
+	minThroughput             float64
+	thinkTime                 time.Duration
+	thinkTimeJitter           time.Duration
+	healthCheckInterval       time.Duration
+	workerMetricsURL          string
+	workerMetricsInterval     time.Duration
+	scenarioOptions           []string
+	metricsOptions            cmdoptions.MetricsOptions
+	reportOptions             cmdoptions.ReportOptions
+	faultOptions              cmdoptions.FaultInjectionOptions
 }
 
 func (r *workerWithScenarioRunner) addCLIFlags(fs *pflag.FlagSet) {
 	r.workerRunner.addCLIFlags(fs)
+	fs.StringVar(&r.idPrefix, "id-prefix", loadgen.DefaultIDPrefix, "Prefix of workflow IDs, before the run ID")
 	fs.IntVar(&r.iterations, "iterations", 0, "Override default iterations for the scenario (cannot be provided with duration)")
 	fs.DurationVar(&r.duration, "duration", 0, "Override duration for the scenario (cannot be provided with iteration)")
 	fs.IntVar(&r.maxConcurrent, "max-concurrent", 0, "Override max-concurrent for the scenario")
+	fs.BoolVar(&r.skipLateIterations, "skip-late-iterations", false,
+		"Do not start iterations estimated to end after the duration by more than the deadline tolerance")
+	fs.DurationVar(&r.deadlineTolerance, "deadline-tolerance", 0, "Tolerance for --skip-late-iterations")
+	fs.BoolVar(&r.shuffleIterations, "shuffle-iterations", false,
+		"Dispatch iterations in an order shuffled by the run's seed (requires iterations)")
+	fs.DurationVar(&r.gracePeriod, "grace-period", 0,
+		"Time to wait for in-flight iterations after the duration before abandoning them (default 30s)")
+	fs.DurationVar(&r.progressInterval, "progress-interval", 0,
+		"How often to log progress and windowed throughput (no progress logging if unset)")
+	fs.DurationVar(&r.throughputWindow, "throughput-window", 0, "Window of the throughput logged with progress (default 30s)")
+	fs.BoolVar(&r.throughputGauge, "throughput-gauge", false, "Also emit the windowed throughput as the omes_throughput gauge")
+	fs.Int64Var(&r.maxBacklog, "max-backlog", 0,
+		"Hold back new iterations while the task queue's workflow task backlog exceeds this (no limit if unset)")
+	fs.IntVar(&r.batchSize, "batch-size", 0,
+		"Run iterations in batches of this size, waiting for each batch to complete before the next")
+	fs.DurationVar(&r.batchPause, "batch-pause", 0, "Pause between batches of --batch-size")
+	fs.DurationVar(&r.batchTimeout, "batch-timeout", 0,
+		"Maximum time to wait for a batch of --batch-size to complete before pausing (default no limit)")
+	fs.IntVar(&r.iterationRetries, "iteration-retries", 0,
+		"Maximum retries of an iteration failing with an error matching --retryable-error (default 3)")
+	fs.StringArrayVar(&r.retryableErrors, "retryable-error", nil,
+		"Retry iterations failing with an error containing this, or matching it if prefixed with regex: (repeatable)")
+	fs.StringVar(&r.arrivalTrace, "arrival-trace", "",
+		"CSV file of iteration start offsets from the run start to replay (runs the whole trace unless limited)")
+	fs.IntVar(&r.maxConcurrentAwaits, "max-concurrent-awaits", 0,
+		"Max workflows to await concurrently for scenarios starting and awaiting separately (default max-concurrent)")
+	fs.IntVar(&r.maxTotalStarts, "max-total-starts", 0,
+		"Stop starting iterations once this many were started, even if the duration has not elapsed")
+	fs.DurationVar(&r.awaitPollersTimeout, "await-pollers-timeout", 0,
+		"Before starting load, wait up to this long for workflow pollers on each of the run's task queues")
+	fs.DurationVar(&r.maxScheduleToStartLatency, "max-schedule-to-start-latency", 0,
+		"Lower the start rate while the run's workflow task schedule-to-start latency exceeds this (no limit if unset)")
+	fs.DurationVar(&r.taskQueueStatsInterval, "task-queue-stats-interval", 0,
+		"Snapshot server-side task queue stats at this interval and include the time series in the report")
+	fs.Float64Var(&r.minThroughput, "min-throughput", 0,
+		"Fail the run if its steady-state throughput in iterations/sec is below this (no floor if unset)")
+	fs.DurationVar(&r.thinkTime, "think-time", 0,
+		"Time each iteration holds its concurrency slot after completing, modeling user think time in closed-loop load")
+	fs.DurationVar(&r.thinkTimeJitter, "think-time-jitter", 0, "Random extra think time of up to this duration per iteration")
+	fs.DurationVar(&r.healthCheckInterval, "health-check-interval", 0,
+		"Check cluster health at this interval and pause new starts while unhealthy (disabled if zero)")
+	fs.StringVar(&r.workerMetricsURL, "worker-metrics-url", "",
+		"URL of a worker Prometheus metrics endpoint to scrape during the run, summarized in the report")
+	fs.DurationVar(&r.workerMetricsInterval, "worker-metrics-interval", 0, "Interval of scrapes of --worker-metrics-url (default 10s)")
 	fs.StringSliceVar(&r.scenarioOptions, "option", nil, "Additional options for the scenario, in key=value format")
 	r.metricsOptions.AddCLIFlags(fs, "")
+	r.reportOptions.AddCLIFlags(fs)
+	r.faultOptions.AddCLIFlags(fs)
 }
 
 func (r *workerWithScenarioRunner) run(ctx context.Context) error {
@@ -64,16 +140,44 @@ func (r *workerWithScenarioRunner) run(ctx context.Context) error {
 
 	// Run scenario
 	scenarioRunner := scenariorunner.ScenarioRunner{
-		Logger:          r.logger,
-		Scenario:        r.scenario,
-		RunID:           r.runID,
-		Iterations:      r.iterations,
-		Duration:        r.duration,
-		MaxConcurrent:   r.maxConcurrent,
-		ScenarioOptions: r.scenarioOptions,
-		ClientOptions:   r.clientOptions,
-		MetricsOptions:  r.metricsOptions,
-		LoggingOptions:  r.loggingOptions,
+		Logger:                    r.logger,
+		Scenario:                  r.scenario,
+		RunID:                     r.runID,
+		IDPrefix:                  r.idPrefix,
+		Iterations:                r.iterations,
+		Duration:                  r.duration,
+		MaxConcurrent:             r.maxConcurrent,
+		SkipLateIterations:        r.skipLateIterations,
+		DeadlineTolerance:         r.deadlineTolerance,
+		ShuffleIterations:         r.shuffleIterations,
+		GracePeriod:               r.gracePeriod,
+		ProgressInterval:          r.progressInterval,
+		ThroughputWindow:          r.throughputWindow,
+		ThroughputGauge:           r.throughputGauge,
+		MaxBacklog:                r.maxBacklog,
+		BatchSize:                 r.batchSize,
+		BatchPause:                r.batchPause,
+		BatchTimeout:              r.batchTimeout,
+		IterationRetries:          r.iterationRetries,
+		RetryableErrors:           r.retryableErrors,
+		ArrivalTrace:              r.arrivalTrace,
+		MaxConcurrentAwaits:       r.maxConcurrentAwaits,
+		MaxTotalStarts:            r.maxTotalStarts,
+		AwaitPollersTimeout:       r.awaitPollersTimeout,
+		MaxScheduleToStartLatency: r.maxScheduleToStartLatency,
+		TaskQueueStatsInterval:    r.taskQueueStatsInterval,
+		MinThroughput:             r.minThroughput,
+		ThinkTime:                 r.thinkTime,
+		ThinkTimeJitter:           r.thinkTimeJitter,
+		HealthCheckInterval:       r.healthCheckInterval,
+		WorkerMetricsURL:          r.workerMetricsURL,
+		WorkerMetricsInterval:     r.workerMetricsInterval,
+		ScenarioOptions:           r.scenarioOptions,
+		ClientOptions:             r.clientOptions,
+		MetricsOptions:            r.metricsOptions,
+		LoggingOptions:            r.loggingOptions,
+		ReportOptions:             r.reportOptions,
+		FaultOptions:              r.faultOptions,
 	}
 	scenarioErr := scenarioRunner.Run(ctx)
 	cancel()
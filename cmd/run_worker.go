@@ -80,6 +80,9 @@ func (r *workerRunner) run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if err := r.workerOptions.CheckLanguage(lang); err != nil {
+		return err
+	}
 	scenario := loadgen.GetScenario(r.scenario)
 	if scenario == nil {
 		return fmt.Errorf("scenario %v not found", r.scenario)
@@ -168,7 +171,7 @@ func (r *workerRunner) run(ctx context.Context) error {
 	args = append(args, r.clientOptions.ToFlags()...)
 	args = append(args, r.metricsOptions.ToFlags()...)
 	args = append(args, r.loggingOptions.ToFlags()...)
-	args = append(args, r.workerOptions.ToFlags()...)
+	args = append(args, r.workerOptions.ToFlags(lang)...)
 
 	// Start the command. Do not use the context so we can send interrupt.
 	cmd, err := prog.NewCommand(context.Background(), args...)
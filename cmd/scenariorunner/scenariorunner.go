@@ -3,6 +3,8 @@ package scenariorunner
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -16,30 +18,115 @@ import (
 )
 
 type ScenarioRunner struct {
-	Logger          *zap.SugaredLogger
-	Scenario        string
-	RunID           string
-	Iterations      int
-	Duration        time.Duration
-	MaxConcurrent   int
-	ScenarioOptions []string
-	ConnectTimeout  time.Duration
-	ClientOptions   cmdoptions.ClientOptions
-	MetricsOptions  cmdoptions.MetricsOptions
-	LoggingOptions  cmdoptions.LoggingOptions
+	Logger                    *zap.SugaredLogger
+	Scenario                  string
+	RunID                     string
+	IDPrefix                  string
+	Iterations                int
+	Duration                  time.Duration
+	MaxConcurrent             int
+	SkipLateIterations        bool
+	DeadlineTolerance         time.Duration
+	ShuffleIterations         bool
+	GracePeriod               time.Duration
+	ProgressInterval          time.Duration
+	ThroughputWindow          time.Duration
+	ThroughputGauge           bool
+	MaxBacklog                int64
+	BatchSize                 int
+	BatchPause                time.Duration
+	BatchTimeout              time.Duration
+	IterationRetries          int
+	RetryableErrors           []string
+	ArrivalTrace              string
+	MaxConcurrentAwaits       int
+	MaxTotalStarts            int
+	AwaitPollersTimeout       time.Duration
+	MaxScheduleToStartLatency time.Duration
+	TaskQueueStatsInterval    time.Duration
+	TaskQueueRateLimit        float64
+	MinThroughput             float64
+	ThinkTime                 time.Duration
+	ThinkTimeJitter           time.Duration
+	HealthCheckInterval       time.Duration
+	WorkerMetricsURL          string
+	WorkerMetricsInterval     time.Duration
+	NexusEndpoint             string
+	ScenarioOptions           []string
+	ConnectTimeout            time.Duration
+	ClientOptions             cmdoptions.ClientOptions
+	MetricsOptions            cmdoptions.MetricsOptions
+	LoggingOptions            cmdoptions.LoggingOptions
+	ReportOptions             cmdoptions.ReportOptions
+	FaultOptions              cmdoptions.FaultInjectionOptions
+	DevServerOptions          cmdoptions.DevServerOptions
+	TracingOptions            cmdoptions.TracingOptions
 }
 
 func (r *ScenarioRunner) AddCLIFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&r.Scenario, "scenario", "", "Scenario name to run")
 	fs.StringVar(&r.RunID, "run-id", "", "Run ID for this run")
+	fs.StringVar(&r.IDPrefix, "id-prefix", loadgen.DefaultIDPrefix, "Prefix of workflow IDs, before the run ID")
 	fs.IntVar(&r.Iterations, "iterations", 0, "Override default iterations for the scenario (cannot be provided with duration)")
 	fs.DurationVar(&r.Duration, "duration", 0, "Override duration for the scenario (cannot be provided with iteration)")
 	fs.IntVar(&r.MaxConcurrent, "max-concurrent", 0, "Override max-concurrent for the scenario")
+	fs.BoolVar(&r.SkipLateIterations, "skip-late-iterations", false,
+		"Do not start iterations estimated to end after the duration by more than the deadline tolerance")
+	fs.DurationVar(&r.DeadlineTolerance, "deadline-tolerance", 0, "Tolerance for --skip-late-iterations")
+	fs.BoolVar(&r.ShuffleIterations, "shuffle-iterations", false,
+		"Dispatch iterations in an order shuffled by the run's seed (requires iterations)")
+	fs.DurationVar(&r.GracePeriod, "grace-period", 0,
+		"Time to wait for in-flight iterations after the duration before abandoning them (default 30s)")
+	fs.DurationVar(&r.ProgressInterval, "progress-interval", 0,
+		"How often to log progress and windowed throughput (no progress logging if unset)")
+	fs.DurationVar(&r.ThroughputWindow, "throughput-window", 0, "Window of the throughput logged with progress (default 30s)")
+	fs.BoolVar(&r.ThroughputGauge, "throughput-gauge", false, "Also emit the windowed throughput as the omes_throughput gauge")
+	fs.Int64Var(&r.MaxBacklog, "max-backlog", 0,
+		"Hold back new iterations while the task queue's workflow task backlog exceeds this (no limit if unset)")
+	fs.IntVar(&r.BatchSize, "batch-size", 0,
+		"Run iterations in batches of this size, waiting for each batch to complete before the next")
+	fs.DurationVar(&r.BatchPause, "batch-pause", 0, "Pause between batches of --batch-size")
+	fs.DurationVar(&r.BatchTimeout, "batch-timeout", 0,
+		"Maximum time to wait for a batch of --batch-size to complete before pausing (default no limit)")
+	fs.IntVar(&r.IterationRetries, "iteration-retries", 0,
+		"Maximum retries of an iteration failing with an error matching --retryable-error (default 3)")
+	fs.StringArrayVar(&r.RetryableErrors, "retryable-error", nil,
+		"Retry iterations failing with an error containing this, or matching it if prefixed with regex: (repeatable)")
+	fs.StringVar(&r.ArrivalTrace, "arrival-trace", "",
+		"CSV file of iteration start offsets from the run start to replay (runs the whole trace unless limited)")
+	fs.IntVar(&r.MaxConcurrentAwaits, "max-concurrent-awaits", 0,
+		"Max workflows to await concurrently for scenarios starting and awaiting separately (default max-concurrent)")
+	fs.IntVar(&r.MaxTotalStarts, "max-total-starts", 0,
+		"Stop starting iterations once this many were started, even if the duration has not elapsed")
+	fs.DurationVar(&r.AwaitPollersTimeout, "await-pollers-timeout", 0,
+		"Before starting load, wait up to this long for workflow pollers on each of the run's task queues")
+	fs.DurationVar(&r.MaxScheduleToStartLatency, "max-schedule-to-start-latency", 0,
+		"Lower the start rate while the run's workflow task schedule-to-start latency exceeds this (no limit if unset)")
+	fs.DurationVar(&r.TaskQueueStatsInterval, "task-queue-stats-interval", 0,
+		"Snapshot server-side task queue stats at this interval and include the time series in the report")
+	fs.Float64Var(&r.TaskQueueRateLimit, "task-queue-rate-limit", 0,
+		"Set the server-side activity dispatch rate limit of the run's task queue for the run, restoring it after (unchanged if unset)")
+	fs.Float64Var(&r.MinThroughput, "min-throughput", 0,
+		"Fail the run if its steady-state throughput in iterations/sec is below this (no floor if unset)")
+	fs.DurationVar(&r.ThinkTime, "think-time", 0,
+		"Time each iteration holds its concurrency slot after completing, modeling user think time in closed-loop load")
+	fs.DurationVar(&r.ThinkTimeJitter, "think-time-jitter", 0, "Random extra think time of up to this duration per iteration")
+	fs.DurationVar(&r.HealthCheckInterval, "health-check-interval", 0,
+		"Check cluster health at this interval and pause new starts while unhealthy (disabled if zero)")
+	fs.StringVar(&r.WorkerMetricsURL, "worker-metrics-url", "",
+		"URL of a worker Prometheus metrics endpoint to scrape during the run, summarized in the report")
+	fs.DurationVar(&r.WorkerMetricsInterval, "worker-metrics-interval", 0, "Interval of scrapes of --worker-metrics-url (default 10s)")
+	fs.StringVar(&r.NexusEndpoint, "nexus-endpoint", "",
+		"URL a Nexus endpoint serves its services under, for scenarios invoking Nexus operations (sent the --auth-header)")
 	fs.StringSliceVar(&r.ScenarioOptions, "option", nil, "Additional options for the scenario, in key=value format")
 	fs.DurationVar(&r.ConnectTimeout, "connect-timeout", 0, "Duration to try to connect to server before failing")
 	r.ClientOptions.AddCLIFlags(fs)
 	r.MetricsOptions.AddCLIFlags(fs, "")
 	r.LoggingOptions.AddCLIFlags(fs)
+	r.ReportOptions.AddCLIFlags(fs)
+	r.FaultOptions.AddCLIFlags(fs)
+	r.DevServerOptions.AddCLIFlags(fs)
+	r.TracingOptions.AddCLIFlags(fs)
 }
 
 func (r *ScenarioRunner) Run(ctx context.Context) error {
@@ -58,6 +145,10 @@ func (r *ScenarioRunner) Run(ctx context.Context) error {
 		return fmt.Errorf("cannot provide both iterations and duration")
 	}
 	r.Logger.Infof("runId: %v, scenario: %v", r.RunID, r.Scenario)
+	envConfig, err := loadgen.RunConfigurationFromEnv(nil)
+	if err != nil {
+		return err
+	}
 
 	// Parse options
 	scenarioOptions := make(map[string]string, len(r.ScenarioOptions))
@@ -69,13 +160,63 @@ func (r *ScenarioRunner) Run(ctx context.Context) error {
 		scenarioOptions[pieces[0]] = pieces[1]
 	}
 
+	reportSinks, err := r.ReportOptions.Sinks()
+	if err != nil {
+		return fmt.Errorf("invalid report options: %w", err)
+	}
+
+	clientOptions := r.ClientOptions
+	if executor, ok := scenario.Executor.(loadgen.HasClientInterceptors); ok {
+		clientOptions.UnaryInterceptors = append(clientOptions.UnaryInterceptors, executor.GetClientInterceptors()...)
+	}
+	var sdkMetrics *loadgen.CapturingMetricsHandler
+	if executor, ok := scenario.Executor.(loadgen.HasSDKMetricsCapture); ok && executor.GetCaptureSDKMetrics() {
+		clientOptions.WrapMetricsHandler = func(handler client.MetricsHandler) client.MetricsHandler {
+			sdkMetrics = loadgen.NewCapturingMetricsHandler(handler)
+			return sdkMetrics
+		}
+	}
+	if executor, ok := scenario.Executor.(loadgen.HasPayloadCodecs); ok {
+		clientOptions.PayloadCodecs = append(clientOptions.PayloadCodecs, executor.GetPayloadCodecs()...)
+	}
+	if executor, ok := scenario.Executor.(loadgen.HasDataConverter); ok && executor.GetDataConverter() != nil {
+		clientOptions.DataConverter = executor.GetDataConverter()
+	}
+	clientOptions.Interceptors = append(clientOptions.Interceptors, r.TracingOptions.ClientInterceptors()...)
+	faults, err := r.FaultOptions.FaultInjection()
+	if err != nil {
+		return fmt.Errorf("invalid fault injection options: %w", err)
+	}
+	if faults.Enabled() {
+		r.Logger.Warnf("Injecting client faults: %+v", faults)
+		clientOptions.UnaryInterceptors = append(clientOptions.UnaryInterceptors, faults.Interceptor())
+	}
+
+	if r.DevServerOptions.Enabled {
+		if clientOptions.EnableTLS || clientOptions.ClientCertPath != "" || clientOptions.ClientKeyPath != "" {
+			return fmt.Errorf("cannot use TLS with dev server")
+		} else if clientOptions.Address != client.DefaultHostPort || len(clientOptions.Endpoints) > 0 {
+			return fmt.Errorf("cannot supply non-default client address when using dev server")
+		}
+		server, err := r.DevServerOptions.Start(ctx, clientOptions.Namespace, r.Logger)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			r.Logger.Info("Stopping dev server")
+			if err := server.Stop(); err != nil {
+				r.Logger.Warnf("Failed stopping dev server: %v", err)
+			}
+		}()
+		clientOptions.Address = server.FrontendHostPort()
+	}
+
 	metrics := r.MetricsOptions.MustCreateMetrics(r.Logger)
 	defer metrics.Shutdown(ctx)
 	start := time.Now()
 	var client client.Client
-	var err error
 	for {
-		client, err = r.ClientOptions.Dial(metrics, r.Logger)
+		client, err = clientOptions.Dial(metrics, r.Logger)
 		if err == nil {
 			break
 		}
@@ -88,20 +229,105 @@ func (r *ScenarioRunner) Run(ctx context.Context) error {
 	}
 	r.Logger.Infof("Connected to server. client: %v", client)
 	defer client.Close()
+	var endpoints []loadgen.Endpoint
+	for _, address := range clientOptions.Endpoints {
+		endpointOptions := clientOptions
+		endpointOptions.Address = address
+		// SDK metrics are only captured from the main client
+		endpointOptions.WrapMetricsHandler = nil
+		endpointClient, err := endpointOptions.Dial(metrics, r.Logger)
+		if err != nil {
+			return fmt.Errorf("failed dialing endpoint %v: %w", address, err)
+		}
+		defer endpointClient.Close()
+		endpoints = append(endpoints, loadgen.Endpoint{Address: address, Client: endpointClient})
+	}
+	tracer, shutdownTracing, err := r.TracingOptions.Tracer(ctx)
+	if err != nil {
+		return fmt.Errorf("invalid tracing options: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			r.Logger.Warnf("Failed exporting iteration traces: %v", err)
+		}
+	}()
+	var nexusClient loadgen.NexusClient
+	if r.NexusEndpoint != "" {
+		header := http.Header{}
+		authHeader := r.ClientOptions.AuthHeader
+		if authHeader == "" {
+			authHeader = os.Getenv(cmdoptions.AUTH_HEADER_ENV_VAR)
+		}
+		if authHeader != "" {
+			header.Set("Authorization", authHeader)
+		}
+		nexusClient = &loadgen.HTTPNexusClient{BaseURL: r.NexusEndpoint, Header: header}
+	}
 	scenarioInfo := loadgen.ScenarioInfo{
 		ScenarioName:   r.Scenario,
 		RunID:          r.RunID,
 		Logger:         r.Logger,
 		MetricsHandler: metrics.NewHandler(),
 		Client:         client,
-		Configuration: loadgen.RunConfiguration{
-			Iterations:    r.Iterations,
-			Duration:      r.Duration,
-			MaxConcurrent: r.MaxConcurrent,
-		},
-		ScenarioOptions: scenarioOptions,
-		Namespace:       r.ClientOptions.Namespace,
-		RootPath:        rootDir(),
+		// Flags over the environment, over the scenario defaults
+		Configuration: loadgen.LayerRunConfiguration(envConfig, loadgen.RunConfiguration{
+			Iterations:                r.Iterations,
+			Duration:                  r.Duration,
+			MaxConcurrent:             r.MaxConcurrent,
+			SkipLateIterations:        r.SkipLateIterations,
+			DeadlineTolerance:         r.DeadlineTolerance,
+			ShuffleIterations:         r.ShuffleIterations,
+			GracePeriod:               r.GracePeriod,
+			ProgressInterval:          r.ProgressInterval,
+			ThroughputWindow:          r.ThroughputWindow,
+			ThroughputGauge:           r.ThroughputGauge,
+			MaxBacklog:                r.MaxBacklog,
+			BatchSize:                 r.BatchSize,
+			BatchPause:                r.BatchPause,
+			BatchTimeout:              r.BatchTimeout,
+			IterationRetries:          r.IterationRetries,
+			RetryableErrors:           r.RetryableErrors,
+			ArrivalTrace:              r.ArrivalTrace,
+			MaxConcurrentAwaits:       r.MaxConcurrentAwaits,
+			MaxTotalStarts:            r.MaxTotalStarts,
+			AwaitPollersTimeout:       r.AwaitPollersTimeout,
+			MaxScheduleToStartLatency: r.MaxScheduleToStartLatency,
+			TaskQueueStatsInterval:    r.TaskQueueStatsInterval,
+			MinThroughput:             r.MinThroughput,
+			ThinkTime:                 r.ThinkTime,
+			ThinkTimeJitter:           r.ThinkTimeJitter,
+			HealthCheckInterval:       r.HealthCheckInterval,
+			WorkerMetricsURL:          r.WorkerMetricsURL,
+			WorkerMetricsInterval:     r.WorkerMetricsInterval,
+		}),
+		ScenarioOptions:      scenarioOptions,
+		Namespace:            r.ClientOptions.Namespace,
+		ServerAddress:        clientOptions.Address,
+		RootPath:             rootDir(),
+		ReportSinks:          reportSinks,
+		LatencySamplesPath:   r.ReportOptions.SamplesFilePath,
+		SegmentBreakdownPath: r.ReportOptions.SegmentBreakdownFilePath,
+		ErrorLogPath:         r.ReportOptions.ErrorLogFilePath,
+		IDPrefix:             r.IDPrefix,
+		SDKMetrics:           sdkMetrics,
+		Endpoints:            endpoints,
+		Tracer:               tracer,
+		NexusClient:          nexusClient,
+		DataConverter:        clientOptions.ClientDataConverter(),
+	}
+	if err := scenarioInfo.ValidateStartOptions(); err != nil {
+		return fmt.Errorf("invalid scenario options: %w", err)
+	}
+	if r.TaskQueueRateLimit > 0 {
+		restore, err := scenarioInfo.SetTaskQueueRateLimit(ctx, r.TaskQueueRateLimit)
+		if err != nil {
+			return fmt.Errorf("failed setting task queue rate limit: %w", err)
+		}
+		defer func() {
+			if err := restore(context.Background()); err != nil {
+				r.Logger.Warnf("Failed restoring task queue rate limit: %v", err)
+			}
+		}()
 	}
 	err = scenario.Executor.Run(ctx, scenarioInfo)
 	if err != nil {
@@ -0,0 +1,140 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ABVariant is one of the two configurations of an ABRun.
+type ABVariant struct {
+	// Name of the variant. Default is "A" or "B".
+	Name string
+	// Run configuration layered over the run's, see LayerRunConfiguration.
+	Configuration RunConfiguration
+	// Scenario options layered over the run's.
+	ScenarioOptions map[string]string
+}
+
+// ABRun runs a scenario under two configurations against the same cluster and compares their
+// results, for tuning. The variants run one after the other so they do not interfere. Workflow IDs
+// of each variant have its lower-cased name appended to the ID prefix, e.g. "w-a-<RunID>-<N>", so
+// they do not collide.
+type ABRun struct {
+	Executor Executor
+	A, B     ABVariant
+	// Where to write the comparison as JSON, if anywhere. It is logged either way.
+	Output io.Writer
+}
+
+// ABResult is the result of one variant of an ABRun.
+type ABResult struct {
+	Name   string     `json:"name"`
+	Result *RunResult `json:"result"`
+	// Completed iterations per second over the run.
+	Throughput float64       `json:"throughput"`
+	P99        time.Duration `json:"p99"`
+	// Fraction of ended iterations that failed.
+	ErrorRate float64 `json:"errorRate"`
+}
+
+// ABComparison compares the results of the variants of an ABRun. Changes are of B relative to A,
+// e.g. 0.1 for a 10% higher value, and 0 if A's value is 0.
+type ABComparison struct {
+	A                ABResult `json:"a"`
+	B                ABResult `json:"b"`
+	ThroughputChange float64  `json:"throughputChange"`
+	P99Change        float64  `json:"p99Change"`
+	// Difference of the error rates, since they are often 0.
+	ErrorRateDifference float64 `json:"errorRateDifference"`
+}
+
+// captureReportSink keeps the reported result.
+type captureReportSink struct {
+	result *RunResult
+}
+
+func (s *captureReportSink) WriteReport(ctx context.Context, result *RunResult) error {
+	s.result = result
+	return nil
+}
+
+// Run runs variant A, then variant B, and returns their comparison. The variants report their
+// results to the comparison only, not to the run's report sinks.
+func (r *ABRun) Run(ctx context.Context, info ScenarioInfo) (*ABComparison, error) {
+	a, err := r.runVariant(ctx, info, r.A, "A")
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.runVariant(ctx, info, r.B, "B")
+	if err != nil {
+		return nil, err
+	}
+	comparison := &ABComparison{
+		A:                   a,
+		B:                   b,
+		ThroughputChange:    relativeChange(a.Throughput, b.Throughput),
+		P99Change:           relativeChange(float64(a.P99), float64(b.P99)),
+		ErrorRateDifference: b.ErrorRate - a.ErrorRate,
+	}
+	info.Logger.Infof("A/B comparison: throughput %.2f/s vs %.2f/s (%+.1f%%), p99 %v vs %v (%+.1f%%), "+
+		"error rate %.2f%% vs %.2f%%", a.Throughput, b.Throughput, 100*comparison.ThroughputChange,
+		a.P99, b.P99, 100*comparison.P99Change, 100*a.ErrorRate, 100*b.ErrorRate)
+	if r.Output != nil {
+		enc := json.NewEncoder(r.Output)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(comparison); err != nil {
+			return nil, fmt.Errorf("failed writing A/B comparison: %w", err)
+		}
+	}
+	return comparison, nil
+}
+
+func (r *ABRun) runVariant(ctx context.Context, info ScenarioInfo, variant ABVariant, defaultName string) (ABResult, error) {
+	name := variant.Name
+	if name == "" {
+		name = defaultName
+	}
+	info.Configuration = LayerRunConfiguration(info.Configuration, variant.Configuration)
+	options := make(map[string]string, len(info.ScenarioOptions)+len(variant.ScenarioOptions))
+	for k, v := range info.ScenarioOptions {
+		options[k] = v
+	}
+	for k, v := range variant.ScenarioOptions {
+		options[k] = v
+	}
+	info.ScenarioOptions = options
+	idPrefix := info.IDPrefix
+	if idPrefix == "" {
+		idPrefix = DefaultIDPrefix
+	}
+	info.IDPrefix = idPrefix + "-" + strings.ToLower(name)
+	info.Logger = info.Logger.With("variant", name)
+	capture := &captureReportSink{}
+	info.ReportSinks = []ReportSink{capture}
+
+	info.Logger.Infof("Running variant %v", name)
+	if err := r.Executor.Run(ctx, info); err != nil {
+		return ABResult{}, fmt.Errorf("variant %v failed: %w", name, err)
+	} else if capture.result == nil {
+		return ABResult{}, fmt.Errorf("variant %v reported no result", name)
+	}
+	result := ABResult{Name: name, Result: capture.result, P99: capture.result.Latency.P99}
+	if seconds := capture.result.Duration.Seconds(); seconds > 0 {
+		result.Throughput = float64(capture.result.IterationsCompleted) / seconds
+	}
+	if ended := capture.result.IterationsCompleted + capture.result.IterationsFailed; ended > 0 {
+		result.ErrorRate = float64(capture.result.IterationsFailed) / float64(ended)
+	}
+	return result, nil
+}
+
+func relativeChange(a, b float64) float64 {
+	if a == 0 {
+		return 0
+	}
+	return (b - a) / a
+}
@@ -0,0 +1,91 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestABRunComparesVariants(t *testing.T) {
+	var lock sync.Mutex
+	ids := map[string][]string{}
+	executor := &GenericExecutor{
+		DefaultConfiguration: RunConfiguration{Iterations: 4},
+		Execute: func(ctx context.Context, run *Run) error {
+			delay, err := time.ParseDuration(run.ScenarioOptions["delay"])
+			if err != nil {
+				return err
+			}
+			time.Sleep(delay)
+			lock.Lock()
+			defer lock.Unlock()
+			ids[run.ScenarioOptions["variant"]] = append(ids[run.ScenarioOptions["variant"]],
+				run.StartWorkflowOptions().ID)
+			return nil
+		},
+	}
+	var out bytes.Buffer
+	ab := &ABRun{
+		Executor: executor,
+		A:        ABVariant{ScenarioOptions: map[string]string{"variant": "a", "delay": "10ms"}},
+		B: ABVariant{
+			Name:            "Fast",
+			Configuration:   RunConfiguration{MaxConcurrent: 4},
+			ScenarioOptions: map[string]string{"variant": "b", "delay": "1ms"},
+		},
+		Output: &out,
+	}
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{MaxConcurrent: 1})
+	var reported bool
+	info.ReportSinks = []ReportSink{reportSinkFunc(func(*RunResult) { reported = true })}
+	comparison, err := ab.Run(context.Background(), info)
+	require.NoError(t, err)
+	require.False(t, reported)
+
+	// Both variants ran all iterations of the scenario default, with their own IDs
+	require.Len(t, ids["a"], 4)
+	require.Len(t, ids["b"], 4)
+	require.Contains(t, ids["a"], "w-a-test-run-1")
+	require.Contains(t, ids["b"], "w-fast-test-run-1")
+
+	require.Equal(t, "A", comparison.A.Name)
+	require.Equal(t, "Fast", comparison.B.Name)
+	require.Equal(t, 4, comparison.A.Result.IterationsCompleted)
+	require.Equal(t, 4, comparison.B.Result.IterationsCompleted)
+	require.Greater(t, comparison.B.Throughput, comparison.A.Throughput)
+	require.Less(t, comparison.B.P99, comparison.A.P99)
+	require.Equal(t, comparison.A.P99, comparison.A.Result.Latency.P99)
+	require.InDelta(t, (comparison.B.Throughput-comparison.A.Throughput)/comparison.A.Throughput,
+		comparison.ThroughputChange, 1e-9)
+	require.Greater(t, comparison.ThroughputChange, 0.0)
+	require.Less(t, comparison.P99Change, 0.0)
+	require.Zero(t, comparison.ErrorRateDifference)
+
+	var written ABComparison
+	require.NoError(t, json.Unmarshal(out.Bytes(), &written))
+	require.Equal(t, comparison.ThroughputChange, written.ThroughputChange)
+}
+
+func TestABRunVariantFailure(t *testing.T) {
+	var ran []string
+	executor := ExecutorFunc(func(ctx context.Context, info ScenarioInfo) error {
+		ran = append(ran, info.IDPrefix)
+		return errors.New("boom")
+	})
+	_, err := (&ABRun{Executor: executor}).Run(context.Background(), NewTestScenarioInfo(&FakeClient{}, RunConfiguration{}))
+	require.ErrorContains(t, err, "variant A failed: boom")
+	require.Equal(t, []string{"w-a"}, ran)
+}
+
+type reportSinkFunc func(*RunResult)
+
+func (f reportSinkFunc) WriteReport(ctx context.Context, result *RunResult) error {
+	f(result)
+	return nil
+}
@@ -0,0 +1,71 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+)
+
+// ErrActivityHeartbeatTimeout is returned (wrapped) by Run.ExecuteHeartbeatWorkflow when the
+// activity failed by missing its heartbeat timeout.
+var ErrActivityHeartbeatTimeout = errors.New("activity heartbeat timed out")
+
+// ExecuteHeartbeatWorkflow executes a kitchen sink workflow running an activity that heartbeats
+// every interval for the duration (see kitchensink.HeartbeatActivityActionSet), requiring a Go
+// worker. While it runs, the workflow is described every interval until a heartbeat of the activity
+// is recorded on the server, failing if none is. A failure from the heartbeat timeout is returned
+// wrapping ErrActivityHeartbeatTimeout and counted in the omes_activity_heartbeat_timeout counter.
+func (r *Run) ExecuteHeartbeatWorkflow(ctx context.Context, interval, duration, heartbeatTimeout time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("heartbeat interval must be positive")
+	} else if duration < 2*interval {
+		return fmt.Errorf("heartbeat duration must be at least twice the interval for heartbeats to be observed")
+	}
+	input := &kitchensink.WorkflowInput{InitialActions: []*kitchensink.ActionSet{
+		kitchensink.HeartbeatActivityActionSet(
+			kitchensink.HeartbeatActivityInput{Interval: interval, Duration: duration}, heartbeatTimeout),
+	}}
+	execution, err := r.Client.ExecuteWorkflow(ctx, r.StartWorkflowOptions(), "kitchenSink", input)
+	if err != nil {
+		return fmt.Errorf("failed to start kitchen sink workflow: %w", err)
+	}
+	resultCh := make(chan error, 1)
+	go func() { resultCh <- r.getWorkflowResult(ctx, execution, nil) }()
+	var heartbeated bool
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-resultCh:
+			var timeoutErr *temporal.TimeoutError
+			if errors.As(err, &timeoutErr) && timeoutErr.TimeoutType() == enums.TIMEOUT_TYPE_HEARTBEAT {
+				r.RecordCounter("omes_activity_heartbeat_timeout", nil, 1)
+				return fmt.Errorf("%w in workflow %v: %v", ErrActivityHeartbeatTimeout, execution.GetID(), err)
+			} else if err != nil {
+				return fmt.Errorf("kitchen sink workflow failed: %w", err)
+			} else if !heartbeated {
+				return fmt.Errorf("no heartbeat of the activity of workflow %v was recorded", execution.GetID())
+			}
+			return nil
+		case <-ticker.C:
+			if heartbeated {
+				continue
+			}
+			resp, err := r.Client.DescribeWorkflowExecution(ctx, execution.GetID(), execution.GetRunID())
+			if err != nil {
+				r.Logger.Warnf("Failed describing workflow %v: %v", execution.GetID(), err)
+				continue
+			}
+			for _, activity := range resp.GetPendingActivities() {
+				if activity.GetLastHeartbeatTime() != nil {
+					heartbeated = true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestExecuteHeartbeatWorkflowHeartbeatTimeout(t *testing.T) {
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			timeout := temporal.NewTimeoutError(enums.TIMEOUT_TYPE_HEARTBEAT, nil)
+			return &FakeWorkflowRun{ID: options.ID, Err: temporal.NewNonRetryableApplicationError(
+				"activity error", "ActivityError", timeout)}, nil
+		},
+	}
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	err := info.NewRun(1).ExecuteHeartbeatWorkflow(context.Background(), 10*time.Millisecond, time.Second, time.Second)
+	require.ErrorIs(t, err, ErrActivityHeartbeatTimeout)
+	require.Equal(t, []recordedMetric{{
+		kind: "counter", name: "omes_activity_heartbeat_timeout", tags: map[string]string{"scenario": "test"}, value: 1,
+	}}, *handler.recorded)
+
+	// Other failures are not heartbeat timeouts
+	fake.OnExecuteWorkflow = func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+		args ...interface{}) (client.WorkflowRun, error) {
+		return &FakeWorkflowRun{ID: options.ID, Err: temporal.NewTimeoutError(enums.TIMEOUT_TYPE_START_TO_CLOSE, nil)}, nil
+	}
+	err = info.NewRun(2).ExecuteHeartbeatWorkflow(context.Background(), 10*time.Millisecond, time.Second, time.Second)
+	require.ErrorContains(t, err, "kitchen sink workflow failed")
+	require.NotErrorIs(t, err, ErrActivityHeartbeatTimeout)
+}
@@ -0,0 +1,77 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/enums/v1"
+)
+
+// ErrUnexpectedActivityRetries is returned (wrapped) by Run.ExecuteActivityRetryWorkflow when the
+// activity was not retried the expected number of times.
+var ErrUnexpectedActivityRetries = errors.New("activity retried an unexpected number of times")
+
+// ActivityAttempts returns the attempt each started activity of the workflow was last started on,
+// by activity ID, from history. The server writes the started event of an activity once its last
+// attempt completes, so this is the number of attempts of completed activities.
+func (r *Run) ActivityAttempts(ctx context.Context, workflowID, runID string) (map[string]int, error) {
+	scheduled := map[int64]string{}
+	attempts := map[string]int{}
+	iter := r.Client.GetWorkflowHistory(ctx, workflowID, runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading history of workflow %v: %w", workflowID, err)
+		}
+		if attrs := event.GetActivityTaskScheduledEventAttributes(); attrs != nil {
+			scheduled[event.EventId] = attrs.ActivityId
+		} else if attrs := event.GetActivityTaskStartedEventAttributes(); attrs != nil {
+			activityID, ok := scheduled[attrs.ScheduledEventId]
+			if !ok {
+				return nil, fmt.Errorf("history of workflow %v lacks scheduled event %v of started activity",
+					workflowID, attrs.ScheduledEventId)
+			}
+			attempts[activityID] = int(attrs.Attempt)
+		}
+	}
+	return attempts, nil
+}
+
+// ExecuteActivityRetryWorkflow executes a kitchen sink workflow running an activity that fails the
+// given number of times before succeeding, retried every retry interval (see
+// kitchensink.FailingActivityActionSet), requiring a Go worker. It then verifies from history that
+// the activity was retried exactly that many times, returning an error wrapping
+// ErrUnexpectedActivityRetries otherwise. Retries are counted in the omes_activity_retries counter.
+func (r *Run) ExecuteActivityRetryWorkflow(ctx context.Context, failures int, retryInterval time.Duration) error {
+	if failures < 0 {
+		return fmt.Errorf("activity failures must not be negative")
+	}
+	input := &kitchensink.WorkflowInput{InitialActions: []*kitchensink.ActionSet{
+		kitchensink.FailingActivityActionSet(failures, retryInterval),
+	}}
+	execution, err := r.Client.ExecuteWorkflow(ctx, r.StartWorkflowOptions(), "kitchenSink", input)
+	if err != nil {
+		return fmt.Errorf("failed to start kitchen sink workflow: %w", err)
+	}
+	if err := r.getWorkflowResult(ctx, execution, nil); err != nil {
+		return fmt.Errorf("kitchen sink workflow failed: %w", err)
+	}
+	attempts, err := r.ActivityAttempts(ctx, execution.GetID(), execution.GetRunID())
+	if err != nil {
+		return err
+	} else if len(attempts) != 1 {
+		return fmt.Errorf("%w: workflow %v started %v activities, expected 1",
+			ErrUnexpectedActivityRetries, execution.GetID(), len(attempts))
+	}
+	for _, attempt := range attempts {
+		r.RecordCounter("omes_activity_retries", nil, int64(attempt-1))
+		if attempt-1 != failures {
+			return fmt.Errorf("%w: activity of workflow %v retried %v times, expected %v",
+				ErrUnexpectedActivityRetries, execution.GetID(), attempt-1, failures)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+)
+
+// activityHistory returns the history of a workflow whose activity completed on the given attempt.
+func activityHistory(attempt int32) []*history.HistoryEvent {
+	now := time.Now()
+	scheduled := historyEvent(enums.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED, now)
+	scheduled.EventId = 5
+	scheduled.Attributes = &history.HistoryEvent_ActivityTaskScheduledEventAttributes{
+		ActivityTaskScheduledEventAttributes: &history.ActivityTaskScheduledEventAttributes{ActivityId: "1"},
+	}
+	started := historyEvent(enums.EVENT_TYPE_ACTIVITY_TASK_STARTED, now)
+	started.EventId = 7
+	started.Attributes = &history.HistoryEvent_ActivityTaskStartedEventAttributes{
+		ActivityTaskStartedEventAttributes: &history.ActivityTaskStartedEventAttributes{ScheduledEventId: 5, Attempt: attempt},
+	}
+	return []*history.HistoryEvent{
+		historyEvent(enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, now),
+		scheduled,
+		started,
+		historyEvent(enums.EVENT_TYPE_ACTIVITY_TASK_COMPLETED, now),
+	}
+}
+
+func TestExecuteActivityRetryWorkflow(t *testing.T) {
+	fake := &FakeClient{
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return activityHistory(4), nil
+		},
+	}
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	require.NoError(t, info.NewRun(1).ExecuteActivityRetryWorkflow(context.Background(), 3, time.Millisecond))
+	require.Equal(t, []recordedMetric{{
+		kind: "counter", name: "omes_activity_retries", tags: map[string]string{"scenario": "test"}, value: 3,
+	}}, *handler.recorded)
+
+	err := info.NewRun(2).ExecuteActivityRetryWorkflow(context.Background(), 5, time.Millisecond)
+	require.ErrorIs(t, err, ErrUnexpectedActivityRetries)
+	require.ErrorContains(t, err, "retried 3 times, expected 5")
+}
+
+func TestExecuteActivityRetryWorkflowNoActivity(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	err := info.NewRun(1).ExecuteActivityRetryWorkflow(context.Background(), 0, time.Millisecond)
+	require.ErrorIs(t, err, ErrUnexpectedActivityRetries)
+	require.ErrorContains(t, err, "started 0 activities")
+}
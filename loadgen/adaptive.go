@@ -0,0 +1,49 @@
+package loadgen
+
+import (
+	"sync"
+	"time"
+)
+
+// IterationParams are scenario-defined parameters of an iteration, e.g. a payload size, set by
+// GenericExecutor.AdjustNext and read by the iteration from Run.Params.
+type IterationParams map[string]interface{}
+
+// RunResultPartial is the outcome of a completed iteration and the run's progress so far, passed
+// to GenericExecutor.AdjustNext.
+type RunResultPartial struct {
+	// The completed iteration, its parameters, latency and error if failed.
+	Iteration int
+	Params    IterationParams
+	Latency   time.Duration
+	Err       error
+	// Iteration counts of the run so far, including the completed iteration.
+	IterationsStarted   int
+	IterationsCompleted int
+	IterationsFailed    int
+}
+
+// iterationFeedback holds the parameters of the next iterations, adjusted by
+// GenericExecutor.AdjustNext as iterations complete.
+type iterationFeedback struct {
+	adjust func(RunResultPartial) IterationParams
+	lock   sync.Mutex
+	params IterationParams
+}
+
+// next returns the parameters of an iteration starting now.
+func (f *iterationFeedback) next() IterationParams {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.params
+}
+
+// completed passes the outcome of an iteration to the hook, which is called for one iteration at a
+// time, and applies its adjustment, if any, to iterations started from now on.
+func (f *iterationFeedback) completed(outcome RunResultPartial) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if params := f.adjust(outcome); params != nil {
+		f.params = params
+	}
+}
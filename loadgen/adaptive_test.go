@@ -0,0 +1,65 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAdjustNext(t *testing.T) {
+	var lock sync.Mutex
+	sizes := map[int]int{}
+	var outcomes []RunResultPartial
+	err := execute(&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			size := run.Params["payload-size"].(int)
+			lock.Lock()
+			sizes[run.Iteration] = size
+			lock.Unlock()
+			// Latency grows with the payload size
+			time.Sleep(time.Duration(size) * time.Millisecond)
+			return nil
+		},
+		InitialParams: IterationParams{"payload-size": 1},
+		// Double the payload size until latency crosses 8ms
+		AdjustNext: func(prev RunResultPartial) IterationParams {
+			outcomes = append(outcomes, prev)
+			if prev.Latency >= 8*time.Millisecond {
+				return nil
+			}
+			return IterationParams{"payload-size": prev.Params["payload-size"].(int) * 2}
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 6, MaxConcurrent: 1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{1: 1, 2: 2, 3: 4, 4: 8, 5: 8, 6: 8}, sizes)
+	require.Len(t, outcomes, 6)
+	require.Equal(t, 3, outcomes[2].Iteration)
+	require.Equal(t, 3, outcomes[2].IterationsCompleted)
+	require.Equal(t, 3, outcomes[2].IterationsStarted)
+}
+
+func TestRunAdjustNextSeesFailures(t *testing.T) {
+	var failed []int
+	err := execute(&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			if run.Params != nil {
+				return errors.New("adjusted iteration failed")
+			}
+			return nil
+		},
+		AdjustNext: func(prev RunResultPartial) IterationParams {
+			if prev.Err != nil {
+				failed = append(failed, prev.IterationsFailed)
+			}
+			return IterationParams{"adjusted": true}
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 2, MaxConcurrent: 1},
+	})
+	require.ErrorContains(t, err, "iteration 2 failed: adjusted iteration failed")
+	require.Equal(t, []int{1}, failed)
+}
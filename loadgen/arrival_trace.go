@@ -0,0 +1,93 @@
+package loadgen
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// arrivalLagWarningThreshold is how late an iteration may start relative to its scheduled start
+// before a warning is logged.
+var arrivalLagWarningThreshold = 100 * time.Millisecond
+
+// StartScheduler decides when each iteration of a GenericExecutor run starts. Iterations still
+// respect the run's concurrency, rate and backlog limits, so they may start late.
+type StartScheduler interface {
+	// StartOffset returns the offset from the start of the run at which the iteration with the
+	// given 0-based dispatch index is to start, or false if no more iterations are to start.
+	StartOffset(index int) (time.Duration, bool)
+}
+
+// ArrivalTrace is a StartScheduler replaying recorded arrivals, starting the Nth iteration at the
+// Nth offset.
+type ArrivalTrace struct {
+	// Offsets from the start of the run, ascending.
+	Offsets []time.Duration
+}
+
+func (a *ArrivalTrace) StartOffset(index int) (time.Duration, bool) {
+	if index >= len(a.Offsets) {
+		return 0, false
+	}
+	return a.Offsets[index], true
+}
+
+// ParseArrivalTrace parses a CSV arrival trace with the offset of each arrival in the first column,
+// either in seconds (e.g. "1.5") or in Go duration format (e.g. "1.5s"). Other columns, lines
+// starting with "#" and a header line are ignored.
+func ParseArrivalTrace(r io.Reader) (*ArrivalTrace, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	trace := &ArrivalTrace{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed reading arrival trace: %w", err)
+		}
+		offset, err := parseArrivalOffset(record[0])
+		if err != nil {
+			if line == 1 {
+				// Header
+				continue
+			}
+			return nil, fmt.Errorf("invalid arrival trace offset %q on line %v", record[0], line)
+		}
+		if offset < 0 {
+			return nil, fmt.Errorf("negative arrival trace offset on line %v", line)
+		} else if n := len(trace.Offsets); n > 0 && offset < trace.Offsets[n-1] {
+			return nil, fmt.Errorf("arrival trace offsets must ascend, line %v is before the previous", line)
+		}
+		trace.Offsets = append(trace.Offsets, offset)
+	}
+	if len(trace.Offsets) == 0 {
+		return nil, fmt.Errorf("arrival trace has no arrivals")
+	}
+	return trace, nil
+}
+
+func parseArrivalOffset(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// LoadArrivalTrace reads and parses a CSV arrival trace file, see ParseArrivalTrace.
+func LoadArrivalTrace(path string) (*ArrivalTrace, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening arrival trace: %w", err)
+	}
+	defer f.Close()
+	return ParseArrivalTrace(f)
+}
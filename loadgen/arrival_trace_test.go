@@ -0,0 +1,100 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseArrivalTrace(t *testing.T) {
+	trace, err := ParseArrivalTrace(strings.NewReader("offset,workflow\n# comment\n0,a\n0.25,b\n500ms,c\n1.5\n"))
+	require.NoError(t, err)
+	require.Equal(t, []time.Duration{0, 250 * time.Millisecond, 500 * time.Millisecond, 1500 * time.Millisecond},
+		trace.Offsets)
+	offset, ok := trace.StartOffset(3)
+	require.True(t, ok)
+	require.Equal(t, 1500*time.Millisecond, offset)
+	_, ok = trace.StartOffset(4)
+	require.False(t, ok)
+
+	_, err = ParseArrivalTrace(strings.NewReader("0\n2\n1\n"))
+	require.ErrorContains(t, err, "must ascend")
+	_, err = ParseArrivalTrace(strings.NewReader("0\nsoon\n"))
+	require.ErrorContains(t, err, `invalid arrival trace offset "soon" on line 2`)
+	_, err = ParseArrivalTrace(strings.NewReader("offset\n"))
+	require.ErrorContains(t, err, "no arrivals")
+}
+
+func TestRunArrivalTrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.csv")
+	require.NoError(t, os.WriteFile(path, []byte("0\n0.05\n0.1\n0.3\n0.35\n"), 0o644))
+	var lock sync.Mutex
+	var starts []time.Duration
+	runStart := time.Now()
+	err := execute(&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			defer lock.Unlock()
+			starts = append(starts, time.Since(runStart))
+			return nil
+		},
+		DefaultConfiguration: RunConfiguration{ArrivalTrace: path},
+	})
+	require.NoError(t, err)
+	// The whole trace is replayed, each start near its offset
+	require.Len(t, starts, 5)
+	for i, offset := range []time.Duration{0, 50, 100, 300, 350} {
+		require.GreaterOrEqual(t, starts[i], offset*time.Millisecond)
+		require.Less(t, starts[i], (offset+40)*time.Millisecond)
+	}
+}
+
+func TestRunArrivalTraceFallingBehind(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			time.Sleep(200 * time.Millisecond)
+			return nil
+		},
+		// Only one at a time cannot keep up with arrivals every 10ms
+		Scheduler:            &ArrivalTrace{Offsets: []time.Duration{0, 10 * time.Millisecond, 20 * time.Millisecond}},
+		DefaultConfiguration: RunConfiguration{Iterations: 3, MaxConcurrent: 1},
+	}
+	err := executor.Run(context.Background(), ScenarioInfo{MetricsHandler: client.MetricsNopHandler, Logger: zap.New(core).Sugar()})
+	require.NoError(t, err)
+	warnings := logs.FilterMessageSnippet("not keeping up with the schedule").All()
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0].Message, "Start 2 of the schedule")
+}
+
+func TestRunArrivalTraceDoesNotDelayFailure(t *testing.T) {
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			return errors.New("boom")
+		},
+		// The second arrival is long after the first fails
+		Scheduler:            &ArrivalTrace{Offsets: []time.Duration{0, time.Hour}},
+		DefaultConfiguration: RunConfiguration{Iterations: 2},
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- executor.Run(context.Background(), NewTestScenarioInfo(&FakeClient{}, RunConfiguration{}))
+	}()
+	select {
+	case err := <-done:
+		require.ErrorContains(t, err, "iteration 1 failed: boom")
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not fail while waiting for the next arrival")
+	}
+}
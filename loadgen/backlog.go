@@ -0,0 +1,123 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// backlogPollInterval is the interval between backlog reads of a BacklogThrottle.
+var backlogPollInterval = time.Second
+
+// TaskQueueBacklog returns the approximate number of tasks of the given type backlogged on the
+// run's task queue.
+func (s *ScenarioInfo) TaskQueueBacklog(ctx context.Context, taskQueueType enums.TaskQueueType) (int64, error) {
+	resp, err := s.Client.WorkflowService().DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+		Namespace:              s.Namespace,
+		TaskQueue:              &taskqueue.TaskQueue{Name: TaskQueueForRun(s.ScenarioName, s.RunID)},
+		TaskQueueType:          taskQueueType,
+		IncludeTaskQueueStatus: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed describing task queue: %w", err)
+	}
+	return resp.GetTaskQueueStatus().GetBacklogCountHint(), nil
+}
+
+// BacklogThrottle holds back new starts while the workflow task backlog of the run's task queue
+// exceeds MaxBacklog, resuming once it is at or below it. The backlog is read every second once
+// started. It is safe for concurrent use.
+type BacklogThrottle struct {
+	MaxBacklog int64
+
+	lock      sync.Mutex
+	throttled bool
+	// Closed when no longer throttled.
+	resumed chan struct{}
+}
+
+// Start reads the backlog once, then keeps reading it and updating the throttle in the background
+// until the context is done. Failed reads are logged and leave the throttle unchanged.
+func (b *BacklogThrottle) Start(ctx context.Context, info *ScenarioInfo) {
+	b.poll(ctx, info)
+	go func() {
+		ticker := time.NewTicker(backlogPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.poll(ctx, info)
+			}
+		}
+	}()
+}
+
+func (b *BacklogThrottle) poll(ctx context.Context, info *ScenarioInfo) {
+	backlog, err := info.TaskQueueBacklog(ctx, enums.TASK_QUEUE_TYPE_WORKFLOW)
+	if err != nil {
+		if ctx.Err() == nil {
+			info.Logger.Warnf("Failed reading backlog for throttling: %v", err)
+		}
+		return
+	}
+	b.update(info, backlog)
+}
+
+func (b *BacklogThrottle) update(info *ScenarioInfo, backlog int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	throttled := backlog > b.MaxBacklog
+	if throttled == b.throttled {
+		return
+	}
+	b.throttled = throttled
+	if throttled {
+		info.Logger.Infof("Throttling starts, backlog of %v exceeds %v", backlog, b.MaxBacklog)
+		b.resumed = make(chan struct{})
+	} else {
+		info.Logger.Infof("Resuming starts, backlog of %v is within %v", backlog, b.MaxBacklog)
+		close(b.resumed)
+	}
+}
+
+// Throttled returns whether starts are currently throttled.
+func (b *BacklogThrottle) Throttled() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.throttled
+}
+
+// Wait blocks while starts are throttled or until the context is done.
+func (b *BacklogThrottle) Wait(ctx context.Context) error {
+	resumed := b.resumedCh()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resumedCh returns the channel closed when starts are no longer throttled, or nil if they are
+// not or b is nil.
+func (b *BacklogThrottle) resumedCh() <-chan struct{} {
+	if b == nil {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if !b.throttled {
+		return nil
+	}
+	return b.resumed
+}
@@ -0,0 +1,113 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+func TestBacklogThrottleHoldsBackStarts(t *testing.T) {
+	prev := backlogPollInterval
+	backlogPollInterval = time.Millisecond
+	t.Cleanup(func() { backlogPollInterval = prev })
+
+	var backlog int64 = 20
+	fake := &FakeClient{
+		OnDescribeTaskQueue: func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+			*workflowservice.DescribeTaskQueueResponse, error) {
+			if !request.IncludeTaskQueueStatus || request.TaskQueueType != enums.TASK_QUEUE_TYPE_WORKFLOW {
+				panic("unexpected describe request")
+			}
+			return &workflowservice.DescribeTaskQueueResponse{
+				TaskQueueStatus: &taskqueue.TaskQueueStatus{BacklogCountHint: atomic.LoadInt64(&backlog)},
+			}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 3, MaxBacklog: 10})
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			return run.ExecuteAnyWorkflow(ctx, run.StartWorkflowOptions(), "wf", nil)
+		},
+	}
+	done := make(chan error, 1)
+	go func() { done <- executor.Run(context.Background(), info) }()
+
+	// Nothing starts while the backlog exceeds the threshold
+	require.Eventually(t, func() bool { return len(fake.Calls("DescribeTaskQueue")) >= 5 },
+		time.Second, time.Millisecond)
+	require.Empty(t, fake.Calls("ExecuteWorkflow"))
+	require.Equal(t, info.NewRun(1).TaskQueue(), fake.Calls("DescribeTaskQueue")[0].Name)
+
+	atomic.StoreInt64(&backlog, 10)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not resume")
+	}
+	require.Len(t, fake.Calls("ExecuteWorkflow"), 3)
+}
+
+func TestBacklogThrottleWait(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	throttle := &BacklogThrottle{MaxBacklog: 5}
+	require.NoError(t, throttle.Wait(context.Background()))
+
+	throttle.update(&info, 6)
+	require.True(t, throttle.Throttled())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, throttle.Wait(ctx), context.DeadlineExceeded)
+
+	go throttle.update(&info, 5)
+	require.NoError(t, throttle.Wait(context.Background()))
+	require.False(t, throttle.Throttled())
+}
+
+func TestBacklogThrottleDoesNotDelayFailure(t *testing.T) {
+	prev := backlogPollInterval
+	backlogPollInterval = time.Millisecond
+	t.Cleanup(func() { backlogPollInterval = prev })
+
+	var backlog int64
+	fake := &FakeClient{
+		OnDescribeTaskQueue: func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+			*workflowservice.DescribeTaskQueueResponse, error) {
+			return &workflowservice.DescribeTaskQueueResponse{
+				TaskQueueStatus: &taskqueue.TaskQueueStatus{BacklogCountHint: atomic.LoadInt64(&backlog)},
+			}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 1000, MaxConcurrent: 2, MaxBacklog: 10})
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			if run.Iteration > 1 {
+				time.Sleep(time.Millisecond)
+				return nil
+			}
+			// Fail once starts are throttled for good, with a concurrency slot free
+			atomic.StoreInt64(&backlog, 20)
+			polls := len(fake.Calls("DescribeTaskQueue"))
+			for len(fake.Calls("DescribeTaskQueue")) < polls+3 {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(10 * time.Millisecond)
+			return errors.New("boom")
+		},
+	}
+	done := make(chan error, 1)
+	go func() { done <- executor.Run(context.Background(), info) }()
+	select {
+	case err := <-done:
+		require.ErrorContains(t, err, "iteration 1 failed: boom")
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not fail while throttled")
+	}
+}
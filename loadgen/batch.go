@@ -0,0 +1,139 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/batch/v1"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+const (
+	defaultBatchPollInterval = time.Second
+	batchIdentity            = "omes"
+)
+
+// BatchOperationOptions selects the workflows a batch operation applies to and controls how the
+// batch job is awaited.
+type BatchOperationOptions struct {
+	// Visibility query selecting the workflows to operate on (mutually exclusive with Executions).
+	VisibilityQuery string
+	// Explicit workflow executions to operate on (mutually exclusive with VisibilityQuery).
+	Executions []*common.WorkflowExecution
+	// Job ID of the batch operation. Default is derived from the run ID and iteration.
+	JobID string
+	// Reason recorded on the batch operation. Default is "omes batch operation".
+	Reason string
+	// Interval for polling completion of the batch job. Default is 1s.
+	PollInterval time.Duration
+}
+
+// BatchOperationResult contains the final counts of a completed batch operation.
+type BatchOperationResult struct {
+	JobID                  string
+	TotalOperationCount    int64
+	CompleteOperationCount int64
+	FailureOperationCount  int64
+}
+
+// BatchSignalWorkflows signals every workflow selected by the options using the batch API and
+// waits for the batch job to complete.
+func (r *Run) BatchSignalWorkflows(
+	ctx context.Context,
+	options BatchOperationOptions,
+	signalName string,
+	args ...interface{},
+) (*BatchOperationResult, error) {
+	input, err := r.dataConverter().ToPayloads(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert signal args: %w", err)
+	}
+	return r.executeBatchOperation(ctx, "signal", options, &workflowservice.StartBatchOperationRequest{
+		Operation: &workflowservice.StartBatchOperationRequest_SignalOperation{
+			SignalOperation: &batch.BatchOperationSignal{
+				Signal:   signalName,
+				Input:    input,
+				Identity: batchIdentity,
+			},
+		},
+	})
+}
+
+// BatchTerminateWorkflows terminates every workflow selected by the options using the batch API
+// and waits for the batch job to complete.
+func (r *Run) BatchTerminateWorkflows(ctx context.Context, options BatchOperationOptions) (*BatchOperationResult, error) {
+	return r.executeBatchOperation(ctx, "terminate", options, &workflowservice.StartBatchOperationRequest{
+		Operation: &workflowservice.StartBatchOperationRequest_TerminationOperation{
+			TerminationOperation: &batch.BatchOperationTermination{Identity: batchIdentity},
+		},
+	})
+}
+
+func (r *Run) executeBatchOperation(
+	ctx context.Context,
+	kind string,
+	options BatchOperationOptions,
+	request *workflowservice.StartBatchOperationRequest,
+) (*BatchOperationResult, error) {
+	if (options.VisibilityQuery == "") == (len(options.Executions) == 0) {
+		return nil, fmt.Errorf("exactly one of visibility query or executions must be provided")
+	}
+	jobID := options.JobID
+	if jobID == "" {
+		jobID = fmt.Sprintf("omes-batch-%s-%s-%d", kind, r.RunID, r.Iteration)
+	}
+	reason := options.Reason
+	if reason == "" {
+		reason = "omes batch operation"
+	}
+	pollInterval := options.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultBatchPollInterval
+	}
+
+	r.Logger.Debugf("Starting batch %s operation %s", kind, jobID)
+	request.Namespace = r.Namespace
+	request.VisibilityQuery = options.VisibilityQuery
+	request.Executions = options.Executions
+	request.JobId = jobID
+	request.Reason = reason
+	_, err := r.Client.WorkflowService().StartBatchOperation(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed starting batch %s operation: %w", kind, err)
+	}
+
+	// Loop waiting for batch complete
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("context done while waiting for batch %s: %w", jobID, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+		resp, err := r.Client.WorkflowService().DescribeBatchOperation(ctx, &workflowservice.DescribeBatchOperationRequest{
+			Namespace: r.Namespace,
+			JobId:     jobID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed checking batch %s: %w", jobID, err)
+		}
+		switch resp.State {
+		case enums.BATCH_OPERATION_STATE_RUNNING:
+			continue
+		case enums.BATCH_OPERATION_STATE_COMPLETED:
+			return &BatchOperationResult{
+				JobID:                  jobID,
+				TotalOperationCount:    resp.TotalOperationCount,
+				CompleteOperationCount: resp.CompleteOperationCount,
+				FailureOperationCount:  resp.FailureOperationCount,
+			}, nil
+		case enums.BATCH_OPERATION_STATE_FAILED:
+			return nil, fmt.Errorf("batch %s failed after %d/%d operations, reason: %v",
+				jobID, resp.CompleteOperationCount, resp.TotalOperationCount, resp.Reason)
+		default:
+			return nil, fmt.Errorf("unexpected batch state %v, reason: %v", resp.State, resp.Reason)
+		}
+	}
+}
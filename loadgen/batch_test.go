@@ -0,0 +1,123 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+type fakeBatchService struct {
+	workflowservice.WorkflowServiceClient
+	started   []*workflowservice.StartBatchOperationRequest
+	describes []*workflowservice.DescribeBatchOperationResponse
+}
+
+func (f *fakeBatchService) StartBatchOperation(
+	ctx context.Context,
+	req *workflowservice.StartBatchOperationRequest,
+	opts ...grpc.CallOption,
+) (*workflowservice.StartBatchOperationResponse, error) {
+	f.started = append(f.started, req)
+	return &workflowservice.StartBatchOperationResponse{}, nil
+}
+
+func (f *fakeBatchService) DescribeBatchOperation(
+	ctx context.Context,
+	req *workflowservice.DescribeBatchOperationRequest,
+	opts ...grpc.CallOption,
+) (*workflowservice.DescribeBatchOperationResponse, error) {
+	resp := f.describes[0]
+	if len(f.describes) > 1 {
+		f.describes = f.describes[1:]
+	}
+	return resp, nil
+}
+
+type fakeBatchClient struct {
+	client.Client
+	service *fakeBatchService
+}
+
+func (f *fakeBatchClient) WorkflowService() workflowservice.WorkflowServiceClient { return f.service }
+
+func newBatchTestRun(service *fakeBatchService) *Run {
+	info := &ScenarioInfo{
+		RunID:     "batch-test",
+		Namespace: "default",
+		Logger:    zap.NewNop().Sugar(),
+		Client:    &fakeBatchClient{service: service},
+	}
+	return info.NewRun(1)
+}
+
+func TestBatchSignalWorkflows(t *testing.T) {
+	service := &fakeBatchService{describes: []*workflowservice.DescribeBatchOperationResponse{
+		{State: enums.BATCH_OPERATION_STATE_RUNNING, TotalOperationCount: 3, CompleteOperationCount: 1},
+		{State: enums.BATCH_OPERATION_STATE_RUNNING, TotalOperationCount: 3, CompleteOperationCount: 2},
+		{State: enums.BATCH_OPERATION_STATE_COMPLETED, TotalOperationCount: 3, CompleteOperationCount: 3},
+	}}
+	run := newBatchTestRun(service)
+	result, err := run.BatchSignalWorkflows(context.Background(), BatchOperationOptions{
+		VisibilityQuery: "TaskQueue = 'foo'",
+		PollInterval:    time.Millisecond,
+	}, "my-signal", "arg")
+	require.NoError(t, err)
+	require.Equal(t, &BatchOperationResult{
+		JobID:                  "omes-batch-signal-batch-test-1",
+		TotalOperationCount:    3,
+		CompleteOperationCount: 3,
+	}, result)
+	require.Len(t, service.started, 1)
+	require.Equal(t, "TaskQueue = 'foo'", service.started[0].VisibilityQuery)
+	require.Equal(t, "my-signal", service.started[0].GetSignalOperation().GetSignal())
+	require.Len(t, service.started[0].GetSignalOperation().GetInput().GetPayloads(), 1)
+}
+
+func TestBatchSignalWorkflowsUsesClientDataConverter(t *testing.T) {
+	service := &fakeBatchService{describes: []*workflowservice.DescribeBatchOperationResponse{
+		{State: enums.BATCH_OPERATION_STATE_COMPLETED, TotalOperationCount: 1, CompleteOperationCount: 1},
+	}}
+	run := newBatchTestRun(service)
+	run.DataConverter = converter.NewCodecDataConverter(converter.GetDefaultDataConverter(),
+		converter.NewZlibCodec(converter.ZlibCodecOptions{AlwaysEncode: true}))
+	_, err := run.BatchSignalWorkflows(context.Background(), BatchOperationOptions{
+		VisibilityQuery: "TaskQueue = 'foo'",
+		PollInterval:    time.Millisecond,
+	}, "my-signal", "arg")
+	require.NoError(t, err)
+	// Encoded like the client's own signals
+	payload := service.started[0].GetSignalOperation().GetInput().GetPayloads()[0]
+	require.Equal(t, "binary/zlib", string(payload.Metadata[converter.MetadataEncoding]))
+	var arg string
+	require.NoError(t, run.DataConverter.FromPayload(payload, &arg))
+	require.Equal(t, "arg", arg)
+}
+
+func TestBatchTerminateWorkflowsFailed(t *testing.T) {
+	service := &fakeBatchService{describes: []*workflowservice.DescribeBatchOperationResponse{
+		{State: enums.BATCH_OPERATION_STATE_FAILED, TotalOperationCount: 2, Reason: "boom"},
+	}}
+	run := newBatchTestRun(service)
+	_, err := run.BatchTerminateWorkflows(context.Background(), BatchOperationOptions{
+		Executions:   []*common.WorkflowExecution{{WorkflowId: "a"}, {WorkflowId: "b"}},
+		PollInterval: time.Millisecond,
+	})
+	require.ErrorContains(t, err, "boom")
+	require.NotNil(t, service.started[0].GetTerminationOperation())
+	require.Len(t, service.started[0].Executions, 2)
+}
+
+func TestBatchOperationRequiresSelection(t *testing.T) {
+	run := newBatchTestRun(&fakeBatchService{})
+	_, err := run.BatchTerminateWorkflows(context.Background(), BatchOperationOptions{})
+	require.ErrorContains(t, err, "exactly one of visibility query or executions")
+}
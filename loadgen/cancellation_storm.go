@@ -0,0 +1,158 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+// DefaultCancellationStormConcurrency is the default maximum of starts and cancellations of a
+// CancellationStorm in flight.
+const DefaultCancellationStormConcurrency = 10
+
+// DefaultCancellationStormTimeout is the default CancellationStorm.Timeout.
+const DefaultCancellationStormTimeout = time.Minute
+
+// CancellationStorm is a set of workflows started then canceled concurrently by
+// Run.CancellationStorm, for benchmarking the cancellation path, as opposed to termination.
+type CancellationStorm struct {
+	// Number of workflows to start and cancel.
+	Workflows int
+	// Workflow type and arguments to start. Default is a kitchen sink workflow waiting on a day-long
+	// timer, which fails as canceled once canceled.
+	Workflow     interface{}
+	WorkflowArgs []interface{}
+	// Target cancellations per second. Default is no limit.
+	Rate float64
+	// Maximum starts, and then cancellations, in flight. Default is
+	// DefaultCancellationStormConcurrency.
+	Concurrency int
+	// Maximum time to wait for each workflow to be canceled after it is requested. Default is
+	// DefaultCancellationStormTimeout.
+	Timeout time.Duration
+}
+
+// CancellationStormResult is the outcome of a CancellationStorm.
+type CancellationStormResult struct {
+	Canceled int
+	// Workflows that closed before their cancellation landed, which is not a failure.
+	CompletedBeforeCancel int
+	// Workflows that failed to start, to be canceled or to close as canceled.
+	Failed int
+	// Latency of the cancellation requests.
+	AckLatency LatencySummary
+	// Latency from the cancellation requests until the workflows closed as canceled.
+	CanceledLatency LatencySummary
+}
+
+// CancellationStorm starts storm.Workflows workflows, then requests their cancellation concurrently,
+// paced at storm.Rate, and waits for each to close as canceled. The latencies of the cancellation
+// requests and until the workflows are canceled are recorded in the omes_cancel_ack_latency and
+// omes_cancel_to_canceled_latency timers. Workflows that completed before their cancellation
+// landed, because the cancellation found them closed or they completed anyway, are counted apart
+// and do not fail the storm. Other failures are aggregated into the returned error with the count of
+// each distinct error.
+func (r *Run) CancellationStorm(ctx context.Context, storm CancellationStorm) (CancellationStormResult, error) {
+	concurrency := storm.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultCancellationStormConcurrency
+	}
+	timeout := storm.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCancellationStormTimeout
+	}
+	workflow, args := storm.Workflow, storm.WorkflowArgs
+	if workflow == nil {
+		workflow = "kitchenSink"
+		args = []interface{}{&kitchensink.WorkflowInput{
+			InitialActions: []*kitchensink.ActionSet{kitchensink.TimersActionSet(1, 24*time.Hour)},
+		}}
+	}
+
+	var lock sync.Mutex
+	var result CancellationStormResult
+	var ackLatencies, canceledLatencies []time.Duration
+	errorCounts := map[string]int{}
+	failed := func(err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		result.Failed++
+		errorCounts[err.Error()]++
+	}
+
+	executions := make([]client.WorkflowRun, storm.Workflows)
+	options := r.StartWorkflowOptions()
+	ctxErr := runPaced(ctx, storm.Workflows, 0, concurrency, func(i int) {
+		options := options
+		options.ID = fmt.Sprintf("%v-cancel-%v", options.ID, i)
+		execution, err := r.Client.ExecuteWorkflow(ctx, options, workflow, args...)
+		if err != nil {
+			failed(fmt.Errorf("failed to start workflow: %w", err))
+			return
+		}
+		executions[i] = execution
+	})
+	if ctxErr == nil {
+		ctxErr = runPaced(ctx, storm.Workflows, storm.Rate, concurrency, func(i int) {
+			execution := executions[i]
+			if execution == nil {
+				return
+			}
+			start := time.Now()
+			err := r.Client.CancelWorkflow(ctx, execution.GetID(), execution.GetRunID())
+			var notFound *serviceerror.NotFound
+			if errors.As(err, &notFound) {
+				// The workflow closed before the cancellation
+				lock.Lock()
+				defer lock.Unlock()
+				result.CompletedBeforeCancel++
+				return
+			} else if err != nil {
+				failed(fmt.Errorf("failed to cancel workflow: %w", err))
+				return
+			}
+			ack := time.Since(start)
+			r.RecordTimer("omes_cancel_ack_latency", nil, ack)
+
+			waitCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			err = r.getWorkflowResult(waitCtx, execution, nil)
+			canceled := time.Since(start)
+			var canceledErr *temporal.CanceledError
+			if err != nil && !errors.As(err, &canceledErr) {
+				failed(fmt.Errorf("workflow not canceled: %w", err))
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			ackLatencies = append(ackLatencies, ack)
+			if err == nil {
+				// The workflow completed before the cancellation landed
+				result.CompletedBeforeCancel++
+				return
+			}
+			result.Canceled++
+			canceledLatencies = append(canceledLatencies, canceled)
+			r.RecordTimer("omes_cancel_to_canceled_latency", nil, canceled)
+		})
+	}
+	result.AckLatency = NewLatencySummary(ackLatencies)
+	result.CanceledLatency = NewLatencySummary(canceledLatencies)
+	r.Logger.Debugf("Canceled %v of %v workflow(s), %v completed before cancel, ack p50 %v, canceled p50 %v",
+		result.Canceled, storm.Workflows, result.CompletedBeforeCancel, result.AckLatency.P50, result.CanceledLatency.P50)
+
+	if ctxErr != nil {
+		return result, fmt.Errorf("cancellation storm interrupted: %w", ctxErr)
+	} else if result.Failed > 0 {
+		return result, fmt.Errorf("%v of %v workflows of cancellation storm failed: %w",
+			result.Failed, storm.Workflows, aggregateErrors(errorCounts))
+	}
+	return result, nil
+}
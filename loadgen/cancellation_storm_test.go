@@ -0,0 +1,162 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+// cancelableRun is a workflow run that closes as canceled once canceled.
+type cancelableRun struct {
+	FakeWorkflowRun
+	canceled chan struct{}
+}
+
+func (r *cancelableRun) Get(ctx context.Context, valuePtr interface{}) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.canceled:
+		return temporal.NewCanceledError()
+	}
+}
+
+func (r *cancelableRun) GetWithOptions(ctx context.Context, valuePtr interface{}, options client.WorkflowRunGetOptions) error {
+	return r.Get(ctx, valuePtr)
+}
+
+// cancellationClient returns a fake client whose workflows close as canceled after the given delay
+// once canceled, except those for which completed returns true, which complete immediately. It
+// tracks the maximum of concurrent cancellations.
+func cancellationClient(delay time.Duration, completed func(workflowID string) bool) (*FakeClient, *atomic.Int32) {
+	var lock sync.Mutex
+	runs := map[string]*cancelableRun{}
+	var inFlight, maxInFlight atomic.Int32
+	return &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			if completed(options.ID) {
+				return &FakeWorkflowRun{ID: options.ID}, nil
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			run := &cancelableRun{FakeWorkflowRun: FakeWorkflowRun{ID: options.ID}, canceled: make(chan struct{})}
+			runs[options.ID] = run
+			return run, nil
+		},
+		OnCancelWorkflow: func(ctx context.Context, workflowID, runID string) error {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				if max := maxInFlight.Load(); n <= max || maxInFlight.CompareAndSwap(max, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			lock.Lock()
+			run, ok := runs[workflowID]
+			lock.Unlock()
+			if !ok {
+				return serviceerror.NewNotFound("workflow execution already completed")
+			}
+			time.AfterFunc(delay, func() { close(run.canceled) })
+			return nil
+		},
+	}, &maxInFlight
+}
+
+func TestCancellationStorm(t *testing.T) {
+	fake, maxInFlight := cancellationClient(5*time.Millisecond, func(string) bool { return false })
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	result, err := info.NewRun(1).CancellationStorm(context.Background(),
+		CancellationStorm{Workflows: 20, Concurrency: 5})
+	require.NoError(t, err)
+	require.Equal(t, 20, result.Canceled)
+	require.Zero(t, result.CompletedBeforeCancel)
+	require.Zero(t, result.Failed)
+	require.GreaterOrEqual(t, result.CanceledLatency.Min, 5*time.Millisecond)
+	require.Less(t, result.AckLatency.Max, result.CanceledLatency.Max)
+
+	// Cancellations ran concurrently, within the limit
+	require.Greater(t, maxInFlight.Load(), int32(1))
+	require.LessOrEqual(t, maxInFlight.Load(), int32(5))
+	require.Len(t, fake.Calls("CancelWorkflow"), 20)
+	// Canceled workflows are not looked up for workflow task failures
+	require.Empty(t, fake.Calls("GetWorkflowExecutionHistoryReverse"))
+	starts := fake.Calls("ExecuteWorkflow")
+	require.Len(t, starts, 20)
+	ids := map[string]bool{}
+	for _, start := range starts {
+		ids[start.Options.ID] = true
+	}
+	require.Len(t, ids, 20)
+	require.True(t, ids["w-test-run-1-cancel-0"])
+
+	counts := map[string]int{}
+	for _, metric := range *handler.recorded {
+		counts[metric.name]++
+	}
+	require.Equal(t, map[string]int{"omes_cancel_ack_latency": 20, "omes_cancel_to_canceled_latency": 20}, counts)
+}
+
+func TestCancellationStormCompletedBeforeCancel(t *testing.T) {
+	// Some workflows complete before their cancellation lands
+	fake, _ := cancellationClient(0, func(id string) bool {
+		return id == "w-test-run-1-cancel-0" || id == "w-test-run-1-cancel-3"
+	})
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	result, err := info.NewRun(1).CancellationStorm(context.Background(), CancellationStorm{Workflows: 6})
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Canceled)
+	require.Equal(t, 2, result.CompletedBeforeCancel)
+	require.Zero(t, result.Failed)
+
+	// Including when the cancellation succeeded but the workflow completed anyway
+	fake = &FakeClient{}
+	info = NewTestScenarioInfo(fake, RunConfiguration{})
+	result, err = info.NewRun(1).CancellationStorm(context.Background(), CancellationStorm{Workflows: 3})
+	require.NoError(t, err)
+	require.Zero(t, result.Canceled)
+	require.Equal(t, 3, result.CompletedBeforeCancel)
+	require.Zero(t, result.Failed)
+}
+
+func TestCancellationStormFailures(t *testing.T) {
+	fake, _ := cancellationClient(time.Hour, func(string) bool { return false })
+	onCancel := fake.OnCancelWorkflow
+	fake.OnCancelWorkflow = func(ctx context.Context, workflowID, runID string) error {
+		if workflowID == "w-test-run-1-cancel-1" {
+			return errors.New("cancel rejected")
+		}
+		return onCancel(ctx, workflowID, runID)
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	result, err := info.NewRun(1).CancellationStorm(context.Background(),
+		CancellationStorm{Workflows: 3, Timeout: 10 * time.Millisecond})
+	require.Equal(t, 3, result.Failed)
+	require.ErrorContains(t, err, "3 of 3 workflows of cancellation storm failed")
+	require.ErrorContains(t, err, "failed to cancel workflow: cancel rejected (x1)")
+	require.ErrorContains(t, err, "workflow not canceled: context deadline exceeded (x2)")
+}
+
+func TestCancellationStormRate(t *testing.T) {
+	fake, _ := cancellationClient(0, func(string) bool { return false })
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	start := time.Now()
+	result, err := info.NewRun(1).CancellationStorm(context.Background(),
+		CancellationStorm{Workflows: 5, Rate: 100})
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Canceled)
+	// 5 cancellations at 100/s are spread over at least 40ms
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
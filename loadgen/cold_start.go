@@ -0,0 +1,110 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// coldStartPollerCheckInterval is the interval between task queue descriptions of
+// Run.MeasureColdStart, short enough for the poller registration latency to be meaningful.
+var coldStartPollerCheckInterval = 50 * time.Millisecond
+
+// ColdStart is the outcome of Run.MeasureColdStart.
+type ColdStart struct {
+	TaskQueue string
+	// Time from the workflow start until a workflow poller was seen on the task queue. At most the
+	// time until the workflow completed if the poller was not seen before.
+	PollerRegistration time.Duration
+	// Time from the workflow start until its first workflow task completed, see DispatchLatency.
+	FirstTaskCompletion time.Duration
+}
+
+// ColdStartTaskQueue returns a task queue unique to the run and iteration, for
+// Run.MeasureColdStart.
+func (r *Run) ColdStartTaskQueue() string {
+	return fmt.Sprintf("%v-cold-%v", r.TaskQueue(), r.Iteration)
+}
+
+// MeasureColdStart measures how long a brand-new task queue takes to get a poller and complete its
+// first workflow task. It starts a kitchen sink workflow completing immediately on the task queue of
+// ColdStartTaskQueue, without waiting for pollers, then calls startWorker, if set, to have a worker
+// poll it. The latencies are also recorded in the omes_cold_start_poller_registration_latency and
+// omes_cold_start_latency timers.
+func (r *Run) MeasureColdStart(
+	ctx context.Context,
+	startWorker func(ctx context.Context, taskQueue string) error,
+) (ColdStart, error) {
+	coldStart := ColdStart{TaskQueue: r.ColdStartTaskQueue()}
+	options := r.StartWorkflowOptions()
+	options.TaskQueue = coldStart.TaskQueue
+	start := time.Now()
+	execution, err := r.Client.ExecuteWorkflow(ctx, options, "kitchenSink",
+		&kitchensink.WorkflowInput{InitialActions: []*kitchensink.ActionSet{kitchensink.EmptyResultActionSet()}})
+	if err != nil {
+		return coldStart, fmt.Errorf("failed to start kitchen sink workflow: %w", err)
+	}
+
+	// The poller check is joined before returning, stopping it if it did not see a poller yet
+	pollCtx, cancelPoll := context.WithCancel(ctx)
+	pollerSeen := make(chan time.Duration, 1)
+	pollDone := make(chan struct{})
+	stopPoll := func() {
+		cancelPoll()
+		<-pollDone
+	}
+	defer stopPoll()
+	go func() {
+		defer close(pollDone)
+		if r.awaitColdStartPoller(pollCtx, coldStart.TaskQueue) {
+			pollerSeen <- time.Since(start)
+		}
+	}()
+	if startWorker != nil {
+		if err := startWorker(ctx, coldStart.TaskQueue); err != nil {
+			return coldStart, fmt.Errorf("failed to start worker on task queue %v: %w", coldStart.TaskQueue, err)
+		}
+	}
+	if err := r.getWorkflowResult(ctx, execution, nil); err != nil {
+		return coldStart, fmt.Errorf("kitchen sink workflow failed: %w", err)
+	}
+	completed := time.Since(start)
+	stopPoll()
+	select {
+	case coldStart.PollerRegistration = <-pollerSeen:
+	default:
+		coldStart.PollerRegistration = completed
+	}
+
+	if coldStart.FirstTaskCompletion, err = r.DispatchLatency(ctx, execution.GetID(), execution.GetRunID()); err != nil {
+		return coldStart, err
+	}
+	r.RecordTimer("omes_cold_start_poller_registration_latency", nil, coldStart.PollerRegistration)
+	r.RecordTimer("omes_cold_start_latency", nil, coldStart.FirstTaskCompletion)
+	return coldStart, nil
+}
+
+// awaitColdStartPoller returns whether a workflow poller was seen on the task queue before the
+// context is done. Failed descriptions are retried.
+func (r *Run) awaitColdStartPoller(ctx context.Context, taskQueue string) bool {
+	for {
+		resp, err := r.Client.WorkflowService().DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+			Namespace:     r.Namespace,
+			TaskQueue:     &taskqueue.TaskQueue{Name: taskQueue},
+			TaskQueueType: enums.TASK_QUEUE_TYPE_WORKFLOW,
+		})
+		if err == nil && len(resp.GetPollers()) > 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(coldStartPollerCheckInterval):
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+)
+
+func useFastColdStartPollerChecks(t *testing.T) {
+	prev := coldStartPollerCheckInterval
+	coldStartPollerCheckInterval = time.Millisecond
+	t.Cleanup(func() { coldStartPollerCheckInterval = prev })
+}
+
+// coldStartClient returns a client whose workflows complete after the delay, with a first workflow
+// task completing after the given time in history, and on whose task queues a poller registers once
+// the returned flag is set.
+func coldStartClient(completion, firstTask time.Duration) (*FakeClient, *int32) {
+	var registered int32
+	start := time.Now()
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: options.ID, Delay: completion}, nil
+		},
+		OnDescribeTaskQueue: pollersOn(func(taskQueue string) bool {
+			return atomic.LoadInt32(&registered) == 1
+		}),
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return []*history.HistoryEvent{
+				historyEvent(enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, start),
+				historyEvent(enums.EVENT_TYPE_WORKFLOW_TASK_COMPLETED, start.Add(firstTask)),
+			}, nil
+		},
+	}
+	return fake, &registered
+}
+
+func TestMeasureColdStart(t *testing.T) {
+	useFastColdStartPollerChecks(t)
+	fake, registered := coldStartClient(200*time.Millisecond, 180*time.Millisecond)
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	var workerTaskQueue string
+	coldStart, err := info.NewRun(3).MeasureColdStart(context.Background(),
+		func(ctx context.Context, taskQueue string) error {
+			workerTaskQueue = taskQueue
+			// The worker registers its poller a while after being started
+			time.AfterFunc(50*time.Millisecond, func() { atomic.StoreInt32(registered, 1) })
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, "test:test-run-cold-3", coldStart.TaskQueue)
+	require.Equal(t, coldStart.TaskQueue, workerTaskQueue)
+	require.Equal(t, coldStart.TaskQueue, fake.Calls("ExecuteWorkflow")[0].Options.TaskQueue)
+	require.GreaterOrEqual(t, coldStart.PollerRegistration, 50*time.Millisecond)
+	require.Less(t, coldStart.PollerRegistration, 200*time.Millisecond)
+	require.Equal(t, 180*time.Millisecond, coldStart.FirstTaskCompletion)
+	require.Len(t, *handler.recorded, 2)
+	require.Equal(t, "omes_cold_start_poller_registration_latency", (*handler.recorded)[0].name)
+	require.Equal(t, "omes_cold_start_latency", (*handler.recorded)[1].name)
+}
+
+func TestMeasureColdStartPollerNotSeen(t *testing.T) {
+	useFastColdStartPollerChecks(t)
+	// The workflow completes without a poller ever being described
+	fake, _ := coldStartClient(20*time.Millisecond, 10*time.Millisecond)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	coldStart, err := info.NewRun(1).MeasureColdStart(context.Background(), nil)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, coldStart.PollerRegistration, 20*time.Millisecond)
+	require.Equal(t, 10*time.Millisecond, coldStart.FirstTaskCompletion)
+}
+
+func TestMeasureColdStartWorkerFailure(t *testing.T) {
+	fake, _ := coldStartClient(time.Millisecond, time.Millisecond)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.NewRun(1).MeasureColdStart(context.Background(),
+		func(ctx context.Context, taskQueue string) error { return errors.New("no capacity") })
+	require.ErrorContains(t, err, "no capacity")
+}
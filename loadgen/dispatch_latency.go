@@ -0,0 +1,54 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/enums/v1"
+)
+
+// DispatchLatency returns the time from the start of the given workflow until its first workflow
+// task completed, from history event timestamps. For a workflow that completes in its first task,
+// this isolates task queue dispatch and worker pickup from workflow execution.
+func (r *Run) DispatchLatency(ctx context.Context, workflowID, runID string) (time.Duration, error) {
+	iter := r.Client.GetWorkflowHistory(ctx, workflowID, runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	var started *time.Time
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return 0, fmt.Errorf("failed reading history of workflow %v: %w", workflowID, err)
+		}
+		switch event.EventType {
+		case enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED:
+			started = event.EventTime
+		case enums.EVENT_TYPE_WORKFLOW_TASK_COMPLETED:
+			if started == nil || event.EventTime == nil {
+				return 0, fmt.Errorf("history of workflow %v lacks start or task completion time", workflowID)
+			}
+			return event.EventTime.Sub(*started), nil
+		}
+	}
+	return 0, fmt.Errorf("workflow %v has no completed workflow task", workflowID)
+}
+
+// ExecuteDispatchLatencyWorkflow executes a kitchen sink workflow that completes immediately and
+// returns its dispatch latency (see DispatchLatency), also recorded in the omes_dispatch_latency
+// timer.
+func (r *Run) ExecuteDispatchLatencyWorkflow(ctx context.Context) (time.Duration, error) {
+	execution, err := r.Client.ExecuteWorkflow(ctx, r.StartWorkflowOptions(), "kitchenSink",
+		&kitchensink.WorkflowInput{InitialActions: []*kitchensink.ActionSet{kitchensink.EmptyResultActionSet()}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to start kitchen sink workflow: %w", err)
+	}
+	if err := r.getWorkflowResult(ctx, execution, nil); err != nil {
+		return 0, fmt.Errorf("kitchen sink workflow failed: %w", err)
+	}
+	latency, err := r.DispatchLatency(ctx, execution.GetID(), execution.GetRunID())
+	if err != nil {
+		return 0, err
+	}
+	r.RecordTimer("omes_dispatch_latency", nil, latency)
+	return latency, nil
+}
@@ -0,0 +1,51 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+)
+
+func historyEvent(eventType enums.EventType, eventTime time.Time) *history.HistoryEvent {
+	return &history.HistoryEvent{EventType: eventType, EventTime: &eventTime}
+}
+
+func TestExecuteDispatchLatencyWorkflow(t *testing.T) {
+	start := time.Now()
+	fake := &FakeClient{
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return []*history.HistoryEvent{
+				historyEvent(enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, start),
+				historyEvent(enums.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED, start),
+				historyEvent(enums.EVENT_TYPE_WORKFLOW_TASK_STARTED, start.Add(40*time.Millisecond)),
+				historyEvent(enums.EVENT_TYPE_WORKFLOW_TASK_COMPLETED, start.Add(55*time.Millisecond)),
+				historyEvent(enums.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED, start.Add(55*time.Millisecond)),
+			}, nil
+		},
+	}
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	latency, err := info.NewRun(1).ExecuteDispatchLatencyWorkflow(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 55*time.Millisecond, latency)
+	require.Equal(t, "kitchenSink", fake.Calls("ExecuteWorkflow")[0].Name)
+	require.Equal(t, []recordedMetric{{
+		kind: "timer", name: "omes_dispatch_latency", tags: map[string]string{"scenario": "test"}, value: 0.055,
+	}}, *handler.recorded)
+}
+
+func TestDispatchLatencyWithoutCompletedTask(t *testing.T) {
+	fake := &FakeClient{
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return []*history.HistoryEvent{historyEvent(enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, time.Now())}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.NewRun(1).DispatchLatency(context.Background(), "wf", "run")
+	require.ErrorContains(t, err, "no completed workflow task")
+}
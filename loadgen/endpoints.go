@@ -0,0 +1,37 @@
+package loadgen
+
+import (
+	"go.temporal.io/sdk/client"
+)
+
+// Endpoint is a server endpoint with its own client, see ScenarioInfo.Endpoints.
+type Endpoint struct {
+	// Address of the endpoint, tagging its metrics and results.
+	Address string
+	Client  client.Client
+}
+
+// EndpointResult contains the iteration outcomes of a single endpoint of a run distributing
+// iterations across endpoints.
+type EndpointResult struct {
+	Address             string         `json:"address"`
+	IterationsStarted   int            `json:"iterationsStarted"`
+	IterationsCompleted int            `json:"iterationsCompleted"`
+	IterationsFailed    int            `json:"iterationsFailed"`
+	Latency             LatencySummary `json:"latency"`
+}
+
+// useEndpoint makes the run use the endpoint's client and address, leaving the shared
+// ScenarioInfo and other runs unchanged.
+func (r *Run) useEndpoint(endpoint Endpoint) {
+	info := *r.ScenarioInfo
+	info.Client = endpoint.Client
+	info.ServerAddress = endpoint.Address
+	r.ScenarioInfo = &info
+}
+
+// endpointFor returns the endpoint of the iteration, round-robin over the endpoints in order.
+func (s *ScenarioInfo) endpointFor(iteration int) Endpoint {
+	n := len(s.Endpoints)
+	return s.Endpoints[((iteration-1)%n+n)%n]
+}
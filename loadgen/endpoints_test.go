@@ -0,0 +1,57 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointsRoundRobin(t *testing.T) {
+	fakes := []*FakeClient{{}, {}, {}}
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 9, MaxConcurrent: 3})
+	for i, fake := range fakes {
+		info.Endpoints = append(info.Endpoints, Endpoint{Address: []string{"a:7233", "b:7233", "c:7233"}[i], Client: fake})
+	}
+	handler := newRecordingMetricsHandler()
+	info.MetricsHandler = handler
+	var result *RunResult
+	info.ReportSinks = []ReportSink{reportSinkFunc(func(r *RunResult) { result = r })}
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+		_, err := run.Client.ExecuteWorkflow(ctx, run.StartWorkflowOptions(), "kitchenSink")
+		return err
+	}}
+	require.NoError(t, executor.Run(context.Background(), info))
+
+	// Each endpoint got every third iteration, with IDs unique across endpoints
+	ids := map[string]bool{}
+	for i, fake := range fakes {
+		starts := fake.Calls("ExecuteWorkflow")
+		require.Len(t, starts, 3)
+		for _, start := range starts {
+			require.False(t, ids[start.Options.ID], "duplicate workflow ID %v", start.Options.ID)
+			ids[start.Options.ID] = true
+		}
+		require.ElementsMatch(t, []string{
+			info.WorkflowIDPrefix() + []string{"1", "2", "3"}[i],
+			info.WorkflowIDPrefix() + []string{"4", "5", "6"}[i],
+			info.WorkflowIDPrefix() + []string{"7", "8", "9"}[i],
+		}, []string{starts[0].Options.ID, starts[1].Options.ID, starts[2].Options.ID})
+	}
+	require.Empty(t, info.Client.(*FakeClient).Calls("ExecuteWorkflow"))
+
+	require.NotNil(t, result)
+	require.Len(t, result.Endpoints, 3)
+	for i, endpoint := range result.Endpoints {
+		require.Equal(t, info.Endpoints[i].Address, endpoint.Address)
+		require.Equal(t, 3, endpoint.IterationsStarted)
+		require.Equal(t, 3, endpoint.IterationsCompleted)
+	}
+	latencies := map[string]int{}
+	for _, metric := range *handler.recorded {
+		if metric.name == "omes_endpoint_iteration_latency" {
+			latencies[metric.tags["endpoint"]]++
+		}
+	}
+	require.Equal(t, map[string]int{"a:7233": 3, "b:7233": 3, "c:7233": 3}, latencies)
+}
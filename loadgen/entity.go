@@ -0,0 +1,113 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+)
+
+// Entities directs iteration load at a fixed set of long-lived "entity" workflows as signals or
+// updates instead of starting a workflow per iteration. Iterations are routed to entities round
+// robin by iteration number. Signal and update latencies are recorded in the
+// omes_entity_signal_latency and omes_entity_update_latency timers. Generated entities outlive the
+// run unless terminated with Terminate, e.g. as GenericExecutor.Teardown. The default kitchen sink
+// entities of the Go worker continue as new when the server suggests it, bounding their history on
+// long runs.
+type Entities struct {
+	// IDs of pre-existing entity workflows to attach to. If empty, Count entities with IDs
+	// "<WorkflowIDPrefix>entity-<index>" are used, and started by the first signal sent to each.
+	IDs []string
+	// Number of entities when IDs is not set. Default is 1.
+	Count int
+	// Workflow entities are started as.
+	WorkflowSpec
+}
+
+// EntityIDs returns the IDs of the entity workflows of the run.
+func (e *Entities) EntityIDs(info *ScenarioInfo) []string {
+	if len(e.IDs) > 0 {
+		return e.IDs
+	}
+	count := e.Count
+	if count <= 0 {
+		count = 1
+	}
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%sentity-%d", info.WorkflowIDPrefix(), i)
+	}
+	return ids
+}
+
+// EntityFor returns the ID of the entity workflow the given iteration is routed to.
+func (e *Entities) EntityFor(run *Run) string {
+	ids := e.EntityIDs(run.ScenarioInfo)
+	index := (run.Iteration - 1) % len(ids)
+	if index < 0 {
+		index += len(ids)
+	}
+	return ids[index]
+}
+
+// Signal sends the signal to the iteration's entity. Entities given by ID must already be running,
+// generated entities are started if not running.
+func (e *Entities) Signal(ctx context.Context, run *Run, signalName string, arg interface{}) error {
+	id := e.EntityFor(run)
+	start := time.Now()
+	var err error
+	if len(e.IDs) > 0 {
+		err = run.Client.SignalWorkflow(ctx, id, "", signalName, arg)
+	} else {
+		workflow, args := e.workflowAndArgs()
+		options := run.DefaultStartWorkflowOptions()
+		options.ID = id
+		_, err = run.Client.SignalWithStartWorkflow(ctx, id, signalName, arg, options, workflow, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed signaling entity %v: %w", id, err)
+	}
+	run.RecordTimer("omes_entity_signal_latency", nil, time.Since(start))
+	return nil
+}
+
+// Update sends the update to the iteration's entity and waits for its result into valuePtr, which
+// may be nil. The entity must already be running.
+func (e *Entities) Update(
+	ctx context.Context,
+	run *Run,
+	updateName string,
+	valuePtr interface{},
+	args ...interface{},
+) error {
+	id := e.EntityFor(run)
+	start := time.Now()
+	handle, err := run.Client.UpdateWorkflow(ctx, id, "", updateName, args...)
+	if err == nil {
+		err = handle.Get(ctx, valuePtr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed updating entity %v: %w", id, err)
+	}
+	run.RecordTimer("omes_entity_update_latency", nil, time.Since(start))
+	return nil
+}
+
+// Terminate terminates the generated entity workflows that are still running. Entities given by
+// ID were not started by the run and are left running.
+func (e *Entities) Terminate(ctx context.Context, info *ScenarioInfo) error {
+	if len(e.IDs) > 0 {
+		return nil
+	}
+	var errs []error
+	for _, id := range e.EntityIDs(info) {
+		err := info.Client.TerminateWorkflow(ctx, id, "", "omes run ended", nil)
+		var notFound *serviceerror.NotFound
+		if err != nil && !errors.As(err, &notFound) {
+			errs = append(errs, fmt.Errorf("failed terminating entity %v: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
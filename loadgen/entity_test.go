@@ -0,0 +1,75 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntitiesSignalStartsGeneratedEntities(t *testing.T) {
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	entities := Entities{Count: 3}
+	for iteration := 1; iteration <= 5; iteration++ {
+		require.NoError(t, entities.Signal(context.Background(), info.NewRun(iteration), "sig", iteration))
+	}
+	calls := fake.Calls()
+	require.Len(t, calls, 5)
+	var ids []string
+	for _, call := range calls {
+		require.Equal(t, "SignalWithStartWorkflow", call.Method)
+		require.Equal(t, "sig", call.Name)
+		require.Equal(t, call.WorkflowID, call.Options.ID)
+		require.Equal(t, info.NewRun(1).TaskQueue(), call.Options.TaskQueue)
+		ids = append(ids, call.WorkflowID)
+	}
+	require.Equal(t, []string{
+		"w-test-run-entity-0", "w-test-run-entity-1", "w-test-run-entity-2",
+		"w-test-run-entity-0", "w-test-run-entity-1",
+	}, ids)
+}
+
+func TestEntitiesAttachToExisting(t *testing.T) {
+	fake := &FakeClient{
+		OnUpdateWorkflow: func(ctx context.Context, workflowID, runID, updateName string,
+			args ...interface{}) (interface{}, error) {
+			return workflowID + "-updated", nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	entities := Entities{IDs: []string{"existing-a", "existing-b"}}
+	require.NoError(t, entities.Signal(context.Background(), info.NewRun(1), "sig", nil))
+	require.NoError(t, entities.Signal(context.Background(), info.NewRun(2), "sig", nil))
+	signals := fake.Calls("SignalWorkflow")
+	require.Len(t, signals, 2)
+	require.Equal(t, "existing-a", signals[0].WorkflowID)
+	require.Equal(t, "existing-b", signals[1].WorkflowID)
+	require.Empty(t, fake.Calls("SignalWithStartWorkflow"))
+
+	var result string
+	require.NoError(t, entities.Update(context.Background(), info.NewRun(4), "upd", &result, "arg"))
+	require.Equal(t, "existing-b-updated", result)
+	require.Equal(t, "upd", fake.Calls("UpdateWorkflow")[0].Name)
+}
+
+func TestEntitiesTerminatedAtRunEnd(t *testing.T) {
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 3})
+	entities := Entities{Count: 2}
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			return entities.Signal(ctx, run, "sig", nil)
+		},
+		Teardown: entities.Terminate,
+	}
+	require.NoError(t, executor.Run(context.Background(), info))
+	terminations := fake.Calls("TerminateWorkflow")
+	require.Len(t, terminations, 2)
+	require.Equal(t, "w-test-run-entity-0", terminations[0].WorkflowID)
+	require.Equal(t, "w-test-run-entity-1", terminations[1].WorkflowID)
+
+	// Entities given by ID are left running
+	require.NoError(t, (&Entities{IDs: []string{"existing"}}).Terminate(context.Background(), &info))
+	require.Len(t, fake.Calls("TerminateWorkflow"), 2)
+}
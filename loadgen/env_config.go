@@ -0,0 +1,65 @@
+package loadgen
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables setting the core run configuration, for containerized and CI environments
+// where flags are awkward, see RunConfigurationFromEnv.
+const (
+	IterationsEnvVar             = "OMES_ITERATIONS"
+	DurationEnvVar               = "OMES_DURATION"
+	MaxConcurrentEnvVar          = "OMES_MAX_CONCURRENT"
+	MaxIterationsPerSecondEnvVar = "OMES_MAX_ITERATIONS_PER_SECOND"
+)
+
+// RunConfigurationFromEnv reads the iterations, duration, max concurrent iterations and max
+// iterations per second of the run from the IterationsEnvVar, DurationEnvVar, MaxConcurrentEnvVar
+// and MaxIterationsPerSecondEnvVar environment variables, looked up with lookup, or os.LookupEnv if
+// nil. Unset or empty variables are left zero. CLI flags are meant to be layered over the result
+// with LayerRunConfiguration, so that it is itself layered over the scenario's defaults.
+func RunConfigurationFromEnv(lookup func(string) (string, bool)) (RunConfiguration, error) {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	get := func(name string) string {
+		v, _ := lookup(name)
+		return v
+	}
+	var config RunConfiguration
+	if v := get(IterationsEnvVar); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return config, fmt.Errorf("invalid %v %q: expected a non-negative integer", IterationsEnvVar, v)
+		}
+		config.Iterations = i
+	}
+	if v := get(DurationEnvVar); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return config, fmt.Errorf("invalid %v %q: expected a non-negative duration", DurationEnvVar, v)
+		}
+		config.Duration = d
+	}
+	if config.Iterations > 0 && config.Duration > 0 {
+		return config, fmt.Errorf("only one of %v and %v can be set", IterationsEnvVar, DurationEnvVar)
+	}
+	if v := get(MaxConcurrentEnvVar); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return config, fmt.Errorf("invalid %v %q: expected a non-negative integer", MaxConcurrentEnvVar, v)
+		}
+		config.MaxConcurrent = i
+	}
+	if v := get(MaxIterationsPerSecondEnvVar); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return config, fmt.Errorf("invalid %v %q: expected a non-negative number", MaxIterationsPerSecondEnvVar, v)
+		}
+		config.MaxIterationsPerSecond = f
+	}
+	return config, nil
+}
@@ -0,0 +1,87 @@
+package loadgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestRunConfigurationFromEnv(t *testing.T) {
+	config, err := RunConfigurationFromEnv(envLookup(map[string]string{
+		IterationsEnvVar:             "100",
+		MaxConcurrentEnvVar:          "20",
+		MaxIterationsPerSecondEnvVar: "12.5",
+		DurationEnvVar:               "",
+	}))
+	require.NoError(t, err)
+	require.Equal(t, RunConfiguration{Iterations: 100, MaxConcurrent: 20, MaxIterationsPerSecond: 12.5}, config)
+
+	config, err = RunConfigurationFromEnv(envLookup(map[string]string{DurationEnvVar: "90s"}))
+	require.NoError(t, err)
+	require.Equal(t, RunConfiguration{Duration: 90 * time.Second}, config)
+
+	config, err = RunConfigurationFromEnv(envLookup(nil))
+	require.NoError(t, err)
+	require.Equal(t, RunConfiguration{}, config)
+
+	t.Setenv(MaxConcurrentEnvVar, "7")
+	config, err = RunConfigurationFromEnv(nil)
+	require.NoError(t, err)
+	require.Equal(t, 7, config.MaxConcurrent)
+}
+
+func TestRunConfigurationFromEnvInvalid(t *testing.T) {
+	for env, message := range map[string]string{
+		IterationsEnvVar:             "invalid OMES_ITERATIONS \"ten\"",
+		DurationEnvVar:               "invalid OMES_DURATION \"ten\"",
+		MaxConcurrentEnvVar:          "invalid OMES_MAX_CONCURRENT \"ten\"",
+		MaxIterationsPerSecondEnvVar: "invalid OMES_MAX_ITERATIONS_PER_SECOND \"ten\"",
+	} {
+		_, err := RunConfigurationFromEnv(envLookup(map[string]string{env: "ten"}))
+		require.ErrorContains(t, err, message)
+	}
+	_, err := RunConfigurationFromEnv(envLookup(map[string]string{MaxConcurrentEnvVar: "-1"}))
+	require.ErrorContains(t, err, "expected a non-negative integer")
+	_, err = RunConfigurationFromEnv(envLookup(map[string]string{IterationsEnvVar: "5", DurationEnvVar: "1m"}))
+	require.EqualError(t, err, "only one of OMES_ITERATIONS and OMES_DURATION can be set")
+}
+
+func TestRunConfigurationFromEnvPrecedence(t *testing.T) {
+	defaults := RunConfiguration{Iterations: 10, MaxConcurrent: 5, MaxIterationsPerSecond: 1}
+	env, err := RunConfigurationFromEnv(envLookup(map[string]string{
+		IterationsEnvVar:             "100",
+		MaxConcurrentEnvVar:          "20",
+		MaxIterationsPerSecondEnvVar: "2",
+	}))
+	require.NoError(t, err)
+
+	// Env over defaults
+	config := EffectiveRunConfiguration(defaults, LayerRunConfiguration(env, RunConfiguration{}))
+	require.Equal(t, 100, config.Iterations)
+	require.Equal(t, 20, config.MaxConcurrent)
+	require.Equal(t, 2.0, config.MaxIterationsPerSecond)
+
+	// Flags over env, which remains over defaults where flags are unset
+	config = EffectiveRunConfiguration(defaults, LayerRunConfiguration(env, RunConfiguration{MaxConcurrent: 50}))
+	require.Equal(t, 100, config.Iterations)
+	require.Equal(t, 50, config.MaxConcurrent)
+	require.Equal(t, 2.0, config.MaxIterationsPerSecond)
+
+	// A duration flag replaces iterations from env rather than conflicting with them
+	config = EffectiveRunConfiguration(defaults, LayerRunConfiguration(env, RunConfiguration{Duration: time.Minute}))
+	require.Zero(t, config.Iterations)
+	require.Equal(t, time.Minute, config.Duration)
+
+	// Unset env leaves the defaults
+	config = EffectiveRunConfiguration(defaults, LayerRunConfiguration(RunConfiguration{}, RunConfiguration{}))
+	require.Equal(t, 10, config.Iterations)
+	require.Equal(t, 5, config.MaxConcurrent)
+}
@@ -0,0 +1,120 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/grpc/status"
+)
+
+// Categories of iteration errors, see ClassifyIterationError.
+const (
+	ErrorCategoryNonDeterminism      = "non_determinism"
+	ErrorCategoryWorkflowTaskFailure = "workflow_task_failure"
+	ErrorCategoryPayloadSizeLimit    = "payload_size_limit"
+	ErrorCategoryTimeout             = "timeout"
+	ErrorCategoryCanceled            = "canceled"
+	ErrorCategoryApplication         = "application"
+	ErrorCategoryRPC                 = "rpc"
+	ErrorCategoryOther               = "other"
+)
+
+// ClassifyIterationError returns the category of an iteration error, the first of: workflow
+// non-determinism (see DetectNonDeterminism), other workflow task failures, size limits (see
+// IsPayloadSizeLimitError), timeouts, cancellations, application errors, other server or gRPC
+// errors, and other errors.
+func ClassifyIterationError(err error) string {
+	var taskErr *WorkflowTaskFailureError
+	var timeoutErr *temporal.TimeoutError
+	var canceledErr *temporal.CanceledError
+	var applicationErr *temporal.ApplicationError
+	var serviceErr serviceerror.ServiceError
+	if _, ok := DetectNonDeterminism(err); ok {
+		return ErrorCategoryNonDeterminism
+	} else if errors.As(err, &taskErr) {
+		return ErrorCategoryWorkflowTaskFailure
+	} else if IsPayloadSizeLimitError(err) {
+		return ErrorCategoryPayloadSizeLimit
+	} else if errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCategoryTimeout
+	} else if errors.As(err, &canceledErr) || errors.Is(err, context.Canceled) {
+		return ErrorCategoryCanceled
+	} else if errors.As(err, &applicationErr) {
+		return ErrorCategoryApplication
+	} else if _, ok := status.FromError(err); ok || errors.As(err, &serviceErr) {
+		return ErrorCategoryRPC
+	}
+	return ErrorCategoryOther
+}
+
+// IterationErrorRecord is a line of the error log, see ScenarioInfo.ErrorLogPath.
+type IterationErrorRecord struct {
+	Time      time.Time `json:"time"`
+	Iteration int       `json:"iteration"`
+	// ID of the failed workflow if the error carries it, otherwise the default workflow ID of the
+	// iteration.
+	WorkflowID string `json:"workflowId"`
+	// See ClassifyIterationError.
+	Category string `json:"category"`
+	Error    string `json:"error"`
+}
+
+// errorLogFile appends an IterationErrorRecord per failed iteration to a file as JSON lines. Each
+// record is written as it happens, unbuffered, so that failures are on disk even if the run dies.
+// It is safe for concurrent use.
+type errorLogFile struct {
+	lock sync.Mutex
+	file *os.File
+	err  error
+}
+
+func openErrorLogFile(path string) (*errorLogFile, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening error log file: %w", err)
+	}
+	return &errorLogFile{file: file}, nil
+}
+
+func (l *errorLogFile) record(run *Run, err error) {
+	record := IterationErrorRecord{
+		Time:       time.Now(),
+		Iteration:  run.Iteration,
+		WorkflowID: run.DefaultStartWorkflowOptions().ID,
+		Category:   ClassifyIterationError(err),
+		Error:      err.Error(),
+	}
+	var taskErr *WorkflowTaskFailureError
+	if errors.As(err, &taskErr) && taskErr.WorkflowID != "" {
+		record.WorkflowID = taskErr.WorkflowID
+	}
+	line, marshalErr := json.Marshal(record)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.err == nil {
+		l.err = marshalErr
+	}
+	if l.err == nil {
+		_, l.err = l.file.Write(append(line, '\n'))
+	}
+}
+
+// Close closes the file, returning the first error that occurred while writing.
+func (l *errorLogFile) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if err := l.file.Close(); l.err == nil {
+		l.err = err
+	}
+	if l.err != nil {
+		return fmt.Errorf("failed writing error log: %w", l.err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package loadgen
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestClassifyIterationError(t *testing.T) {
+	for category, err := range map[string]error{
+		ErrorCategoryNonDeterminism: fmt.Errorf("failed: %w", &WorkflowTaskFailureError{
+			Cause: enums.WORKFLOW_TASK_FAILED_CAUSE_NON_DETERMINISTIC_ERROR, Err: errors.New("timeout")}),
+		ErrorCategoryWorkflowTaskFailure: &WorkflowTaskFailureError{
+			Cause: enums.WORKFLOW_TASK_FAILED_CAUSE_WORKFLOW_WORKER_UNHANDLED_FAILURE, Err: errors.New("panic")},
+		ErrorCategoryPayloadSizeLimit: serviceerror.NewInvalidArgument("Blob data size exceeds limit."),
+		ErrorCategoryTimeout:          temporal.NewTimeoutError(enums.TIMEOUT_TYPE_START_TO_CLOSE, nil),
+		ErrorCategoryCanceled:         fmt.Errorf("awaiting: %w", context.Canceled),
+		ErrorCategoryApplication:      temporal.NewApplicationError("boom", "Boom"),
+		ErrorCategoryRPC:              serviceerror.NewUnavailable("unavailable"),
+		ErrorCategoryOther:            errors.New("something else"),
+	} {
+		require.Equal(t, category, ClassifyIterationError(err), err.Error())
+	}
+}
+
+func TestGenericExecutorWritesErrorLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+	// Existing content is kept
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644))
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 5, MaxConcurrent: 1})
+	info.ErrorLogPath = path
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			if run.Iteration == 3 {
+				return temporal.NewTimeoutError(enums.TIMEOUT_TYPE_START_TO_CLOSE, nil)
+			}
+			return nil
+		},
+	}
+	require.ErrorContains(t, executor.Run(context.Background(), info), "run finished with error")
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, lines, 2)
+	require.Equal(t, "{}", lines[0])
+	var record IterationErrorRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	require.Equal(t, 3, record.Iteration)
+	require.Equal(t, info.WorkflowIDPrefix()+"3", record.WorkflowID)
+	require.Equal(t, ErrorCategoryTimeout, record.Category)
+	require.Contains(t, record.Error, "StartToClose")
+	require.False(t, record.Time.IsZero())
+}
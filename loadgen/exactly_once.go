@@ -0,0 +1,91 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ExactlyOnceError is returned by ExactlyOnce.Verify when the number of distinct side effects does
+// not equal the number of unique idempotency keys issued.
+type ExactlyOnceError struct {
+	// Unique idempotency keys issued.
+	Keys int
+	// Distinct side effects counted.
+	Effects int
+}
+
+func (e *ExactlyOnceError) Error() string {
+	if e.Effects > e.Keys {
+		return fmt.Sprintf("%v side effects for %v idempotency keys, %v applied more than once",
+			e.Effects, e.Keys, e.Effects-e.Keys)
+	}
+	return fmt.Sprintf("%v side effects for %v idempotency keys, %v not applied", e.Effects, e.Keys, e.Keys-e.Effects)
+}
+
+// ExactlyOnce verifies exactly-once semantics with idempotency keys: iterations pass a key with
+// each side effect they cause, e.g. as a signal argument to an accumulator workflow, possibly more
+// than once as when retrying, and Verify checks that the side effects counted at the end equal the
+// number of unique keys issued. It is safe for concurrent use.
+type ExactlyOnce struct {
+	// Counts the distinct side effects applied, e.g. by querying an accumulator workflow, see
+	// QueryCount.
+	CountEffects func(ctx context.Context) (int, error)
+
+	lock sync.Mutex
+	keys map[string]struct{}
+}
+
+// Key returns the idempotency key of the iteration, "<RunID>-<Iteration>", and records it. The same
+// iteration always gets the same key, so reissuing it must not cause another side effect.
+func (e *ExactlyOnce) Key(run *Run) string {
+	key := fmt.Sprintf("%v-%v", run.RunID, run.Iteration)
+	e.Record(key)
+	return key
+}
+
+// Record records an idempotency key issued other than by Key.
+func (e *ExactlyOnce) Record(key string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.keys == nil {
+		e.keys = map[string]struct{}{}
+	}
+	e.keys[key] = struct{}{}
+}
+
+// Keys returns the number of unique idempotency keys issued.
+func (e *ExactlyOnce) Keys() int {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return len(e.keys)
+}
+
+// Verify counts the side effects and returns an *ExactlyOnceError if they do not equal the number
+// of unique keys issued. Call it once iterations are done, e.g. after the run.
+func (e *ExactlyOnce) Verify(ctx context.Context) error {
+	effects, err := e.CountEffects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed counting side effects: %w", err)
+	}
+	if keys := e.Keys(); effects != keys {
+		return &ExactlyOnceError{Keys: keys, Effects: effects}
+	}
+	return nil
+}
+
+// QueryCount returns a function counting side effects by sending the query to the workflow and
+// decoding its result as an integer, for ExactlyOnce.CountEffects.
+func (s *ScenarioInfo) QueryCount(workflowID, queryType string, args ...interface{}) func(context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		value, err := s.Client.QueryWorkflow(ctx, workflowID, "", queryType, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed querying %v of workflow %v: %w", queryType, workflowID, err)
+		}
+		var count int
+		if err := value.Get(&count); err != nil {
+			return 0, fmt.Errorf("failed decoding %v of workflow %v: %w", queryType, workflowID, err)
+		}
+		return count, nil
+	}
+}
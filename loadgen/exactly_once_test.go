@@ -0,0 +1,83 @@
+package loadgen
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccumulator applies signaled keys as side effects, deduplicating them if idempotent.
+type fakeAccumulator struct {
+	idempotent bool
+	lock       sync.Mutex
+	keys       map[string]bool
+	effects    int
+}
+
+func (a *fakeAccumulator) client() *FakeClient {
+	a.keys = map[string]bool{}
+	return &FakeClient{
+		OnSignalWorkflow: func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+			a.lock.Lock()
+			defer a.lock.Unlock()
+			key := arg.(string)
+			if !a.idempotent || !a.keys[key] {
+				a.effects++
+			}
+			a.keys[key] = true
+			return nil
+		},
+		OnQueryWorkflow: func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error) {
+			a.lock.Lock()
+			defer a.lock.Unlock()
+			return a.effects, nil
+		},
+	}
+}
+
+// runExactlyOnce runs iterations signaling their key to the accumulator, every third twice as if
+// retried, and verifies the effects.
+func runExactlyOnce(t *testing.T, accumulator *fakeAccumulator) (*ExactlyOnce, error) {
+	info := NewTestScenarioInfo(accumulator.client(), RunConfiguration{Iterations: 30, MaxConcurrent: 5})
+	verifier := &ExactlyOnce{CountEffects: info.QueryCount("accumulator", "count")}
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+		sends := 1
+		if run.Iteration%3 == 0 {
+			sends = 2
+		}
+		for i := 0; i < sends; i++ {
+			if err := run.Client.SignalWorkflow(ctx, "accumulator", "", "add", verifier.Key(run)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+	require.NoError(t, executor.Run(context.Background(), info))
+	return verifier, verifier.Verify(context.Background())
+}
+
+func TestExactlyOnceDuplicateKeysCountOnce(t *testing.T) {
+	accumulator := &fakeAccumulator{idempotent: true}
+	verifier, err := runExactlyOnce(t, accumulator)
+	require.NoError(t, err)
+	require.Equal(t, 30, verifier.Keys())
+	require.Len(t, accumulator.keys, 30)
+}
+
+func TestExactlyOnceDetectsDoubleCounting(t *testing.T) {
+	_, err := runExactlyOnce(t, &fakeAccumulator{})
+	var exactlyOnceErr *ExactlyOnceError
+	require.ErrorAs(t, err, &exactlyOnceErr)
+	require.Equal(t, &ExactlyOnceError{Keys: 30, Effects: 40}, exactlyOnceErr)
+	require.EqualError(t, err, "40 side effects for 30 idempotency keys, 10 applied more than once")
+}
+
+func TestExactlyOnceDetectsMissingEffects(t *testing.T) {
+	verifier := &ExactlyOnce{CountEffects: func(ctx context.Context) (int, error) { return 1, nil }}
+	verifier.Record("a")
+	verifier.Record("b")
+	verifier.Record("a")
+	require.EqualError(t, verifier.Verify(context.Background()), "1 side effects for 2 idempotency keys, 1 not applied")
+}
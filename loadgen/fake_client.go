@@ -0,0 +1,651 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/api/operatorservice/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// FakeClient is an in-memory client.Client for unit testing scenario executors without a server.
+// It implements the subset of the client used by Run helpers, including the kitchen sink helpers, and records every call. Responses
+// are programmable via the On* fields, by default workflows complete immediately with a nil
+// result and other calls succeed. Calling any other client method panics. It is safe for
+// concurrent use.
+type FakeClient struct {
+	client.Client
+	// Called by ExecuteWorkflow and SignalWithStartWorkflow to create the started workflow run.
+	// An error is returned as the start error.
+	OnExecuteWorkflow func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+		args ...interface{}) (client.WorkflowRun, error)
+	// Called by SignalWorkflow and SignalWithStartWorkflow.
+	OnSignalWorkflow func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error
+	// Called by QueryWorkflow, the result is encoded with the default data converter.
+	OnQueryWorkflow func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error)
+	// Called by UpdateWorkflow, the result is returned by the update handle. An error is returned
+	// as the update failure.
+	OnUpdateWorkflow func(ctx context.Context, workflowID, runID, updateName string, args ...interface{}) (interface{}, error)
+	// Called by CancelWorkflow, an error is returned as the cancel error.
+	OnCancelWorkflow func(ctx context.Context, workflowID, runID string) error
+	// Called by GetWorkflowHistory and GetWorkflowExecutionHistoryReverse of the workflow service,
+	// which returns the events newest first. Default is an empty history.
+	OnGetWorkflowHistory func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error)
+	// Called by ResetWorkflowExecution to create the new run. An error is returned as the reset
+	// error. Default is a run completing immediately with a nil result.
+	OnResetWorkflowExecution func(ctx context.Context, request *workflowservice.ResetWorkflowExecutionRequest) (
+		client.WorkflowRun, error)
+	// Called by ListWorkflow. Default is an empty response.
+	OnListWorkflow func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (
+		*workflowservice.ListWorkflowExecutionsResponse, error)
+	// Called by ListSearchAttributes of the operator service. Default is an empty response.
+	OnListSearchAttributes func(ctx context.Context, request *operatorservice.ListSearchAttributesRequest) (
+		*operatorservice.ListSearchAttributesResponse, error)
+	// Called by DescribeTaskQueue of the workflow service. Default is an empty response.
+	OnDescribeTaskQueue func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+		*workflowservice.DescribeTaskQueueResponse, error)
+	// Called by PollActivityTaskQueue of the workflow service. Default is an empty response, i.e. a
+	// poll without a task.
+	OnPollActivityTaskQueue func(ctx context.Context, request *workflowservice.PollActivityTaskQueueRequest) (
+		*workflowservice.PollActivityTaskQueueResponse, error)
+	// Called by DescribeWorkflowExecution. Default is an empty response.
+	OnDescribeWorkflowExecution func(ctx context.Context, workflowID, runID string) (
+		*workflowservice.DescribeWorkflowExecutionResponse, error)
+
+	lock      sync.Mutex
+	calls     []FakeClientCall
+	runs      map[string]client.WorkflowRun
+	schedules map[string]*fakeSchedule
+}
+
+// FakeClientCall is a call recorded by FakeClient.
+type FakeClientCall struct {
+	// Client method name, e.g. "ExecuteWorkflow".
+	Method     string
+	WorkflowID string
+	// Workflow type for starts, signal name for signals, query type for queries, and schedule ID
+	// for schedule calls.
+	Name string
+	// Start options for starts.
+	Options *client.StartWorkflowOptions
+	Args    []interface{}
+}
+
+// FakeWorkflowRun is a client.WorkflowRun that completes with the given result or error after the
+// given delay.
+type FakeWorkflowRun struct {
+	ID     string
+	RunID  string
+	Result interface{}
+	Err    error
+	Delay  time.Duration
+}
+
+// NewTestScenarioInfo creates a scenario info around the given client, typically a FakeClient,
+// with no-op logging and metrics for unit testing executors.
+func NewTestScenarioInfo(c client.Client, configuration RunConfiguration) ScenarioInfo {
+	return ScenarioInfo{
+		ScenarioName:   "test",
+		RunID:          "test-run",
+		Logger:         zap.NewNop().Sugar(),
+		MetricsHandler: client.MetricsNopHandler,
+		Client:         c,
+		Configuration:  configuration,
+		Namespace:      "default",
+	}
+}
+
+// Calls returns the calls recorded so far, optionally filtered to the given methods.
+func (f *FakeClient) Calls(methods ...string) []FakeClientCall {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var calls []FakeClientCall
+	for _, call := range f.calls {
+		if len(methods) == 0 || containsString(methods, call.Method) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (f *FakeClient) record(call FakeClientCall) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *FakeClient) start(
+	ctx context.Context,
+	options client.StartWorkflowOptions,
+	workflow interface{},
+	args ...interface{},
+) (client.WorkflowRun, error) {
+	var run client.WorkflowRun = &FakeWorkflowRun{ID: options.ID, RunID: "run-" + options.ID}
+	if f.OnExecuteWorkflow != nil {
+		var err error
+		if run, err = f.OnExecuteWorkflow(ctx, options, workflow, args...); err != nil {
+			return nil, err
+		}
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.runs == nil {
+		f.runs = map[string]client.WorkflowRun{}
+	}
+	f.runs[options.ID] = run
+	return run, nil
+}
+
+func (f *FakeClient) ExecuteWorkflow(
+	ctx context.Context,
+	options client.StartWorkflowOptions,
+	workflow interface{},
+	args ...interface{},
+) (client.WorkflowRun, error) {
+	f.record(FakeClientCall{
+		Method: "ExecuteWorkflow", WorkflowID: options.ID, Name: workflowName(workflow), Options: &options, Args: args,
+	})
+	return f.start(ctx, options, workflow, args...)
+}
+
+// GetWorkflow returns the run of a workflow started on this client, or a run completing
+// immediately with a nil result if unknown.
+func (f *FakeClient) GetWorkflow(ctx context.Context, workflowID string, runID string) client.WorkflowRun {
+	f.record(FakeClientCall{Method: "GetWorkflow", WorkflowID: workflowID})
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if run, ok := f.runs[workflowID]; ok {
+		return run
+	}
+	return &FakeWorkflowRun{ID: workflowID, RunID: runID}
+}
+
+func (f *FakeClient) SignalWorkflow(
+	ctx context.Context,
+	workflowID string,
+	runID string,
+	signalName string,
+	arg interface{},
+) error {
+	f.record(FakeClientCall{Method: "SignalWorkflow", WorkflowID: workflowID, Name: signalName, Args: []interface{}{arg}})
+	if f.OnSignalWorkflow != nil {
+		return f.OnSignalWorkflow(ctx, workflowID, runID, signalName, arg)
+	}
+	return nil
+}
+
+func (f *FakeClient) SignalWithStartWorkflow(
+	ctx context.Context,
+	workflowID string,
+	signalName string,
+	signalArg interface{},
+	options client.StartWorkflowOptions,
+	workflow interface{},
+	workflowArgs ...interface{},
+) (client.WorkflowRun, error) {
+	f.record(FakeClientCall{
+		Method: "SignalWithStartWorkflow", WorkflowID: workflowID, Name: signalName, Options: &options,
+		Args: append([]interface{}{signalArg}, workflowArgs...),
+	})
+	if f.OnSignalWorkflow != nil {
+		if err := f.OnSignalWorkflow(ctx, workflowID, "", signalName, signalArg); err != nil {
+			return nil, err
+		}
+	}
+	options.ID = workflowID
+	return f.start(ctx, options, workflow, workflowArgs...)
+}
+
+func (f *FakeClient) QueryWorkflow(
+	ctx context.Context,
+	workflowID string,
+	runID string,
+	queryType string,
+	args ...interface{},
+) (converter.EncodedValue, error) {
+	f.record(FakeClientCall{Method: "QueryWorkflow", WorkflowID: workflowID, Name: queryType, Args: args})
+	var result interface{}
+	if f.OnQueryWorkflow != nil {
+		var err error
+		if result, err = f.OnQueryWorkflow(ctx, workflowID, runID, queryType, args...); err != nil {
+			return nil, err
+		}
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(result)
+	if err != nil {
+		return nil, err
+	}
+	return fakeEncodedValue{payload}, nil
+}
+
+func (f *FakeClient) UpdateWorkflow(
+	ctx context.Context,
+	workflowID string,
+	workflowRunID string,
+	updateName string,
+	args ...interface{},
+) (client.WorkflowUpdateHandle, error) {
+	f.record(FakeClientCall{Method: "UpdateWorkflow", WorkflowID: workflowID, Name: updateName, Args: args})
+	handle := &fakeUpdateHandle{workflowID: workflowID, runID: workflowRunID}
+	if f.OnUpdateWorkflow != nil {
+		handle.result, handle.err = f.OnUpdateWorkflow(ctx, workflowID, workflowRunID, updateName, args...)
+	}
+	return handle, nil
+}
+
+func (f *FakeClient) GetWorkflowHistory(
+	ctx context.Context,
+	workflowID string,
+	runID string,
+	isLongPoll bool,
+	filterType enums.HistoryEventFilterType,
+) client.HistoryEventIterator {
+	f.record(FakeClientCall{Method: "GetWorkflowHistory", WorkflowID: workflowID})
+	iter := &fakeHistoryIterator{}
+	if f.OnGetWorkflowHistory != nil {
+		iter.events, iter.err = f.OnGetWorkflowHistory(ctx, workflowID, runID)
+	}
+	return iter
+}
+
+func (f *FakeClient) ListWorkflow(
+	ctx context.Context,
+	request *workflowservice.ListWorkflowExecutionsRequest,
+) (*workflowservice.ListWorkflowExecutionsResponse, error) {
+	f.record(FakeClientCall{Method: "ListWorkflow", Name: request.Query})
+	if f.OnListWorkflow != nil {
+		return f.OnListWorkflow(ctx, request)
+	}
+	return &workflowservice.ListWorkflowExecutionsResponse{}, nil
+}
+
+func (f *FakeClient) DescribeWorkflowExecution(
+	ctx context.Context,
+	workflowID string,
+	runID string,
+) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+	f.record(FakeClientCall{Method: "DescribeWorkflowExecution", WorkflowID: workflowID})
+	if f.OnDescribeWorkflowExecution != nil {
+		return f.OnDescribeWorkflowExecution(ctx, workflowID, runID)
+	}
+	return &workflowservice.DescribeWorkflowExecutionResponse{}, nil
+}
+
+func (f *FakeClient) ResetWorkflowExecution(
+	ctx context.Context,
+	request *workflowservice.ResetWorkflowExecutionRequest,
+) (*workflowservice.ResetWorkflowExecutionResponse, error) {
+	workflowID := request.GetWorkflowExecution().GetWorkflowId()
+	f.record(FakeClientCall{Method: "ResetWorkflowExecution", WorkflowID: workflowID, Args: []interface{}{request}})
+	var run client.WorkflowRun = &FakeWorkflowRun{
+		ID: workflowID, RunID: "reset-" + request.GetWorkflowExecution().GetRunId(),
+	}
+	if f.OnResetWorkflowExecution != nil {
+		var err error
+		if run, err = f.OnResetWorkflowExecution(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.runs == nil {
+		f.runs = map[string]client.WorkflowRun{}
+	}
+	f.runs[workflowID] = run
+	return &workflowservice.ResetWorkflowExecutionResponse{RunId: run.GetRunID()}, nil
+}
+
+func (f *FakeClient) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
+	f.record(FakeClientCall{Method: "CancelWorkflow", WorkflowID: workflowID})
+	if f.OnCancelWorkflow != nil {
+		return f.OnCancelWorkflow(ctx, workflowID, runID)
+	}
+	return nil
+}
+
+func (f *FakeClient) TerminateWorkflow(
+	ctx context.Context,
+	workflowID string,
+	runID string,
+	reason string,
+	details ...interface{},
+) error {
+	f.record(FakeClientCall{Method: "TerminateWorkflow", WorkflowID: workflowID, Name: reason, Args: details})
+	return nil
+}
+
+// WorkflowService returns a workflow service that only supports DescribeTaskQueue, recorded as
+// "DescribeTaskQueue" with the task queue name as Name, PollActivityTaskQueue, recorded as
+// "PollActivityTaskQueue" with the task queue name as Name and the request as the only arg,
+// RespondActivityTaskFailed, recorded as "RespondActivityTaskFailed" with the request as the only
+// arg, StartWorkflowExecution, recorded as "StartWorkflowExecution" with the workflow type as Name and
+// the request as the only arg, and GetWorkflowExecutionHistoryReverse, recorded once per page as
+// "GetWorkflowExecutionHistoryReverse". Workflows started through it complete immediately with a
+// nil result.
+func (f *FakeClient) WorkflowService() workflowservice.WorkflowServiceClient {
+	return &fakeWorkflowService{client: f}
+}
+
+// ScheduleClient returns an in-memory schedule client. Creating an existing schedule fails with
+// temporal.ErrScheduleAlreadyRunning, and each trigger counts as one action of the schedule.
+// Schedule calls are recorded as "CreateSchedule", "TriggerSchedule", "PauseSchedule",
+// "UnpauseSchedule", "DescribeSchedule" and "DeleteSchedule".
+func (f *FakeClient) ScheduleClient() client.ScheduleClient {
+	return &fakeScheduleClient{f}
+}
+
+// OperatorService returns an operator service that only accepts adding search attributes.
+func (f *FakeClient) OperatorService() operatorservice.OperatorServiceClient {
+	return fakeOperatorService{client: f}
+}
+
+func (f *FakeClient) Close() {}
+
+type fakeWorkflowService struct {
+	workflowservice.WorkflowServiceClient
+	client *FakeClient
+}
+
+func (s *fakeWorkflowService) DescribeTaskQueue(
+	ctx context.Context,
+	request *workflowservice.DescribeTaskQueueRequest,
+	opts ...grpc.CallOption,
+) (*workflowservice.DescribeTaskQueueResponse, error) {
+	s.client.record(FakeClientCall{Method: "DescribeTaskQueue", Name: request.GetTaskQueue().GetName()})
+	if s.client.OnDescribeTaskQueue != nil {
+		return s.client.OnDescribeTaskQueue(ctx, request)
+	}
+	return &workflowservice.DescribeTaskQueueResponse{}, nil
+}
+
+func (s *fakeWorkflowService) PollActivityTaskQueue(
+	ctx context.Context,
+	request *workflowservice.PollActivityTaskQueueRequest,
+	opts ...grpc.CallOption,
+) (*workflowservice.PollActivityTaskQueueResponse, error) {
+	s.client.record(FakeClientCall{
+		Method: "PollActivityTaskQueue", Name: request.GetTaskQueue().GetName(), Args: []interface{}{request},
+	})
+	if s.client.OnPollActivityTaskQueue != nil {
+		return s.client.OnPollActivityTaskQueue(ctx, request)
+	}
+	return &workflowservice.PollActivityTaskQueueResponse{}, nil
+}
+
+func (s *fakeWorkflowService) RespondActivityTaskFailed(
+	ctx context.Context,
+	request *workflowservice.RespondActivityTaskFailedRequest,
+	opts ...grpc.CallOption,
+) (*workflowservice.RespondActivityTaskFailedResponse, error) {
+	s.client.record(FakeClientCall{Method: "RespondActivityTaskFailed", Args: []interface{}{request}})
+	return &workflowservice.RespondActivityTaskFailedResponse{}, nil
+}
+
+func (s *fakeWorkflowService) StartWorkflowExecution(
+	ctx context.Context,
+	request *workflowservice.StartWorkflowExecutionRequest,
+	opts ...grpc.CallOption,
+) (*workflowservice.StartWorkflowExecutionResponse, error) {
+	s.client.record(FakeClientCall{
+		Method: "StartWorkflowExecution", WorkflowID: request.WorkflowId, Name: request.GetWorkflowType().GetName(),
+		Args: []interface{}{request},
+	})
+	run := &FakeWorkflowRun{ID: request.WorkflowId, RunID: "run-" + request.WorkflowId}
+	s.client.lock.Lock()
+	defer s.client.lock.Unlock()
+	if s.client.runs == nil {
+		s.client.runs = map[string]client.WorkflowRun{}
+	}
+	s.client.runs[request.WorkflowId] = run
+	return &workflowservice.StartWorkflowExecutionResponse{RunId: run.RunID}, nil
+}
+
+func (s *fakeWorkflowService) GetWorkflowExecutionHistoryReverse(
+	ctx context.Context,
+	request *workflowservice.GetWorkflowExecutionHistoryReverseRequest,
+	opts ...grpc.CallOption,
+) (*workflowservice.GetWorkflowExecutionHistoryReverseResponse, error) {
+	workflowID, runID := request.GetExecution().GetWorkflowId(), request.GetExecution().GetRunId()
+	s.client.record(FakeClientCall{Method: "GetWorkflowExecutionHistoryReverse", WorkflowID: workflowID})
+	var events []*history.HistoryEvent
+	if s.client.OnGetWorkflowHistory != nil {
+		var err error
+		if events, err = s.client.OnGetWorkflowHistory(ctx, workflowID, runID); err != nil {
+			return nil, err
+		}
+	}
+	// The page token is the number of newest events already returned
+	offset := 0
+	if len(request.NextPageToken) > 0 {
+		offset, _ = strconv.Atoi(string(request.NextPageToken))
+	}
+	resp := &workflowservice.GetWorkflowExecutionHistoryReverseResponse{History: &history.History{}}
+	for i := len(events) - 1 - offset; i >= 0; i-- {
+		if request.MaximumPageSize > 0 && len(resp.History.Events) == int(request.MaximumPageSize) {
+			resp.NextPageToken = []byte(strconv.Itoa(offset + len(resp.History.Events)))
+			break
+		}
+		resp.History.Events = append(resp.History.Events, events[i])
+	}
+	return resp, nil
+}
+
+type fakeSchedule struct {
+	options    client.ScheduleOptions
+	numActions int
+	paused     bool
+}
+
+type fakeScheduleClient struct {
+	client *FakeClient
+}
+
+func (c *fakeScheduleClient) Create(ctx context.Context, options client.ScheduleOptions) (client.ScheduleHandle, error) {
+	c.client.record(FakeClientCall{Method: "CreateSchedule", Name: options.ID})
+	c.client.lock.Lock()
+	defer c.client.lock.Unlock()
+	if _, ok := c.client.schedules[options.ID]; ok {
+		return nil, temporal.ErrScheduleAlreadyRunning
+	}
+	if c.client.schedules == nil {
+		c.client.schedules = map[string]*fakeSchedule{}
+	}
+	c.client.schedules[options.ID] = &fakeSchedule{options: options, paused: options.Paused}
+	return &fakeScheduleHandle{client: c.client, id: options.ID}, nil
+}
+
+func (c *fakeScheduleClient) List(context.Context, client.ScheduleListOptions) (client.ScheduleListIterator, error) {
+	panic("List not implemented by FakeClient")
+}
+
+func (c *fakeScheduleClient) GetHandle(ctx context.Context, scheduleID string) client.ScheduleHandle {
+	return &fakeScheduleHandle{client: c.client, id: scheduleID}
+}
+
+type fakeScheduleHandle struct {
+	client.ScheduleHandle
+	client *FakeClient
+	id     string
+}
+
+func (h *fakeScheduleHandle) GetID() string { return h.id }
+
+// update records the call and applies fn to the schedule, failing if it does not exist.
+func (h *fakeScheduleHandle) update(method string, fn func(*fakeSchedule)) error {
+	h.client.record(FakeClientCall{Method: method, Name: h.id})
+	h.client.lock.Lock()
+	defer h.client.lock.Unlock()
+	schedule, ok := h.client.schedules[h.id]
+	if !ok {
+		return serviceerror.NewNotFound("schedule not found")
+	}
+	fn(schedule)
+	return nil
+}
+
+func (h *fakeScheduleHandle) Delete(context.Context) error {
+	return h.update("DeleteSchedule", func(*fakeSchedule) { delete(h.client.schedules, h.id) })
+}
+
+func (h *fakeScheduleHandle) Describe(context.Context) (*client.ScheduleDescription, error) {
+	var description client.ScheduleDescription
+	err := h.update("DescribeSchedule", func(schedule *fakeSchedule) {
+		description.Schedule = client.Schedule{
+			Action: schedule.options.Action,
+			Spec:   &schedule.options.Spec,
+			State:  &client.ScheduleState{Paused: schedule.paused},
+		}
+		description.Info.NumActions = schedule.numActions
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &description, nil
+}
+
+func (h *fakeScheduleHandle) Trigger(context.Context, client.ScheduleTriggerOptions) error {
+	return h.update("TriggerSchedule", func(schedule *fakeSchedule) { schedule.numActions++ })
+}
+
+func (h *fakeScheduleHandle) Pause(context.Context, client.SchedulePauseOptions) error {
+	return h.update("PauseSchedule", func(schedule *fakeSchedule) { schedule.paused = true })
+}
+
+func (h *fakeScheduleHandle) Unpause(context.Context, client.ScheduleUnpauseOptions) error {
+	return h.update("UnpauseSchedule", func(schedule *fakeSchedule) { schedule.paused = false })
+}
+
+type fakeUpdateHandle struct {
+	workflowID string
+	runID      string
+	result     interface{}
+	err        error
+}
+
+func (h *fakeUpdateHandle) WorkflowID() string { return h.workflowID }
+func (h *fakeUpdateHandle) RunID() string      { return h.runID }
+func (h *fakeUpdateHandle) UpdateID() string   { return "" }
+
+func (h *fakeUpdateHandle) Get(ctx context.Context, valuePtr interface{}) error {
+	return (&FakeWorkflowRun{Result: h.result, Err: h.err}).Get(ctx, valuePtr)
+}
+
+type fakeHistoryIterator struct {
+	events []*history.HistoryEvent
+	err    error
+}
+
+func (i *fakeHistoryIterator) HasNext() bool { return i.err != nil || len(i.events) > 0 }
+
+func (i *fakeHistoryIterator) Next() (*history.HistoryEvent, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+	event := i.events[0]
+	i.events = i.events[1:]
+	return event, nil
+}
+
+type fakeEncodedValue struct {
+	payload *common.Payload
+}
+
+func (v fakeEncodedValue) HasValue() bool { return v.payload != nil }
+
+func (v fakeEncodedValue) Get(valuePtr interface{}) error {
+	return converter.GetDefaultDataConverter().FromPayload(v.payload, valuePtr)
+}
+
+type fakeOperatorService struct {
+	operatorservice.OperatorServiceClient
+	client *FakeClient
+}
+
+func (fakeOperatorService) AddSearchAttributes(
+	ctx context.Context,
+	req *operatorservice.AddSearchAttributesRequest,
+	opts ...grpc.CallOption,
+) (*operatorservice.AddSearchAttributesResponse, error) {
+	return &operatorservice.AddSearchAttributesResponse{}, nil
+}
+
+func (s fakeOperatorService) ListSearchAttributes(
+	ctx context.Context,
+	request *operatorservice.ListSearchAttributesRequest,
+	opts ...grpc.CallOption,
+) (*operatorservice.ListSearchAttributesResponse, error) {
+	s.client.record(FakeClientCall{Method: "ListSearchAttributes"})
+	if s.client.OnListSearchAttributes != nil {
+		return s.client.OnListSearchAttributes(ctx, request)
+	}
+	return &operatorservice.ListSearchAttributesResponse{}, nil
+}
+
+func (r *FakeWorkflowRun) GetID() string    { return r.ID }
+func (r *FakeWorkflowRun) GetRunID() string { return r.RunID }
+
+// Get waits for the delay, then returns the error or decodes the result into valuePtr.
+func (r *FakeWorkflowRun) Get(ctx context.Context, valuePtr interface{}) error {
+	if r.Delay > 0 {
+		select {
+		case <-time.After(r.Delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if r.Err != nil {
+		return r.Err
+	}
+	if valuePtr == nil {
+		return nil
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(r.Result)
+	if err != nil {
+		return err
+	}
+	return converter.GetDefaultDataConverter().FromPayload(payload, valuePtr)
+}
+
+func (r *FakeWorkflowRun) GetWithOptions(
+	ctx context.Context,
+	valuePtr interface{},
+	options client.WorkflowRunGetOptions,
+) error {
+	return r.Get(ctx, valuePtr)
+}
+
+// workflowName returns the workflow type name for a name or workflow function.
+func workflowName(workflow interface{}) string {
+	if name, ok := workflow.(string); ok {
+		return name
+	}
+	if fn := reflect.ValueOf(workflow); fn.Kind() == reflect.Func {
+		name := runtime.FuncForPC(fn.Pointer()).Name()
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+	return fmt.Sprintf("%T", workflow)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/sdk/client"
+)
+
+func TestScenarioAgainstFakeClient(t *testing.T) {
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			run := &FakeWorkflowRun{ID: options.ID, RunID: "run", Result: "done"}
+			if options.ID == "w-test-run-3" {
+				run.Err = errors.New("workflow failed")
+			}
+			return run, nil
+		},
+	}
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			options := run.DefaultKitchenSinkWorkflowOptions()
+			options.Params = &kitchensink.TestInput{WorkflowInput: &kitchensink.WorkflowInput{}}
+			if err := run.ExecuteKitchenSinkWorkflow(ctx, &options); err != nil {
+				return err
+			}
+			return run.Client.SignalWorkflow(ctx, options.StartOptions.ID, "", "done", run.Iteration)
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 2, MaxConcurrent: 1},
+	}
+	require.NoError(t, executor.Run(context.Background(), NewTestScenarioInfo(fake, RunConfiguration{})))
+
+	starts := fake.Calls("ExecuteWorkflow")
+	require.Len(t, starts, 2)
+	require.Equal(t, "kitchenSink", starts[0].Name)
+	require.Equal(t, "w-test-run-1", starts[0].WorkflowID)
+	require.Equal(t, TaskQueueForRun("test", "test-run"), starts[0].Options.TaskQueue)
+	signals := fake.Calls("SignalWorkflow")
+	require.Len(t, signals, 2)
+	require.Equal(t, []interface{}{2}, signals[1].Args)
+	require.Len(t, fake.Calls(), 4)
+
+	// Programmed workflow failure fails the run
+	err := executor.Run(context.Background(), NewTestScenarioInfo(fake, RunConfiguration{Iterations: 3}))
+	require.ErrorContains(t, err, "workflow failed")
+}
+
+func TestFakeWorkflowRunResult(t *testing.T) {
+	fake := &FakeClient{}
+	run := fake.GetWorkflow(context.Background(), "unknown", "")
+	require.NoError(t, run.Get(context.Background(), nil))
+
+	fake.OnExecuteWorkflow = func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+		args ...interface{}) (client.WorkflowRun, error) {
+		return &FakeWorkflowRun{ID: options.ID, Result: map[string]int{"count": 3}}, nil
+	}
+	_, err := fake.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{ID: "known"}, replayTestWorkflow)
+	require.NoError(t, err)
+	require.Equal(t, "replayTestWorkflow", fake.Calls("ExecuteWorkflow")[0].Name)
+	var result map[string]int
+	require.NoError(t, fake.GetWorkflow(context.Background(), "known", "").Get(context.Background(), &result))
+	require.Equal(t, map[string]int{"count": 3}, result)
+
+	fake.OnQueryWorkflow = func(ctx context.Context, workflowID, runID, queryType string,
+		args ...interface{}) (interface{}, error) {
+		return "answer", nil
+	}
+	value, err := fake.QueryWorkflow(context.Background(), "known", "", "q")
+	require.NoError(t, err)
+	var answer string
+	require.NoError(t, value.Get(&answer))
+	require.Equal(t, "answer", answer)
+}
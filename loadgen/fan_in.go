@@ -0,0 +1,67 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/enums/v1"
+)
+
+// ExecuteFanInWorkflow executes a kitchen sink workflow starting the given number of children that
+// each signal a result of the given size back to it (see kitchensink.FanInWorkflowInput), requiring
+// a Go worker. Once it completes, its history is checked to hold a result from every child. The
+// latency is recorded in the omes_fan_in_latency timer.
+func (r *Run) ExecuteFanInWorkflow(ctx context.Context, children, resultSize int) error {
+	if children <= 0 {
+		return fmt.Errorf("fan-in requires at least one child")
+	}
+	options := r.StartWorkflowOptions()
+	input, err := kitchensink.FanInWorkflowInput(options.ID, children, resultSize)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	execution, err := r.Client.ExecuteWorkflow(ctx, options, "kitchenSink", input)
+	if err != nil {
+		return fmt.Errorf("failed to start fan-in workflow: %w", err)
+	}
+	if err := r.getWorkflowResult(ctx, execution, nil); err != nil {
+		return fmt.Errorf("fan-in workflow failed: %w", err)
+	}
+	r.RecordTimer("omes_fan_in_latency", nil, time.Since(start))
+	return r.verifyFanIn(ctx, execution.GetID(), execution.GetRunID(), children)
+}
+
+// verifyFanIn checks the history of the fan-in workflow has a result signal from every child.
+func (r *Run) verifyFanIn(ctx context.Context, workflowID, runID string, children int) error {
+	iter := r.Client.GetWorkflowHistory(ctx, workflowID, runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	received := make(map[int]bool, children)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("failed reading history of workflow %v: %w", workflowID, err)
+		}
+		attrs := event.GetWorkflowExecutionSignaledEventAttributes()
+		if attrs == nil || attrs.SignalName != kitchensink.FanInSignalName || len(attrs.GetInput().GetPayloads()) == 0 {
+			continue
+		}
+		result, err := kitchensink.DecodeFanInResult(attrs.Input.Payloads[0])
+		if err != nil {
+			return fmt.Errorf("invalid fan-in result in history of workflow %v: %w", workflowID, err)
+		}
+		received[result.Child] = true
+	}
+	var missing []int
+	for i := 0; i < children; i++ {
+		if !received[i] {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("fan-in workflow %v completed without results of %v of %v children, e.g. child %v",
+			workflowID, len(missing), children, missing[0])
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+)
+
+// fanInClient simulates fan-in workflows whose children signal their results to the parent as the
+// Go worker does, except for the children to drop.
+func fanInClient(t *testing.T, drop map[int]bool) *FakeClient {
+	var signals []*history.HistoryEvent
+	return &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			input := args[0].(*kitchensink.WorkflowInput)
+			require.Len(t, input.InitialActions, 2)
+			for i, action := range input.InitialActions[0].Actions {
+				child := action.GetExecChildWorkflow()
+				var childInput kitchensink.WorkflowInput
+				require.NoError(t, converter.GetDefaultDataConverter().FromPayload(child.Input[0], &childInput))
+				signal := childInput.InitialActions[0].Actions[0].GetSendSignal()
+				require.Equal(t, options.ID, signal.WorkflowId)
+				if drop[i] {
+					continue
+				}
+				// The worker passes the result payload on as a signal argument
+				arg, err := converter.GetDefaultDataConverter().ToPayload(signal.Args[0])
+				require.NoError(t, err)
+				signals = append(signals, &history.HistoryEvent{
+					EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED,
+					Attributes: &history.HistoryEvent_WorkflowExecutionSignaledEventAttributes{
+						WorkflowExecutionSignaledEventAttributes: &history.WorkflowExecutionSignaledEventAttributes{
+							SignalName: signal.SignalName,
+							Input:      &common.Payloads{Payloads: []*common.Payload{arg}},
+						},
+					},
+				})
+			}
+			await := input.InitialActions[1].Actions[0].GetAwaitWorkflowState()
+			require.Equal(t, kitchensink.FanInReceivedKey, await.Key)
+			require.Equal(t, "3", await.Value)
+			return &FakeWorkflowRun{ID: options.ID}, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return signals, nil
+		},
+	}
+}
+
+func TestExecuteFanInWorkflow(t *testing.T) {
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fanInClient(t, nil), RunConfiguration{})
+	info.MetricsHandler = handler
+	require.NoError(t, info.NewRun(1).ExecuteFanInWorkflow(context.Background(), 3, 16))
+	require.Len(t, *handler.recorded, 1)
+	require.Equal(t, "omes_fan_in_latency", (*handler.recorded)[0].name)
+
+	result, err := kitchensink.DecodeFanInResult(func() *common.Payload {
+		p, err := converter.GetDefaultDataConverter().ToPayload(kitchensink.FanInResult{Child: 2, Result: []byte("abc")})
+		require.NoError(t, err)
+		return p
+	}())
+	require.NoError(t, err)
+	require.Equal(t, kitchensink.FanInResult{Child: 2, Result: []byte("abc")}, result)
+}
+
+func TestExecuteFanInWorkflowMissingResult(t *testing.T) {
+	info := NewTestScenarioInfo(fanInClient(t, map[int]bool{1: true}), RunConfiguration{})
+	err := info.NewRun(1).ExecuteFanInWorkflow(context.Background(), 3, 16)
+	require.ErrorContains(t, err, "without results of 1 of 3 children, e.g. child 1")
+}
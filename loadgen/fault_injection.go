@@ -0,0 +1,68 @@
+package loadgen
+
+import (
+	"context"
+	"math/rand"
+	"path"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FaultInjection configures synthetic faults injected into client RPCs, for resiliency testing of
+// scenarios and worker fleets. The interceptor is installed inside the SDK's retry interceptor, so
+// retryable injected errors exercise the client retry logic.
+type FaultInjection struct {
+	// RPC method names, e.g. "StartWorkflowExecution", to inject faults into. Default is all.
+	Methods []string
+	// Probability of failing a call with one of ErrorCodes instead of sending it.
+	ErrorRate float64
+	// Codes of injected errors, chosen uniformly. Default is Unavailable and DeadlineExceeded.
+	ErrorCodes []codes.Code
+	// Probability of delaying a call by Latency before sending it.
+	LatencyRate float64
+	Latency     time.Duration
+	// Seed of the random source deciding which calls get faults.
+	Seed int64
+}
+
+// Enabled returns whether any fault is configured.
+func (f FaultInjection) Enabled() bool {
+	return f.ErrorRate > 0 || (f.LatencyRate > 0 && f.Latency > 0)
+}
+
+// Interceptor returns a gRPC client interceptor injecting the configured faults.
+func (f FaultInjection) Interceptor() grpc.UnaryClientInterceptor {
+	errorCodes := f.ErrorCodes
+	if len(errorCodes) == 0 {
+		errorCodes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded}
+	}
+	var lock sync.Mutex
+	random := rand.New(rand.NewSource(f.Seed))
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		name := path.Base(method)
+		if len(f.Methods) > 0 && !containsString(f.Methods, name) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		lock.Lock()
+		delay := f.LatencyRate > 0 && random.Float64() < f.LatencyRate
+		fail := f.ErrorRate > 0 && random.Float64() < f.ErrorRate
+		code := errorCodes[random.Intn(len(errorCodes))]
+		lock.Unlock()
+		if delay {
+			select {
+			case <-ctx.Done():
+				return status.FromContextError(ctx.Err()).Err()
+			case <-time.After(f.Latency):
+			}
+		}
+		if fail {
+			return status.Errorf(code, "omes injected fault on %v", name)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,64 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const startMethod = "/temporal.api.workflowservice.v1.WorkflowService/StartWorkflowExecution"
+
+func invokeFaulted(interceptor grpc.UnaryClientInterceptor, method string, calls int) (sent int, errs map[codes.Code]int) {
+	errs = map[codes.Code]int{}
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		sent++
+		return nil
+	}
+	for i := 0; i < calls; i++ {
+		if err := interceptor(context.Background(), method, nil, nil, nil, invoker); err != nil {
+			errs[status.Code(err)]++
+		}
+	}
+	return sent, errs
+}
+
+func TestFaultInjectionErrorRate(t *testing.T) {
+	const calls = 10000
+	interceptor := FaultInjection{ErrorRate: 0.2, Seed: 1}.Interceptor()
+	sent, errs := invokeFaulted(interceptor, startMethod, calls)
+	failed := errs[codes.Unavailable] + errs[codes.DeadlineExceeded]
+	require.Equal(t, calls, sent+failed)
+	require.InDelta(t, 0.2, float64(failed)/calls, 0.02)
+	// Both default codes are used
+	require.InDelta(t, 0.5, float64(errs[codes.Unavailable])/float64(failed), 0.05)
+}
+
+func TestFaultInjectionMethodsAndCodes(t *testing.T) {
+	interceptor := FaultInjection{
+		Methods:    []string{"SignalWorkflowExecution"},
+		ErrorRate:  1,
+		ErrorCodes: []codes.Code{codes.ResourceExhausted},
+	}.Interceptor()
+	sent, errs := invokeFaulted(interceptor, startMethod, 10)
+	require.Equal(t, 10, sent)
+	require.Empty(t, errs)
+	sent, errs = invokeFaulted(interceptor,
+		"/temporal.api.workflowservice.v1.WorkflowService/SignalWorkflowExecution", 10)
+	require.Zero(t, sent)
+	require.Equal(t, map[codes.Code]int{codes.ResourceExhausted: 10}, errs)
+}
+
+func TestFaultInjectionLatency(t *testing.T) {
+	interceptor := FaultInjection{LatencyRate: 1, Latency: 20 * time.Millisecond}.Interceptor()
+	start := time.Now()
+	sent, errs := invokeFaulted(interceptor, startMethod, 2)
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	require.Equal(t, 2, sent)
+	require.Empty(t, errs)
+	require.False(t, FaultInjection{LatencyRate: 1}.Enabled())
+}
@@ -2,24 +2,90 @@ package loadgen
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"time"
 
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 type GenericExecutor struct {
 	// Function to execute a single iteration of this scenario
 	Execute func(context.Context, *Run) error
+	// Function to start the workflow of a single iteration, for scenarios whose starts and awaits
+	// have different costs. If set, it is used instead of Execute and the returned workflows are
+	// awaited separately, with RunConfiguration.MaxConcurrent limiting the starts in flight and
+	// RunConfiguration.MaxConcurrentAwaits the awaits.
+	Start func(context.Context, *Run) (client.WorkflowRun, error)
+	// Optional function run once before the first iteration starts, e.g. to start long-lived
+	// workflows that iterations then direct load at, like QueryTargets. Its duration does not
+	// count towards the run's.
+	Setup func(context.Context, *ScenarioInfo) error
+	// Optional function run once after the last iteration, even if the run or Setup failed, e.g. to
+	// stop long-lived workflows like Entities. It is given up to a minute, and a failure is logged.
+	Teardown func(context.Context, *ScenarioInfo) error
 	// Default configuration if any.
 	DefaultConfiguration RunConfiguration
+	// gRPC interceptors to install on the client, see HasClientInterceptors.
+	ClientInterceptors []grpc.UnaryClientInterceptor
+	// Whether to capture SDK metrics, see HasSDKMetricsCapture.
+	CaptureSDKMetrics bool
+	// Codecs to encode the client's payloads with, see HasPayloadCodecs.
+	PayloadCodecs []converter.PayloadCodec
+	// Converter of the client's values to payloads, see HasDataConverter.
+	DataConverter converter.DataConverter
+	// Optional feedback hook called with the outcome of each completed iteration, one at a time,
+	// returning the parameters of iterations started afterwards (see Run.Params), or nil to keep
+	// the current ones, e.g. to grow a payload size until latency crosses a threshold. Iterations
+	// started before the first adjustment have InitialParams.
+	AdjustNext func(prev RunResultPartial) IterationParams
+	// Parameters of iterations before AdjustNext first adjusts them.
+	InitialParams IterationParams
+	// Optional function returning scenario options overriding the run's for the given iteration
+	// only, e.g. to ramp a payload size, see Run.OverrideScenarioOptions.
+	IterationOptions func(iteration int) map[string]string
+	// Source of the schedule-to-start latency for RunConfiguration.MaxScheduleToStartLatency.
+	// Default is HistoryScheduleToStart.
+	ScheduleToStartSource ScheduleToStartSource
+	// When to start iterations, if not as soon as limits allow. Default is an ArrivalTrace loaded
+	// from RunConfiguration.ArrivalTrace if set.
+	Scheduler StartScheduler
+	// Health check of the cluster for RunConfiguration.HealthCheckInterval. Default is
+	// ClientHealthCheck.
+	HealthCheck func(ctx context.Context, info *ScenarioInfo) error
+	// Optional function adding to the result of the run before it is reported, for executors built
+	// on this one, e.g. SessionExecutor.
+	completeResult func(*RunResult)
 }
 
 func (g *GenericExecutor) GetDefaultConfiguration() RunConfiguration {
 	return g.DefaultConfiguration
 }
 
+func (g *GenericExecutor) GetClientInterceptors() []grpc.UnaryClientInterceptor {
+	return g.ClientInterceptors
+}
+
+func (g *GenericExecutor) GetCaptureSDKMetrics() bool {
+	return g.CaptureSDKMetrics
+}
+
+func (g *GenericExecutor) GetPayloadCodecs() []converter.PayloadCodec {
+	return g.PayloadCodecs
+}
+
+func (g *GenericExecutor) GetDataConverter() converter.DataConverter {
+	return g.DataConverter
+}
+
+// teardownTimeout bounds GenericExecutor.Teardown and writing the report, which happen after the run's
+// context may be done.
+const teardownTimeout = time.Minute
+
 type genericRun struct {
 	executor *GenericExecutor
 	info     ScenarioInfo
@@ -27,37 +93,223 @@ type genericRun struct {
 	logger   *zap.SugaredLogger
 	// Timer capturing E2E execution of each scenario run iteration.
 	executeTimer client.MetricsTimer
+	// Artificial delay added to each iteration, see injectLatency.
+	injectedLatency       time.Duration
+	injectedLatencyJitter time.Duration
+	// Iteration outcomes for the end-of-run report.
+	stats runStats
+	// Set once the run is complete.
+	result *RunResult
+	// Raw latency sample export, if enabled.
+	samples *latencySampleFile
+	// Segment breakdown export, if enabled.
+	segments *segmentBreakdownFile
+	// Iteration error log, if enabled.
+	errorLog *errorLogFile
+	// Completed iterations for the throughput logged with progress.
+	throughput *ThroughputWindow
+	// Compiled RunConfiguration.RetryableErrors.
+	retryableErrors errorPatterns
+	// Scheduler of iteration starts, if any.
+	scheduler StartScheduler
+	// Iteration parameters adjusted by GenericExecutor.AdjustNext, if set.
+	feedback *iterationFeedback
+	// Closed once no more iterations start, ending think times early, see think.
+	startsStopped chan struct{}
 }
 
+// ErrBelowMinThroughput is returned (wrapped) by GenericExecutor.Run when the run's steady-state
+// throughput is below RunConfiguration.MinThroughput.
+var ErrBelowMinThroughput = errors.New("throughput below minimum")
+
 func (g *GenericExecutor) Run(ctx context.Context, info ScenarioInfo) error {
 	r, err := g.newRun(info)
 	if err != nil {
 		return err
 	}
-	return r.Run(ctx)
+	r.logger.Infof("Effective run configuration: %+v", r.config)
+	if buildID := info.TargetBuildID(); buildID != "" {
+		revert, err := info.PinBuildID(ctx, buildID)
+		if err != nil {
+			return err
+		}
+		// Revert even if the run was canceled
+		defer func() {
+			revertCtx, cancel := context.WithTimeout(context.Background(), buildIDRevertTimeout)
+			defer cancel()
+			if err := revert(revertCtx); err != nil {
+				r.logger.Warnf("Failed reverting build ID of task queue: %v", err)
+			}
+		}()
+	}
+	if info.TagsRunID() {
+		if err := info.CheckRunIDSearchAttribute(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.AwaitPollersTimeout > 0 {
+		taskQueues := info.RunTaskQueues()
+		r.logger.Infof("Waiting for pollers on %v task queue(s)", len(taskQueues))
+		if err := info.AwaitPollers(ctx, taskQueues, r.config.AwaitPollersTimeout); err != nil {
+			return err
+		}
+	}
+	if g.Teardown != nil {
+		defer func() {
+			teardownCtx, cancel := context.WithTimeout(context.Background(), teardownTimeout)
+			defer cancel()
+			if err := g.Teardown(teardownCtx, &r.info); err != nil {
+				r.logger.Warnf("Failed scenario teardown: %v", err)
+			}
+		}()
+	}
+	if g.Setup != nil {
+		if err := g.Setup(ctx, &r.info); err != nil {
+			return fmt.Errorf("failed scenario setup: %w", err)
+		}
+	}
+	metadata := info.newRunMetadata(r.config)
+	if info.LatencySamplesPath != "" {
+		if r.samples, err = createLatencySampleFile(info.LatencySamplesPath, metadata); err != nil {
+			return err
+		}
+	}
+	if info.SegmentBreakdownPath != "" {
+		if r.segments, err = createSegmentBreakdownFile(info.SegmentBreakdownPath); err != nil {
+			if r.samples != nil {
+				_ = r.samples.Close()
+			}
+			return err
+		}
+	}
+	if info.ErrorLogPath != "" {
+		if r.errorLog, err = openErrorLogFile(info.ErrorLogPath); err != nil {
+			if r.samples != nil {
+				_ = r.samples.Close()
+			}
+			if r.segments != nil {
+				_ = r.segments.Close()
+			}
+			return err
+		}
+	}
+	err = r.Run(ctx)
+	if r.samples != nil {
+		if closeErr := r.samples.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if r.segments != nil {
+		if closeErr := r.segments.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if r.errorLog != nil {
+		if closeErr := r.errorLog.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if r.result == nil {
+		return err
+	}
+	endTime := time.Now()
+	metadata.EndTime = &endTime
+	r.result.Metadata = &metadata
+	if g.completeResult != nil {
+		g.completeResult(r.result)
+	}
+	// The run's context may be done if it was canceled
+	reportCtx, cancel := context.WithTimeout(context.Background(), teardownTimeout)
+	defer cancel()
+	if err != nil {
+		// Report the partial result of the aborted run, failing with why it aborted
+		if reportErr := info.writeReport(reportCtx, r.result); reportErr != nil {
+			r.logger.Warnf("Failed writing report of incomplete run: %v", reportErr)
+		}
+		return err
+	}
+	if err := info.writeReport(reportCtx, r.result); err != nil {
+		return err
+	}
+	if r.config.MinThroughput > 0 && r.result.SteadyStateThroughput < r.config.MinThroughput {
+		return fmt.Errorf("%w: steady-state throughput of %.2f iterations/sec is below the minimum of %.2f",
+			ErrBelowMinThroughput, r.result.SteadyStateThroughput, r.config.MinThroughput)
+	}
+	return nil
 }
 
 func (g *GenericExecutor) newRun(info ScenarioInfo) (*genericRun, error) {
 	run := &genericRun{
 		executor: g,
 		info:     info,
-		config:   info.Configuration,
+		config:   EffectiveRunConfiguration(g.DefaultConfiguration, info.Configuration),
 		logger:   info.Logger,
 		executeTimer: info.MetricsHandler.WithTags(
 			map[string]string{"scenario": info.ScenarioName}).Timer("omes_execute_histogram"),
+		startsStopped: make(chan struct{}),
 	}
 
-	// Setup config
-	if run.config.Duration == 0 && run.config.Iterations == 0 {
-		run.config.Duration, run.config.Iterations = g.DefaultConfiguration.Duration, g.DefaultConfiguration.Iterations
-	}
-	if run.config.MaxConcurrent == 0 {
-		run.config.MaxConcurrent = g.DefaultConfiguration.MaxConcurrent
-	}
-	run.config.ApplyDefaults()
+	// Validate config
 	if run.config.Iterations > 0 && run.config.Duration > 0 {
 		return nil, fmt.Errorf("invalid scenario: iterations and duration are mutually exclusive")
 	}
+	if len(run.config.Phases) > 0 && (run.config.Iterations > 0 || run.config.Duration > 0) {
+		return nil, fmt.Errorf("invalid scenario: phases are mutually exclusive with iterations and duration")
+	}
+	if run.config.ShuffleIterations && run.config.Iterations == 0 {
+		return nil, fmt.Errorf("invalid scenario: shuffling iterations requires an iteration limit")
+	}
+	if run.config.MinThroughput < 0 {
+		return nil, fmt.Errorf("invalid scenario: min throughput must not be negative")
+	}
+	if err := info.ValidateStartOptions(); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	if run.config.ThinkTime < 0 || run.config.ThinkTimeJitter < 0 {
+		return nil, fmt.Errorf("invalid scenario: think time must not be negative")
+	}
+	if run.config.MaxTotalStarts < 0 {
+		return nil, fmt.Errorf("invalid scenario: max total starts must not be negative")
+	}
+	if run.config.MaxConcurrentAwaits < 0 {
+		return nil, fmt.Errorf("invalid scenario: max concurrent awaits must not be negative")
+	}
+	if run.config.BatchSize < 0 || run.config.BatchPause < 0 || run.config.BatchTimeout < 0 {
+		return nil, fmt.Errorf("invalid scenario: batch size, pause and timeout must not be negative")
+	}
+	var err error
+	if run.retryableErrors, err = compileErrorPatterns(run.config.RetryableErrors); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	for _, phase := range run.config.Phases {
+		if phase.Duration <= 0 {
+			return nil, fmt.Errorf("invalid scenario: phase %v must have a duration", phase.Name)
+		}
+	}
+	run.scheduler = g.Scheduler
+	if g.AdjustNext != nil {
+		run.feedback = &iterationFeedback{adjust: g.AdjustNext, params: g.InitialParams}
+	}
+	if run.scheduler == nil && run.config.ArrivalTrace != "" {
+		trace, err := LoadArrivalTrace(run.config.ArrivalTrace)
+		if err != nil {
+			return nil, err
+		}
+		run.scheduler = trace
+		// Without an explicit limit, replay the whole trace
+		if !hasRunLimit(g.DefaultConfiguration) && !hasRunLimit(info.Configuration) {
+			run.config.Iterations = len(trace.Offsets)
+		}
+	}
+	// Expose the effective configuration to iterations
+	run.info.Configuration = run.config
+	throughputWindow := run.config.ThroughputWindow
+	if throughputWindow == 0 {
+		throughputWindow = DefaultThroughputWindow
+	}
+	run.throughput = NewThroughputWindow(throughputWindow)
+	run.injectedLatency = info.ScenarioOptionDuration("inject-latency", 0)
+	run.injectedLatencyJitter = info.ScenarioOptionDuration("inject-latency-jitter", 0)
 
 	return run, nil
 }
@@ -65,69 +317,571 @@ func (g *GenericExecutor) newRun(info ScenarioInfo) (*genericRun, error) {
 // Run a scenario.
 // Spins up coroutines according to the scenario configuration.
 // Each coroutine runs the scenario Execute method in a loop until the scenario duration or max
-// iterations is reached.
+// iterations is reached. Phased runs go through each phase in order, limiting concurrency and rate
+// of new iterations by the current phase's configuration.
 func (g *genericRun) Run(ctx context.Context) error {
-	ctx, cancel := context.WithCancel(ctx)
-	if g.config.Duration > 0 {
-		ctx, cancel = context.WithTimeout(ctx, g.config.Duration)
+	// Iterations run until done or abandoned, while new ones are only started until the duration
+	// elapses. The cause of canceling iterations tells interruptions by deadline and cancel apart.
+	iterCtx, cancelIterations := context.WithCancelCause(ctx)
+	defer cancelIterations(nil)
+	ctx, cancel := context.WithCancel(iterCtx)
+	if duration := g.config.TotalDuration(); duration > 0 {
+		ctx, cancel = context.WithTimeout(iterCtx, duration)
 	}
 	defer cancel()
 
 	startTime := time.Now()
+	phases := g.schedulePhases(startTime)
+	var deadline time.Time
+	if duration := g.config.TotalDuration(); duration > 0 {
+		deadline = startTime.Add(duration)
+	}
+	if len(g.config.Phases) > 0 {
+		g.stats.trackPhases(len(phases))
+	}
+	sampler := startResourceSampler(resourceSampleInterval)
+	var throttle *BacklogThrottle
+	if g.config.MaxBacklog > 0 {
+		throttle = &BacklogThrottle{MaxBacklog: g.config.MaxBacklog}
+		throttle.Start(ctx, &g.info)
+	}
+	var healthPause *HealthPause
+	if g.config.HealthCheckInterval > 0 {
+		healthPause = &HealthPause{Interval: g.config.HealthCheckInterval, Check: g.executor.HealthCheck}
+		healthPause.Start(ctx, &g.info)
+	}
+	var backpressure *ScheduleToStartBackpressure
+	if g.config.MaxScheduleToStartLatency > 0 {
+		backpressure = &ScheduleToStartBackpressure{
+			MaxLatency: g.config.MaxScheduleToStartLatency,
+			Source:     g.executor.ScheduleToStartSource,
+		}
+		backpressure.Start(ctx, &g.info, func() float64 { return g.throughput.Rate(time.Now()) })
+	}
+	var taskQueueStats *taskQueueStatsRecorder
+	if g.config.TaskQueueStatsInterval > 0 {
+		taskQueueStats = startTaskQueueStatsRecorder(iterCtx, &g.info, g.config.TaskQueueStatsInterval)
+	}
+	var workerMetrics *workerMetricsScraper
+	if g.config.WorkerMetricsURL != "" {
+		interval := g.config.WorkerMetricsInterval
+		if interval == 0 {
+			interval = DefaultWorkerMetricsInterval
+		}
+		workerMetrics = startWorkerMetricsScraper(iterCtx, &g.info, g.config.WorkerMetricsURL, interval)
+	}
+	if g.config.ProgressInterval > 0 {
+		stopProgress := make(chan struct{})
+		defer close(stopProgress)
+		go g.logProgress(g.config.ProgressInterval, stopProgress)
+	}
 	var runErr error
-	doneCh := make(chan error)
-	var currentlyRunning int
-	waitOne := func() {
-		select {
-		case err := <-doneCh:
+	doneCh := make(chan iterationDone)
+	// Iterations starting or executing, and, with GenericExecutor.Start, awaiting their workflow
+	var currentlyRunning, currentlyAwaiting int
+	received := func(done iterationDone) {
+		switch {
+		case done.handedOver:
 			currentlyRunning--
-			if err != nil {
-				runErr = err
-			}
+			currentlyAwaiting++
+		case done.awaited:
+			currentlyAwaiting--
+		default:
+			currentlyRunning--
+		}
+		if done.err != nil {
+			runErr = done.err
+		}
+	}
+	var awaitCh chan pendingAwait
+	if g.executor.Start != nil {
+		awaitCh = make(chan pendingAwait)
+		maxAwaits := g.config.MaxConcurrentAwaits
+		if maxAwaits == 0 {
+			maxAwaits = g.config.MaxConcurrent
+		}
+		for i := 0; i < maxAwaits; i++ {
+			go g.awaitWorkflows(iterCtx, awaitCh, doneCh)
+		}
+	}
+	// Waits for an iteration to complete or the deadline to pass, if any
+	waitOne := func(deadline time.Time) {
+		var deadlineCh <-chan time.Time
+		if !deadline.IsZero() {
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			deadlineCh = timer.C
+		}
+		select {
+		case done := <-doneCh:
+			received(done)
+		case <-deadlineCh:
+		case <-ctx.Done():
+		}
+	}
+	// Waits for the channel to be closed, an iteration to complete or the context to be done, so
+	// that iterations are not held up reporting completion while starts are held back
+	waitClosed := func(ch <-chan struct{}) {
+		select {
+		case <-ch:
+		case done := <-doneCh:
+			received(done)
 		case <-ctx.Done():
 		}
 	}
+	// Sleeps until the given time, an iteration fails or the context is done, receiving completed
+	// iterations meanwhile
+	sleepUntil := func(t time.Time) {
+		timer := time.NewTimer(time.Until(t))
+		defer timer.Stop()
+		for runErr == nil {
+			select {
+			case <-timer.C:
+				return
+			case done := <-doneCh:
+				received(done)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	// Iteration numbers in dispatch order, if shuffled
+	var order []int
+	if g.config.ShuffleIterations {
+		order = rand.New(rand.NewSource(g.info.Seed())).Perm(g.config.Iterations)
+	}
 
 	// Run all until we've gotten an error or reached iteration limit
+	phaseIndex := -1
+	var lastStart, lastLagWarning time.Time
+	var reachedMaxTotalStarts bool
 	for i := 0; runErr == nil && ctx.Err() == nil &&
 		(g.config.Iterations == 0 || i < g.config.Iterations); i++ {
-		// If there are already MaxConcurrent running, wait for one
-		if currentlyRunning >= g.config.MaxConcurrent {
-			waitOne()
-			// Exit loop if error
+		if g.config.MaxTotalStarts > 0 && i >= g.config.MaxTotalStarts {
+			g.logger.Infof("Not starting more iterations, reached the maximum of %v total starts", g.config.MaxTotalStarts)
+			reachedMaxTotalStarts = true
+			break
+		}
+		// Between batches, wait for the previous batch to complete, then pause
+		if g.config.BatchSize > 0 && i > 0 && i%g.config.BatchSize == 0 {
+			batchDeadline := deadline
+			if g.config.BatchTimeout > 0 {
+				if timeout := time.Now().Add(g.config.BatchTimeout); batchDeadline.IsZero() || timeout.Before(batchDeadline) {
+					batchDeadline = timeout
+				}
+			}
+			for runErr == nil && ctx.Err() == nil && currentlyRunning+currentlyAwaiting > 0 &&
+				(batchDeadline.IsZero() || time.Now().Before(batchDeadline)) {
+				waitOne(batchDeadline)
+			}
+			if inFlight := currentlyRunning + currentlyAwaiting; inFlight > 0 && runErr == nil && ctx.Err() == nil {
+				g.logger.Warnf("Starting next batch with %v iteration(s) of previous batches still running", inFlight)
+			}
+			if g.config.BatchPause > 0 {
+				sleepUntil(time.Now().Add(g.config.BatchPause))
+			}
 			if runErr != nil || ctx.Err() != nil {
 				break
 			}
+			g.logger.Debugf("Starting batch %v", i/g.config.BatchSize+1)
+		}
+		// Wait until the iteration is scheduled to start, if scheduled
+		var scheduledStart time.Time
+		if g.scheduler != nil {
+			offset, ok := g.scheduler.StartOffset(i)
+			if !ok {
+				g.logger.Infof("Not starting more iterations, the start schedule is exhausted")
+				break
+			}
+			scheduledStart = startTime.Add(offset)
+			sleepUntil(scheduledStart)
+		}
+		// Wait until the current phase allows starting another iteration
+		var phase scheduledPhase
+		for runErr == nil && ctx.Err() == nil {
+			index := currentPhase(phases, time.Now())
+			if index >= len(phases) {
+				phaseIndex = index
+				break
+			}
+			if index != phaseIndex && len(g.config.Phases) > 0 {
+				g.logger.Infof("Starting phase %v", phases[index].Name)
+			}
+			phaseIndex, phase = index, phases[index]
+			// If there are already MaxConcurrent running, wait for one
+			if currentlyRunning >= phase.MaxConcurrent {
+				waitOne(phase.end)
+				continue
+			}
+			// If the rate limit, possibly lowered by backpressure, has been reached, wait until it
+			// is not or the phase ends
+			rate := phase.MaxIterationsPerSecond
+			if backpressure != nil {
+				rate = backpressure.Limit(rate)
+			}
+			if rate > 0 && !lastStart.IsZero() {
+				next := lastStart.Add(time.Duration(float64(time.Second) / rate))
+				if !phase.end.IsZero() && phase.end.Before(next) {
+					next = phase.end
+				}
+				if time.Now().Before(next) {
+					sleepUntil(next)
+					continue
+				}
+			}
+			// If the task queue backlog is too large, wait until it is not
+			if resumed := throttle.resumedCh(); resumed != nil {
+				waitClosed(resumed)
+				continue
+			}
+			// If the cluster is unhealthy, wait until it is healthy again
+			if resumed := healthPause.resumedCh(); resumed != nil {
+				waitClosed(resumed)
+				continue
+			}
+			break
+		}
+		// Exit loop if error or all phases are done
+		if runErr != nil || ctx.Err() != nil || phaseIndex >= len(phases) {
+			break
+		}
+		// Stop if the iteration is expected to overshoot the deadline
+		if g.config.SkipLateIterations && !deadline.IsZero() {
+			estimate := g.stats.recentLatencyAverage()
+			if overshoot := time.Until(deadline.Add(g.config.DeadlineTolerance)); estimate > overshoot {
+				g.logger.Infof("Not starting more iterations, estimated iteration duration %v would exceed deadline", estimate)
+				break
+			}
+		}
+		if !scheduledStart.IsZero() {
+			if lag := time.Since(scheduledStart); lag > arrivalLagWarningThreshold && time.Since(lastLagWarning) > time.Second {
+				g.logger.Warnf("Start %v of the schedule is %v late, not keeping up with the schedule", i+1, lag)
+				lastLagWarning = time.Now()
+			}
 		}
 		// Run concurrently
 		g.logger.Debugf("Running iteration %v", i)
 		currentlyRunning++
-		run := g.info.NewRun(i + 1)
+		lastStart = time.Now()
+		iteration := i + 1
+		if order != nil {
+			iteration = order[i] + 1
+		}
+		run := g.info.NewRun(iteration)
+		run.Params = g.executor.InitialParams
+		if g.feedback != nil {
+			run.Params = g.feedback.next()
+		}
+		if g.executor.IterationOptions != nil {
+			run.OverrideScenarioOptions(g.executor.IterationOptions(iteration))
+		}
+		iterationPhase := phaseIndex
+		g.stats.recordStart(iterationPhase)
+		if len(g.info.Endpoints) > 0 {
+			g.stats.recordEndpointStart(run.ServerAddress)
+		}
 		go func() {
-			startTime := time.Now()
-			err := g.executor.Execute(ctx, run)
-			// Only log/wrap/send to channel if context is not done
-			if ctx.Err() == nil {
-				if err != nil {
-					err = fmt.Errorf("iteration %v failed: %w", run.Iteration, err)
-					g.logger.Error(err)
-				}
-				select {
-				case <-ctx.Done():
-				case doneCh <- err:
-					// Record/log here, not if it was cut short by context complete
-					g.executeTimer.Record(time.Since(startTime))
+			it := &runningIteration{run: run, phase: iterationPhase, startTime: time.Now(), endTrace: func(error) {}}
+			executeCtx := iterCtx
+			if g.info.Tracer != nil {
+				executeCtx, it.endTrace = g.info.Tracer.StartIteration(iterCtx, run.traceAttributes())
+			}
+			g.injectLatency(executeCtx)
+			if g.executor.Start != nil {
+				handle, err := g.start(executeCtx, run)
+				if err == nil {
+					// Keep the start slot until an await worker takes over the workflow
+					select {
+					case <-iterCtx.Done():
+						it.endTrace(iterCtx.Err())
+					case awaitCh <- pendingAwait{runningIteration: it, ctx: executeCtx, handle: handle}:
+					}
+					return
 				}
+				g.finishIteration(iterCtx, doneCh, it, iterationDone{err: err})
+				return
 			}
+			err := g.execute(executeCtx, run)
+			g.finishIteration(iterCtx, doneCh, it, iterationDone{err: err})
 		}()
 	}
-	// Wait for all to be done or an error to occur
-	for runErr == nil && ctx.Err() == nil && currentlyRunning > 0 {
-		waitOne()
+	close(g.startsStopped)
+	// Wait for all to be done or an error to occur. Iterations of a duration-limited run still
+	// running after the grace period are abandoned.
+	var graceCh <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline.Add(g.config.GracePeriod)))
+		defer timer.Stop()
+		graceCh = timer.C
+	}
+waitLoop:
+	for runErr == nil && iterCtx.Err() == nil && currentlyRunning+currentlyAwaiting > 0 {
+		select {
+		case done := <-doneCh:
+			received(done)
+		case <-graceCh:
+			g.logger.Warnf("Abandoning %v iteration(s) still running after grace period of %v",
+				currentlyRunning+currentlyAwaiting, g.config.GracePeriod)
+			cancelIterations(ErrRunDeadline)
+			break waitLoop
+		case <-iterCtx.Done():
+		}
+	}
+	// Iterations still running now are interrupted by whatever ended the run
+	interruption := InterruptionCauseOf(iterCtx)
+	cancelIterations(nil)
+	resourceUsage := sampler.Stop()
+	var taskQueueSnapshots []TaskQueueStatsSnapshot
+	if taskQueueStats != nil {
+		taskQueueSnapshots = taskQueueStats.Stop()
+	}
+	var workerMetricsSummary *WorkerMetricsSummary
+	if workerMetrics != nil {
+		summary := workerMetrics.Stop()
+		workerMetricsSummary = &summary
+		if summary.Scrapes == 0 {
+			g.logger.Warnf("Worker metrics endpoint %v was unreachable for the whole run", summary.URL)
+		}
+	}
+	if resourceUsage.GoroutinesGrowing {
+		g.logger.Warnf("Load generator goroutines kept growing during the run (%v to %v), possible leak",
+			resourceUsage.Goroutines.Min, resourceUsage.Goroutines.Final)
+	}
+	// The result has the stats accumulated so far even if the run aborted
+	g.result = g.stats.result(&g.info, startTime, time.Now())
+	g.result.ResourceUsage = &resourceUsage
+	g.result.StoppedAtMaxTotalStarts = reachedMaxTotalStarts
+	g.result.tagInterrupted(interruption)
+	g.result.TaskQueueStats = taskQueueSnapshots
+	g.result.WorkerMetrics = workerMetricsSummary
+	for i := range g.result.Phases {
+		g.result.Phases[i].Name = phases[i].Name
+		g.result.Phases[i].Duration = phases[i].Duration
+		g.result.Phases[i].MaxConcurrent = phases[i].MaxConcurrent
+		g.result.Phases[i].MaxIterationsPerSecond = phases[i].MaxIterationsPerSecond
 	}
 	if runErr != nil {
-		return fmt.Errorf("run finished with error after %v: %w", time.Since(startTime), runErr)
+		if _, ok := DetectNonDeterminism(runErr); ok {
+			runErr = fmt.Errorf("run finished with non-determinism error after %v: %w", g.result.Duration, runErr)
+		} else {
+			runErr = fmt.Errorf("run finished with error after %v: %w", g.result.Duration, runErr)
+		}
+		g.result.Incomplete = true
+		g.result.Error = runErr.Error()
+		return runErr
+	}
+	g.logger.Infof("Run complete in %v", g.result.Duration)
+	for _, endpoint := range g.result.Endpoints {
+		g.logger.Infof("Endpoint %v: %v iterations completed, %v failed, latency p50 %v, p99 %v", endpoint.Address,
+			endpoint.IterationsCompleted, endpoint.IterationsFailed, endpoint.Latency.P50, endpoint.Latency.P99)
 	}
-	g.logger.Infof("Run complete in %v", time.Since(startTime))
 	return nil
 }
+
+// runningIteration is an iteration of the run in progress.
+type runningIteration struct {
+	run       *Run
+	phase     int
+	startTime time.Time
+	endTrace  func(error)
+}
+
+// iterationDone is sent to the run loop when an iteration completes, or when its workflow started
+// by GenericExecutor.Start is taken over by an await worker, freeing its start slot.
+type iterationDone struct {
+	err        error
+	handedOver bool
+	// Whether the iteration completed in an await worker.
+	awaited bool
+}
+
+// pendingAwait is a workflow started by GenericExecutor.Start to be awaited.
+type pendingAwait struct {
+	*runningIteration
+	ctx    context.Context
+	handle client.WorkflowRun
+}
+
+// finishIteration records the outcome of the iteration and sends it to the run loop, unless the
+// context is done.
+func (g *genericRun) finishIteration(ctx context.Context, doneCh chan<- iterationDone, it *runningIteration, done iterationDone) {
+	if ctx.Err() != nil {
+		// Counted as abandoned, see RunResult.IterationsInterrupted
+		err := &IterationInterruptedError{Iteration: it.run.Iteration, Cause: InterruptionCauseOf(ctx), Err: done.err}
+		g.logger.Debug(err)
+		it.endTrace(err)
+		return
+	}
+	it.endTrace(done.err)
+	if done.err != nil {
+		if g.errorLog != nil {
+			g.errorLog.record(it.run, done.err)
+		}
+		done.err = fmt.Errorf("iteration %v failed: %w", it.run.Iteration, done.err)
+		g.logger.Error(done.err)
+	}
+	// Record before sending so the stats are complete once all iterations are received
+	elapsed := time.Since(it.startTime)
+	g.executeTimer.Record(elapsed)
+	g.stats.recordEnd(it.phase, elapsed, done.err)
+	if len(g.info.Endpoints) > 0 {
+		g.stats.recordEndpointEnd(it.run.ServerAddress, elapsed, done.err)
+		it.run.RecordTimer("omes_endpoint_iteration_latency", map[string]string{"endpoint": it.run.ServerAddress}, elapsed)
+	}
+	if g.feedback != nil {
+		g.stats.Lock()
+		outcome := RunResultPartial{
+			Iteration:           it.run.Iteration,
+			Params:              it.run.Params,
+			Latency:             elapsed,
+			Err:                 done.err,
+			IterationsStarted:   g.stats.started,
+			IterationsCompleted: g.stats.completed,
+			IterationsFailed:    g.stats.failed,
+		}
+		g.stats.Unlock()
+		g.feedback.completed(outcome)
+	}
+	g.throughput.Record(time.Now())
+	if g.samples != nil {
+		g.samples.record(it.run.Iteration, it.startTime, elapsed, done.err)
+	}
+	if g.segments != nil {
+		g.segments.record(it.run, elapsed)
+	}
+	g.think(ctx)
+	select {
+	case <-ctx.Done():
+	case doneCh <- done:
+	}
+}
+
+// think sleeps for RunConfiguration.ThinkTime plus a random duration up to ThinkTimeJitter after an
+// iteration completed, keeping its concurrency slot, or with GenericExecutor.Start its await slot,
+// busy. Think time ends early once no more iterations start, so as not to delay the end of the run.
+func (g *genericRun) think(ctx context.Context) {
+	delay := g.config.ThinkTime
+	if g.config.ThinkTimeJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(g.config.ThinkTimeJitter)))
+	}
+	if delay <= 0 {
+		return
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-g.startsStopped:
+	case <-timer.C:
+	}
+}
+
+// awaitWorkflows takes over workflows started by GenericExecutor.Start and awaits them one at a
+// time, completing their iterations, until the context is done.
+func (g *genericRun) awaitWorkflows(ctx context.Context, awaitCh <-chan pendingAwait, doneCh chan<- iterationDone) {
+	for {
+		var pending pendingAwait
+		select {
+		case <-ctx.Done():
+			return
+		case pending = <-awaitCh:
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case doneCh <- iterationDone{handedOver: true}:
+		}
+		err := pending.run.getWorkflowResult(pending.ctx, pending.handle, nil)
+		if err != nil {
+			err = fmt.Errorf("workflow execution failed (ID: %s, run ID: %s): %w",
+				pending.handle.GetID(), pending.handle.GetRunID(), err)
+		}
+		g.finishIteration(ctx, doneCh, pending.runningIteration, iterationDone{err: err, awaited: true})
+	}
+}
+
+// scheduledPhase is a phase of a run with its end time. A zero end time means the phase does not
+// end by time.
+type scheduledPhase struct {
+	RunPhase
+	end time.Time
+}
+
+// schedulePhases returns the phases of the run with their end times. A run without configured
+// phases is a single phase with the run's duration, concurrency, and rate.
+func (g *genericRun) schedulePhases(startTime time.Time) []scheduledPhase {
+	phases := g.config.Phases
+	if len(phases) == 0 {
+		phases = []RunPhase{{
+			Duration:               g.config.Duration,
+			MaxConcurrent:          g.config.MaxConcurrent,
+			MaxIterationsPerSecond: g.config.MaxIterationsPerSecond,
+		}}
+	}
+	scheduled := make([]scheduledPhase, len(phases))
+	end := startTime
+	for i, phase := range phases {
+		scheduled[i].RunPhase = phase
+		if phase.Duration > 0 {
+			end = end.Add(phase.Duration)
+			scheduled[i].end = end
+		}
+	}
+	return scheduled
+}
+
+// currentPhase returns the index of the phase running at the given time, or the number of phases
+// if all are done.
+func currentPhase(phases []scheduledPhase, now time.Time) int {
+	for i, phase := range phases {
+		if phase.end.IsZero() || now.Before(phase.end) {
+			return i
+		}
+	}
+	return len(phases)
+}
+
+// logProgress logs the iteration counts and windowed throughput every interval until stopped.
+func (g *genericRun) logProgress(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			rate := g.throughput.Rate(now)
+			g.stats.Lock()
+			started, completed, failed := g.stats.started, g.stats.completed, g.stats.failed
+			g.stats.Unlock()
+			g.logger.Infof("Progress: %v iterations started, %v completed, %v failed, %.2f iterations/sec over last %v",
+				started, completed, failed, rate, g.throughput.Window())
+			if g.config.ThroughputGauge {
+				g.info.RecordGauge("omes_throughput", nil, rate)
+			}
+		}
+	}
+}
+
+// injectLatency sleeps for the "inject-latency" scenario option duration plus a random duration
+// up to the "inject-latency-jitter" option before an iteration executes. This is meant for
+// calibrating the harness itself. The delay is included in the measured iteration latency.
+func (g *genericRun) injectLatency(ctx context.Context) {
+	delay := g.injectedLatency
+	if g.injectedLatencyJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(g.injectedLatencyJitter)))
+	}
+	if delay <= 0 {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(delay):
+	}
+}
+
+// hasRunLimit returns whether the configuration limits the run by iterations, duration or phases.
+func hasRunLimit(config RunConfiguration) bool {
+	return config.Iterations > 0 || config.Duration > 0 || len(config.Phases) > 0
+}
@@ -1,8 +1,12 @@
 package loadgen
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand"
 	"sync"
 	"testing"
 	"time"
@@ -128,3 +132,634 @@ func TestRunFailDuration(t *testing.T) {
 	require.ErrorContains(t, err, "run finished with error")
 	tracker.assertSeen(t, 2)
 }
+
+func TestRunFailReportsPartialResult(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{MaxConcurrent: 1, Iterations: 10})
+	var result *RunResult
+	info.ReportSinks = []ReportSink{reportSinkFunc(func(r *RunResult) { result = r })}
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+		time.Sleep(time.Millisecond)
+		if run.Iteration == 4 {
+			return errors.New("deliberate fail from test")
+		}
+		return nil
+	}}
+	err := executor.Run(context.Background(), info)
+	require.ErrorContains(t, err, "run finished with error")
+
+	// The report has the stats up to the failure
+	require.NotNil(t, result)
+	require.True(t, result.Incomplete)
+	require.Equal(t, err.Error(), result.Error)
+	require.Equal(t, 4, result.IterationsStarted)
+	require.Equal(t, 3, result.IterationsCompleted)
+	require.Equal(t, 1, result.IterationsFailed)
+	require.Zero(t, result.IterationsAbandoned)
+	require.GreaterOrEqual(t, result.Latency.Min, time.Millisecond)
+	require.NotNil(t, result.Metadata)
+	require.NotNil(t, result.Metadata.EndTime)
+}
+
+func TestRunCanceledWritesReport(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{MaxConcurrent: 1, Duration: time.Minute})
+	var result *RunResult
+	var reportCtxErr error
+	info.ReportSinks = []ReportSink{reportSinkCtxFunc(func(ctx context.Context, r *RunResult) {
+		result, reportCtxErr = r, ctx.Err()
+	})}
+	ctx, cancel := context.WithCancel(context.Background())
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+		if run.Iteration == 3 {
+			cancel()
+		}
+		return nil
+	}}
+	require.NoError(t, executor.Run(ctx, info))
+
+	// The report is written with a live context although the run's was canceled
+	require.NotNil(t, result)
+	require.GreaterOrEqual(t, result.IterationsCompleted, 2)
+	require.NoError(t, reportCtxErr)
+}
+
+// reportSinkCtxFunc is a ReportSink also given the context of the write.
+type reportSinkCtxFunc func(context.Context, *RunResult)
+
+func (f reportSinkCtxFunc) WriteReport(ctx context.Context, result *RunResult) error {
+	f(ctx, result)
+	return nil
+}
+
+func TestRunInjectLatency(t *testing.T) {
+	var buf bytes.Buffer
+	info := ScenarioInfo{
+		MetricsHandler:  client.MetricsNopHandler,
+		Logger:          zap.NewNop().Sugar(),
+		ScenarioOptions: map[string]string{"inject-latency": "30ms", "inject-latency-jitter": "10ms"},
+		ReportSinks:     []ReportSink{&WriterReportSink{Writer: &buf}},
+	}
+	executor := &GenericExecutor{
+		Execute:              func(ctx context.Context, run *Run) error { return nil },
+		DefaultConfiguration: RunConfiguration{Iterations: 3},
+	}
+	require.NoError(t, executor.Run(context.Background(), info))
+	var result RunResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Equal(t, 3, result.IterationsCompleted)
+	require.GreaterOrEqual(t, result.Latency.Min, 30*time.Millisecond)
+}
+
+func TestPhasesValidation(t *testing.T) {
+	info := ScenarioInfo{MetricsHandler: client.MetricsNopHandler}
+	executor := &GenericExecutor{DefaultConfiguration: RunConfiguration{
+		Phases: []RunPhase{{Name: "warm", Duration: time.Second}, {Name: "steady"}},
+	}}
+	_, err := executor.newRun(info)
+	require.ErrorContains(t, err, "phase steady must have a duration")
+
+	info.Configuration = RunConfiguration{Iterations: 3, Phases: []RunPhase{{Duration: time.Second}}}
+	_, err = executor.newRun(info)
+	require.ErrorContains(t, err, "phases are mutually exclusive with iterations and duration")
+}
+
+func TestRunPhases(t *testing.T) {
+	var lock sync.Mutex
+	var running, maxRunning int
+	type start struct {
+		at      time.Duration
+		running int
+	}
+	var starts []start
+	runStart := time.Now()
+	var buf bytes.Buffer
+	info := ScenarioInfo{
+		MetricsHandler: client.MetricsNopHandler,
+		Logger:         zap.NewNop().Sugar(),
+		ReportSinks:    []ReportSink{&WriterReportSink{Writer: &buf}},
+	}
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			starts = append(starts, start{at: time.Since(runStart), running: running})
+			lock.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			lock.Lock()
+			running--
+			lock.Unlock()
+			return nil
+		},
+		DefaultConfiguration: RunConfiguration{
+			MaxConcurrent: 4,
+			Phases: []RunPhase{
+				{Name: "warm", Duration: 200 * time.Millisecond, MaxConcurrent: 1},
+				{Name: "steady", Duration: 200 * time.Millisecond},
+				{Name: "rate", Duration: 400 * time.Millisecond, MaxIterationsPerSecond: 5},
+			},
+		},
+	}
+	require.NoError(t, executor.Run(context.Background(), info))
+
+	// Only one iteration at a time during warm phase, up to 4 during steady
+	lock.Lock()
+	defer lock.Unlock()
+	for _, s := range starts {
+		if s.at < 180*time.Millisecond {
+			require.Equal(t, 1, s.running)
+		}
+	}
+	require.Equal(t, 4, maxRunning)
+
+	var result RunResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Len(t, result.Phases, 3)
+	var total int
+	for i, name := range []string{"warm", "steady", "rate"} {
+		phase := result.Phases[i]
+		require.Equal(t, name, phase.Name)
+		require.Positive(t, phase.IterationsStarted)
+		require.Equal(t, phase.IterationsStarted, phase.IterationsCompleted)
+		total += phase.IterationsStarted
+	}
+	require.Equal(t, result.IterationsStarted, total)
+	require.Equal(t, 1, result.Phases[0].MaxConcurrent)
+	require.Equal(t, 4, result.Phases[1].MaxConcurrent)
+	// Steady runs 4 concurrently, so it starts more than warm
+	require.Greater(t, result.Phases[1].IterationsStarted, result.Phases[0].IterationsStarted)
+	// 5 per second for 400ms
+	require.LessOrEqual(t, result.Phases[2].IterationsStarted, 3)
+}
+
+func TestRunSkipLateIterations(t *testing.T) {
+	run := func(skip bool) int {
+		var buf bytes.Buffer
+		info := ScenarioInfo{
+			MetricsHandler: client.MetricsNopHandler,
+			Logger:         zap.NewNop().Sugar(),
+			ReportSinks:    []ReportSink{&WriterReportSink{Writer: &buf}},
+		}
+		executor := &GenericExecutor{
+			Execute: func(ctx context.Context, run *Run) error {
+				select {
+				case <-time.After(50 * time.Millisecond):
+				case <-ctx.Done():
+				}
+				return nil
+			},
+			DefaultConfiguration: RunConfiguration{
+				Duration:           230 * time.Millisecond,
+				MaxConcurrent:      1,
+				SkipLateIterations: skip,
+			},
+		}
+		require.NoError(t, executor.Run(context.Background(), info))
+		var result RunResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		return result.IterationsStarted
+	}
+	// Iterations start at roughly 0, 50, 100, 150 and 200ms, the last one would end at 250ms. How
+	// many fit depends on scheduling, but skipping always leaves out the last one.
+	require.Less(t, run(true), run(false))
+}
+
+func TestRunShuffleIterations(t *testing.T) {
+	dispatchOrder := func(runID string, shuffle bool) []int {
+		tracker := newIterationTracker()
+		info := ScenarioInfo{
+			RunID:          runID,
+			MetricsHandler: client.MetricsNopHandler,
+			Logger:         zap.NewNop().Sugar(),
+		}
+		executor := &GenericExecutor{
+			Execute: func(ctx context.Context, run *Run) error {
+				tracker.track(run.Iteration)
+				return nil
+			},
+			DefaultConfiguration: RunConfiguration{Iterations: 20, MaxConcurrent: 1, ShuffleIterations: shuffle},
+		}
+		require.NoError(t, executor.Run(context.Background(), info))
+		return tracker.seen
+	}
+
+	sequential := dispatchOrder("shuffle", false)
+	for i, iteration := range sequential {
+		require.Equal(t, i+1, iteration)
+	}
+
+	shuffled := dispatchOrder("shuffle", true)
+	info := ScenarioInfo{RunID: "shuffle"}
+	for i, n := range rand.New(rand.NewSource(info.Seed())).Perm(20) {
+		require.Equal(t, n+1, shuffled[i])
+	}
+	require.NotEqual(t, sequential, shuffled)
+	require.ElementsMatch(t, sequential, shuffled)
+	// Reproducible for the same run ID, different for another
+	require.Equal(t, shuffled, dispatchOrder("shuffle", true))
+	require.NotEqual(t, shuffled, dispatchOrder("other", true))
+}
+
+func TestShuffleIterationsRequiresIterations(t *testing.T) {
+	executor := &GenericExecutor{DefaultConfiguration: RunConfiguration{Duration: time.Second, ShuffleIterations: true}}
+	_, err := executor.newRun(ScenarioInfo{MetricsHandler: client.MetricsNopHandler})
+	require.ErrorContains(t, err, "requires an iteration limit")
+}
+
+func TestRunGracePeriodAbandonsSlowIterations(t *testing.T) {
+	run := func(slowIterationTime, gracePeriod time.Duration) (RunResult, time.Duration) {
+		var buf bytes.Buffer
+		info := ScenarioInfo{
+			MetricsHandler: client.MetricsNopHandler,
+			Logger:         zap.NewNop().Sugar(),
+			ReportSinks:    []ReportSink{&WriterReportSink{Writer: &buf}},
+		}
+		executor := &GenericExecutor{
+			Execute: func(ctx context.Context, run *Run) error {
+				sleep := 10 * time.Millisecond
+				if run.Iteration <= 2 {
+					sleep = slowIterationTime
+				}
+				select {
+				case <-time.After(sleep):
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+			DefaultConfiguration: RunConfiguration{
+				Duration:      100 * time.Millisecond,
+				MaxConcurrent: 4,
+				GracePeriod:   gracePeriod,
+			},
+		}
+		start := time.Now()
+		require.NoError(t, executor.Run(context.Background(), info))
+		elapsed := time.Since(start)
+		var result RunResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		return result, elapsed
+	}
+
+	// Slow iterations outlast the grace period and are abandoned, the run ends after the grace
+	result, elapsed := run(time.Hour, 50*time.Millisecond)
+	require.Equal(t, 2, result.IterationsAbandoned)
+	require.Zero(t, result.IterationsFailed)
+	require.Equal(t, result.IterationsStarted-2, result.IterationsCompleted)
+	require.GreaterOrEqual(t, elapsed, 150*time.Millisecond)
+	require.Less(t, elapsed, time.Second)
+
+	// Slow iterations complete within the grace period after the duration elapsed
+	result, elapsed = run(150*time.Millisecond, time.Second)
+	require.Zero(t, result.IterationsAbandoned)
+	require.Equal(t, result.IterationsStarted, result.IterationsCompleted)
+	require.GreaterOrEqual(t, elapsed, 150*time.Millisecond)
+	require.Less(t, elapsed, time.Second)
+}
+
+func TestRunBatches(t *testing.T) {
+	var lock sync.Mutex
+	starts := map[int]time.Time{}
+	ends := map[int]time.Time{}
+	err := execute(&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			starts[run.Iteration] = time.Now()
+			lock.Unlock()
+			time.Sleep(time.Duration(run.Iteration) * 5 * time.Millisecond)
+			lock.Lock()
+			ends[run.Iteration] = time.Now()
+			lock.Unlock()
+			return nil
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 9, BatchSize: 3, BatchPause: 50 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	require.Len(t, starts, 9)
+	for batch := 1; batch < 3; batch++ {
+		// Every iteration of the next batch starts after the whole previous batch has ended,
+		// plus the pause
+		var lastEnd time.Time
+		for i := batch*3 - 2; i <= batch*3; i++ {
+			if ends[i].After(lastEnd) {
+				lastEnd = ends[i]
+			}
+		}
+		for i := batch*3 + 1; i <= batch*3+3; i++ {
+			require.GreaterOrEqual(t, starts[i].Sub(lastEnd), 50*time.Millisecond)
+		}
+	}
+	// Iterations within a batch start together
+	require.Less(t, starts[6].Sub(starts[4]), 20*time.Millisecond)
+}
+
+func TestRunBatchTimeout(t *testing.T) {
+	var lock sync.Mutex
+	starts := map[int]time.Time{}
+	runStart := time.Now()
+	err := execute(&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			starts[run.Iteration] = time.Now()
+			lock.Unlock()
+			if run.Iteration == 1 {
+				time.Sleep(300 * time.Millisecond)
+			}
+			return nil
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 4, BatchSize: 2, BatchTimeout: 50 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	// The second batch starts at the batch timeout without waiting for the slow iteration
+	require.GreaterOrEqual(t, starts[3].Sub(runStart), 50*time.Millisecond)
+	require.Less(t, starts[3].Sub(runStart), 250*time.Millisecond)
+}
+
+// concurrencyGauge tracks the peak number of concurrent calls.
+type concurrencyGauge struct {
+	sync.Mutex
+	current, peak int
+}
+
+func (c *concurrencyGauge) do(d time.Duration) {
+	c.Lock()
+	c.current++
+	if c.current > c.peak {
+		c.peak = c.current
+	}
+	c.Unlock()
+	time.Sleep(d)
+	c.Lock()
+	c.current--
+	c.Unlock()
+}
+
+// gaugedWorkflowRun is a workflow run whose result takes the given time, tracked by the gauge.
+type gaugedWorkflowRun struct {
+	FakeWorkflowRun
+	gauge *concurrencyGauge
+	delay time.Duration
+}
+
+func (r *gaugedWorkflowRun) Get(ctx context.Context, valuePtr interface{}) error {
+	r.gauge.do(r.delay)
+	return nil
+}
+
+func TestRunSeparateStartAndAwaitConcurrency(t *testing.T) {
+	for _, tc := range []struct {
+		name                   string
+		maxStarts, maxAwaits   int
+		startDelay, awaitDelay time.Duration
+	}{
+		// Slow awaits saturate the awaits, not limited by the starts
+		{name: "await-bound", maxStarts: 2, maxAwaits: 6, startDelay: 10 * time.Millisecond, awaitDelay: 100 * time.Millisecond},
+		// Slow starts saturate the starts, not limited by the awaits
+		{name: "start-bound", maxStarts: 6, maxAwaits: 1, startDelay: 100 * time.Millisecond, awaitDelay: time.Millisecond},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var starts, awaits concurrencyGauge
+			var lock sync.Mutex
+			var awaited []int
+			err := execute(&GenericExecutor{
+				Start: func(ctx context.Context, run *Run) (client.WorkflowRun, error) {
+					starts.do(tc.startDelay)
+					lock.Lock()
+					awaited = append(awaited, run.Iteration)
+					lock.Unlock()
+					return &gaugedWorkflowRun{gauge: &awaits, delay: tc.awaitDelay}, nil
+				},
+				DefaultConfiguration: RunConfiguration{
+					Iterations:          24,
+					MaxConcurrent:       tc.maxStarts,
+					MaxConcurrentAwaits: tc.maxAwaits,
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, awaited, 24)
+			require.Equal(t, tc.maxStarts, starts.peak)
+			require.Equal(t, tc.maxAwaits, awaits.peak)
+		})
+	}
+}
+
+func TestRunSeparateAwaitFailure(t *testing.T) {
+	executor := &GenericExecutor{
+		Start: func(ctx context.Context, run *Run) (client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: "w", RunID: "r", Err: errors.New("workflow failed")}, nil
+		},
+	}
+	err := executor.Run(context.Background(), NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 3}))
+	require.ErrorContains(t, err, "workflow execution failed (ID: w, run ID: r): workflow failed")
+}
+
+func TestRunMaxTotalStarts(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		config        RunConfiguration
+		expectStarted int
+		expectStopped bool
+	}{
+		{name: "duration", config: RunConfiguration{Duration: time.Minute, MaxTotalStarts: 5}, expectStarted: 5, expectStopped: true},
+		{name: "under-cap", config: RunConfiguration{Iterations: 3, MaxTotalStarts: 5}, expectStarted: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			info := ScenarioInfo{
+				MetricsHandler: client.MetricsNopHandler,
+				Logger:         zap.NewNop().Sugar(),
+				ReportSinks:    []ReportSink{&WriterReportSink{Writer: &buf}},
+			}
+			tracker := newIterationTracker()
+			start := time.Now()
+			executor := &GenericExecutor{
+				Execute: func(ctx context.Context, run *Run) error {
+					tracker.track(run.Iteration)
+					return nil
+				},
+				DefaultConfiguration: tc.config,
+			}
+			require.NoError(t, executor.Run(context.Background(), info))
+			// Does not wait out the duration
+			require.Less(t, time.Since(start), 10*time.Second)
+			require.Len(t, tracker.seen, tc.expectStarted)
+
+			var result RunResult
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+			require.Equal(t, tc.expectStarted, result.IterationsStarted)
+			require.Equal(t, tc.expectStopped, result.StoppedAtMaxTotalStarts)
+		})
+	}
+}
+
+func TestRunIterationOptions(t *testing.T) {
+	var lock sync.Mutex
+	seen := map[int]map[string]string{}
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 4, MaxConcurrent: 4})
+	info.ScenarioOptions = map[string]string{"payload-size": "10", "mode": "base"}
+	executor := &GenericExecutor{
+		IterationOptions: func(iteration int) map[string]string {
+			if iteration%2 == 0 {
+				return map[string]string{"payload-size": fmt.Sprint(iteration * 100)}
+			}
+			return nil
+		},
+		Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			defer lock.Unlock()
+			seen[run.Iteration] = map[string]string{
+				"payload-size": fmt.Sprint(run.ScenarioOptionInt("payload-size", 0)),
+				"mode":         run.ScenarioOptions["mode"],
+			}
+			return nil
+		},
+	}
+	require.NoError(t, executor.Run(context.Background(), info))
+	require.Equal(t, map[int]map[string]string{
+		1: {"payload-size": "10", "mode": "base"},
+		2: {"payload-size": "200", "mode": "base"},
+		3: {"payload-size": "10", "mode": "base"},
+		4: {"payload-size": "400", "mode": "base"},
+	}, seen)
+	// The shared options are unchanged
+	require.Equal(t, map[string]string{"payload-size": "10", "mode": "base"}, info.ScenarioOptions)
+}
+
+func TestSteadyStateThroughput(t *testing.T) {
+	start := time.Now()
+	var completions []time.Time
+	// Slow ramp-up and drain around 100 completions 10ms apart
+	completions = append(completions, start, start.Add(time.Second))
+	for i := 0; i <= 100; i++ {
+		completions = append(completions, start.Add(2*time.Second+time.Duration(i)*10*time.Millisecond))
+	}
+	completions = append(completions, start.Add(10*time.Second))
+	require.InDelta(t, 100, steadyStateThroughput(completions), 5)
+
+	require.Zero(t, steadyStateThroughput(nil))
+	require.Zero(t, steadyStateThroughput([]time.Time{start}))
+	require.Zero(t, steadyStateThroughput([]time.Time{start, start}))
+}
+
+func TestRunMinThroughput(t *testing.T) {
+	run := func(minThroughput float64) (RunResult, error) {
+		var buf bytes.Buffer
+		info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{
+			Iterations:    20,
+			MaxConcurrent: 1,
+			MinThroughput: minThroughput,
+		})
+		info.ReportSinks = []ReportSink{&WriterReportSink{Writer: &buf}}
+		executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}}
+		err := executor.Run(context.Background(), info)
+		var result RunResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		return result, err
+	}
+
+	// Iterations of 10ms one at a time complete at up to 100/s
+	result, err := run(20)
+	require.NoError(t, err)
+	require.Greater(t, result.SteadyStateThroughput, 20.0)
+	require.LessOrEqual(t, result.SteadyStateThroughput, 101.0)
+
+	// The report is still written when the floor is not met
+	result, err = run(1000)
+	require.ErrorIs(t, err, ErrBelowMinThroughput)
+	require.ErrorContains(t, err, "is below the minimum of 1000.00")
+	require.Equal(t, 20, result.IterationsCompleted)
+}
+
+func TestRunThinkTime(t *testing.T) {
+	// Returns the intervals between iteration starts and the report of a sequential run
+	run := func(thinkTime, jitter time.Duration) ([]time.Duration, RunResult) {
+		var buf bytes.Buffer
+		info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{
+			Iterations:      5,
+			MaxConcurrent:   1,
+			ThinkTime:       thinkTime,
+			ThinkTimeJitter: jitter,
+		})
+		info.ReportSinks = []ReportSink{&WriterReportSink{Writer: &buf}}
+		var lock sync.Mutex
+		var starts []time.Time
+		executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			starts = append(starts, time.Now())
+			lock.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}}
+		require.NoError(t, executor.Run(context.Background(), info))
+		var intervals []time.Duration
+		for i := 1; i < len(starts); i++ {
+			intervals = append(intervals, starts[i].Sub(starts[i-1]))
+		}
+		var result RunResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		return intervals, result
+	}
+
+	intervals, _ := run(0, 0)
+	for _, interval := range intervals {
+		require.Less(t, interval, 40*time.Millisecond)
+	}
+	// Think time adds to the interval between starts, but not to iteration latency
+	intervals, result := run(50*time.Millisecond, 0)
+	for _, interval := range intervals {
+		require.GreaterOrEqual(t, interval, 55*time.Millisecond)
+		require.Less(t, interval, 100*time.Millisecond)
+	}
+	require.Less(t, result.Latency.Max, 40*time.Millisecond)
+	// Think time ends early after the last iteration
+	require.Less(t, result.Duration, 5*55*time.Millisecond)
+	// Jitter adds up to its duration
+	intervals, _ = run(20*time.Millisecond, 30*time.Millisecond)
+	for _, interval := range intervals {
+		require.GreaterOrEqual(t, interval, 25*time.Millisecond)
+		require.Less(t, interval, 90*time.Millisecond)
+	}
+
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 1, ThinkTime: -time.Second})
+	err := (&GenericExecutor{Execute: func(ctx context.Context, run *Run) error { return nil }}).Run(context.Background(), info)
+	require.ErrorContains(t, err, "think time must not be negative")
+}
+
+func TestRunResultTimeoutFailsOnlyItsIteration(t *testing.T) {
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			delay := time.Millisecond
+			if options.ID == "w-test-run-1" {
+				delay = time.Hour
+			}
+			return &FakeWorkflowRun{ID: options.ID, Delay: delay}, nil
+		},
+	}
+	var buf bytes.Buffer
+	info := NewTestScenarioInfo(fake, RunConfiguration{
+		Iterations:    5,
+		MaxConcurrent: 5,
+		ResultTimeout: 100 * time.Millisecond,
+	})
+	info.ReportSinks = []ReportSink{&WriterReportSink{Writer: &buf}}
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			return run.ExecuteAnyWorkflow(ctx, run.DefaultStartWorkflowOptions(), "wf", nil)
+		},
+	}
+	err := executor.Run(context.Background(), info)
+	require.ErrorIs(t, err, ErrResultTimeout)
+	require.ErrorContains(t, err, "iteration 1 failed")
+
+	// The other iterations started and completed while the first awaited its result
+	var result RunResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Equal(t, 5, result.IterationsStarted)
+	require.Equal(t, 1, result.IterationsFailed)
+	require.Equal(t, 4, result.IterationsCompleted)
+}
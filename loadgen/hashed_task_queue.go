@@ -0,0 +1,40 @@
+package loadgen
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"go.temporal.io/sdk/client"
+)
+
+// HashTaskQueueIndex returns the index among count task queues the key hashes to. Unlike assigning
+// iterations round-robin, hashing spreads keys like real workloads' IDs do, with the uneven counts
+// of a random distribution, while the same key always maps to the same index.
+func HashTaskQueueIndex(key string, count int) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	// Finalize as in SplitMix64 so that keys differing only in their last characters, as
+	// sequential IDs do, spread over all indexes
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return int(x % uint64(count))
+}
+
+// WithHashedTaskQueue moves the workflow to one of count task queues, the task queue suffixed
+// "-0" to "-<count-1>" (as served by workers with --task-queue-suffix-index-end) that its
+// workflow ID hashes to with HashTaskQueueIndex. Apply it after setting the ID.
+func WithHashedTaskQueue(count int) StartOption {
+	return func(options *client.StartWorkflowOptions) {
+		options.TaskQueue = fmt.Sprintf("%v-%v", options.TaskQueue, HashTaskQueueIndex(options.ID, count))
+	}
+}
+
+// HashedTaskQueue returns the run's task queue among count for this iteration, by hashing the
+// iteration's default workflow ID, see WithHashedTaskQueue. It is reproducible for the same run ID.
+func (r *Run) HashedTaskQueue(count int) string {
+	options := r.DefaultStartWorkflowOptions()
+	WithHashedTaskQueue(count)(&options)
+	return options.TaskQueue
+}
@@ -0,0 +1,47 @@
+package loadgen
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashTaskQueueIndexSpreadsEvenly(t *testing.T) {
+	const count, keys = 8, 10000
+	counts := make([]int, count)
+	for i := 1; i <= keys; i++ {
+		index := HashTaskQueueIndex(fmt.Sprintf("omes-run-%v", i), count)
+		require.GreaterOrEqual(t, index, 0)
+		require.Less(t, index, count)
+		counts[index]++
+	}
+	// Each queue gets its share within 10%, well beyond the expected deviation of a random spread
+	for i, c := range counts {
+		require.InDelta(t, keys/count, c, keys/count/10, "task queue %v", i)
+	}
+}
+
+func TestHashTaskQueueIndexIsNotRoundRobin(t *testing.T) {
+	// Consecutive keys do not simply cycle through the queues
+	roundRobin := true
+	for i := 0; i < 16; i++ {
+		if HashTaskQueueIndex(fmt.Sprintf("omes-run-%v", i), 4) != i%4 {
+			roundRobin = false
+		}
+	}
+	require.False(t, roundRobin)
+}
+
+func TestRunHashedTaskQueue(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	run := info.NewRun(7)
+	taskQueue := run.HashedTaskQueue(4)
+	require.Equal(t, fmt.Sprintf("test:test-run-%v", HashTaskQueueIndex("w-test-run-7", 4)), taskQueue)
+	// Reproducible for the same run
+	require.Equal(t, taskQueue, info.NewRun(7).HashedTaskQueue(4))
+
+	options := run.StartWorkflowOptions(WithHashedTaskQueue(4))
+	require.Equal(t, taskQueue, options.TaskQueue)
+	require.Equal(t, "w-test-run-7", options.ID)
+}
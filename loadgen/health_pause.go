@@ -0,0 +1,124 @@
+package loadgen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+// ClientHealthCheck checks the health of the cluster with the client's gRPC health check of the
+// frontend. It is the default GenericExecutor.HealthCheck.
+func ClientHealthCheck(ctx context.Context, info *ScenarioInfo) error {
+	_, err := info.Client.CheckHealth(ctx, &client.CheckHealthRequest{})
+	return err
+}
+
+// HealthPause holds back new starts while the cluster is unhealthy, i.e. while Check fails,
+// resuming once it succeeds again. The check runs every Interval once started, each bounded by the
+// interval. Pauses are logged, reflected in the omes_health_paused gauge (1 while paused, 0
+// otherwise) and failed checks are counted in the omes_health_check_failures counter. It is safe
+// for concurrent use.
+type HealthPause struct {
+	Interval time.Duration
+	// Returns an error if the cluster is unhealthy. Default is ClientHealthCheck.
+	Check func(ctx context.Context, info *ScenarioInfo) error
+
+	lock     sync.Mutex
+	paused   bool
+	pausedAt time.Time
+	// Closed when no longer paused.
+	resumed chan struct{}
+}
+
+// Start checks health once, then keeps checking it and updating the pause in the background until
+// the context is done.
+func (h *HealthPause) Start(ctx context.Context, info *ScenarioInfo) {
+	info.RecordGauge("omes_health_paused", nil, 0)
+	h.poll(ctx, info)
+	go func() {
+		ticker := time.NewTicker(h.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.poll(ctx, info)
+			}
+		}
+	}()
+}
+
+func (h *HealthPause) poll(ctx context.Context, info *ScenarioInfo) {
+	check := h.Check
+	if check == nil {
+		check = ClientHealthCheck
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, h.Interval)
+	defer cancel()
+	err := check(checkCtx, info)
+	if ctx.Err() != nil {
+		return
+	}
+	h.update(info, err)
+}
+
+func (h *HealthPause) update(info *ScenarioInfo, err error) {
+	if err != nil {
+		info.RecordCounter("omes_health_check_failures", nil, 1)
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	paused := err != nil
+	if paused == h.paused {
+		return
+	}
+	h.paused = paused
+	if paused {
+		info.Logger.Warnf("Pausing starts, cluster health check failed: %v", err)
+		info.RecordGauge("omes_health_paused", nil, 1)
+		h.pausedAt = time.Now()
+		h.resumed = make(chan struct{})
+	} else {
+		info.Logger.Infof("Resuming starts, cluster healthy again after %v", time.Since(h.pausedAt))
+		info.RecordGauge("omes_health_paused", nil, 0)
+		close(h.resumed)
+	}
+}
+
+// Paused returns whether starts are currently paused.
+func (h *HealthPause) Paused() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.paused
+}
+
+// Wait blocks while starts are paused or until the context is done.
+func (h *HealthPause) Wait(ctx context.Context) error {
+	resumed := h.resumedCh()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resumedCh returns the channel closed when starts are no longer paused, or nil if they are not or
+// h is nil.
+func (h *HealthPause) resumedCh() <-chan struct{} {
+	if h == nil {
+		return nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if !h.paused {
+		return nil
+	}
+	return h.resumed
+}
@@ -0,0 +1,121 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthPauseHoldsBackStarts(t *testing.T) {
+	var healthy, checks int32
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 3, HealthCheckInterval: time.Millisecond})
+	handler := newRecordingMetricsHandler()
+	info.MetricsHandler = handler
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			return run.ExecuteAnyWorkflow(ctx, run.StartWorkflowOptions(), "wf", nil)
+		},
+		HealthCheck: func(ctx context.Context, info *ScenarioInfo) error {
+			atomic.AddInt32(&checks, 1)
+			if atomic.LoadInt32(&healthy) == 0 {
+				return errors.New("unavailable")
+			}
+			return nil
+		},
+	}
+	done := make(chan error, 1)
+	go func() { done <- executor.Run(context.Background(), info) }()
+
+	// Nothing starts while the cluster is unhealthy
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&checks) >= 5 }, time.Second, time.Millisecond)
+	require.Empty(t, fake.Calls("ExecuteWorkflow"))
+
+	atomic.StoreInt32(&healthy, 1)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not resume")
+	}
+	require.Len(t, fake.Calls("ExecuteWorkflow"), 3)
+
+	var paused []float64
+	var failures float64
+	handler.lock.Lock()
+	for _, metric := range *handler.recorded {
+		switch metric.name {
+		case "omes_health_paused":
+			paused = append(paused, metric.value)
+		case "omes_health_check_failures":
+			failures += metric.value
+		}
+	}
+	handler.lock.Unlock()
+	require.Equal(t, []float64{0, 1, 0}, paused)
+	require.GreaterOrEqual(t, failures, float64(5))
+}
+
+func TestHealthPauseWait(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	pause := &HealthPause{Interval: time.Second}
+	require.NoError(t, pause.Wait(context.Background()))
+
+	pause.update(&info, errors.New("unavailable"))
+	require.True(t, pause.Paused())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, pause.Wait(ctx), context.DeadlineExceeded)
+
+	// Further failures keep it paused
+	pause.update(&info, errors.New("unavailable"))
+	require.True(t, pause.Paused())
+
+	go pause.update(&info, nil)
+	require.NoError(t, pause.Wait(context.Background()))
+	require.False(t, pause.Paused())
+}
+
+func TestHealthPauseDoesNotDelayFailure(t *testing.T) {
+	var unhealthy, checks int32
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{
+		Iterations:          1000,
+		MaxConcurrent:       2,
+		HealthCheckInterval: time.Millisecond,
+	})
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			if run.Iteration > 1 {
+				time.Sleep(time.Millisecond)
+				return nil
+			}
+			// Fail once starts are paused for good, with a concurrency slot free
+			atomic.StoreInt32(&unhealthy, 1)
+			prev := atomic.LoadInt32(&checks)
+			for atomic.LoadInt32(&checks) < prev+3 {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(10 * time.Millisecond)
+			return errors.New("boom")
+		},
+		HealthCheck: func(ctx context.Context, info *ScenarioInfo) error {
+			atomic.AddInt32(&checks, 1)
+			if atomic.LoadInt32(&unhealthy) == 1 {
+				return errors.New("unavailable")
+			}
+			return nil
+		},
+	}
+	done := make(chan error, 1)
+	go func() { done <- executor.Run(context.Background(), info) }()
+	select {
+	case err := <-done:
+		require.ErrorContains(t, err, "iteration 1 failed: boom")
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not fail while paused")
+	}
+}
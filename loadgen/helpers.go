@@ -2,13 +2,41 @@ package loadgen
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"time"
 
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
 )
 
+// WorkflowSpec is the workflow type and arguments a helper starts its workflows with.
+type WorkflowSpec struct {
+	// Workflow type and arguments to start. Default is a kitchen sink workflow that runs until told
+	// otherwise by signal.
+	Workflow     interface{}
+	WorkflowArgs []interface{}
+}
+
+// workflowAndArgs returns the workflow type and arguments to start, the default if Workflow is not
+// set.
+func (w *WorkflowSpec) workflowAndArgs() (interface{}, []interface{}) {
+	if w.Workflow == nil {
+		return defaultWorkflowAndArgs()
+	}
+	return w.Workflow, w.WorkflowArgs
+}
+
+// defaultWorkflowAndArgs returns the default of WorkflowSpec, a kitchen sink workflow without
+// actions, which runs until told otherwise by signal.
+func defaultWorkflowAndArgs() (interface{}, []interface{}) {
+	return "kitchenSink", []interface{}{&kitchensink.WorkflowInput{}}
+}
+
 // VisibilityCountIsEventually ensures that some visibility query count matches the provided
 // expected number within the provided time limit.
 func VisibilityCountIsEventually(
@@ -34,3 +62,152 @@ func VisibilityCountIsEventually(
 		time.Sleep(5 * time.Second)
 	}
 }
+
+// workflowStatusNames are the visibility names of all closed and open workflow execution statuses.
+var workflowStatusNames = []string{
+	"Running", "Completed", "Failed", "Canceled", "Terminated", "ContinuedAsNew", "TimedOut",
+}
+
+// visibilityPollInterval is the interval between visibility queries when waiting for results to
+// settle.
+var visibilityPollInterval = 5 * time.Second
+
+const defaultVisibilitySettleTimeout = time.Minute
+
+// CountWorkflowsByStatus counts the workflows of this scenario run (matched by RunVisibilityQuery)
+// in visibility and tallies them by execution status name. Each tally is a single GROUP BY
+// ExecutionStatus count where visibility supports it. Otherwise it falls back to one count per
+// status, so a tally is not a snapshot: a workflow closing between two counts may be counted under
+// both statuses or neither. Since visibility is eventually consistent, counts are re-queried until
+// two consecutive tallies agree or the context deadline (or one minute if there is none) is
+// reached, in which case the latest tally is returned with an error.
+func (r *Run) CountWorkflowsByStatus(ctx context.Context) (map[string]int, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultVisibilitySettleTimeout)
+	}
+	groupBy := true
+	var prev map[string]int
+	for {
+		var counts map[string]int
+		var err error
+		if groupBy {
+			counts, err = r.countWorkflowsGroupedByStatus(ctx)
+			var invalidArgument *serviceerror.InvalidArgument
+			var unimplemented *serviceerror.Unimplemented
+			if errors.As(err, &invalidArgument) || errors.As(err, &unimplemented) {
+				r.Logger.Debugf("Visibility does not support grouping counts by status, counting per status: %v", err)
+				groupBy = false
+			}
+		}
+		if !groupBy {
+			counts, err = r.countWorkflowsPerStatus(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if prev != nil && reflect.DeepEqual(prev, counts) {
+			return counts, nil
+		}
+		prev = counts
+		if time.Now().Add(visibilityPollInterval).After(deadline) {
+			return counts, fmt.Errorf("workflow status counts did not stabilize before deadline, latest: %v", counts)
+		}
+		select {
+		case <-ctx.Done():
+			return counts, fmt.Errorf("workflow status counts did not stabilize: %w", ctx.Err())
+		case <-time.After(visibilityPollInterval):
+		}
+	}
+}
+
+// countWorkflowsGroupedByStatus tallies the run's workflows by status with a single GROUP BY
+// ExecutionStatus count.
+func (r *Run) countWorkflowsGroupedByStatus(ctx context.Context) (map[string]int, error) {
+	resp, err := r.Client.CountWorkflow(ctx, &workflowservice.CountWorkflowExecutionsRequest{
+		Namespace: r.Namespace,
+		Query:     r.RunVisibilityQuery() + " GROUP BY ExecutionStatus",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count workflows by status in visibility: %w", err)
+	}
+	counts := make(map[string]int, len(workflowStatusNames))
+	for _, status := range workflowStatusNames {
+		counts[status] = 0
+	}
+	for _, group := range resp.Groups {
+		var status string
+		if len(group.GroupValues) != 1 {
+			return nil, fmt.Errorf("count group has %v values, expected the status only", len(group.GroupValues))
+		} else if err := converter.GetDefaultDataConverter().FromPayload(group.GroupValues[0], &status); err != nil {
+			return nil, fmt.Errorf("failed to decode status of count group: %w", err)
+		}
+		counts[status] = int(group.Count)
+	}
+	return counts, nil
+}
+
+// countWorkflowsPerStatus tallies the run's workflows by status with one count per status.
+func (r *Run) countWorkflowsPerStatus(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int, len(workflowStatusNames))
+	for _, status := range workflowStatusNames {
+		resp, err := r.Client.CountWorkflow(ctx, &workflowservice.CountWorkflowExecutionsRequest{
+			Namespace: r.Namespace,
+			Query:     fmt.Sprintf("%v AND ExecutionStatus = %q", r.RunVisibilityQuery(), status),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s workflows in visibility: %w", status, err)
+		}
+		counts[status] = int(resp.Count)
+	}
+	return counts, nil
+}
+
+// maxReportedRunningWorkflows limits the number of running workflow IDs listed by
+// AssertNoRunningWorkflows.
+const maxReportedRunningWorkflows = 100
+
+// RunningWorkflowsError is returned by AssertNoRunningWorkflows when workflows of the run are
+// still running.
+type RunningWorkflowsError struct {
+	// IDs of the running workflows, up to 100.
+	WorkflowIDs []string
+	Timeout     time.Duration
+}
+
+func (e *RunningWorkflowsError) Error() string {
+	return fmt.Sprintf("workflows still running after %v: %v", e.Timeout, e.WorkflowIDs)
+}
+
+// AssertNoRunningWorkflows queries visibility for running workflows of this scenario run (matched
+// by RunVisibilityQuery) until there are none or the timeout elapses, in which case a
+// RunningWorkflowsError with the stuck workflow IDs is returned.
+func (r *Run) AssertNoRunningWorkflows(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	query := fmt.Sprintf("%v AND ExecutionStatus = \"Running\"", r.RunVisibilityQuery())
+	for {
+		resp, err := r.Client.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Namespace: r.Namespace,
+			PageSize:  maxReportedRunningWorkflows,
+			Query:     query,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list running workflows in visibility: %w", err)
+		}
+		if len(resp.Executions) == 0 {
+			return nil
+		}
+		if time.Now().Add(visibilityPollInterval).After(deadline) {
+			runningErr := &RunningWorkflowsError{Timeout: timeout}
+			for _, execution := range resp.Executions {
+				runningErr.WorkflowIDs = append(runningErr.WorkflowIDs, execution.Execution.GetWorkflowId())
+			}
+			return runningErr
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(visibilityPollInterval):
+		}
+	}
+}
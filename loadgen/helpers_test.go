@@ -0,0 +1,189 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.uber.org/zap"
+)
+
+var statusQueryRegexp = regexp.MustCompile(`ExecutionStatus = "(\w+)"`)
+
+// fakeVisibilityClient answers count queries by execution status from a sequence of snapshots,
+// advancing to the next snapshot at each tally after the first. GROUP BY ExecutionStatus counts are
+// rejected unless groupByStatus is set.
+type fakeVisibilityClient struct {
+	client.Client
+	sync.Mutex
+	snapshots     []map[string]int
+	groupByStatus bool
+	queries       []string
+	tallies       int
+}
+
+func (f *fakeVisibilityClient) CountWorkflow(
+	ctx context.Context,
+	request *workflowservice.CountWorkflowExecutionsRequest,
+) (*workflowservice.CountWorkflowExecutionsResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.queries = append(f.queries, request.Query)
+	if strings.HasSuffix(request.Query, " GROUP BY ExecutionStatus") {
+		if !f.groupByStatus {
+			return nil, serviceerror.NewInvalidArgument("GROUP BY not supported")
+		}
+		f.nextTally()
+		resp := &workflowservice.CountWorkflowExecutionsResponse{}
+		for status, count := range f.snapshots[0] {
+			value, err := converter.GetDefaultDataConverter().ToPayload(status)
+			if err != nil {
+				return nil, err
+			}
+			resp.Groups = append(resp.Groups, &workflowservice.CountWorkflowExecutionsResponse_AggregationGroup{
+				GroupValues: []*common.Payload{value}, Count: int64(count),
+			})
+			resp.Count += int64(count)
+		}
+		return resp, nil
+	}
+	status := statusQueryRegexp.FindStringSubmatch(request.Query)[1]
+	if status == "Running" {
+		f.nextTally()
+	}
+	return &workflowservice.CountWorkflowExecutionsResponse{Count: int64(f.snapshots[0][status])}, nil
+}
+
+func (f *fakeVisibilityClient) nextTally() {
+	if f.tallies > 0 && len(f.snapshots) > 1 {
+		f.snapshots = f.snapshots[1:]
+	}
+	f.tallies++
+}
+
+func useFastVisibilityPolling(t *testing.T) {
+	prev := visibilityPollInterval
+	visibilityPollInterval = time.Millisecond
+	t.Cleanup(func() { visibilityPollInterval = prev })
+}
+
+func TestCountWorkflowsByStatus(t *testing.T) {
+	useFastVisibilityPolling(t)
+	fakeClient := &fakeVisibilityClient{snapshots: []map[string]int{
+		{"Running": 2, "Completed": 1},
+		{"Completed": 2, "Failed": 1},
+		{"Completed": 2, "Failed": 1},
+	}}
+	info := &ScenarioInfo{RunID: "count-test", Logger: zap.NewNop().Sugar(), Client: fakeClient}
+	counts, err := info.NewRun(0).CountWorkflowsByStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, counts["Completed"])
+	require.Equal(t, 1, counts["Failed"])
+	require.Equal(t, 0, counts["Running"])
+	require.Contains(t, fakeClient.queries[0], `WorkflowId STARTS_WITH "w-count-test-"`)
+}
+
+func TestCountWorkflowsByStatusGroupBy(t *testing.T) {
+	useFastVisibilityPolling(t)
+	fakeClient := &fakeVisibilityClient{groupByStatus: true, snapshots: []map[string]int{
+		{"Running": 2, "Completed": 1},
+		{"Completed": 2, "Failed": 1},
+		{"Completed": 2, "Failed": 1},
+	}}
+	info := &ScenarioInfo{RunID: "count-test", Logger: zap.NewNop().Sugar(), Client: fakeClient}
+	counts, err := info.NewRun(0).CountWorkflowsByStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, counts["Completed"])
+	require.Equal(t, 1, counts["Failed"])
+	require.Equal(t, 0, counts["Running"])
+	require.Len(t, counts, len(workflowStatusNames))
+	require.Equal(t, []string{
+		`WorkflowId STARTS_WITH "w-count-test-" GROUP BY ExecutionStatus`,
+		`WorkflowId STARTS_WITH "w-count-test-" GROUP BY ExecutionStatus`,
+		`WorkflowId STARTS_WITH "w-count-test-" GROUP BY ExecutionStatus`,
+	}, fakeClient.queries)
+}
+
+func TestCountWorkflowsByStatusNeverStable(t *testing.T) {
+	useFastVisibilityPolling(t)
+	var snapshots []map[string]int
+	for i := 0; i < 1000; i++ {
+		snapshots = append(snapshots, map[string]int{"Running": i})
+	}
+	info := &ScenarioInfo{RunID: "count-test", Logger: zap.NewNop().Sugar(),
+		Client: &fakeVisibilityClient{snapshots: snapshots}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := info.NewRun(0).CountWorkflowsByStatus(ctx)
+	require.ErrorContains(t, err, "did not stabilize")
+}
+
+func TestCountWorkflowsByStatusIDPrefix(t *testing.T) {
+	useFastVisibilityPolling(t)
+	fakeClient := &fakeVisibilityClient{snapshots: []map[string]int{{"Completed": 1}}}
+	info := &ScenarioInfo{RunID: "count-test", IDPrefix: "omes", Logger: zap.NewNop().Sugar(), Client: fakeClient}
+	run := info.NewRun(3)
+	require.Equal(t, "omes-count-test-3", run.DefaultStartWorkflowOptions().ID)
+	_, err := run.CountWorkflowsByStatus(context.Background())
+	require.NoError(t, err)
+	for _, query := range fakeClient.queries {
+		require.Contains(t, query, `WorkflowId STARTS_WITH "omes-count-test-"`)
+	}
+}
+
+// fakeListClient answers list queries from a sequence of running workflow ID snapshots, advancing
+// to the next snapshot on each query.
+type fakeListClient struct {
+	client.Client
+	snapshots [][]string
+	queries   []string
+}
+
+func (f *fakeListClient) ListWorkflow(
+	ctx context.Context,
+	request *workflowservice.ListWorkflowExecutionsRequest,
+) (*workflowservice.ListWorkflowExecutionsResponse, error) {
+	f.queries = append(f.queries, request.Query)
+	ids := f.snapshots[0]
+	if len(f.snapshots) > 1 {
+		f.snapshots = f.snapshots[1:]
+	}
+	resp := &workflowservice.ListWorkflowExecutionsResponse{}
+	for _, id := range ids {
+		resp.Executions = append(resp.Executions, &workflow.WorkflowExecutionInfo{
+			Execution: &common.WorkflowExecution{WorkflowId: id},
+		})
+	}
+	return resp, nil
+}
+
+func TestAssertNoRunningWorkflowsEventuallyClear(t *testing.T) {
+	useFastVisibilityPolling(t)
+	fakeClient := &fakeListClient{snapshots: [][]string{{"w-list-test-1", "w-list-test-2"}, {"w-list-test-2"}, {}}}
+	info := &ScenarioInfo{RunID: "list-test", Logger: zap.NewNop().Sugar(), Client: fakeClient}
+	require.NoError(t, info.NewRun(0).AssertNoRunningWorkflows(context.Background(), time.Second))
+	require.Len(t, fakeClient.queries, 3)
+	require.Equal(t, `WorkflowId STARTS_WITH "w-list-test-" AND ExecutionStatus = "Running"`, fakeClient.queries[0])
+}
+
+func TestAssertNoRunningWorkflowsStuck(t *testing.T) {
+	useFastVisibilityPolling(t)
+	fakeClient := &fakeListClient{snapshots: [][]string{{"w-list-test-1", "w-list-test-2"}, {"w-list-test-2"}}}
+	info := &ScenarioInfo{RunID: "list-test", Logger: zap.NewNop().Sugar(), Client: fakeClient}
+	err := info.NewRun(0).AssertNoRunningWorkflows(context.Background(), 20*time.Millisecond)
+	var runningErr *RunningWorkflowsError
+	require.True(t, errors.As(err, &runningErr))
+	require.Equal(t, []string{"w-list-test-2"}, runningErr.WorkflowIDs)
+	require.Greater(t, len(fakeClient.queries), 2)
+}
@@ -0,0 +1,184 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// HistoryInvariant is a named check of the events of a closed workflow's history.
+type HistoryInvariant struct {
+	Name  string
+	Check func(events []*history.HistoryEvent) error
+}
+
+// ActivitiesResolvedInvariant requires every scheduled activity to have completed, failed, timed
+// out or been canceled. It only holds for workflows that wait on all their activities.
+var ActivitiesResolvedInvariant = HistoryInvariant{
+	Name: "activities resolved",
+	Check: func(events []*history.HistoryEvent) error {
+		pending := map[int64]string{}
+		for _, event := range events {
+			if attrs := event.GetActivityTaskScheduledEventAttributes(); attrs != nil {
+				pending[event.EventId] = attrs.GetActivityType().GetName()
+			}
+			var scheduledEventID int64
+			switch {
+			case event.GetActivityTaskCompletedEventAttributes() != nil:
+				scheduledEventID = event.GetActivityTaskCompletedEventAttributes().ScheduledEventId
+			case event.GetActivityTaskFailedEventAttributes() != nil:
+				scheduledEventID = event.GetActivityTaskFailedEventAttributes().ScheduledEventId
+			case event.GetActivityTaskTimedOutEventAttributes() != nil:
+				scheduledEventID = event.GetActivityTaskTimedOutEventAttributes().ScheduledEventId
+			case event.GetActivityTaskCanceledEventAttributes() != nil:
+				scheduledEventID = event.GetActivityTaskCanceledEventAttributes().ScheduledEventId
+			default:
+				continue
+			}
+			delete(pending, scheduledEventID)
+		}
+		if len(pending) > 0 {
+			ids := make([]int64, 0, len(pending))
+			for id := range pending {
+				ids = append(ids, id)
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			return fmt.Errorf("activity scheduled in event %v (%v) never resolved", ids[0], pending[ids[0]])
+		}
+		return nil
+	},
+}
+
+// TimersFireInOrderInvariant requires timers to fire in order of their due time, the time they
+// were started plus their duration.
+var TimersFireInOrderInvariant = HistoryInvariant{
+	Name: "timers fire in order",
+	Check: func(events []*history.HistoryEvent) error {
+		due := map[int64]time.Time{}
+		var lastDue time.Time
+		var lastTimerID string
+		for _, event := range events {
+			if attrs := event.GetTimerStartedEventAttributes(); attrs != nil && event.EventTime != nil {
+				var duration time.Duration
+				if attrs.StartToFireTimeout != nil {
+					duration = *attrs.StartToFireTimeout
+				}
+				due[event.EventId] = event.EventTime.Add(duration)
+			} else if attrs := event.GetTimerFiredEventAttributes(); attrs != nil {
+				fireDue, ok := due[attrs.StartedEventId]
+				if !ok {
+					continue
+				}
+				if fireDue.Before(lastDue) {
+					return fmt.Errorf("timer %v due at %v fired after timer %v due at %v",
+						attrs.TimerId, fireDue, lastTimerID, lastDue)
+				}
+				lastDue, lastTimerID = fireDue, attrs.TimerId
+			}
+		}
+		return nil
+	},
+}
+
+// DefaultHistoryInvariants are the invariants checked by VerifyHistoryInvariants if none are given.
+var DefaultHistoryInvariants = []HistoryInvariant{ActivitiesResolvedInvariant, TimersFireInOrderInvariant}
+
+// HistoryInvariantViolation is an invariant a workflow history does not satisfy.
+type HistoryInvariantViolation struct {
+	WorkflowID string
+	RunID      string
+	Invariant  string
+	Message    string
+}
+
+// HistoryInvariantError is returned by VerifyHistoryInvariants when any history violates an
+// invariant.
+type HistoryInvariantError struct {
+	Violations []HistoryInvariantViolation
+	// Number of histories checked, and the number of workflows they were sampled from.
+	Checked int
+	Total   int
+}
+
+func (e *HistoryInvariantError) Error() string {
+	var violations []string
+	for _, v := range e.Violations {
+		violations = append(violations, fmt.Sprintf("%v (%v): %v", v.WorkflowID, v.Invariant, v.Message))
+	}
+	return fmt.Sprintf("%v of %v histories checked (sampled from %v) violate invariants: %v",
+		len(e.Violations), e.Checked, e.Total, strings.Join(violations, "; "))
+}
+
+// VerifyHistoryInvariants checks the histories of a sample of the closed workflows of this scenario
+// run (matched by RunVisibilityQuery), chosen reproducibly with the scenario seed, against the given
+// invariants, or DefaultHistoryInvariants if none. The number of histories checked is returned.
+// Violations are returned in a HistoryInvariantError.
+func (r *Run) VerifyHistoryInvariants(
+	ctx context.Context,
+	sampling VerificationSampling,
+	invariants ...HistoryInvariant,
+) (int, error) {
+	if len(invariants) == 0 {
+		invariants = DefaultHistoryInvariants
+	}
+	var executions []*workflow.WorkflowExecutionInfo
+	request := &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace: r.Namespace,
+		Query:     fmt.Sprintf("%v AND ExecutionStatus != \"Running\"", r.RunVisibilityQuery()),
+	}
+	for {
+		resp, err := r.Client.ListWorkflow(ctx, request)
+		if err != nil {
+			return 0, fmt.Errorf("failed listing workflows to verify: %w", err)
+		}
+		executions = append(executions, resp.Executions...)
+		if len(resp.NextPageToken) == 0 {
+			break
+		}
+		request.NextPageToken = resp.NextPageToken
+	}
+	// Order by ID so the sample does not depend on listing order
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].Execution.GetWorkflowId() < executions[j].Execution.GetWorkflowId()
+	})
+	total := len(executions)
+	sampled := make([]*workflow.WorkflowExecutionInfo, 0, sampling.Size(total))
+	for _, i := range sampling.Indexes(r.Seed(), total) {
+		sampled = append(sampled, executions[i])
+	}
+	executions = sampled
+
+	invariantErr := &HistoryInvariantError{Checked: len(executions), Total: total}
+	for _, execution := range executions {
+		id, runID := execution.Execution.GetWorkflowId(), execution.Execution.GetRunId()
+		var events []*history.HistoryEvent
+		iter := r.Client.GetWorkflowHistory(ctx, id, runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+		for iter.HasNext() {
+			event, err := iter.Next()
+			if err != nil {
+				return 0, fmt.Errorf("failed reading history of workflow %v: %w", id, err)
+			}
+			events = append(events, event)
+		}
+		for _, invariant := range invariants {
+			if err := invariant.Check(events); err != nil {
+				invariantErr.Violations = append(invariantErr.Violations, HistoryInvariantViolation{
+					WorkflowID: id, RunID: runID, Invariant: invariant.Name, Message: err.Error(),
+				})
+			}
+		}
+	}
+	r.Logger.Infof("Verified history invariants of %v workflows sampled from %v, %v violations",
+		len(executions), total, len(invariantErr.Violations))
+	if len(invariantErr.Violations) > 0 {
+		return len(executions), invariantErr
+	}
+	return len(executions), nil
+}
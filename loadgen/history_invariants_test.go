@@ -0,0 +1,137 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+func activityScheduled(eventID int64) *history.HistoryEvent {
+	return &history.HistoryEvent{
+		EventId: eventID,
+		Attributes: &history.HistoryEvent_ActivityTaskScheduledEventAttributes{
+			ActivityTaskScheduledEventAttributes: &history.ActivityTaskScheduledEventAttributes{
+				ActivityType: &common.ActivityType{Name: "noop"},
+			},
+		},
+	}
+}
+
+func activityCompleted(eventID, scheduledEventID int64) *history.HistoryEvent {
+	return &history.HistoryEvent{
+		EventId: eventID,
+		Attributes: &history.HistoryEvent_ActivityTaskCompletedEventAttributes{
+			ActivityTaskCompletedEventAttributes: &history.ActivityTaskCompletedEventAttributes{
+				ScheduledEventId: scheduledEventID,
+			},
+		},
+	}
+}
+
+func timerStarted(eventID int64, timerID string, at time.Time, duration time.Duration) *history.HistoryEvent {
+	return &history.HistoryEvent{
+		EventId:   eventID,
+		EventTime: &at,
+		Attributes: &history.HistoryEvent_TimerStartedEventAttributes{
+			TimerStartedEventAttributes: &history.TimerStartedEventAttributes{
+				TimerId: timerID, StartToFireTimeout: &duration,
+			},
+		},
+	}
+}
+
+func timerFired(eventID, startedEventID int64, timerID string) *history.HistoryEvent {
+	return &history.HistoryEvent{
+		EventId: eventID,
+		Attributes: &history.HistoryEvent_TimerFiredEventAttributes{
+			TimerFiredEventAttributes: &history.TimerFiredEventAttributes{TimerId: timerID, StartedEventId: startedEventID},
+		},
+	}
+}
+
+func newHistoriesClient(histories map[string][]*history.HistoryEvent) *FakeClient {
+	return &FakeClient{
+		OnListWorkflow: func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (
+			*workflowservice.ListWorkflowExecutionsResponse, error) {
+			resp := &workflowservice.ListWorkflowExecutionsResponse{}
+			for id := range histories {
+				resp.Executions = append(resp.Executions, &workflow.WorkflowExecutionInfo{
+					Execution: &common.WorkflowExecution{WorkflowId: id, RunId: "run"},
+				})
+			}
+			return resp, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return histories[workflowID], nil
+		},
+	}
+}
+
+func TestVerifyHistoryInvariantsSatisfied(t *testing.T) {
+	start := time.Now()
+	fake := newHistoriesClient(map[string][]*history.HistoryEvent{
+		"w-test-run-1": {activityScheduled(5), activityCompleted(7, 5)},
+		"w-test-run-2": {
+			timerStarted(5, "long", start, 2*time.Second),
+			timerStarted(6, "short", start, time.Second),
+			timerFired(8, 6, "short"),
+			timerFired(10, 5, "long"),
+		},
+	})
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	checked, err := info.NewRun(0).VerifyHistoryInvariants(context.Background(), VerificationSampling{})
+	require.NoError(t, err)
+	require.Equal(t, 2, checked)
+	require.Equal(t, `WorkflowId STARTS_WITH "w-test-run-" AND ExecutionStatus != "Running"`,
+		fake.Calls("ListWorkflow")[0].Name)
+	require.Len(t, fake.Calls("GetWorkflowHistory"), 2)
+}
+
+func TestVerifyHistoryInvariantsViolated(t *testing.T) {
+	start := time.Now()
+	fake := newHistoriesClient(map[string][]*history.HistoryEvent{
+		"w-test-run-1": {activityScheduled(5), activityScheduled(6), activityCompleted(8, 5)},
+		"w-test-run-2": {
+			timerStarted(5, "long", start, 2*time.Second),
+			timerStarted(6, "short", start, time.Second),
+			timerFired(8, 5, "long"),
+			timerFired(10, 6, "short"),
+		},
+		"w-test-run-3": {activityScheduled(5), activityCompleted(7, 5)},
+	})
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.NewRun(0).VerifyHistoryInvariants(context.Background(), VerificationSampling{})
+	var invariantErr *HistoryInvariantError
+	require.True(t, errors.As(err, &invariantErr))
+	require.Equal(t, 3, invariantErr.Checked)
+	require.Equal(t, 3, invariantErr.Total)
+	violations := map[string]HistoryInvariantViolation{}
+	for _, violation := range invariantErr.Violations {
+		violations[violation.WorkflowID] = violation
+	}
+	require.Len(t, violations, 2)
+	require.Equal(t, ActivitiesResolvedInvariant.Name, violations["w-test-run-1"].Invariant)
+	require.Contains(t, violations["w-test-run-1"].Message, "event 6")
+	require.Equal(t, TimersFireInOrderInvariant.Name, violations["w-test-run-2"].Invariant)
+	require.Contains(t, violations["w-test-run-2"].Message, "timer short")
+}
+
+func TestVerifyHistoryInvariantsSample(t *testing.T) {
+	histories := map[string][]*history.HistoryEvent{}
+	for _, id := range []string{"w-test-run-1", "w-test-run-2", "w-test-run-3", "w-test-run-4"} {
+		histories[id] = nil
+	}
+	fake := newHistoriesClient(histories)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	checked, err := info.NewRun(0).VerifyHistoryInvariants(context.Background(), VerificationSampling{Count: 2})
+	require.NoError(t, err)
+	require.Equal(t, 2, checked)
+	require.Len(t, fake.Calls("GetWorkflowHistory"), 2)
+}
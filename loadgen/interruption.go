@@ -0,0 +1,81 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrRunDeadline is the cause of canceling iterations of a duration-limited run still running
+// after the grace period.
+var ErrRunDeadline = errors.New("run deadline reached")
+
+// InterruptionCause is what interrupted an iteration still running when its run ended.
+type InterruptionCause string
+
+const (
+	// InterruptedByDeadline is the interruption of iterations by the end of a duration-limited run
+	// or a deadline of the run's context.
+	InterruptedByDeadline InterruptionCause = "deadline"
+	// InterruptedByCancel is the interruption of iterations by canceling the run, e.g. on SIGINT.
+	InterruptedByCancel InterruptionCause = "cancel"
+)
+
+// InterruptionSummary counts iterations interrupted when their run ended, by cause.
+type InterruptionSummary struct {
+	Deadline int `json:"deadline"`
+	Cancel   int `json:"cancel"`
+}
+
+func (s *InterruptionSummary) add(cause InterruptionCause, iterations int) {
+	switch cause {
+	case InterruptedByDeadline:
+		s.Deadline += iterations
+	case InterruptedByCancel:
+		s.Cancel += iterations
+	}
+}
+
+// tagInterrupted attributes the abandoned iterations of the result to the cause of interrupting
+// them, if any.
+func (r *RunResult) tagInterrupted(cause InterruptionCause) {
+	if cause == "" || r.IterationsAbandoned == 0 {
+		return
+	}
+	r.IterationsInterrupted = &InterruptionSummary{}
+	r.IterationsInterrupted.add(cause, r.IterationsAbandoned)
+}
+
+// InterruptionCauseOf returns what interrupted the iterations running under the context, or "" if
+// it is not done. Iterations whose context ended by ErrRunDeadline or a deadline were interrupted
+// by the deadline, otherwise by a cancel.
+func InterruptionCauseOf(ctx context.Context) InterruptionCause {
+	if ctx.Err() == nil {
+		return ""
+	}
+	cause := context.Cause(ctx)
+	if errors.Is(cause, ErrRunDeadline) || errors.Is(cause, context.DeadlineExceeded) {
+		return InterruptedByDeadline
+	}
+	return InterruptedByCancel
+}
+
+// IterationInterruptedError is the outcome of an iteration interrupted when its run ended, ending
+// its trace in place of whatever error the interruption caused.
+type IterationInterruptedError struct {
+	Iteration int
+	Cause     InterruptionCause
+	// Error the iteration returned, typically a context error.
+	Err error
+}
+
+func (e *IterationInterruptedError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("iteration %v interrupted by %v", e.Iteration, e.Cause)
+	}
+	return fmt.Sprintf("iteration %v interrupted by %v: %v", e.Iteration, e.Cause, e.Err)
+}
+
+func (e *IterationInterruptedError) Unwrap() error {
+	return e.Err
+}
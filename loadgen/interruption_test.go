@@ -0,0 +1,113 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// interruptionTracer collects the errors iterations end with.
+type interruptionTracer struct {
+	lock sync.Mutex
+	errs []error
+}
+
+func (t *interruptionTracer) StartIteration(ctx context.Context, _ map[string]string) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		t.errs = append(t.errs, err)
+	}
+}
+
+// interrupted returns the causes of the interrupted iterations traced.
+func (t *interruptionTracer) interrupted() []InterruptionCause {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var causes []InterruptionCause
+	for _, err := range t.errs {
+		var interrupted *IterationInterruptedError
+		if errors.As(err, &interrupted) {
+			causes = append(causes, interrupted.Cause)
+		}
+	}
+	return causes
+}
+
+// runInterrupted runs a scenario whose iterations block until interrupted, two at a time.
+func runInterrupted(t *testing.T, ctx context.Context, config RunConfiguration) (RunResult, *interruptionTracer) {
+	var buf bytes.Buffer
+	tracer := &interruptionTracer{}
+	config.MaxConcurrent = 2
+	info := NewTestScenarioInfo(&FakeClient{}, config)
+	info.ReportSinks = []ReportSink{&WriterReportSink{Writer: &buf}}
+	info.Tracer = tracer
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	require.NoError(t, executor.Run(ctx, info))
+	var result RunResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	// Ended traces of the interrupted iterations follow their cancellation
+	require.Eventually(t, func() bool { return len(tracer.interrupted()) == 2 }, time.Second, 5*time.Millisecond)
+	return result, tracer
+}
+
+func TestRunInterruptedByDeadline(t *testing.T) {
+	result, tracer := runInterrupted(t, context.Background(), RunConfiguration{
+		Duration:    50 * time.Millisecond,
+		GracePeriod: 20 * time.Millisecond,
+	})
+	require.Equal(t, 2, result.IterationsAbandoned)
+	require.Zero(t, result.IterationsFailed)
+	require.Equal(t, &InterruptionSummary{Deadline: 2}, result.IterationsInterrupted)
+	require.Equal(t, []InterruptionCause{InterruptedByDeadline, InterruptedByDeadline}, tracer.interrupted())
+}
+
+func TestRunInterruptedByCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(50*time.Millisecond, cancel)
+	result, tracer := runInterrupted(t, ctx, RunConfiguration{Duration: time.Hour})
+	require.Equal(t, 2, result.IterationsAbandoned)
+	require.Zero(t, result.IterationsFailed)
+	require.Equal(t, &InterruptionSummary{Cancel: 2}, result.IterationsInterrupted)
+	require.Equal(t, []InterruptionCause{InterruptedByCancel, InterruptedByCancel}, tracer.interrupted())
+}
+
+func TestRunNotInterrupted(t *testing.T) {
+	var buf bytes.Buffer
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 3})
+	info.ReportSinks = []ReportSink{&WriterReportSink{Writer: &buf}}
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error { return nil }}
+	require.NoError(t, executor.Run(context.Background(), info))
+	var result RunResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Nil(t, result.IterationsInterrupted)
+}
+
+func TestInterruptionCauseOf(t *testing.T) {
+	require.Equal(t, InterruptionCause(""), InterruptionCauseOf(context.Background()))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrRunDeadline)
+	require.Equal(t, InterruptedByDeadline, InterruptionCauseOf(ctx))
+
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), 0)
+	defer cancelTimeout()
+	<-ctx.Done()
+	require.Equal(t, InterruptedByDeadline, InterruptionCauseOf(ctx))
+
+	ctx, cancel = context.WithCancelCause(context.Background())
+	cancel(nil)
+	require.Equal(t, InterruptedByCancel, InterruptionCauseOf(ctx))
+}
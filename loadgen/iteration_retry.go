@@ -0,0 +1,74 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.temporal.io/sdk/client"
+)
+
+// RegexErrorPatternPrefix marks a pattern of RunConfiguration.RetryableErrors as a regular
+// expression rather than a substring.
+const RegexErrorPatternPrefix = "regex:"
+
+// errorPatterns match error messages against RunConfiguration.RetryableErrors.
+type errorPatterns []func(string) bool
+
+// compileErrorPatterns compiles the patterns, failing on an invalid regular expression.
+func compileErrorPatterns(patterns []string) (errorPatterns, error) {
+	matchers := make(errorPatterns, 0, len(patterns))
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, RegexErrorPatternPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retryable error pattern %q: %w", pattern, err)
+			}
+			matchers = append(matchers, re.MatchString)
+		} else {
+			pattern := pattern
+			matchers = append(matchers, func(message string) bool { return strings.Contains(message, pattern) })
+		}
+	}
+	return matchers, nil
+}
+
+// matches returns whether the error's message matches any pattern.
+func (e errorPatterns) matches(err error) bool {
+	message := err.Error()
+	for _, match := range e {
+		if match(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// execute runs the iteration, retrying it up to IterationRetries times while it fails with an error
+// matching RetryableErrors. Retries reuse the same Run with the next Attempt, so they start
+// workflows with IDs of their own. An iteration whose workflow ID fails to render fails with the
+// *WorkflowIDTemplateError.
+func (g *genericRun) execute(ctx context.Context, run *Run) error {
+	return g.retry(ctx, run, func() error { return g.executor.Execute(ctx, run) })
+}
+
+// start starts the iteration's workflow with GenericExecutor.Start, retrying like execute.
+func (g *genericRun) start(ctx context.Context, run *Run) (handle client.WorkflowRun, err error) {
+	err = g.retry(ctx, run, func() error {
+		handle, err = g.executor.Start(ctx, run)
+		return err
+	})
+	return handle, err
+}
+
+func (g *genericRun) retry(ctx context.Context, run *Run, attempt func() error) error {
+	for n := 1; ; n++ {
+		err := catchWorkflowIDTemplateError(attempt)
+		if err == nil || ctx.Err() != nil || n > g.config.IterationRetries || !g.retryableErrors.matches(err) {
+			return err
+		}
+		g.logger.Warnf("Retrying iteration %v after attempt %v failed with retryable error: %v", run.Iteration, n, err)
+		run.Attempt = n + 1
+	}
+}
@@ -0,0 +1,83 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+func TestRetryableErrorPatterns(t *testing.T) {
+	patterns, err := compileErrorPatterns([]string{"resource exhausted", "regex:^shard \\d+ busy$"})
+	require.NoError(t, err)
+	require.True(t, patterns.matches(errors.New("start failed: resource exhausted")))
+	require.True(t, patterns.matches(errors.New("shard 12 busy")))
+	require.False(t, patterns.matches(errors.New("shard twelve busy")))
+	require.False(t, patterns.matches(errors.New("workflow failed")))
+
+	executor := &GenericExecutor{DefaultConfiguration: RunConfiguration{RetryableErrors: []string{"regex:("}}}
+	_, err = executor.newRun(ScenarioInfo{MetricsHandler: client.MetricsNopHandler})
+	require.ErrorContains(t, err, `invalid scenario: invalid retryable error pattern "regex:("`)
+}
+
+func TestRunRetriesMatchingErrors(t *testing.T) {
+	var lock sync.Mutex
+	attempts := map[int]int{}
+	var firstIterationIDs []string
+	err := execute(&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			defer lock.Unlock()
+			attempts[run.Iteration]++
+			require.Equal(t, attempts[run.Iteration], run.Attempt)
+			if run.Iteration == 1 {
+				firstIterationIDs = append(firstIterationIDs, run.DefaultStartWorkflowOptions().ID)
+			}
+			// Every iteration fails twice before succeeding
+			if attempts[run.Iteration] <= 2 {
+				return errors.New("update rejected: shard busy")
+			}
+			return nil
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 3, RetryableErrors: []string{"shard busy"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{1: 3, 2: 3, 3: 3}, attempts)
+	// Retries start workflows of their own, under the run's workflow ID prefix
+	require.Equal(t, []string{"w--1", "w--1-attempt-2", "w--1-attempt-3"}, firstIterationIDs)
+}
+
+func TestRunFailsOnNonMatchingErrors(t *testing.T) {
+	var lock sync.Mutex
+	attempts := 0
+	err := execute(&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			defer lock.Unlock()
+			attempts++
+			return errors.New("workflow failed")
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 1, RetryableErrors: []string{"shard busy"}},
+	})
+	require.ErrorContains(t, err, "iteration 1 failed: workflow failed")
+	require.Equal(t, 1, attempts)
+}
+
+func TestRunRetriesUpToIterationRetries(t *testing.T) {
+	var lock sync.Mutex
+	attempts := 0
+	err := execute(&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			lock.Lock()
+			defer lock.Unlock()
+			attempts++
+			return errors.New("shard busy")
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 1, IterationRetries: 2, RetryableErrors: []string{"shard busy"}},
+	})
+	require.ErrorContains(t, err, "iteration 1 failed: shard busy")
+	require.Equal(t, 3, attempts)
+}
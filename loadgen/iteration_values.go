@@ -0,0 +1,80 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+
+	"go.temporal.io/sdk/client"
+)
+
+// IterationValues are values derived once for an iteration and shared by the builders of its
+// workflow's start options and arguments, so that both see the same task queue shard, correlation
+// ID or payload instead of each computing its own.
+type IterationValues struct {
+	// Task queue of the iteration's workflow. Default is the run's task queue.
+	TaskQueue string
+	// ID correlating the iteration's workflow with its input. Default is "<RunID>-<Iteration>".
+	CorrelationID string
+	// Payload of the workflow input, if any.
+	Payload []byte
+	// Further scenario-defined values.
+	Extra map[string]interface{}
+}
+
+// IterationBuilder builds the start options and arguments of an iteration's workflow from
+// IterationValues derived once per iteration.
+type IterationBuilder struct {
+	// Derives the iteration's values, modifying the defaults. Optional.
+	Derive func(run *Run, values *IterationValues) error
+	// Builds the start options. Default is Run.StartWorkflowOptions on the values' task queue.
+	Options func(run *Run, values *IterationValues) (client.StartWorkflowOptions, error)
+	// Builds the workflow arguments. Default is none.
+	Args func(run *Run, values *IterationValues) ([]interface{}, error)
+}
+
+// Build derives the iteration's values, then builds its workflow's start options and arguments
+// from them.
+func (b *IterationBuilder) Build(run *Run) (*IterationValues, client.StartWorkflowOptions, []interface{}, error) {
+	values := &IterationValues{
+		TaskQueue:     run.TaskQueue(),
+		CorrelationID: fmt.Sprintf("%s-%d", run.RunID, run.Iteration),
+	}
+	if b.Derive != nil {
+		if err := b.Derive(run, values); err != nil {
+			return nil, client.StartWorkflowOptions{}, nil, fmt.Errorf("failed deriving iteration values: %w", err)
+		}
+	}
+	var options client.StartWorkflowOptions
+	if b.Options != nil {
+		var err error
+		if options, err = b.Options(run, values); err != nil {
+			return nil, client.StartWorkflowOptions{}, nil, fmt.Errorf("failed building start options: %w", err)
+		}
+	} else {
+		options = run.StartWorkflowOptions()
+		options.TaskQueue = values.TaskQueue
+	}
+	var args []interface{}
+	if b.Args != nil {
+		var err error
+		if args, err = b.Args(run, values); err != nil {
+			return nil, client.StartWorkflowOptions{}, nil, fmt.Errorf("failed building workflow arguments: %w", err)
+		}
+	}
+	return values, options, args, nil
+}
+
+// ExecuteBuiltWorkflow builds the iteration's workflow with the builder and executes it like
+// ExecuteAnyWorkflow.
+func (r *Run) ExecuteBuiltWorkflow(
+	ctx context.Context,
+	builder *IterationBuilder,
+	workflow interface{},
+	valuePtr interface{},
+) error {
+	_, options, args, err := builder.Build(r)
+	if err != nil {
+		return err
+	}
+	return r.ExecuteAnyWorkflow(ctx, options, workflow, valuePtr, args...)
+}
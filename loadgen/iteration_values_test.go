@@ -0,0 +1,63 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+func TestIterationBuilderSharesValues(t *testing.T) {
+	var derived int
+	var optionsValues, argsValues []*IterationValues
+	builder := &IterationBuilder{
+		Derive: func(run *Run, values *IterationValues) error {
+			derived++
+			values.TaskQueue = fmt.Sprintf("%v-%v", run.TaskQueue(), derived%2)
+			values.Payload = []byte(fmt.Sprint("payload-", derived))
+			return nil
+		},
+		Options: func(run *Run, values *IterationValues) (client.StartWorkflowOptions, error) {
+			optionsValues = append(optionsValues, values)
+			options := run.StartWorkflowOptions()
+			options.TaskQueue = values.TaskQueue
+			options.Memo = map[string]interface{}{"correlation": values.CorrelationID}
+			return options, nil
+		},
+		Args: func(run *Run, values *IterationValues) ([]interface{}, error) {
+			argsValues = append(argsValues, values)
+			return []interface{}{values.CorrelationID, values.Payload}, nil
+		},
+	}
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	for iteration := 1; iteration <= 2; iteration++ {
+		require.NoError(t, info.NewRun(iteration).ExecuteBuiltWorkflow(context.Background(), builder, "wf", nil))
+	}
+
+	require.Equal(t, 2, derived)
+	require.Len(t, optionsValues, 2)
+	for i, values := range optionsValues {
+		require.Same(t, values, argsValues[i])
+	}
+	calls := fake.Calls("ExecuteWorkflow")
+	require.Len(t, calls, 2)
+	for i, call := range calls {
+		correlationID := fmt.Sprintf("test-run-%d", i+1)
+		require.Equal(t, fmt.Sprintf("test:test-run-%d", (i+1)%2), call.Options.TaskQueue)
+		require.Equal(t, correlationID, call.Options.Memo["correlation"])
+		require.Equal(t, []interface{}{correlationID, []byte(fmt.Sprint("payload-", i+1))}, call.Args)
+	}
+}
+
+func TestIterationBuilderDefaults(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	values, options, args, err := (&IterationBuilder{}).Build(info.NewRun(3))
+	require.NoError(t, err)
+	require.Equal(t, "test:test-run", values.TaskQueue)
+	require.Equal(t, "test-run-3", values.CorrelationID)
+	require.Equal(t, info.NewRun(3).StartWorkflowOptions(), options)
+	require.Empty(t, args)
+}
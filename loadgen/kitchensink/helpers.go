@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"go.temporal.io/api/common/v1"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
 	"go.temporal.io/sdk/workflow"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"time"
 )
 
@@ -37,6 +39,236 @@ func NoOpSingleActivityActionSet() *ActionSet {
 	}
 }
 
+// TimersActionSet returns an action set that runs the given number of timers of the given
+// duration concurrently.
+func TimersActionSet(timers int, duration time.Duration) *ActionSet {
+	actionSet := &ActionSet{Concurrent: true}
+	for i := 0; i < timers; i++ {
+		actionSet.Actions = append(actionSet.Actions, &Action{
+			Variant: &Action_Timer{
+				Timer: &TimerAction{Milliseconds: uint64(duration.Milliseconds())},
+			},
+		})
+	}
+	return actionSet
+}
+
+// EmptyResultActionSet returns an action set that completes the workflow with an empty result.
+func EmptyResultActionSet() *ActionSet {
+	return &ActionSet{
+		Actions: []*Action{
+			{
+				Variant: &Action_ReturnResult{
+					ReturnResult: &ReturnResultAction{
+						ReturnThis: &common.Payload{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// HeartbeatActivityType is the type of the generic activity of HeartbeatActivityActionSet. Only the
+// Go worker implements it.
+const HeartbeatActivityType = "heartbeat"
+
+// HeartbeatActivityInput is the argument of the heartbeat activity, which heartbeats every Interval
+// for Duration, then completes.
+type HeartbeatActivityInput struct {
+	Interval time.Duration `json:"interval"`
+	Duration time.Duration `json:"duration"`
+}
+
+// HeartbeatActivityActionSet returns an action set that runs the heartbeat activity with the given
+// heartbeat timeout and no retries, so that heartbeat timeouts fail the workflow, then completes the
+// workflow with an empty result. The SDK sends heartbeats to the server at most every 80% of the
+// heartbeat timeout.
+func HeartbeatActivityActionSet(input HeartbeatActivityInput, heartbeatTimeout time.Duration) *ActionSet {
+	arg, err := converter.GetDefaultDataConverter().ToPayload(input)
+	if err != nil {
+		panic(fmt.Errorf("failed encoding heartbeat activity input: %w", err))
+	}
+	actionSet := EmptyResultActionSet()
+	actionSet.Actions = append([]*Action{{
+		Variant: &Action_ExecActivity{
+			ExecActivity: &ExecuteActivityAction{
+				ActivityType: &ExecuteActivityAction_Generic{
+					Generic: &ExecuteActivityAction_GenericActivity{
+						Type:      HeartbeatActivityType,
+						Arguments: []*common.Payload{arg},
+					},
+				},
+				StartToCloseTimeout: durationpb.New(input.Duration + time.Minute),
+				HeartbeatTimeout:    durationpb.New(heartbeatTimeout),
+				RetryPolicy:         &common.RetryPolicy{MaximumAttempts: 1},
+			},
+		},
+	}}, actionSet.Actions...)
+	return actionSet
+}
+
+// FailingActivityType is the type of the generic activity of FailingActivityActionSet. Only the Go
+// worker implements it.
+const FailingActivityType = "fail_attempts"
+
+// FailingActivityInput is the argument of the failing activity, which fails its first Failures
+// attempts with a retryable error, then completes.
+type FailingActivityInput struct {
+	Failures int `json:"failures"`
+}
+
+// FailingActivityActionSet returns an action set that runs the failing activity, retried every
+// retry interval without backoff for up to one more attempt than its failures, then completes the
+// workflow with an empty result.
+func FailingActivityActionSet(failures int, retryInterval time.Duration) *ActionSet {
+	arg, err := converter.GetDefaultDataConverter().ToPayload(FailingActivityInput{Failures: failures})
+	if err != nil {
+		panic(fmt.Errorf("failed encoding failing activity input: %w", err))
+	}
+	actionSet := EmptyResultActionSet()
+	actionSet.Actions = append([]*Action{{
+		Variant: &Action_ExecActivity{
+			ExecActivity: &ExecuteActivityAction{
+				ActivityType: &ExecuteActivityAction_Generic{
+					Generic: &ExecuteActivityAction_GenericActivity{
+						Type:      FailingActivityType,
+						Arguments: []*common.Payload{arg},
+					},
+				},
+				StartToCloseTimeout: durationpb.New(time.Minute),
+				RetryPolicy: &common.RetryPolicy{
+					InitialInterval:    &retryInterval,
+					BackoffCoefficient: 1,
+					MaximumAttempts:    int32(failures + 1),
+				},
+			},
+		},
+	}}, actionSet.Actions...)
+	return actionSet
+}
+
+// OrderedSignalName is a signal carrying a sequence number as an int. The Go worker's kitchen sink
+// workflow appends the numbers of the ordered signals it receives, in the order received, to its
+// state under OrderedSignalsKey, comma-separated.
+const (
+	OrderedSignalName = "ordered_signal"
+	OrderedSignalsKey = "ordered_signals"
+)
+
+// FanInSignalName is the signal children of FanInWorkflowInput send their FanInResult to the parent
+// with. The Go worker's kitchen sink workflow counts the distinct children it received results from
+// in its state under FanInReceivedKey.
+const (
+	FanInSignalName  = "fan_in_result"
+	FanInReceivedKey = "fan_in_received"
+)
+
+// FanInResult is the result a child of FanInWorkflowInput signals to its parent.
+type FanInResult struct {
+	Child  int    `json:"child"`
+	Result []byte `json:"result"`
+}
+
+// FanInWorkflowInput returns the input of a parent kitchen sink workflow with the given ID that
+// starts the given number of children, each signaling a FanInResult of the given size back to the
+// parent, then waits for the results of all children and completes with an empty result. Only the
+// Go worker implements the signal.
+func FanInWorkflowInput(parentID string, children, resultSize int) (*WorkflowInput, error) {
+	childActions := &ActionSet{Concurrent: true}
+	for i := 0; i < children; i++ {
+		result, err := converter.GetDefaultDataConverter().ToPayload(
+			FanInResult{Child: i, Result: make([]byte, resultSize)})
+		if err != nil {
+			return nil, fmt.Errorf("failed encoding fan-in result: %w", err)
+		}
+		childInput, err := converter.GetDefaultDataConverter().ToPayload(&WorkflowInput{
+			InitialActions: []*ActionSet{{
+				Actions: []*Action{
+					{
+						Variant: &Action_SendSignal{
+							SendSignal: &SendSignalAction{
+								WorkflowId: parentID,
+								SignalName: FanInSignalName,
+								Args:       []*common.Payload{result},
+							},
+						},
+					},
+					{Variant: &Action_ReturnResult{ReturnResult: &ReturnResultAction{ReturnThis: &common.Payload{}}}},
+				},
+			}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed encoding child input: %w", err)
+		}
+		childActions.Actions = append(childActions.Actions, &Action{
+			Variant: &Action_ExecChildWorkflow{
+				ExecChildWorkflow: &ExecuteChildWorkflowAction{
+					WorkflowId:   fmt.Sprintf("%v-child-%v", parentID, i),
+					WorkflowType: "kitchenSink",
+					Input:        []*common.Payload{childInput},
+				},
+			},
+		})
+	}
+	awaitResults := EmptyResultActionSet()
+	awaitResults.Actions = append([]*Action{{
+		Variant: &Action_AwaitWorkflowState{
+			AwaitWorkflowState: &AwaitWorkflowState{Key: FanInReceivedKey, Value: fmt.Sprint(children)},
+		},
+	}}, awaitResults.Actions...)
+	return &WorkflowInput{InitialActions: []*ActionSet{childActions, awaitResults}}, nil
+}
+
+// parentCloseBlockKey is a workflow state key no action sets, awaited by the workflows of
+// ParentCloseWorkflowInput to run until closed.
+const parentCloseBlockKey = "parent_close_never_set"
+
+// ParentCloseWorkflowInput returns the input of a parent kitchen sink workflow that starts the given
+// number of children under the given parent close policy, without waiting for them, then runs until
+// closed from outside, e.g. terminated. The children also run until closed.
+func ParentCloseWorkflowInput(children int, policy ParentClosePolicy) (*WorkflowInput, error) {
+	block := &Action{
+		Variant: &Action_AwaitWorkflowState{AwaitWorkflowState: &AwaitWorkflowState{Key: parentCloseBlockKey, Value: "set"}},
+	}
+	childInput, err := converter.GetDefaultDataConverter().ToPayload(&WorkflowInput{
+		InitialActions: []*ActionSet{{Actions: []*Action{block}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding child input: %w", err)
+	}
+	childActions := &ActionSet{Concurrent: true}
+	for i := 0; i < children; i++ {
+		childActions.Actions = append(childActions.Actions, &Action{
+			Variant: &Action_ExecChildWorkflow{
+				ExecChildWorkflow: &ExecuteChildWorkflowAction{
+					WorkflowType:      "kitchenSink",
+					Input:             []*common.Payload{childInput},
+					ParentClosePolicy: policy,
+					AwaitableChoice:   &AwaitableChoice{Condition: &AwaitableChoice_Abandon{Abandon: &emptypb.Empty{}}},
+				},
+			},
+		})
+	}
+	return &WorkflowInput{InitialActions: []*ActionSet{childActions, {Actions: []*Action{block}}}}, nil
+}
+
+// DecodeFanInResult decodes the FanInResult of a fan-in signal, which is encoded in turn as a
+// payload if it was passed on as one by the worker.
+func DecodeFanInResult(payload *common.Payload) (FanInResult, error) {
+	var result FanInResult
+	if string(payload.GetMetadata()[converter.MetadataEncoding]) == converter.MetadataEncodingProtoJSON {
+		var inner common.Payload
+		if err := converter.GetDefaultDataConverter().FromPayload(payload, &inner); err != nil {
+			return result, fmt.Errorf("failed decoding fan-in result payload: %w", err)
+		}
+		payload = &inner
+	}
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &result); err != nil {
+		return result, fmt.Errorf("failed decoding fan-in result: %w", err)
+	}
+	return result, nil
+}
+
 type ClientActionsExecutor struct {
 	Client     client.Client
 	WorkflowID string
@@ -0,0 +1,18 @@
+package kitchensink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimersActionSet(t *testing.T) {
+	actionSet := TimersActionSet(3, 1500*time.Millisecond)
+	require.True(t, actionSet.Concurrent)
+	require.Len(t, actionSet.Actions, 3)
+	for _, action := range actionSet.Actions {
+		require.Equal(t, uint64(1500), action.GetTimer().GetMilliseconds())
+	}
+	require.Empty(t, TimersActionSet(0, time.Second).Actions)
+}
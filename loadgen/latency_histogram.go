@@ -0,0 +1,210 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// latencyHistogramBounds are the default bucket upper bounds of a LatencyHistogram, growing by a
+// factor of 2^(1/8) (about 9%) from 100µs to over an hour, which bounds the relative error of
+// percentiles computed from the histogram.
+var latencyHistogramBounds = func() []time.Duration {
+	var bounds []time.Duration
+	for bound := float64(100 * time.Microsecond); bound < float64(2*time.Hour); bound *= math.Pow(2, 1.0/8) {
+		bounds = append(bounds, time.Duration(bound))
+	}
+	return bounds
+}()
+
+// LatencyHistogram counts latencies in buckets so that the latencies of several runs, e.g. of
+// distributed omes instances, can be combined and still yield correct percentiles. It serializes
+// to JSON as its non-empty buckets, see MarshalJSON.
+type LatencyHistogram struct {
+	// Inclusive upper bounds of the buckets, ascending. Serialized histograms only have the bounds
+	// of their non-empty buckets.
+	Bounds []time.Duration
+	// Count of latencies per bucket, with one more than Bounds for latencies over the last bound.
+	Counts []int64
+	Sum    time.Duration
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// NewLatencyHistogram creates a histogram of the samples with the default bucket bounds.
+func NewLatencyHistogram(samples []time.Duration) *LatencyHistogram {
+	h := &LatencyHistogram{
+		Bounds: latencyHistogramBounds,
+		Counts: make([]int64, len(latencyHistogramBounds)+1),
+	}
+	for _, sample := range samples {
+		h.Record(sample)
+	}
+	return h
+}
+
+// Record adds a latency to the histogram.
+func (h *LatencyHistogram) Record(latency time.Duration) {
+	if h.Count() == 0 || latency < h.Min {
+		h.Min = latency
+	}
+	if latency > h.Max {
+		h.Max = latency
+	}
+	h.Sum += latency
+	h.Counts[sort.Search(len(h.Bounds), func(i int) bool { return latency <= h.Bounds[i] })]++
+}
+
+// Count returns the number of latencies recorded.
+func (h *LatencyHistogram) Count() int64 {
+	var count int64
+	for _, c := range h.Counts {
+		count += c
+	}
+	return count
+}
+
+// Merge adds the latencies of the other histogram. Buckets with the same bound are summed and the
+// others kept, so histograms with different bounds can be merged, at the precision of the coarser.
+func (h *LatencyHistogram) Merge(other *LatencyHistogram) {
+	if other.Count() == 0 {
+		return
+	}
+	if h.Count() == 0 || other.Min < h.Min {
+		h.Min = other.Min
+	}
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+	h.Sum += other.Sum
+	bounds := make([]time.Duration, 0, len(h.Bounds)+len(other.Bounds))
+	counts := make([]int64, 0, len(h.Counts)+len(other.Counts))
+	i, j := 0, 0
+	for i < len(h.Bounds) || j < len(other.Bounds) {
+		switch {
+		case j == len(other.Bounds) || (i < len(h.Bounds) && h.Bounds[i] < other.Bounds[j]):
+			bounds, counts = append(bounds, h.Bounds[i]), append(counts, h.Counts[i])
+			i++
+		case i == len(h.Bounds) || other.Bounds[j] < h.Bounds[i]:
+			bounds, counts = append(bounds, other.Bounds[j]), append(counts, other.Counts[j])
+			j++
+		default:
+			bounds, counts = append(bounds, h.Bounds[i]), append(counts, h.Counts[i]+other.Counts[j])
+			i++
+			j++
+		}
+	}
+	// Latencies over the last bound
+	counts = append(counts, h.Counts[len(h.Bounds)]+other.Counts[len(other.Bounds)])
+	h.Bounds, h.Counts = bounds, counts
+}
+
+// MergeLatencyHistograms returns a new histogram of the latencies of all the histograms, see
+// LatencyHistogram.Merge.
+func MergeLatencyHistograms(histograms ...*LatencyHistogram) *LatencyHistogram {
+	merged := NewLatencyHistogram(nil)
+	for _, histogram := range histograms {
+		merged.Merge(histogram)
+	}
+	return merged
+}
+
+// Percentile returns the upper bound of the bucket containing the latency at the given percentile
+// (between 0 and 1), limited to the recorded minimum and maximum. Ranks match NewLatencySummary.
+func (h *LatencyHistogram) Percentile(p float64) time.Duration {
+	count := h.Count()
+	if count == 0 {
+		return 0
+	}
+	rank := int64(p * float64(count-1))
+	var seen int64
+	for i, c := range h.Counts {
+		seen += c
+		if seen > rank {
+			if i == len(h.Bounds) || h.Bounds[i] > h.Max {
+				return h.Max
+			} else if h.Bounds[i] < h.Min {
+				return h.Min
+			}
+			return h.Bounds[i]
+		}
+	}
+	return h.Max
+}
+
+// Summary returns the summary statistics of the histogram, with percentiles approximated to
+// bucket bounds.
+func (h *LatencyHistogram) Summary() LatencySummary {
+	count := h.Count()
+	if count == 0 {
+		return LatencySummary{}
+	}
+	return LatencySummary{
+		Min:  h.Min,
+		Mean: h.Sum / time.Duration(count),
+		P50:  h.Percentile(0.5),
+		P90:  h.Percentile(0.9),
+		P99:  h.Percentile(0.99),
+		Max:  h.Max,
+	}
+}
+
+// latencyHistogramJSON is the serialized form of a LatencyHistogram. Only non-empty buckets are
+// included, each with its upper bound except for latencies over the last bound.
+type latencyHistogramJSON struct {
+	Buckets []latencyBucketJSON `json:"buckets"`
+	Sum     time.Duration       `json:"sum"`
+	Min     time.Duration       `json:"min"`
+	Max     time.Duration       `json:"max"`
+}
+
+type latencyBucketJSON struct {
+	UpperBound *time.Duration `json:"le,omitempty"`
+	Count      int64          `json:"count"`
+}
+
+// MarshalJSON serializes the histogram's non-empty buckets.
+func (h *LatencyHistogram) MarshalJSON() ([]byte, error) {
+	serialized := latencyHistogramJSON{Buckets: []latencyBucketJSON{}, Sum: h.Sum, Min: h.Min, Max: h.Max}
+	for i, count := range h.Counts {
+		if count == 0 {
+			continue
+		}
+		bucket := latencyBucketJSON{Count: count}
+		if i < len(h.Bounds) {
+			bucket.UpperBound = &h.Bounds[i]
+		}
+		serialized.Buckets = append(serialized.Buckets, bucket)
+	}
+	return json.Marshal(serialized)
+}
+
+// UnmarshalJSON deserializes a histogram serialized by MarshalJSON. Its bounds are those of the
+// non-empty buckets, which is enough for percentiles and merging.
+func (h *LatencyHistogram) UnmarshalJSON(data []byte) error {
+	var serialized latencyHistogramJSON
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return err
+	}
+	*h = LatencyHistogram{Counts: []int64{0}, Sum: serialized.Sum, Min: serialized.Min, Max: serialized.Max}
+	for i, bucket := range serialized.Buckets {
+		if bucket.Count < 0 {
+			return fmt.Errorf("invalid latency histogram: negative count")
+		}
+		if bucket.UpperBound == nil {
+			if i != len(serialized.Buckets)-1 {
+				return fmt.Errorf("invalid latency histogram: bucket without bound must be last")
+			}
+			h.Counts[len(h.Bounds)] = bucket.Count
+			continue
+		}
+		if len(h.Bounds) > 0 && *bucket.UpperBound <= h.Bounds[len(h.Bounds)-1] {
+			return fmt.Errorf("invalid latency histogram: bucket bounds must ascend")
+		}
+		h.Bounds = append(h.Bounds, *bucket.UpperBound)
+		h.Counts = append(h.Counts[:len(h.Counts)-1], bucket.Count, 0)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomLatencies(seed int64, count int, scale time.Duration) []time.Duration {
+	random := rand.New(rand.NewSource(seed))
+	latencies := make([]time.Duration, count)
+	for i := range latencies {
+		latencies[i] = time.Duration(random.ExpFloat64() * float64(scale))
+	}
+	return latencies
+}
+
+func TestLatencyHistogramRoundTrip(t *testing.T) {
+	histogram := NewLatencyHistogram(append(randomLatencies(1, 1000, 50*time.Millisecond), 3*time.Hour))
+	data, err := json.Marshal(histogram)
+	require.NoError(t, err)
+	var serialized latencyHistogramJSON
+	require.NoError(t, json.Unmarshal(data, &serialized))
+	// Only non-empty buckets, the last one over all bounds
+	require.Less(t, len(serialized.Buckets), len(latencyHistogramBounds))
+	require.Nil(t, serialized.Buckets[len(serialized.Buckets)-1].UpperBound)
+
+	var decoded LatencyHistogram
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, histogram.Count(), decoded.Count())
+	require.Equal(t, histogram.Summary(), decoded.Summary())
+	for _, p := range []float64{0, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999, 1} {
+		require.Equal(t, histogram.Percentile(p), decoded.Percentile(p))
+	}
+
+	var empty LatencyHistogram
+	data, err = json.Marshal(NewLatencyHistogram(nil))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"buckets": [], "sum": 0, "min": 0, "max": 0}`, string(data))
+	require.NoError(t, json.Unmarshal(data, &empty))
+	require.Equal(t, LatencySummary{}, empty.Summary())
+}
+
+func TestLatencyHistogramInvalidJSON(t *testing.T) {
+	var histogram LatencyHistogram
+	require.ErrorContains(t, json.Unmarshal([]byte(`{"buckets": [{"le": 2}, {"le": 1}]}`), &histogram), "must ascend")
+	require.ErrorContains(t, json.Unmarshal([]byte(`{"buckets": [{"count": 1}, {"le": 1}]}`), &histogram), "must be last")
+	require.ErrorContains(t, json.Unmarshal([]byte(`{"buckets": [{"le": 1, "count": -1}]}`), &histogram), "negative")
+}
+
+func TestMergeLatencyHistograms(t *testing.T) {
+	fast := randomLatencies(1, 2000, 10*time.Millisecond)
+	slow := randomLatencies(2, 1000, time.Second)
+	all := NewLatencyHistogram(append(append([]time.Duration{}, fast...), slow...))
+
+	// Merging deserialized histograms, which have different sparse bounds, matches recording all
+	var decoded []*LatencyHistogram
+	for _, samples := range [][]time.Duration{fast, slow} {
+		data, err := json.Marshal(NewLatencyHistogram(samples))
+		require.NoError(t, err)
+		var histogram LatencyHistogram
+		require.NoError(t, json.Unmarshal(data, &histogram))
+		decoded = append(decoded, &histogram)
+	}
+	merged := MergeLatencyHistograms(decoded...)
+	require.Equal(t, int64(3000), merged.Count())
+	require.Equal(t, all.Summary(), merged.Summary())
+
+	// Percentiles are within a bucket's width of the exact ones
+	exact := NewLatencySummary(append(fast, slow...))
+	require.InEpsilon(t, exact.P50, merged.Summary().P50, 0.1)
+	require.InEpsilon(t, exact.P90, merged.Summary().P90, 0.1)
+	require.InEpsilon(t, exact.P99, merged.Summary().P99, 0.1)
+}
+
+func TestMergeLatencyHistogramsWithDifferentBounds(t *testing.T) {
+	coarse := &LatencyHistogram{
+		Bounds: []time.Duration{10 * time.Millisecond, 100 * time.Millisecond},
+		Counts: []int64{0, 0, 0},
+	}
+	coarse.Record(5 * time.Millisecond)
+	coarse.Record(50 * time.Millisecond)
+	coarse.Record(time.Second)
+	fine := NewLatencyHistogram([]time.Duration{10 * time.Millisecond, 20 * time.Millisecond})
+
+	merged := MergeLatencyHistograms(coarse, fine)
+	require.Equal(t, int64(5), merged.Count())
+	require.Equal(t, 5*time.Millisecond, merged.Min)
+	require.Equal(t, time.Second, merged.Max)
+	require.Equal(t, 1085*time.Millisecond, merged.Sum)
+	// 5ms in the coarse 10ms bucket, 10ms and 20ms in fine buckets, 50ms under 100ms, 1s over all
+	require.Equal(t, 10*time.Millisecond, merged.Percentile(0))
+	require.InEpsilon(t, 10*time.Millisecond, merged.Percentile(0.25), 0.1)
+	require.InEpsilon(t, 20*time.Millisecond, merged.Percentile(0.5), 0.1)
+	require.Equal(t, 100*time.Millisecond, merged.Percentile(0.75))
+	require.Equal(t, time.Second, merged.Percentile(1))
+}
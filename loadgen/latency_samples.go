@@ -0,0 +1,75 @@
+package loadgen
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencySampleFile streams one CSV row per completed iteration to a file as iterations complete,
+// so exporting raw samples does not keep them in memory. Columns are iteration, start time
+// (RFC 3339), latency in milliseconds and outcome (success or failure). The CSV is preceded by a
+// "# " comment line with the run metadata as JSON. It is safe for concurrent use.
+type latencySampleFile struct {
+	lock   sync.Mutex
+	file   *os.File
+	buf    *bufio.Writer
+	writer *csv.Writer
+	err    error
+}
+
+func createLatencySampleFile(path string, metadata RunMetadata) (*latencySampleFile, error) {
+	header, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding run metadata: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating latency samples file: %w", err)
+	}
+	buf := bufio.NewWriter(file)
+	s := &latencySampleFile{file: file, buf: buf, writer: csv.NewWriter(buf)}
+	if _, s.err = fmt.Fprintf(buf, "# %s\n", header); s.err == nil {
+		s.err = s.writer.Write([]string{"iteration", "start_time", "latency_ms", "outcome"})
+	}
+	return s, nil
+}
+
+func (s *latencySampleFile) record(iteration int, startTime time.Time, latency time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err == nil {
+		s.err = s.writer.Write([]string{
+			strconv.Itoa(iteration), startTime.Format(time.RFC3339Nano), formatMillis(latency), outcome,
+		})
+	}
+}
+
+// Close flushes and closes the file, returning the first error that occurred while writing.
+func (s *latencySampleFile) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.writer.Flush()
+	if s.err == nil {
+		s.err = s.writer.Error()
+	}
+	if err := s.buf.Flush(); s.err == nil {
+		s.err = err
+	}
+	if err := s.file.Close(); s.err == nil {
+		s.err = err
+	}
+	if s.err != nil {
+		return fmt.Errorf("failed writing latency samples: %w", s.err)
+	}
+	return nil
+}
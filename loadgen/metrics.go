@@ -0,0 +1,34 @@
+package loadgen
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+// metricsHandlerWithTags returns the scenario metrics handler tagged with the scenario name and
+// the given tags.
+func (s *ScenarioInfo) metricsHandlerWithTags(tags map[string]string) client.MetricsHandler {
+	merged := make(map[string]string, len(tags)+1)
+	merged["scenario"] = s.ScenarioName
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return s.MetricsHandler.WithTags(merged)
+}
+
+// RecordCounter increments the named counter, tagged with the scenario name and the given tags.
+func (s *ScenarioInfo) RecordCounter(name string, tags map[string]string, incr int64) {
+	s.metricsHandlerWithTags(tags).Counter(name).Inc(incr)
+}
+
+// RecordGauge sets the named gauge, tagged with the scenario name and the given tags.
+func (s *ScenarioInfo) RecordGauge(name string, tags map[string]string, value float64) {
+	s.metricsHandlerWithTags(tags).Gauge(name).Update(value)
+}
+
+// RecordTimer records a duration on the named timer, tagged with the scenario name and the given
+// tags.
+func (s *ScenarioInfo) RecordTimer(name string, tags map[string]string, d time.Duration) {
+	s.metricsHandlerWithTags(tags).Timer(name).Record(d)
+}
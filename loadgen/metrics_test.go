@@ -0,0 +1,83 @@
+package loadgen
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+type recordedMetric struct {
+	kind  string
+	name  string
+	tags  map[string]string
+	value float64
+}
+
+// recordingMetricsHandler records every metric update with the tags of the handler it was made on.
+type recordingMetricsHandler struct {
+	tags     map[string]string
+	lock     *sync.Mutex
+	recorded *[]recordedMetric
+}
+
+func newRecordingMetricsHandler() *recordingMetricsHandler {
+	return &recordingMetricsHandler{lock: &sync.Mutex{}, recorded: &[]recordedMetric{}}
+}
+
+func (h *recordingMetricsHandler) WithTags(tags map[string]string) client.MetricsHandler {
+	merged := make(map[string]string, len(h.tags)+len(tags))
+	for k, v := range h.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return &recordingMetricsHandler{tags: merged, lock: h.lock, recorded: h.recorded}
+}
+
+func (h *recordingMetricsHandler) record(kind, name string, value float64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	*h.recorded = append(*h.recorded, recordedMetric{kind: kind, name: name, tags: h.tags, value: value})
+}
+
+type counterFunc func(int64)
+
+func (f counterFunc) Inc(incr int64) { f(incr) }
+
+type gaugeFunc func(float64)
+
+func (f gaugeFunc) Update(v float64) { f(v) }
+
+type timerFunc func(time.Duration)
+
+func (f timerFunc) Record(d time.Duration) { f(d) }
+
+func (h *recordingMetricsHandler) Counter(name string) client.MetricsCounter {
+	return counterFunc(func(incr int64) { h.record("counter", name, float64(incr)) })
+}
+
+func (h *recordingMetricsHandler) Gauge(name string) client.MetricsGauge {
+	return gaugeFunc(func(v float64) { h.record("gauge", name, v) })
+}
+
+func (h *recordingMetricsHandler) Timer(name string) client.MetricsTimer {
+	return timerFunc(func(d time.Duration) { h.record("timer", name, d.Seconds()) })
+}
+
+func TestRecordCustomMetrics(t *testing.T) {
+	handler := newRecordingMetricsHandler()
+	info := &ScenarioInfo{ScenarioName: "metrics_test", MetricsHandler: handler}
+	info.RecordCounter("sla_hits", map[string]string{"tier": "gold"}, 2)
+	info.RecordGauge("queue_depth", nil, 7)
+	info.RecordTimer("checkout_latency", map[string]string{"scenario": "override"}, 3*time.Second)
+
+	require.Equal(t, []recordedMetric{
+		{kind: "counter", name: "sla_hits", tags: map[string]string{"scenario": "metrics_test", "tier": "gold"}, value: 2},
+		{kind: "gauge", name: "queue_depth", tags: map[string]string{"scenario": "metrics_test"}, value: 7},
+		{kind: "timer", name: "checkout_latency", tags: map[string]string{"scenario": "override"}, value: 3},
+	}, *handler.recorded)
+}
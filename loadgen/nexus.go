@@ -0,0 +1,69 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NexusClient invokes operations of a Nexus endpoint, see HTTPNexusClient.
+type NexusClient interface {
+	// StartOperation starts an operation. If it completes synchronously, its result is put in
+	// resultPtr (if not nil) and the returned operation ID is empty. Otherwise the ID of the
+	// asynchronous operation is returned.
+	StartOperation(ctx context.Context, service, operation string, input interface{}, resultPtr interface{}) (string, error)
+	// GetOperationResult waits for an asynchronous operation to complete, putting its result in
+	// resultPtr if not nil.
+	GetOperationResult(ctx context.Context, service, operation, operationID string, resultPtr interface{}) error
+}
+
+// NexusOperationError is returned by a NexusClient when an operation completes unsuccessfully.
+type NexusOperationError struct {
+	// State the operation ended in, "failed" or "canceled".
+	State   string
+	Message string
+}
+
+func (e *NexusOperationError) Error() string {
+	return fmt.Sprintf("nexus operation %v: %v", e.State, e.Message)
+}
+
+// ExecuteNexusOperation invokes the operation through the run's Nexus client and waits for its
+// result, putting it in resultPtr if not nil. The time until the result or failure is available is
+// returned and recorded in the omes_nexus_operation_latency timer, tagged with whether the operation
+// completed synchronously and whether it succeeded.
+func (r *Run) ExecuteNexusOperation(
+	ctx context.Context,
+	service, operation string,
+	input interface{},
+	resultPtr interface{},
+) (time.Duration, error) {
+	if r.NexusClient == nil {
+		return 0, errors.New("no Nexus client configured")
+	}
+	start := time.Now()
+	mode := "sync"
+	record := func(err error) time.Duration {
+		elapsed := time.Since(start)
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		r.RecordTimer("omes_nexus_operation_latency", map[string]string{"mode": mode, "outcome": outcome}, elapsed)
+		return elapsed
+	}
+	operationID, err := r.NexusClient.StartOperation(ctx, service, operation, input, resultPtr)
+	if err != nil {
+		return record(err), fmt.Errorf("failed starting Nexus operation %v/%v: %w", service, operation, err)
+	}
+	if operationID != "" {
+		mode = "async"
+		err = r.NexusClient.GetOperationResult(ctx, service, operation, operationID, resultPtr)
+		if err != nil {
+			return record(err), fmt.Errorf("nexus operation %v/%v (%v) failed: %w",
+				service, operation, operationID, err)
+		}
+	}
+	return record(nil), nil
+}
@@ -0,0 +1,161 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// nexusResultWait is how long a result request of HTTPNexusClient waits for an asynchronous
+// operation to complete before it is retried.
+var nexusResultWait = 10 * time.Second
+
+// HTTPNexusClient is a NexusClient speaking the Nexus HTTP protocol to an endpoint. Inputs and
+// results are JSON encoded. It is safe for concurrent use.
+type HTTPNexusClient struct {
+	// URL the endpoint serves its services under, operations are at <BaseURL>/<service>/<operation>.
+	BaseURL string
+	// Client to send requests with, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// Headers added to every request, e.g. for authorization.
+	Header http.Header
+}
+
+// nexusFailure is the body of unsuccessful Nexus responses.
+type nexusFailure struct {
+	Message string `json:"message"`
+}
+
+func (c *HTTPNexusClient) StartOperation(
+	ctx context.Context,
+	service, operation string,
+	input interface{},
+	resultPtr interface{},
+) (string, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed encoding Nexus operation input: %w", err)
+	}
+	requestID := make([]byte, 16)
+	if _, err := rand.Read(requestID); err != nil {
+		return "", err
+	}
+	resp, respBody, err := c.do(ctx, http.MethodPost, []string{service, operation}, nil, body,
+		http.Header{"Nexus-Request-Id": {hex.EncodeToString(requestID)}})
+	if err != nil {
+		return "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return "", decodeNexusResult(respBody, resultPtr)
+	case http.StatusCreated:
+		var info struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(respBody, &info); err != nil || info.ID == "" {
+			return "", fmt.Errorf("invalid Nexus operation info %q", respBody)
+		}
+		return info.ID, nil
+	}
+	return "", nexusResponseError(resp, respBody)
+}
+
+func (c *HTTPNexusClient) GetOperationResult(
+	ctx context.Context,
+	service, operation, operationID string,
+	resultPtr interface{},
+) error {
+	query := url.Values{"wait": {fmt.Sprintf("%dms", nexusResultWait.Milliseconds())}}
+	for {
+		resp, respBody, err := c.do(ctx, http.MethodGet, []string{service, operation, operationID, "result"}, query,
+			nil, nil)
+		if err != nil {
+			return err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return decodeNexusResult(respBody, resultPtr)
+		case http.StatusRequestTimeout, http.StatusPreconditionFailed:
+			// Still running after the wait
+			continue
+		}
+		return nexusResponseError(resp, respBody)
+	}
+}
+
+func (c *HTTPNexusClient) do(
+	ctx context.Context,
+	method string,
+	path []string,
+	query url.Values,
+	body []byte,
+	header http.Header,
+) (*http.Response, []byte, error) {
+	escaped := make([]string, len(path))
+	for i, segment := range path {
+		escaped[i] = url.PathEscape(segment)
+	}
+	u, err := url.JoinPath(c.BaseURL, escaped...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid Nexus endpoint URL %v: %w", c.BaseURL, err)
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, h := range []http.Header{c.Header, header} {
+		for name, values := range h {
+			req.Header[name] = values
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed calling Nexus endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed reading Nexus response: %w", err)
+	}
+	return resp, respBody, nil
+}
+
+func decodeNexusResult(body []byte, resultPtr interface{}) error {
+	if resultPtr == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, resultPtr); err != nil {
+		return fmt.Errorf("failed decoding Nexus operation result: %w", err)
+	}
+	return nil
+}
+
+// nexusResponseError returns the NexusOperationError of an operation that completed unsuccessfully,
+// or the handler error of another unexpected response.
+func nexusResponseError(resp *http.Response, body []byte) error {
+	var failure nexusFailure
+	if json.Unmarshal(body, &failure) != nil || failure.Message == "" {
+		failure.Message = string(body)
+	}
+	if resp.StatusCode == http.StatusFailedDependency {
+		return &NexusOperationError{State: resp.Header.Get("Nexus-Operation-State"), Message: failure.Message}
+	}
+	return fmt.Errorf("nexus handler error %v: %v", resp.Status, failure.Message)
+}
@@ -0,0 +1,100 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// nexusTestServer serves the operations of service "svc" like a Nexus endpoint under /services:
+// "echo" completes synchronously with its input, "fail" fails, "missing" does not exist, and others
+// are asynchronous with the ID "op/<input>", running on every other result request, then completing
+// with "done" or, for "async-cancel", canceled.
+func nexusTestServer(t *testing.T) *httptest.Server {
+	var resultRequests int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		path := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/services/svc/"), "/")
+		fail := func(status int, state, message string) {
+			if state != "" {
+				w.Header().Set("Nexus-Operation-State", state)
+			}
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+		}
+		if r.Method == http.MethodPost {
+			require.NotEmpty(t, r.Header.Get("Nexus-Request-Id"))
+			var input string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+			switch path[0] {
+			case "echo":
+				_ = json.NewEncoder(w).Encode(input)
+			case "fail":
+				fail(http.StatusFailedDependency, "failed", "handler failed")
+			case "missing":
+				fail(http.StatusNotFound, "", "no such operation")
+			default:
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(map[string]string{"id": "op/" + input, "state": "running"})
+			}
+			return
+		}
+		require.Len(t, path, 3)
+		require.True(t, strings.HasPrefix(path[1], "op%2F"))
+		require.Equal(t, "result", path[2])
+		require.Equal(t, "50ms", r.URL.Query().Get("wait"))
+		if atomic.AddInt32(&resultRequests, 1)%2 == 1 {
+			w.WriteHeader(http.StatusRequestTimeout)
+		} else if path[0] == "async-cancel" {
+			fail(http.StatusFailedDependency, "canceled", "canceled by handler")
+		} else {
+			_ = json.NewEncoder(w).Encode("done")
+		}
+	}))
+}
+
+func TestHTTPNexusClient(t *testing.T) {
+	prev := nexusResultWait
+	nexusResultWait = 50 * time.Millisecond
+	t.Cleanup(func() { nexusResultWait = prev })
+	server := nexusTestServer(t)
+	defer server.Close()
+	client := &HTTPNexusClient{
+		BaseURL: server.URL + "/services",
+		Header:  http.Header{"Authorization": {"Bearer token"}},
+	}
+	ctx := context.Background()
+
+	var result string
+	id, err := client.StartOperation(ctx, "svc", "echo", "a", &result)
+	require.NoError(t, err)
+	require.Empty(t, id)
+	require.Equal(t, "a", result)
+
+	var opErr *NexusOperationError
+	_, err = client.StartOperation(ctx, "svc", "fail", "a", &result)
+	require.ErrorAs(t, err, &opErr)
+	require.Equal(t, &NexusOperationError{State: "failed", Message: "handler failed"}, opErr)
+	_, err = client.StartOperation(ctx, "svc", "missing", "a", &result)
+	require.EqualError(t, err, "nexus handler error 404 Not Found: no such operation")
+
+	// Asynchronous operations are polled until complete
+	id, err = client.StartOperation(ctx, "svc", "async", "b", &result)
+	require.NoError(t, err)
+	require.Equal(t, "op/b", id)
+	require.NoError(t, client.GetOperationResult(ctx, "svc", "async", id, &result))
+	require.Equal(t, "done", result)
+
+	id, err = client.StartOperation(ctx, "svc", "async-cancel", "c", &result)
+	require.NoError(t, err)
+	err = client.GetOperationResult(ctx, "svc", "async-cancel", id, &result)
+	require.ErrorAs(t, err, &opErr)
+	require.Equal(t, "canceled", opErr.State)
+}
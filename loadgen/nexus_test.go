@@ -0,0 +1,98 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNexusClient completes operations named "sync" synchronously, fails those named "fail" and
+// completes the rest asynchronously after asyncDelay.
+type fakeNexusClient struct {
+	asyncDelay time.Duration
+	results    []string
+}
+
+func (f *fakeNexusClient) StartOperation(
+	ctx context.Context, service, operation string, input interface{}, resultPtr interface{},
+) (string, error) {
+	switch operation {
+	case "sync":
+		*resultPtr.(*string) = "sync:" + input.(string)
+		return "", nil
+	case "fail":
+		return "", &NexusOperationError{State: "failed", Message: "handler error"}
+	}
+	f.results = append(f.results, "async:"+input.(string))
+	return "op-" + input.(string), nil
+}
+
+func (f *fakeNexusClient) GetOperationResult(
+	ctx context.Context, service, operation, operationID string, resultPtr interface{},
+) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(f.asyncDelay):
+	}
+	if operation == "async-fail" {
+		return &NexusOperationError{State: "canceled", Message: "canceled by handler"}
+	}
+	*resultPtr.(*string) = f.results[0]
+	return nil
+}
+
+func TestExecuteNexusOperation(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	nexus := &fakeNexusClient{asyncDelay: 20 * time.Millisecond}
+	info.NexusClient = nexus
+	run := info.NewRun(1)
+
+	var result string
+	_, err := run.ExecuteNexusOperation(context.Background(), "svc", "sync", "a", &result)
+	require.NoError(t, err)
+	require.Equal(t, "sync:a", result)
+
+	elapsed, err := run.ExecuteNexusOperation(context.Background(), "svc", "async", "b", &result)
+	require.NoError(t, err)
+	require.Equal(t, "async:b", result)
+	require.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+}
+
+func TestExecuteNexusOperationFailure(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	handler := newRecordingMetricsHandler()
+	info.MetricsHandler = handler
+	info.NexusClient = &fakeNexusClient{}
+	run := info.NewRun(1)
+
+	var result string
+	var opErr *NexusOperationError
+	_, err := run.ExecuteNexusOperation(context.Background(), "svc", "fail", "a", &result)
+	require.ErrorAs(t, err, &opErr)
+	require.Equal(t, "failed", opErr.State)
+
+	_, err = run.ExecuteNexusOperation(context.Background(), "svc", "async-fail", "b", &result)
+	require.ErrorAs(t, err, &opErr)
+	require.Equal(t, "canceled", opErr.State)
+	require.ErrorContains(t, err, "op-b")
+
+	// Failures are recorded too
+	var tags []map[string]string
+	for _, metric := range *handler.recorded {
+		require.Equal(t, "omes_nexus_operation_latency", metric.name)
+		tags = append(tags, metric.tags)
+	}
+	require.Equal(t, []map[string]string{
+		{"scenario": "test", "mode": "sync", "outcome": "failure"},
+		{"scenario": "test", "mode": "async", "outcome": "failure"},
+	}, tags)
+
+	info.NexusClient = nil
+	_, err = info.NewRun(2).ExecuteNexusOperation(context.Background(), "svc", "sync", "c", &result)
+	require.Error(t, err)
+	require.False(t, errors.As(err, &opErr))
+}
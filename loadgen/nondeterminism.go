@@ -0,0 +1,71 @@
+package loadgen
+
+import (
+	"errors"
+	"strings"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+)
+
+// nonDeterminismMarkers are substrings of the non-determinism error messages of the SDKs, e.g. Go's
+// "[TMPRL1100] nondeterministic workflow definition" and Core-based SDKs' "Nondeterminism error".
+var nonDeterminismMarkers = []string{"tmprl1100", "nondeterminism", "nondeterministic", "non-deterministic"}
+
+// maxNonDeterministicWorkflowIDs bounds the workflow IDs listed in a NonDeterminismSummary.
+const maxNonDeterministicWorkflowIDs = 100
+
+// NonDeterminismSummary counts iterations that failed with a workflow non-determinism error, see
+// DetectNonDeterminism.
+type NonDeterminismSummary struct {
+	Iterations int `json:"iterations"`
+	// IDs of the offending workflows where known, up to the first 100.
+	WorkflowIDs []string `json:"workflowIds,omitempty"`
+}
+
+// DetectNonDeterminism reports whether the error is caused by workflow non-determinism, recognized
+// by the cause or message of a WorkflowTaskFailureError, or by the markers SDKs put in
+// non-determinism failure messages in an application or panic failure at the root of the error.
+// Messages of errors wrapping these are not considered. The offending workflow ID is returned if the error carries it.
+func DetectNonDeterminism(err error) (workflowID string, detected bool) {
+	if err == nil {
+		return "", false
+	}
+	var taskErr *WorkflowTaskFailureError
+	if errors.As(err, &taskErr) {
+		if taskErr.Cause == enums.WORKFLOW_TASK_FAILED_CAUSE_NON_DETERMINISTIC_ERROR ||
+			hasNonDeterminismMarker(taskErr.Message) {
+			return taskErr.WorkflowID, true
+		}
+	}
+	// The failure a workflow failed with, e.g. on a non-determinism panic with the Go SDK's
+	// FailWorkflow panic policy, is the root cause of the error
+	root := err
+	for errors.Unwrap(root) != nil {
+		root = errors.Unwrap(root)
+	}
+	var appErr *temporal.ApplicationError
+	var panicErr *temporal.PanicError
+	if errors.As(root, &appErr) || errors.As(root, &panicErr) {
+		return "", hasNonDeterminismMarker(root.Error())
+	}
+	return "", false
+}
+
+func hasNonDeterminismMarker(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range nonDeterminismMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// record counts a non-determinism failure of the given workflow, if known.
+func (s *NonDeterminismSummary) record(workflowID string) {
+	s.Iterations++
+	if workflowID != "" && len(s.WorkflowIDs) < maxNonDeterministicWorkflowIDs {
+		s.WorkflowIDs = append(s.WorkflowIDs, workflowID)
+	}
+}
@@ -0,0 +1,135 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/failure/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+// executeWithTaskFailure executes a workflow that fails after the given workflow task failure.
+func executeWithTaskFailure(t *testing.T, cause enums.WorkflowTaskFailedCause, message string) error {
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: options.ID, Err: errors.New("workflow timed out")}, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return []*history.HistoryEvent{{
+				EventType: enums.EVENT_TYPE_WORKFLOW_TASK_FAILED,
+				Attributes: &history.HistoryEvent_WorkflowTaskFailedEventAttributes{
+					WorkflowTaskFailedEventAttributes: &history.WorkflowTaskFailedEventAttributes{
+						Cause:   cause,
+						Failure: &failure.Failure{Message: message},
+					},
+				},
+			}}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(7)
+	err := run.ExecuteAnyWorkflow(context.Background(), run.DefaultStartWorkflowOptions(), "wf", nil)
+	require.Error(t, err)
+	return err
+}
+
+func TestDetectNonDeterminism(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cause    enums.WorkflowTaskFailedCause
+		message  string
+		detected bool
+	}{
+		{
+			name:     "cause",
+			cause:    enums.WORKFLOW_TASK_FAILED_CAUSE_NON_DETERMINISTIC_ERROR,
+			message:  "activity type mismatch",
+			detected: true,
+		},
+		{
+			name:     "go-sdk-marker",
+			cause:    enums.WORKFLOW_TASK_FAILED_CAUSE_WORKFLOW_WORKER_UNHANDLED_FAILURE,
+			message:  "[TMPRL1100] nondeterministic workflow definition: history event is ActivityTaskScheduled",
+			detected: true,
+		},
+		{
+			name:     "core-sdk-marker",
+			cause:    enums.WORKFLOW_TASK_FAILED_CAUSE_WORKFLOW_WORKER_UNHANDLED_FAILURE,
+			message:  "Nondeterminism error: Timer machine does not handle this event",
+			detected: true,
+		},
+		{
+			name:    "panic",
+			cause:   enums.WORKFLOW_TASK_FAILED_CAUSE_WORKFLOW_WORKER_UNHANDLED_FAILURE,
+			message: "panic: boom",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := executeWithTaskFailure(t, tc.cause, tc.message)
+			workflowID, detected := DetectNonDeterminism(fmt.Errorf("iteration 7 failed: %w", err))
+			require.Equal(t, tc.detected, detected)
+			if tc.detected {
+				require.Equal(t, "w-test-run-7", workflowID)
+			}
+		})
+	}
+
+	// Markers are also recognized in the failure at the root of errors without a workflow task
+	// failure, but not in the messages of other errors
+	workflowID, detected := DetectNonDeterminism(fmt.Errorf("workflow failed: %w",
+		temporal.NewApplicationError("Nondeterminism error", "")))
+	require.True(t, detected)
+	require.Empty(t, workflowID)
+	_, detected = DetectNonDeterminism(errors.New("workflow failed: Nondeterminism error"))
+	require.False(t, detected)
+	_, detected = DetectNonDeterminism(fmt.Errorf("nondeterministic-looking wrapper: %w",
+		temporal.NewApplicationError("boom", "")))
+	require.False(t, detected)
+	_, detected = DetectNonDeterminism(nil)
+	require.False(t, detected)
+}
+
+func TestRunStatsNonDeterminism(t *testing.T) {
+	var stats runStats
+	ndeErr := executeWithTaskFailure(t, enums.WORKFLOW_TASK_FAILED_CAUSE_NON_DETERMINISTIC_ERROR, "mismatch")
+	stats.recordStart(0)
+	stats.recordStart(0)
+	stats.recordStart(0)
+	stats.recordEnd(0, time.Second, ndeErr)
+	stats.recordEnd(0, time.Second, errors.New("other failure"))
+	stats.recordEnd(0, time.Second, nil)
+
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	result := stats.result(&info, time.Now(), time.Now())
+	require.Equal(t, 2, result.IterationsFailed)
+	require.Equal(t, &NonDeterminismSummary{Iterations: 1, WorkflowIDs: []string{"w-test-run-7"}}, result.NonDeterminism)
+
+	merged, err := MergeRunResults(result, result)
+	require.NoError(t, err)
+	require.Equal(t, &NonDeterminismSummary{
+		Iterations:  2,
+		WorkflowIDs: []string{"w-test-run-7", "w-test-run-7"},
+	}, merged.NonDeterminism)
+}
+
+func TestRunFailsWithNonDeterminism(t *testing.T) {
+	ndeErr := executeWithTaskFailure(t, enums.WORKFLOW_TASK_FAILED_CAUSE_NON_DETERMINISTIC_ERROR, "mismatch")
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			return ndeErr
+		},
+	}
+	err := executor.Run(context.Background(), NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 1}))
+	require.ErrorContains(t, err, "run finished with non-determinism error")
+	var taskErr *WorkflowTaskFailureError
+	require.True(t, errors.As(err, &taskErr))
+	require.Equal(t, "w-test-run-7", taskErr.WorkflowID)
+}
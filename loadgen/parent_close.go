@@ -0,0 +1,170 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+)
+
+// parentClosePollInterval is the interval between checks of the parent's history and the children's
+// status by Run.ExecuteParentCloseWorkflow.
+var parentClosePollInterval = 100 * time.Millisecond
+
+// parentCloseAbandonCheckDelay is how long abandoned children must keep running after their parent
+// closed for Run.ExecuteParentCloseWorkflow to consider them abandoned.
+var parentCloseAbandonCheckDelay = time.Second
+
+// ErrParentClosePolicyViolated is returned (wrapped) by Run.ExecuteParentCloseWorkflow when children
+// do not reach the state expected from their parent close policy.
+var ErrParentClosePolicyViolated = errors.New("children did not reach the state of their parent close policy")
+
+// expectedChildStatus returns the status children reach once their parent closes under the policy.
+func expectedChildStatus(policy kitchensink.ParentClosePolicy) (enums.WorkflowExecutionStatus, error) {
+	switch policy {
+	case kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_TERMINATE:
+		return enums.WORKFLOW_EXECUTION_STATUS_TERMINATED, nil
+	case kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_REQUEST_CANCEL:
+		return enums.WORKFLOW_EXECUTION_STATUS_CANCELED, nil
+	case kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_ABANDON:
+		return enums.WORKFLOW_EXECUTION_STATUS_RUNNING, nil
+	default:
+		return 0, fmt.Errorf("unsupported parent close policy %v", policy)
+	}
+}
+
+// ExecuteParentCloseWorkflow starts a kitchen sink workflow starting the given number of children
+// under the given parent close policy (see kitchensink.ParentCloseWorkflowInput), waits for the
+// children to start, terminates the parent, then verifies the children are terminated, canceled or,
+// for ABANDON, still running a second later, in which case they are terminated afterwards. The
+// timeout bounds both the wait for the children to start and to reach their state, after which an
+// error wrapping ErrParentClosePolicyViolated is returned. The time from the parent's termination
+// until all children are terminated or canceled is recorded in the omes_parent_close_latency timer.
+func (r *Run) ExecuteParentCloseWorkflow(
+	ctx context.Context,
+	children int,
+	policy kitchensink.ParentClosePolicy,
+	timeout time.Duration,
+) error {
+	expected, err := expectedChildStatus(policy)
+	if err != nil {
+		return err
+	}
+	input, err := kitchensink.ParentCloseWorkflowInput(children, policy)
+	if err != nil {
+		return err
+	}
+	execution, err := r.Client.ExecuteWorkflow(ctx, r.StartWorkflowOptions(), "kitchenSink", input)
+	if err != nil {
+		return fmt.Errorf("failed to start parent workflow: %w", err)
+	}
+	childExecutions, err := r.awaitChildrenStarted(ctx, execution.GetID(), execution.GetRunID(), children, timeout)
+	if err != nil {
+		return err
+	}
+	err = r.Client.TerminateWorkflow(ctx, execution.GetID(), execution.GetRunID(), "omes parent close test")
+	if err != nil {
+		return fmt.Errorf("failed to terminate parent workflow %v: %w", execution.GetID(), err)
+	}
+	closed := time.Now()
+
+	if expected == enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(parentCloseAbandonCheckDelay):
+		}
+		verifyErr := r.verifyChildrenStatus(ctx, childExecutions, policy, expected, 0)
+		for _, child := range childExecutions {
+			if err := r.Client.TerminateWorkflow(ctx, child.WorkflowId, child.RunId, "omes parent close test"); err != nil {
+				r.Logger.Warnf("Failed to terminate abandoned child %v: %v", child.WorkflowId, err)
+			}
+		}
+		return verifyErr
+	}
+	if err := r.verifyChildrenStatus(ctx, childExecutions, policy, expected, timeout); err != nil {
+		return err
+	}
+	r.RecordTimer("omes_parent_close_latency", nil, time.Since(closed))
+	return nil
+}
+
+// awaitChildrenStarted polls the history of the parent until it has the given number of started
+// children, and returns them.
+func (r *Run) awaitChildrenStarted(
+	ctx context.Context,
+	workflowID, runID string,
+	children int,
+	timeout time.Duration,
+) ([]*common.WorkflowExecution, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		var started []*common.WorkflowExecution
+		iter := r.Client.GetWorkflowHistory(ctx, workflowID, runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+		for iter.HasNext() {
+			event, err := iter.Next()
+			if err != nil {
+				return nil, fmt.Errorf("failed reading history of workflow %v: %w", workflowID, err)
+			}
+			if attrs := event.GetChildWorkflowExecutionStartedEventAttributes(); attrs != nil {
+				started = append(started, attrs.WorkflowExecution)
+			}
+		}
+		if len(started) >= children {
+			return started, nil
+		} else if time.Now().After(deadline) {
+			return nil, fmt.Errorf("only %v of %v children of workflow %v started after %v",
+				len(started), children, workflowID, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(parentClosePollInterval):
+		}
+	}
+}
+
+// verifyChildrenStatus waits up to the timeout for every child to have the expected status. A zero
+// timeout checks once.
+func (r *Run) verifyChildrenStatus(
+	ctx context.Context,
+	childExecutions []*common.WorkflowExecution,
+	policy kitchensink.ParentClosePolicy,
+	expected enums.WorkflowExecutionStatus,
+	timeout time.Duration,
+) error {
+	deadline := time.Now().Add(timeout)
+	pending := childExecutions
+	for {
+		var stillPending []*common.WorkflowExecution
+		var firstStatus enums.WorkflowExecutionStatus
+		for _, child := range pending {
+			resp, err := r.Client.DescribeWorkflowExecution(ctx, child.WorkflowId, child.RunId)
+			if err != nil {
+				return fmt.Errorf("failed to describe child workflow %v: %w", child.WorkflowId, err)
+			}
+			if status := resp.GetWorkflowExecutionInfo().GetStatus(); status != expected {
+				if len(stillPending) == 0 {
+					firstStatus = status
+				}
+				stillPending = append(stillPending, child)
+			}
+		}
+		if len(stillPending) == 0 {
+			return nil
+		} else if !time.Now().Before(deadline) {
+			return fmt.Errorf("%w: %v of %v children not %v under %v, e.g. %v is %v", ErrParentClosePolicyViolated,
+				len(stillPending), len(childExecutions), expected, policy, stillPending[0].WorkflowId, firstStatus)
+		}
+		pending = stillPending
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(parentClosePollInterval):
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+func useFastParentCloseChecks(t *testing.T) {
+	prevPoll, prevDelay := parentClosePollInterval, parentCloseAbandonCheckDelay
+	parentClosePollInterval, parentCloseAbandonCheckDelay = time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { parentClosePollInterval, parentCloseAbandonCheckDelay = prevPoll, prevDelay })
+}
+
+// parentCloseClient returns a client whose parent workflows have the given number of started
+// children, which reach the given status once their parent is terminated.
+func parentCloseClient(children int, afterParentClose enums.WorkflowExecutionStatus) *FakeClient {
+	fake := &FakeClient{}
+	fake.OnGetWorkflowHistory = func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+		var events []*history.HistoryEvent
+		for i := 0; i < children; i++ {
+			events = append(events, &history.HistoryEvent{
+				EventType: enums.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_STARTED,
+				Attributes: &history.HistoryEvent_ChildWorkflowExecutionStartedEventAttributes{
+					ChildWorkflowExecutionStartedEventAttributes: &history.ChildWorkflowExecutionStartedEventAttributes{
+						WorkflowExecution: &common.WorkflowExecution{
+							WorkflowId: fmt.Sprintf("%v-child-%v", workflowID, i), RunId: "run",
+						},
+					},
+				},
+			})
+		}
+		return events, nil
+	}
+	fake.OnDescribeWorkflowExecution = func(ctx context.Context, workflowID, runID string) (
+		*workflowservice.DescribeWorkflowExecutionResponse, error) {
+		status := enums.WORKFLOW_EXECUTION_STATUS_RUNNING
+		for _, call := range fake.Calls("TerminateWorkflow") {
+			if call.WorkflowID == workflowID {
+				status = enums.WORKFLOW_EXECUTION_STATUS_TERMINATED
+				break
+			}
+			// The parent was terminated
+			status = afterParentClose
+		}
+		return &workflowservice.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: &workflow.WorkflowExecutionInfo{Status: status},
+		}, nil
+	}
+	return fake
+}
+
+func TestExecuteParentCloseWorkflow(t *testing.T) {
+	useFastParentCloseChecks(t)
+	for policy, childStatus := range map[kitchensink.ParentClosePolicy]enums.WorkflowExecutionStatus{
+		kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_TERMINATE:      enums.WORKFLOW_EXECUTION_STATUS_TERMINATED,
+		kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_REQUEST_CANCEL: enums.WORKFLOW_EXECUTION_STATUS_CANCELED,
+		kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_ABANDON:        enums.WORKFLOW_EXECUTION_STATUS_RUNNING,
+	} {
+		fake := parentCloseClient(3, childStatus)
+		info := NewTestScenarioInfo(fake, RunConfiguration{})
+		err := info.NewRun(1).ExecuteParentCloseWorkflow(context.Background(), 3, policy, time.Second)
+		require.NoError(t, err, policy.String())
+
+		input := fake.Calls("ExecuteWorkflow")[0].Args[0].(*kitchensink.WorkflowInput)
+		require.Len(t, input.InitialActions[0].Actions, 3)
+		require.Equal(t, policy, input.InitialActions[0].Actions[0].GetExecChildWorkflow().ParentClosePolicy)
+		terminated := []string{"w-test-run-1"}
+		if childStatus == enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+			// Abandoned children are cleaned up
+			terminated = append(terminated, "w-test-run-1-child-0", "w-test-run-1-child-1", "w-test-run-1-child-2")
+		}
+		var got []string
+		for _, call := range fake.Calls("TerminateWorkflow") {
+			got = append(got, call.WorkflowID)
+		}
+		require.Equal(t, terminated, got, policy.String())
+	}
+}
+
+func TestExecuteParentCloseWorkflowViolation(t *testing.T) {
+	useFastParentCloseChecks(t)
+	// Children keep running although they should have been terminated
+	fake := parentCloseClient(2, enums.WORKFLOW_EXECUTION_STATUS_RUNNING)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	err := info.NewRun(1).ExecuteParentCloseWorkflow(context.Background(), 2,
+		kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_TERMINATE, 20*time.Millisecond)
+	require.ErrorIs(t, err, ErrParentClosePolicyViolated)
+	require.ErrorContains(t, err, "2 of 2 children not Terminated")
+
+	// Children are canceled although they should have been abandoned
+	fake = parentCloseClient(2, enums.WORKFLOW_EXECUTION_STATUS_CANCELED)
+	info = NewTestScenarioInfo(fake, RunConfiguration{})
+	err = info.NewRun(1).ExecuteParentCloseWorkflow(context.Background(), 2,
+		kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_ABANDON, time.Second)
+	require.ErrorIs(t, err, ErrParentClosePolicyViolated)
+}
+
+func TestExecuteParentCloseWorkflowChildrenNotStarted(t *testing.T) {
+	useFastParentCloseChecks(t)
+	fake := parentCloseClient(1, enums.WORKFLOW_EXECUTION_STATUS_TERMINATED)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	err := info.NewRun(1).ExecuteParentCloseWorkflow(context.Background(), 2,
+		kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_TERMINATE, 20*time.Millisecond)
+	require.ErrorContains(t, err, "only 1 of 2 children")
+	require.Empty(t, fake.Calls("TerminateWorkflow"))
+}
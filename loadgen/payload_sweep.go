@@ -0,0 +1,181 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/serviceerror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// payloadSizeLimitMarkers are substrings of the errors of starts rejected for their size, e.g. the
+// server's "Blob data size exceeds limit." and gRPC's "trying to send message larger than max".
+var payloadSizeLimitMarkers = []string{"size exceeds limit", "exceeds size limit", "message larger than max"}
+
+// IsPayloadSizeLimitError reports whether the error is caused by a request or payload exceeding a
+// size limit of the server or gRPC: an invalid argument or resource exhausted error, from the
+// server or a gRPC status, whose own message has a size limit marker. Other errors, e.g. a workflow
+// failing with a message that happens to match, are not.
+func IsPayloadSizeLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var invalidArgument *serviceerror.InvalidArgument
+	var resourceExhausted *serviceerror.ResourceExhausted
+	if errors.As(err, &invalidArgument) {
+		return hasPayloadSizeLimitMarker(invalidArgument.Message)
+	} else if errors.As(err, &resourceExhausted) {
+		return hasPayloadSizeLimitMarker(resourceExhausted.Message)
+	}
+	if s, ok := status.FromError(err); ok && (s.Code() == codes.ResourceExhausted || s.Code() == codes.InvalidArgument) {
+		return hasPayloadSizeLimitMarker(s.Message())
+	}
+	return false
+}
+
+func hasPayloadSizeLimitMarker(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range payloadSizeLimitMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// PayloadSizeSweep is an Executor finding the largest start argument the server accepts: each
+// iteration starts a workflow with a larger argument until a start fails with a size-limit error
+// (see IsPayloadSizeLimitError), then bisects between the largest accepted and smallest rejected
+// sizes. Other errors fail the sweep.
+type PayloadSizeSweep struct {
+	// Size in bytes of the argument of the first iteration. Default is 1 KiB.
+	InitialSize int
+	// Factor the size grows by after each accepted start. Default is 2.
+	GrowthFactor float64
+	// Largest size attempted. Default is 64 MiB.
+	MaxSize int
+	// Bisection iterations once a start is rejected. Default is 8.
+	RefineIterations int
+	// Starts a workflow with an argument of the given size. Default starts a kitchen sink workflow
+	// completing with a result of that size, without waiting for it.
+	Start func(ctx context.Context, run *Run, size int) error
+}
+
+// PayloadSizeSweepResult is the outcome of a PayloadSizeSweep.
+type PayloadSizeSweepResult struct {
+	// Largest size accepted, 0 if none was.
+	LargestSucceeded int
+	// Smallest size rejected, 0 if none was up to PayloadSizeSweep.MaxSize.
+	SmallestFailed int
+	Iterations     int
+}
+
+func (s *PayloadSizeSweep) applyDefaults() {
+	if s.InitialSize <= 0 {
+		s.InitialSize = 1024
+	}
+	if s.GrowthFactor <= 1 {
+		s.GrowthFactor = 2
+	}
+	if s.MaxSize <= 0 {
+		s.MaxSize = 64 * 1024 * 1024
+	}
+	if s.RefineIterations <= 0 {
+		s.RefineIterations = 8
+	}
+	if s.Start == nil {
+		s.Start = startKitchenSinkWithPayload
+	}
+}
+
+// Run implements Executor, logging the threshold found.
+func (s PayloadSizeSweep) Run(ctx context.Context, info ScenarioInfo) error {
+	result, err := s.Sweep(ctx, &info)
+	if err != nil {
+		return err
+	}
+	if result.SmallestFailed == 0 {
+		info.Logger.Infof("No start rejected for its size, largest size attempted: %v bytes", result.LargestSucceeded)
+	} else {
+		info.Logger.Infof("Largest start argument accepted: %v bytes, smallest rejected: %v bytes",
+			result.LargestSucceeded, result.SmallestFailed)
+	}
+	return nil
+}
+
+// Sweep runs the sweep, recording the largest accepted size in the
+// omes_payload_size_largest_succeeded gauge.
+func (s PayloadSizeSweep) Sweep(ctx context.Context, info *ScenarioInfo) (PayloadSizeSweepResult, error) {
+	s.applyDefaults()
+	var result PayloadSizeSweepResult
+	attempt := func(size int) (accepted bool, err error) {
+		result.Iterations++
+		run := info.NewRun(result.Iterations)
+		err = s.Start(ctx, run, size)
+		if IsPayloadSizeLimitError(err) {
+			run.Logger.Debugf("Start with %v byte argument rejected: %v", size, err)
+			if result.SmallestFailed == 0 || size < result.SmallestFailed {
+				result.SmallestFailed = size
+			}
+			return false, nil
+		} else if err != nil {
+			return false, fmt.Errorf("iteration %v with %v byte argument failed: %w", run.Iteration, size, err)
+		}
+		if size > result.LargestSucceeded {
+			result.LargestSucceeded = size
+		}
+		return true, nil
+	}
+
+	// Grow until a start is rejected
+	for size := s.InitialSize; ; {
+		accepted, err := attempt(size)
+		if err != nil {
+			return result, err
+		} else if !accepted || size == s.MaxSize {
+			break
+		}
+		next := int(float64(size) * s.GrowthFactor)
+		if next <= size {
+			next = size + 1
+		}
+		if next > s.MaxSize {
+			next = s.MaxSize
+		}
+		size = next
+	}
+	// Bisect between the largest accepted and smallest rejected sizes
+	for i := 0; i < s.RefineIterations && result.SmallestFailed > 0; i++ {
+		if result.SmallestFailed-result.LargestSucceeded <= 1 {
+			break
+		}
+		if _, err := attempt(result.LargestSucceeded + (result.SmallestFailed-result.LargestSucceeded)/2); err != nil {
+			return result, err
+		}
+	}
+	info.RecordGauge("omes_payload_size_largest_succeeded", nil, float64(result.LargestSucceeded))
+	return result, nil
+}
+
+// startKitchenSinkWithPayload starts a kitchen sink workflow whose input carries a result of the
+// given size.
+func startKitchenSinkWithPayload(ctx context.Context, run *Run, size int) error {
+	input := &kitchensink.WorkflowInput{
+		InitialActions: []*kitchensink.ActionSet{{
+			Actions: []*kitchensink.Action{{
+				Variant: &kitchensink.Action_ReturnResult{
+					ReturnResult: &kitchensink.ReturnResultAction{
+						ReturnThis: &common.Payload{Data: make([]byte, size)},
+					},
+				},
+			}},
+		}},
+	}
+	_, err := run.Client.ExecuteWorkflow(ctx, run.DefaultStartWorkflowOptions(), "kitchenSink", input)
+	return err
+}
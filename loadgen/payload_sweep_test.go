@@ -0,0 +1,96 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sizeLimitedClient returns a client rejecting kitchen sink starts whose result payload is larger
+// than the limit, and a function returning the sizes started.
+func sizeLimitedClient(limit int) (*FakeClient, func() []int) {
+	var sizes []int
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			input := args[0].(*kitchensink.WorkflowInput)
+			size := len(input.InitialActions[0].Actions[0].GetReturnResult().ReturnThis.Data)
+			sizes = append(sizes, size)
+			if size > limit {
+				return nil, serviceerror.NewInvalidArgument("Blob data size exceeds limit.")
+			}
+			return &FakeWorkflowRun{ID: options.ID}, nil
+		},
+	}
+	return fake, func() []int { return sizes }
+}
+
+func TestIsPayloadSizeLimitError(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected bool
+	}{
+		{serviceerror.NewInvalidArgument("Blob data size exceeds limit."), true},
+		{fmt.Errorf("failed to start: %w", serviceerror.NewInvalidArgument("Blob data size exceeds limit.")), true},
+		{status.Error(codes.ResourceExhausted, "grpc: trying to send message larger than max (5000000 vs. 4194304)"), true},
+		{serviceerror.NewInvalidArgument("WorkflowId is not set on request."), false},
+		{status.Error(codes.ResourceExhausted, "namespace rate limit exceeded"), false},
+		{errors.New("boom"), false},
+		{errors.New("activity failed: blob data size exceeds limit"), false},
+		{status.Error(codes.Unavailable, "message larger than max"), false},
+		{nil, false},
+	} {
+		require.Equal(t, tc.expected, IsPayloadSizeLimitError(tc.err), "%v", tc.err)
+	}
+}
+
+func TestPayloadSizeSweepFindsThreshold(t *testing.T) {
+	fake, sizes := sizeLimitedClient(5000)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	result, err := PayloadSizeSweep{InitialSize: 1000, RefineIterations: 20}.Sweep(context.Background(), &info)
+	require.NoError(t, err)
+	require.Equal(t, 5000, result.LargestSucceeded)
+	require.Equal(t, 5001, result.SmallestFailed)
+	require.Equal(t, []int{1000, 2000, 4000, 8000}, sizes()[:4])
+	require.Equal(t, len(sizes()), result.Iterations)
+}
+
+func TestPayloadSizeSweepBoundsRefinement(t *testing.T) {
+	fake, sizes := sizeLimitedClient(5000)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	result, err := PayloadSizeSweep{InitialSize: 1000, RefineIterations: 2}.Sweep(context.Background(), &info)
+	require.NoError(t, err)
+	// Bisected once to 6000, rejected, then to 5000, accepted
+	require.Equal(t, []int{1000, 2000, 4000, 8000, 6000, 5000}, sizes())
+	require.Equal(t, 5000, result.LargestSucceeded)
+	require.Equal(t, 6000, result.SmallestFailed)
+}
+
+func TestPayloadSizeSweepStopsAtMaxSize(t *testing.T) {
+	fake, sizes := sizeLimitedClient(1 << 20)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	result, err := PayloadSizeSweep{InitialSize: 1000, MaxSize: 3000}.Sweep(context.Background(), &info)
+	require.NoError(t, err)
+	require.Equal(t, []int{1000, 2000, 3000}, sizes())
+	require.Equal(t, PayloadSizeSweepResult{LargestSucceeded: 3000, Iterations: 3}, result)
+}
+
+func TestPayloadSizeSweepFailsOnOtherErrors(t *testing.T) {
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return nil, serviceerror.NewUnavailable("server down")
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := PayloadSizeSweep{}.Sweep(context.Background(), &info)
+	require.ErrorContains(t, err, "server down")
+}
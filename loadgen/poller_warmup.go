@@ -0,0 +1,106 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// pollerCheckInterval is the interval between task queue descriptions of AwaitPollers.
+var pollerCheckInterval = time.Second
+
+// TaskQueueCountOption is the integer scenario option sharding the run across that many task queues
+// suffixed "-0" to "-<n-1>", as served by workers with --task-queue-suffix-index-end.
+const TaskQueueCountOption = "task-queue-count"
+
+// RunTaskQueues returns the task queues of the run: the sharded task queues if the
+// TaskQueueCountOption or "task-queue-weights" scenario option is set, the run's task queue
+// otherwise.
+func (s *ScenarioInfo) RunTaskQueues() []string {
+	base := TaskQueueForRun(s.ScenarioName, s.RunID)
+	count := s.ScenarioOptionInt(TaskQueueCountOption, 0)
+	if weights, err := ParseTaskQueueWeights(s.ScenarioOptions["task-queue-weights"]); count == 0 && err == nil {
+		count = weights.Count()
+	}
+	if count == 0 {
+		return []string{base}
+	}
+	taskQueues := make([]string, count)
+	for i := range taskQueues {
+		taskQueues[i] = fmt.Sprintf("%v-%v", base, i)
+	}
+	return taskQueues
+}
+
+// MissingPollersError is returned by AwaitPollers when task queues have no pollers in time.
+type MissingPollersError struct {
+	// Task queues without pollers, sorted.
+	TaskQueues []string
+	Timeout    time.Duration
+}
+
+func (e *MissingPollersError) Error() string {
+	return fmt.Sprintf("no workflow pollers on task queue(s) %v after waiting %v for each, are workers running on them?",
+		strings.Join(e.TaskQueues, ", "), e.Timeout)
+}
+
+// AwaitPollers waits until each of the task queues has a workflow task poller, waiting up to the
+// timeout for each queue independently, so that load does not start before workers registering at
+// different times are all up. Returns a MissingPollersError listing the task queues still without
+// pollers after the timeout.
+func (s *ScenarioInfo) AwaitPollers(ctx context.Context, taskQueues []string, timeout time.Duration) error {
+	var lock sync.Mutex
+	var missing []string
+	var wg sync.WaitGroup
+	for _, taskQueue := range taskQueues {
+		taskQueue := taskQueue
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !s.awaitPoller(ctx, taskQueue, timeout) {
+				lock.Lock()
+				missing = append(missing, taskQueue)
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	} else if len(missing) > 0 {
+		sort.Strings(missing)
+		return &MissingPollersError{TaskQueues: missing, Timeout: timeout}
+	}
+	return nil
+}
+
+// awaitPoller returns whether the task queue had a workflow task poller within the timeout. Failed
+// descriptions are logged and retried.
+func (s *ScenarioInfo) awaitPoller(ctx context.Context, taskQueue string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		resp, err := s.Client.WorkflowService().DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+			Namespace:     s.Namespace,
+			TaskQueue:     &taskqueue.TaskQueue{Name: taskQueue},
+			TaskQueueType: enums.TASK_QUEUE_TYPE_WORKFLOW,
+		})
+		if err == nil && len(resp.GetPollers()) > 0 {
+			return true
+		} else if err != nil && ctx.Err() == nil {
+			s.Logger.Warnf("Failed describing task queue %v: %v", taskQueue, err)
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(pollerCheckInterval):
+		}
+	}
+}
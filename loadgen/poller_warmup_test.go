@@ -0,0 +1,76 @@
+package loadgen
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+func useFastPollerChecks(t *testing.T) {
+	prev := pollerCheckInterval
+	pollerCheckInterval = time.Millisecond
+	t.Cleanup(func() { pollerCheckInterval = prev })
+}
+
+// pollersOn returns a DescribeTaskQueue fake reporting a poller on the task queues for which
+// hasPoller returns true.
+func pollersOn(hasPoller func(taskQueue string) bool) func(context.Context, *workflowservice.DescribeTaskQueueRequest) (
+	*workflowservice.DescribeTaskQueueResponse, error) {
+	return func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+		*workflowservice.DescribeTaskQueueResponse, error) {
+		if request.TaskQueueType != enums.TASK_QUEUE_TYPE_WORKFLOW {
+			panic("unexpected describe request")
+		}
+		if !hasPoller(request.TaskQueue.Name) {
+			return &workflowservice.DescribeTaskQueueResponse{}, nil
+		}
+		return &workflowservice.DescribeTaskQueueResponse{Pollers: []*taskqueue.PollerInfo{{Identity: "worker"}}}, nil
+	}
+}
+
+func TestRunTaskQueues(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	require.Equal(t, []string{"test:test-run"}, info.RunTaskQueues())
+	info.ScenarioOptions = map[string]string{TaskQueueCountOption: "3"}
+	require.Equal(t, []string{"test:test-run-0", "test:test-run-1", "test:test-run-2"}, info.RunTaskQueues())
+	info.ScenarioOptions = map[string]string{"task-queue-weights": "80,20"}
+	require.Equal(t, []string{"test:test-run-0", "test:test-run-1"}, info.RunTaskQueues())
+}
+
+func TestAwaitPollersWaitsForLateWorkers(t *testing.T) {
+	useFastPollerChecks(t)
+	var late int32
+	fake := &FakeClient{OnDescribeTaskQueue: pollersOn(func(taskQueue string) bool {
+		// The last queue's worker registers after a few checks
+		return taskQueue != "test:test-run-2" || atomic.AddInt32(&late, 1) > 3
+	})}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.ScenarioOptions = map[string]string{TaskQueueCountOption: "3"}
+	require.NoError(t, info.AwaitPollers(context.Background(), info.RunTaskQueues(), time.Second))
+	require.Len(t, fake.Calls("DescribeTaskQueue"), 6)
+}
+
+func TestAwaitPollersReportsMissingQueues(t *testing.T) {
+	useFastPollerChecks(t)
+	fake := &FakeClient{OnDescribeTaskQueue: pollersOn(func(taskQueue string) bool {
+		return taskQueue == "test:test-run-1"
+	})}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 1, AwaitPollersTimeout: 20 * time.Millisecond})
+	info.ScenarioOptions = map[string]string{TaskQueueCountOption: "3"}
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+		return run.ExecuteAnyWorkflow(ctx, run.StartWorkflowOptions(), "wf", nil)
+	}}
+	err := executor.Run(context.Background(), info)
+	var missing *MissingPollersError
+	require.ErrorAs(t, err, &missing)
+	require.Equal(t, []string{"test:test-run-0", "test:test-run-2"}, missing.TaskQueues)
+	require.ErrorContains(t, err, "no workflow pollers on task queue(s) test:test-run-0, test:test-run-2 after waiting 20ms")
+	// No load is started
+	require.Empty(t, fake.Calls("ExecuteWorkflow"))
+}
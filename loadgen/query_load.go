@@ -0,0 +1,94 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+)
+
+// ErrQueryTargetsNotStarted is returned by QueryTargets.Query before QueryTargets.Start.
+var ErrQueryTargetsNotStarted = errors.New("query targets not started")
+
+// QueryTargets directs iteration load as queries at a small fixed pool of long-lived workflows
+// instead of starting a workflow per iteration, to benchmark the read path. The pool is started
+// once with Start, typically from GenericExecutor.Setup, after which iterations are routed to
+// targets round robin by iteration number, and terminated with Terminate, typically from
+// GenericExecutor.Teardown. Query latencies are recorded in the
+// omes_query_latency timer.
+type QueryTargets struct {
+	// Number of target workflows, with IDs "<WorkflowIDPrefix>query-target-<index>". Default is 1.
+	Count int
+	// Workflow targets are started as.
+	WorkflowSpec
+	// Query sent by each iteration. Default is the kitchen sink's "report_state".
+	QueryType string
+	QueryArgs []interface{}
+
+	ids []string
+}
+
+// Start starts the target workflows, attaching to any already running from a previous attempt
+// of the run. Targets are left running, to be removed with cleanup-scenario.
+func (q *QueryTargets) Start(ctx context.Context, info *ScenarioInfo) error {
+	count := q.Count
+	if count <= 0 {
+		count = 1
+	}
+	workflow, args := q.workflowAndArgs()
+	ids := make([]string, count)
+	for i := range ids {
+		options := info.NewRun(0).DefaultStartWorkflowOptions()
+		options.ID = fmt.Sprintf("%squery-target-%d", info.WorkflowIDPrefix(), i)
+		options.WorkflowExecutionErrorWhenAlreadyStarted = false
+		if _, err := info.Client.ExecuteWorkflow(ctx, options, workflow, args...); err != nil {
+			return fmt.Errorf("failed starting query target %v: %w", options.ID, err)
+		}
+		ids[i] = options.ID
+	}
+	info.Logger.Infof("Started %v query target workflow(s)", count)
+	q.ids = ids
+	return nil
+}
+
+// Query sends the query to the iteration's target and decodes its result into valuePtr, which may
+// be nil.
+func (q *QueryTargets) Query(ctx context.Context, run *Run, valuePtr interface{}) error {
+	if len(q.ids) == 0 {
+		return ErrQueryTargetsNotStarted
+	}
+	index := (run.Iteration - 1) % len(q.ids)
+	if index < 0 {
+		index += len(q.ids)
+	}
+	id := q.ids[index]
+	queryType := q.QueryType
+	if queryType == "" {
+		queryType = "report_state"
+	}
+	start := time.Now()
+	value, err := run.Client.QueryWorkflow(ctx, id, "", queryType, q.QueryArgs...)
+	if err == nil && valuePtr != nil {
+		err = value.Get(valuePtr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed querying %v of target %v: %w", queryType, id, err)
+	}
+	run.RecordTimer("omes_query_latency", nil, time.Since(start))
+	return nil
+}
+
+// Terminate terminates the started target workflows that are still running.
+func (q *QueryTargets) Terminate(ctx context.Context, info *ScenarioInfo) error {
+	var errs []error
+	for _, id := range q.ids {
+		err := info.Client.TerminateWorkflow(ctx, id, "", "omes run ended", nil)
+		var notFound *serviceerror.NotFound
+		if err != nil && !errors.As(err, &notFound) {
+			errs = append(errs, fmt.Errorf("failed terminating query target %v: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryTargetsDirectLoadAsQueries(t *testing.T) {
+	fake := &FakeClient{
+		OnQueryWorkflow: func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error) {
+			return workflowID, nil
+		},
+	}
+	targets := &QueryTargets{Count: 2}
+	executor := &GenericExecutor{
+		Setup:    targets.Start,
+		Teardown: targets.Terminate,
+		Execute: func(ctx context.Context, run *Run) error {
+			var state string
+			if err := targets.Query(ctx, run, &state); err != nil {
+				return err
+			}
+			require.Contains(t, []string{"w-test-run-query-target-0", "w-test-run-query-target-1"}, state)
+			return nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 10, MaxConcurrent: 3})
+	require.NoError(t, executor.Run(context.Background(), info))
+
+	starts := fake.Calls("ExecuteWorkflow")
+	require.Len(t, starts, 2)
+	require.Equal(t, "w-test-run-query-target-0", starts[0].Options.ID)
+	require.False(t, starts[0].Options.WorkflowExecutionErrorWhenAlreadyStarted)
+	queries := fake.Calls("QueryWorkflow")
+	require.Len(t, queries, 10)
+	perTarget := map[string]int{}
+	for _, query := range queries {
+		require.Equal(t, "report_state", query.Name)
+		perTarget[query.WorkflowID]++
+	}
+	require.Equal(t, map[string]int{"w-test-run-query-target-0": 5, "w-test-run-query-target-1": 5}, perTarget)
+	// Setup happens before any iteration
+	calls := fake.Calls()
+	require.Equal(t, "ExecuteWorkflow", calls[0].Method)
+	require.Equal(t, "ExecuteWorkflow", calls[1].Method)
+	// And the targets are terminated after
+	terminations := fake.Calls("TerminateWorkflow")
+	require.Len(t, terminations, 2)
+	require.Equal(t, "w-test-run-query-target-1", terminations[1].WorkflowID)
+	require.Equal(t, "TerminateWorkflow", calls[len(calls)-1].Method)
+}
+
+func TestQueryTargetsNotStarted(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	err := (&QueryTargets{}).Query(context.Background(), info.NewRun(1), nil)
+	require.ErrorIs(t, err, ErrQueryTargetsNotStarted)
+}
+
+func TestRunSetupFailure(t *testing.T) {
+	executor := &GenericExecutor{
+		Setup: func(ctx context.Context, info *ScenarioInfo) error {
+			return context.DeadlineExceeded
+		},
+		Execute: func(ctx context.Context, run *Run) error {
+			t.Fatal("iteration started despite failed setup")
+			return nil
+		},
+	}
+	err := executor.Run(context.Background(), NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 1}))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "failed scenario setup")
+}
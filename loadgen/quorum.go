@@ -0,0 +1,103 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+// ErrQuorumNotReached is returned (wrapped in a *QuorumError) by Run.AwaitQuorum when the quorum
+// of completions is not reached.
+var ErrQuorumNotReached = errors.New("quorum of workflow completions not reached")
+
+// QuorumError describes why Run.AwaitQuorum did not reach its quorum.
+type QuorumError struct {
+	// Successful completions needed.
+	Quorum    int
+	Completed int
+	Failed    int
+	// Workflows neither completed nor failed when giving up.
+	Pending int
+	// Whether the timeout elapsed, as opposed to too many workflows failing for the quorum to be
+	// reachable.
+	TimedOut bool
+	// Error of the first failed workflow, if any.
+	FirstFailure error
+}
+
+func (e *QuorumError) Error() string {
+	reason := "too many failures"
+	if e.TimedOut {
+		reason = "timed out"
+	}
+	msg := fmt.Sprintf("%v: %v, %v of %v needed completed, %v failed, %v pending",
+		ErrQuorumNotReached, reason, e.Completed, e.Quorum, e.Failed, e.Pending)
+	if e.FirstFailure != nil {
+		msg += fmt.Sprintf(", first failure: %v", e.FirstFailure)
+	}
+	return msg
+}
+
+func (e *QuorumError) Unwrap() error {
+	return ErrQuorumNotReached
+}
+
+// AwaitQuorum waits for n of the started workflows to complete successfully, returning as soon as
+// they have without waiting for stragglers. Returns a *QuorumError if the timeout elapses first, or
+// as soon as so many workflows failed that n can no longer complete. A zero timeout waits
+// indefinitely.
+func (r *Run) AwaitQuorum(runs []client.WorkflowRun, n int, timeout time.Duration) error {
+	if n <= 0 {
+		return nil
+	} else if n > len(runs) {
+		return fmt.Errorf("quorum of %v exceeds the %v workflows awaited", n, len(runs))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	// Stop awaiting stragglers once done
+	defer cancel()
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+	results := make(chan error, len(runs))
+	for _, execution := range runs {
+		execution := execution
+		go func() {
+			err := r.getWorkflowResult(ctx, execution, nil)
+			if err != nil {
+				err = fmt.Errorf("workflow %v failed: %w", execution.GetID(), err)
+			}
+			results <- err
+		}()
+	}
+	quorumErr := &QuorumError{Quorum: n}
+	for {
+		select {
+		case err := <-results:
+			if err == nil {
+				quorumErr.Completed++
+				if quorumErr.Completed == n {
+					return nil
+				}
+				continue
+			}
+			quorumErr.Failed++
+			if quorumErr.FirstFailure == nil {
+				quorumErr.FirstFailure = err
+			}
+			if len(runs)-quorumErr.Failed < n {
+				quorumErr.Pending = len(runs) - quorumErr.Completed - quorumErr.Failed
+				return quorumErr
+			}
+		case <-timeoutCh:
+			quorumErr.TimedOut = true
+			quorumErr.Pending = len(runs) - quorumErr.Completed - quorumErr.Failed
+			return quorumErr
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package loadgen
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+// laggingRuns returns fast workflow runs, failing ones and ones lagging by an hour.
+func laggingRuns(fast, failing, lagging int) []client.WorkflowRun {
+	var runs []client.WorkflowRun
+	for i := 0; i < fast; i++ {
+		runs = append(runs, &FakeWorkflowRun{ID: "fast", Delay: time.Millisecond})
+	}
+	for i := 0; i < failing; i++ {
+		runs = append(runs, &FakeWorkflowRun{ID: "failing", Delay: time.Millisecond, Err: errors.New("boom")})
+	}
+	for i := 0; i < lagging; i++ {
+		runs = append(runs, &FakeWorkflowRun{ID: "lagging", Delay: time.Hour})
+	}
+	return runs
+}
+
+func TestAwaitQuorumIgnoresStragglers(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	start := time.Now()
+	err := info.NewRun(1).AwaitQuorum(laggingRuns(3, 1, 2), 3, time.Minute)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestAwaitQuorumTimeout(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	start := time.Now()
+	err := info.NewRun(1).AwaitQuorum(laggingRuns(2, 1, 3), 3, 50*time.Millisecond)
+	require.ErrorIs(t, err, ErrQuorumNotReached)
+	var quorumErr *QuorumError
+	require.ErrorAs(t, err, &quorumErr)
+	require.True(t, quorumErr.TimedOut)
+	require.Equal(t, 2, quorumErr.Completed)
+	require.Equal(t, 1, quorumErr.Failed)
+	require.Equal(t, 3, quorumErr.Pending)
+	require.ErrorContains(t, err, "timed out, 2 of 3 needed completed, 1 failed, 3 pending, first failure: workflow failing failed: boom")
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestAwaitQuorumTooManyFailures(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	start := time.Now()
+	// With 3 failed, at most 3 of 6 can complete
+	err := info.NewRun(1).AwaitQuorum(laggingRuns(1, 3, 2), 4, time.Minute)
+	var quorumErr *QuorumError
+	require.ErrorAs(t, err, &quorumErr)
+	require.False(t, quorumErr.TimedOut)
+	require.Equal(t, 3, quorumErr.Failed)
+	require.ErrorContains(t, err, "too many failures")
+	// Without waiting for the laggards or the timeout
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestAwaitQuorumInvalid(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	err := info.NewRun(1).AwaitQuorum(laggingRuns(1, 0, 0), 2, time.Minute)
+	require.EqualError(t, err, "quorum of 2 exceeds the 1 workflows awaited")
+	require.NoError(t, info.NewRun(1).AwaitQuorum(nil, 0, time.Minute))
+}
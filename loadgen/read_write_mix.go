@@ -0,0 +1,246 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+)
+
+// ErrReadWriteMixNotStarted is returned by ReadWriteMix.Execute before ReadWriteMix.Start.
+var ErrReadWriteMixNotStarted = errors.New("read/write mix not started")
+
+// readWriteMixSeedSalt decorrelates the read/write choice from other choices made from the seed
+// and iteration, like TaskQueueWeights.
+const readWriteMixSeedSalt = 0x5257
+
+// ReadWriteOp is the operation of an iteration of a ReadWriteMix.
+type ReadWriteOp string
+
+const (
+	// ReadOp queries a workflow of the pool.
+	ReadOp ReadWriteOp = "read"
+	// WriteOp signals a workflow of the pool.
+	WriteOp ReadWriteOp = "write"
+)
+
+// ReadWriteMix interleaves reads and writes against a shared pool of long-lived workflows, for
+// mixed read/write benchmarks. The pool is started once with Start, typically from
+// GenericExecutor.Setup, after which each iteration either queries (reads) or signals (writes) a
+// workflow of the pool, routed round robin by iteration number. The operation is chosen at random
+// from the scenario seed and iteration, so the mix is reproducible. Read and write latencies are
+// recorded separately in the omes_read_write_mix_read_latency and omes_read_write_mix_write_latency
+// timers, and in Stats. It is safe for concurrent use once started.
+type ReadWriteMix struct {
+	// Number of workflows in the pool, with IDs "<WorkflowIDPrefix>read-write-<index>". Default
+	// is 10.
+	PoolSize int
+	// Relative weights of reads and writes, e.g. 9 and 1 for 90% reads. Default is equal weights.
+	ReadWeight  float64
+	WriteWeight float64
+	// Workflow the pool is started as.
+	WorkflowSpec
+	// Signal sent by writes, with the argument returned for the iteration. Default is the kitchen
+	// sink's "do_actions_signal" setting the "last_write" state key to the iteration.
+	SignalName string
+	SignalArg  func(run *Run) interface{}
+	// Query sent by reads. Default is the kitchen sink's "report_state".
+	QueryType string
+	QueryArgs []interface{}
+
+	lock sync.Mutex
+	info *ScenarioInfo
+	// Current workflow ID of each slot of the pool.
+	ids []string
+	// Number of times each slot was replenished, for the IDs of replacements.
+	generations []int
+	stats       readWriteStats
+}
+
+// ReadWriteStats are the outcomes of the operations of a ReadWriteMix.
+type ReadWriteStats struct {
+	Reads        int
+	Writes       int
+	ReadLatency  LatencySummary
+	WriteLatency LatencySummary
+	// Pool workflows replaced after they closed.
+	Replenished int
+}
+
+type readWriteStats struct {
+	readLatencies  []time.Duration
+	writeLatencies []time.Duration
+	replenished    int
+}
+
+// Start starts the pool's workflows, attaching to any already running from a previous attempt of
+// the run. Workflows are left running, to be removed with cleanup-scenario.
+func (m *ReadWriteMix) Start(ctx context.Context, info *ScenarioInfo) error {
+	size := m.PoolSize
+	if size <= 0 {
+		size = 10
+	}
+	ids := make([]string, size)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%sread-write-%d", info.WorkflowIDPrefix(), i)
+		if err := m.startWorkflow(ctx, info, ids[i]); err != nil {
+			return err
+		}
+	}
+	m.lock.Lock()
+	m.info = info
+	m.ids = ids
+	m.generations = make([]int, size)
+	m.lock.Unlock()
+	info.Logger.Infof("Started %v read/write mix workflow(s)", size)
+	return nil
+}
+
+// startWorkflow starts the pool workflow with the given ID, attaching to it if already running.
+func (m *ReadWriteMix) startWorkflow(ctx context.Context, info *ScenarioInfo, id string) error {
+	workflow, args := m.workflowAndArgs()
+	options := info.NewRun(0).DefaultStartWorkflowOptions()
+	options.ID = id
+	options.WorkflowExecutionErrorWhenAlreadyStarted = false
+	if _, err := info.Client.ExecuteWorkflow(ctx, options, workflow, args...); err != nil {
+		return fmt.Errorf("failed starting read/write mix workflow %v: %w", id, err)
+	}
+	return nil
+}
+
+// Op returns the operation of the iteration.
+func (m *ReadWriteMix) Op(run *Run) ReadWriteOp {
+	read, write := m.ReadWeight, m.WriteWeight
+	if read <= 0 && write <= 0 {
+		read, write = 1, 1
+	}
+	if iterationUniform(run.Seed()^readWriteMixSeedSalt, run.Iteration)*(read+write) < read {
+		return ReadOp
+	}
+	return WriteOp
+}
+
+// Execute performs the iteration's operation on its workflow of the pool and returns it. If the
+// workflow has closed, e.g. by completing or timing out, it is replaced in the pool by a new one
+// and the operation retried on it once.
+func (m *ReadWriteMix) Execute(ctx context.Context, run *Run) (ReadWriteOp, error) {
+	op := m.Op(run)
+	m.lock.Lock()
+	if len(m.ids) == 0 {
+		m.lock.Unlock()
+		return op, ErrReadWriteMixNotStarted
+	}
+	slot := (run.Iteration - 1) % len(m.ids)
+	if slot < 0 {
+		slot += len(m.ids)
+	}
+	id := m.ids[slot]
+	m.lock.Unlock()
+
+	latency, err := m.do(ctx, run, op, id)
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		if id, err = m.replenish(ctx, slot, id); err != nil {
+			return op, err
+		}
+		latency, err = m.do(ctx, run, op, id)
+	}
+	if err != nil {
+		return op, fmt.Errorf("failed %v of read/write mix workflow %v: %w", op, id, err)
+	}
+	m.lock.Lock()
+	if op == ReadOp {
+		m.stats.readLatencies = append(m.stats.readLatencies, latency)
+	} else {
+		m.stats.writeLatencies = append(m.stats.writeLatencies, latency)
+	}
+	m.lock.Unlock()
+	run.RecordTimer(fmt.Sprintf("omes_read_write_mix_%v_latency", op), nil, latency)
+	return op, nil
+}
+
+func (m *ReadWriteMix) do(ctx context.Context, run *Run, op ReadWriteOp, id string) (time.Duration, error) {
+	start := time.Now()
+	if op == ReadOp {
+		queryType := m.QueryType
+		if queryType == "" {
+			queryType = "report_state"
+		}
+		_, err := run.Client.QueryWorkflow(ctx, id, "", queryType, m.QueryArgs...)
+		return time.Since(start), err
+	}
+	signalName := m.SignalName
+	var arg interface{}
+	if signalName == "" {
+		signalName, arg = "do_actions_signal", lastWriteSignal(run.Iteration)
+	}
+	if m.SignalArg != nil {
+		arg = m.SignalArg(run)
+	}
+	err := run.Client.SignalWorkflow(ctx, id, "", signalName, arg)
+	return time.Since(start), err
+}
+
+// lastWriteSignal returns kitchen sink signal actions setting the "last_write" state key.
+func lastWriteSignal(iteration int) *kitchensink.DoSignal_DoSignalActions {
+	return &kitchensink.DoSignal_DoSignalActions{
+		Variant: &kitchensink.DoSignal_DoSignalActions_DoActions{
+			DoActions: &kitchensink.ActionSet{
+				Actions: []*kitchensink.Action{{
+					Variant: &kitchensink.Action_SetWorkflowState{
+						SetWorkflowState: &kitchensink.WorkflowState{
+							Kvs: map[string]string{"last_write": strconv.Itoa(iteration)},
+						},
+					},
+				}},
+			},
+		},
+	}
+}
+
+// replenish replaces the closed workflow of the pool slot with a new one, unless another iteration
+// already did, and returns the slot's current workflow ID. The replacement is started without
+// holding the lock. Iterations replenishing the slot concurrently start the same workflow ID, so
+// they attach to a single replacement.
+func (m *ReadWriteMix) replenish(ctx context.Context, slot int, closedID string) (string, error) {
+	m.lock.Lock()
+	if m.ids[slot] != closedID {
+		defer m.lock.Unlock()
+		return m.ids[slot], nil
+	}
+	info, generation := m.info, m.generations[slot]+1
+	m.lock.Unlock()
+
+	id := fmt.Sprintf("%sread-write-%d-%d", info.WorkflowIDPrefix(), slot, generation)
+	if err := m.startWorkflow(ctx, info, id); err != nil {
+		return "", fmt.Errorf("failed replenishing read/write mix pool: %w", err)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.ids[slot] == closedID {
+		info.Logger.Infof("Replaced closed read/write mix workflow %v with %v", closedID, id)
+		m.ids[slot] = id
+		m.generations[slot] = generation
+		m.stats.replenished++
+	}
+	return m.ids[slot], nil
+}
+
+// Stats returns the outcomes of the operations so far.
+func (m *ReadWriteMix) Stats() ReadWriteStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return ReadWriteStats{
+		Reads:        len(m.stats.readLatencies),
+		Writes:       len(m.stats.writeLatencies),
+		ReadLatency:  NewLatencySummary(append([]time.Duration(nil), m.stats.readLatencies...)),
+		WriteLatency: NewLatencySummary(append([]time.Duration(nil), m.stats.writeLatencies...)),
+		Replenished:  m.stats.replenished,
+	}
+}
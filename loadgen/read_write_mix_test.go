@@ -0,0 +1,152 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+func TestReadWriteMixRatio(t *testing.T) {
+	fake := &FakeClient{}
+	mix := &ReadWriteMix{PoolSize: 3, ReadWeight: 9, WriteWeight: 1}
+	executor := &GenericExecutor{
+		Setup: mix.Start,
+		Execute: func(ctx context.Context, run *Run) error {
+			_, err := mix.Execute(ctx, run)
+			return err
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 1000, MaxConcurrent: 10})
+	require.NoError(t, executor.Run(context.Background(), info))
+
+	require.Len(t, fake.Calls("ExecuteWorkflow"), 3)
+	stats := mix.Stats()
+	require.Equal(t, 1000, stats.Reads+stats.Writes)
+	require.InDelta(t, 900, stats.Reads, 50)
+	require.Len(t, fake.Calls("QueryWorkflow"), stats.Reads)
+	require.Len(t, fake.Calls("SignalWorkflow"), stats.Writes)
+	for _, call := range append(fake.Calls("QueryWorkflow"), fake.Calls("SignalWorkflow")...) {
+		require.Contains(t, []string{"w-test-run-read-write-0", "w-test-run-read-write-1", "w-test-run-read-write-2"},
+			call.WorkflowID)
+	}
+
+	// The choice is reproducible from the seed
+	run := info.NewRun(42)
+	require.Equal(t, mix.Op(run), mix.Op(info.NewRun(42)))
+}
+
+func TestReadWriteMixTracksLatenciesSeparately(t *testing.T) {
+	fake := &FakeClient{
+		OnQueryWorkflow: func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error) {
+			time.Sleep(20 * time.Millisecond)
+			return nil, nil
+		},
+	}
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	mix := &ReadWriteMix{}
+	require.NoError(t, mix.Start(context.Background(), &info))
+	ops := map[ReadWriteOp]int{}
+	for i := 1; i <= 20; i++ {
+		op, err := mix.Execute(context.Background(), info.NewRun(i))
+		require.NoError(t, err)
+		ops[op]++
+	}
+
+	stats := mix.Stats()
+	require.Equal(t, ops[ReadOp], stats.Reads)
+	require.Equal(t, ops[WriteOp], stats.Writes)
+	require.Positive(t, stats.Reads)
+	require.Positive(t, stats.Writes)
+	require.GreaterOrEqual(t, stats.ReadLatency.Min, 20*time.Millisecond)
+	require.Less(t, stats.WriteLatency.Max, 20*time.Millisecond)
+
+	timers := map[string]int{}
+	for _, metric := range *handler.recorded {
+		if metric.kind == "timer" {
+			timers[metric.name]++
+		}
+	}
+	require.Equal(t, stats.Reads, timers["omes_read_write_mix_read_latency"])
+	require.Equal(t, stats.Writes, timers["omes_read_write_mix_write_latency"])
+}
+
+func TestReadWriteMixReplenishesClosedWorkflows(t *testing.T) {
+	fake := &FakeClient{
+		OnSignalWorkflow: func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+			if workflowID == "w-test-run-read-write-0" {
+				return serviceerror.NewNotFound("workflow execution already completed")
+			}
+			return nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	// Writes only, all to the single workflow of the pool
+	mix := &ReadWriteMix{PoolSize: 1, WriteWeight: 1}
+	require.NoError(t, mix.Start(context.Background(), &info))
+	for i := 1; i <= 3; i++ {
+		op, err := mix.Execute(context.Background(), info.NewRun(i))
+		require.NoError(t, err)
+		require.Equal(t, WriteOp, op)
+	}
+
+	starts := fake.Calls("ExecuteWorkflow")
+	require.Len(t, starts, 2)
+	require.Equal(t, "w-test-run-read-write-0-1", starts[1].Options.ID)
+	var signaled []string
+	for _, call := range fake.Calls("SignalWorkflow") {
+		signaled = append(signaled, call.WorkflowID)
+	}
+	require.Equal(t, []string{"w-test-run-read-write-0", "w-test-run-read-write-0-1",
+		"w-test-run-read-write-0-1", "w-test-run-read-write-0-1"}, signaled)
+	require.Equal(t, 1, mix.Stats().Replenished)
+	require.Equal(t, 3, mix.Stats().Writes)
+}
+
+func TestReadWriteMixReplenishesWithoutBlockingOtherSlots(t *testing.T) {
+	replacing, release := make(chan struct{}), make(chan struct{})
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			if options.ID == "w-test-run-read-write-0-1" {
+				close(replacing)
+				<-release
+			}
+			return &FakeWorkflowRun{ID: options.ID}, nil
+		},
+		OnSignalWorkflow: func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+			if workflowID == "w-test-run-read-write-0" {
+				return serviceerror.NewNotFound("workflow execution already completed")
+			}
+			return nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	mix := &ReadWriteMix{PoolSize: 2, WriteWeight: 1}
+	require.NoError(t, mix.Start(context.Background(), &info))
+	replenished := make(chan error)
+	go func() {
+		_, err := mix.Execute(context.Background(), info.NewRun(1))
+		replenished <- err
+	}()
+	<-replacing
+	// Other slots and stats are usable while the replacement starts
+	_, err := mix.Execute(context.Background(), info.NewRun(2))
+	require.NoError(t, err)
+	require.Equal(t, 1, mix.Stats().Writes)
+	close(release)
+	require.NoError(t, <-replenished)
+	require.Equal(t, 1, mix.Stats().Replenished)
+	require.Equal(t, 2, mix.Stats().Writes)
+}
+
+func TestReadWriteMixNotStarted(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	_, err := (&ReadWriteMix{}).Execute(context.Background(), info.NewRun(1))
+	require.ErrorIs(t, err, ErrReadWriteMixNotStarted)
+}
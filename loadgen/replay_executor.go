@@ -0,0 +1,89 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/worker"
+	"go.uber.org/zap"
+)
+
+// ReplayExecutor replays recorded workflow histories through the SDK replayer instead of starting
+// workflows on a server. Each iteration replays one history JSON file, cycling through the files
+// in name order if there are more iterations than files. A replay failure (e.g. non-determinism)
+// fails the iteration.
+type ReplayExecutor struct {
+	// Directory containing the history JSON files (*.json). If empty, the "history-dir" scenario
+	// option is used.
+	HistoryDir string
+	// Must be specified, registers the workflows the histories are replayed against.
+	RegisterWorkflows func(worker.WorkflowReplayer)
+	// Default configuration if any. If neither iterations nor duration is set, there is one
+	// iteration per history file.
+	DefaultConfiguration RunConfiguration
+}
+
+func (e ReplayExecutor) Run(ctx context.Context, info ScenarioInfo) error {
+	if e.RegisterWorkflows == nil {
+		return fmt.Errorf("RegisterWorkflows must be specified")
+	}
+	historyDir := e.HistoryDir
+	if historyDir == "" {
+		historyDir = info.ScenarioOptions["history-dir"]
+	}
+	if historyDir == "" {
+		return fmt.Errorf("history directory must be specified")
+	}
+	files, err := filepath.Glob(filepath.Join(historyDir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed listing history files: %w", err)
+	} else if len(files) == 0 {
+		return fmt.Errorf("no history files found in %v", historyDir)
+	}
+	sort.Strings(files)
+
+	replayer := worker.NewWorkflowReplayer()
+	e.RegisterWorkflows(replayer)
+
+	config := e.DefaultConfiguration
+	if config.Iterations == 0 && config.Duration == 0 {
+		config.Iterations = len(files)
+	}
+	// Create generic executor and run it
+	ge := &GenericExecutor{
+		DefaultConfiguration: config,
+		Execute: func(ctx context.Context, run *Run) error {
+			file := files[(run.Iteration-1)%len(files)]
+			run.Logger.Debugf("Replaying history %v", file)
+			start := time.Now()
+			err := replayer.ReplayWorkflowHistoryFromJSONFile(replayLogger{run.Logger}, file)
+			result := "success"
+			if err != nil {
+				result = "failure"
+			}
+			tags := map[string]string{"result": result}
+			run.RecordTimer("omes_replay_latency", tags, time.Since(start))
+			run.RecordCounter("omes_replay_total", tags, 1)
+			if err != nil {
+				return fmt.Errorf("failed replaying %v: %w", file, err)
+			}
+			return nil
+		},
+	}
+	return ge.Run(ctx, info)
+}
+
+func (e ReplayExecutor) GetDefaultConfiguration() RunConfiguration {
+	return e.DefaultConfiguration
+}
+
+// replayLogger adapts a zap logger to the SDK logger interface.
+type replayLogger struct{ *zap.SugaredLogger }
+
+func (l replayLogger) Debug(msg string, keyvals ...interface{}) { l.Debugw(msg, keyvals...) }
+func (l replayLogger) Info(msg string, keyvals ...interface{})  { l.Infow(msg, keyvals...) }
+func (l replayLogger) Warn(msg string, keyvals ...interface{})  { l.Warnw(msg, keyvals...) }
+func (l replayLogger) Error(msg string, keyvals ...interface{}) { l.Errorw(msg, keyvals...) }
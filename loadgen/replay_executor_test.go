@@ -0,0 +1,49 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/zap"
+)
+
+func replayTestWorkflow(ctx workflow.Context) error {
+	return nil
+}
+
+func runReplay(historyDir string) (*recordingMetricsHandler, error) {
+	handler := newRecordingMetricsHandler()
+	err := ReplayExecutor{
+		HistoryDir: historyDir,
+		RegisterWorkflows: func(replayer worker.WorkflowReplayer) {
+			replayer.RegisterWorkflowWithOptions(replayTestWorkflow, workflow.RegisterOptions{Name: "replayTest"})
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 3},
+	}.Run(context.Background(), ScenarioInfo{
+		ScenarioName:   "replay_test",
+		MetricsHandler: handler,
+		Logger:         zap.NewNop().Sugar(),
+	})
+	return handler, err
+}
+
+func TestReplayExecutorSuccess(t *testing.T) {
+	handler, err := runReplay("testdata/replay/good")
+	require.NoError(t, err)
+	var successes int
+	for _, m := range *handler.recorded {
+		if m.name == "omes_replay_total" && m.tags["result"] == "success" {
+			successes++
+		}
+	}
+	require.Equal(t, 3, successes)
+}
+
+func TestReplayExecutorNonDeterminism(t *testing.T) {
+	_, err := runReplay("testdata/replay/bad")
+	require.ErrorContains(t, err, "failed replaying")
+	require.ErrorContains(t, err, "nondeterministic")
+}
@@ -0,0 +1,140 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// ReportFormat is the serialization format of a run report.
+type ReportFormat string
+
+const (
+	ReportFormatJSON ReportFormat = "json"
+	ReportFormatCSV  ReportFormat = "csv"
+)
+
+// ParseReportFormat parses a report format name, defaulting to JSON if empty.
+func ParseReportFormat(s string) (ReportFormat, error) {
+	switch ReportFormat(s) {
+	case "", ReportFormatJSON:
+		return ReportFormatJSON, nil
+	case ReportFormatCSV:
+		return ReportFormatCSV, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q (expected json or csv)", s)
+	}
+}
+
+// Encode writes the result to the writer in this format.
+func (f ReportFormat) Encode(w io.Writer, result *RunResult) error {
+	switch f {
+	case "", ReportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case ReportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(result.csvHeader()); err != nil {
+			return err
+		}
+		if err := cw.Write(result.csvRecord()); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown report format %q", f)
+	}
+}
+
+func (f ReportFormat) contentType() string {
+	if f == ReportFormatCSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+// ReportSink is a destination for the end-of-run report.
+type ReportSink interface {
+	WriteReport(ctx context.Context, result *RunResult) error
+}
+
+// WriterReportSink writes the report to a writer, e.g. os.Stdout.
+type WriterReportSink struct {
+	Writer io.Writer
+	Format ReportFormat
+}
+
+// WriteReport implements [ReportSink.WriteReport].
+func (s *WriterReportSink) WriteReport(ctx context.Context, result *RunResult) error {
+	return s.Format.Encode(s.Writer, result)
+}
+
+// FileReportSink writes the report to a file, replacing it if it exists.
+type FileReportSink struct {
+	Path   string
+	Format ReportFormat
+}
+
+// WriteReport implements [ReportSink.WriteReport].
+func (s *FileReportSink) WriteReport(ctx context.Context, result *RunResult) error {
+	f, err := os.Create(s.Path)
+	if err != nil {
+		return fmt.Errorf("failed creating report file: %w", err)
+	}
+	if err := s.Format.Encode(f, result); err != nil {
+		f.Close()
+		return fmt.Errorf("failed writing report file: %w", err)
+	}
+	return f.Close()
+}
+
+// HTTPReportSink POSTs the report to an HTTP endpoint.
+type HTTPReportSink struct {
+	URL    string
+	Format ReportFormat
+	// HTTP client to use. Default is http.DefaultClient.
+	Client *http.Client
+}
+
+// WriteReport implements [ReportSink.WriteReport].
+func (s *HTTPReportSink) WriteReport(ctx context.Context, result *RunResult) error {
+	var body bytes.Buffer
+	if err := s.Format.Encode(&body, result); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, &body)
+	if err != nil {
+		return fmt.Errorf("failed creating report request: %w", err)
+	}
+	req.Header.Set("Content-Type", s.Format.contentType())
+	httpClient := s.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed posting report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed posting report, got status %v", resp.Status)
+	}
+	return nil
+}
+
+// writeReport writes the result to every configured report sink, returning the first error.
+func (s *ScenarioInfo) writeReport(ctx context.Context, result *RunResult) error {
+	for _, sink := range s.ReportSinks {
+		if err := sink.WriteReport(ctx, result); err != nil {
+			return fmt.Errorf("failed writing report: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+)
+
+func testRunResult() *RunResult {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &RunResult{
+		ScenarioName:        "my_scenario",
+		RunID:               "my-run",
+		StartTime:           start,
+		EndTime:             start.Add(time.Minute),
+		Duration:            time.Minute,
+		IterationsStarted:   3,
+		IterationsCompleted: 2,
+		IterationsFailed:    1,
+		Latency:             NewLatencySummary([]time.Duration{time.Second, 3 * time.Second, 2 * time.Second}),
+	}
+}
+
+func TestWriterReportSinkJSON(t *testing.T) {
+	var buf bytes.Buffer
+	sink := &WriterReportSink{Writer: &buf, Format: ReportFormatJSON}
+	require.NoError(t, sink.WriteReport(context.Background(), testRunResult()))
+	var decoded RunResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, *testRunResult(), decoded)
+	require.Equal(t, 2*time.Second, decoded.Latency.P50)
+}
+
+func TestFileReportSinkCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	sink := &FileReportSink{Path: path, Format: ReportFormatCSV}
+	require.NoError(t, sink.WriteReport(context.Background(), testRunResult()))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "scenario", records[0][0])
+	require.Equal(t, "my_scenario", records[1][0])
+	require.Equal(t, "3000.000", records[1][len(records[1])-1])
+}
+
+func TestHTTPReportSink(t *testing.T) {
+	var gotBody []byte
+	var gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	sink := &HTTPReportSink{URL: server.URL}
+	require.NoError(t, sink.WriteReport(context.Background(), testRunResult()))
+	require.Equal(t, "application/json", gotContentType)
+	require.Contains(t, string(gotBody), `"runId": "my-run"`)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	sink = &HTTPReportSink{URL: failing.URL}
+	require.ErrorContains(t, sink.WriteReport(context.Background(), testRunResult()), "500")
+}
+
+func TestGenericExecutorWritesReport(t *testing.T) {
+	var first, second bytes.Buffer
+	info := ScenarioInfo{
+		ScenarioName:   "report_test",
+		RunID:          "run",
+		MetricsHandler: client.MetricsNopHandler,
+		Logger:         zap.NewNop().Sugar(),
+		ReportSinks: []ReportSink{
+			&WriterReportSink{Writer: &first},
+			&WriterReportSink{Writer: &second, Format: ReportFormatCSV},
+		},
+	}
+	executor := &GenericExecutor{
+		Execute:              func(ctx context.Context, run *Run) error { return nil },
+		DefaultConfiguration: RunConfiguration{Iterations: 4},
+	}
+	require.NoError(t, executor.Run(context.Background(), info))
+	var result RunResult
+	require.NoError(t, json.Unmarshal(first.Bytes(), &result))
+	require.Equal(t, "report_test", result.ScenarioName)
+	require.Equal(t, 4, result.IterationsStarted)
+	require.Equal(t, 4, result.IterationsCompleted)
+	require.Contains(t, second.String(), "report_test,run,")
+}
+
+func TestGenericExecutorExportsLatencySamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.csv")
+	info := ScenarioInfo{
+		MetricsHandler:     client.MetricsNopHandler,
+		Logger:             zap.NewNop().Sugar(),
+		LatencySamplesPath: path,
+	}
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 8, MaxConcurrent: 3},
+	}
+	runStart := time.Now()
+	require.NoError(t, executor.Run(context.Background(), info))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []string{"iteration", "start_time", "latency_ms", "outcome"}, records[0])
+	require.Len(t, records[1:], 8)
+	seen := map[string]bool{}
+	for _, record := range records[1:] {
+		seen[record[0]] = true
+		startTime, err := time.Parse(time.RFC3339Nano, record[1])
+		require.NoError(t, err)
+		require.False(t, startTime.Before(runStart))
+		latencyMillis, err := strconv.ParseFloat(record[2], 64)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, latencyMillis, 5.0)
+		require.Less(t, latencyMillis, 1000.0)
+		require.Equal(t, "success", record[3])
+	}
+	require.Len(t, seen, 8)
+}
+
+func TestRunMetadata(t *testing.T) {
+	var report bytes.Buffer
+	samplesPath := filepath.Join(t.TempDir(), "samples.csv")
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 2})
+	info.ScenarioOptions = map[string]string{"foo": "bar"}
+	info.ServerAddress = "temporal.example:7233"
+	info.ReportSinks = []ReportSink{&WriterReportSink{Writer: &report}}
+	info.LatencySamplesPath = samplesPath
+	before := time.Now()
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error { return nil }}
+	require.NoError(t, executor.Run(context.Background(), info))
+
+	var result RunResult
+	require.NoError(t, json.Unmarshal(report.Bytes(), &result))
+	metadata := result.Metadata
+	require.NotNil(t, metadata)
+	require.Equal(t, "test", metadata.ScenarioName)
+	require.Equal(t, "test-run", metadata.RunID)
+	require.Equal(t, map[string]string{"foo": "bar"}, metadata.ScenarioOptions)
+	require.Equal(t, 2, metadata.Configuration.Iterations)
+	require.Equal(t, DefaultMaxConcurrent, metadata.Configuration.MaxConcurrent)
+	require.Equal(t, "temporal.example:7233", metadata.ServerAddress)
+	require.Equal(t, "default", metadata.Namespace)
+	require.False(t, metadata.StartTime.Before(before))
+	require.NotNil(t, metadata.EndTime)
+	require.False(t, metadata.EndTime.Before(metadata.StartTime))
+	require.Equal(t, OmesVersion(), metadata.OmesVersion)
+	require.NotEmpty(t, metadata.OmesVersion)
+	require.Contains(t, report.String(), `"configuration": {`)
+	require.Contains(t, report.String(), `"iterations": 2,`)
+
+	samples, err := os.ReadFile(samplesPath)
+	require.NoError(t, err)
+	header, _, _ := bytes.Cut(samples, []byte("\n"))
+	require.True(t, bytes.HasPrefix(header, []byte("# ")))
+	var samplesMetadata RunMetadata
+	require.NoError(t, json.Unmarshal(header[2:], &samplesMetadata))
+	require.Nil(t, samplesMetadata.EndTime)
+	metadata.EndTime = nil
+	require.Equal(t, metadata.StartTime.UnixNano(), samplesMetadata.StartTime.UnixNano())
+	samplesMetadata.StartTime = metadata.StartTime
+	require.Equal(t, *metadata, samplesMetadata)
+}
@@ -0,0 +1,298 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// SourceRunIDOption is the scenario option giving the prior run ID for RerunExecutor.
+const SourceRunIDOption = "source-run-id"
+
+// RerunExecutor re-executes the workflows of a prior run under the current run ID, for regression
+// comparison. The prior run's workflows are found in visibility by RunVisibilityQuery, and each
+// iteration starts one of them again, in order of original start time, with the type, input,
+// header, memo, search attributes, retry policy and timeouts recorded in its first history event,
+// then waits for it to complete. RunIDSearchAttribute is set to the current run ID if tagged.
+// Workflow IDs keep their suffix after the run prefix, and task queues of the prior run are mapped
+// to the current run's task queue keeping any suffix.
+//
+// Limitations:
+//   - Only starts are re-issued. Signals, queries, updates and any other client calls of the prior
+//     run, including kitchen sink client sequences, are not.
+//   - Start parameters are not recoverable once the prior run's histories are removed by
+//     retention, failing the iteration.
+//   - Workflows started by other workflows are skipped since their parents start them again.
+//   - Only the first run of a workflow ID is re-issued, so continue-as-new chains restart from
+//     their original input.
+//   - Workflow IDs in inputs are only rewritten for the current run in JSON-encoded kitchen sink
+//     inputs, where child workflow IDs and the targets of signals and cancels are mapped like the
+//     workflow's own ID. Other inputs still refer to the prior run's workflows.
+type RerunExecutor struct {
+	// Run ID of the prior run. If empty, the source-run-id scenario option is used.
+	SourceRunID string
+	// Default configuration if any. If neither iterations nor duration is set, there is one
+	// iteration per prior workflow. Iterations beyond that cycle through the workflows again with
+	// the cycle number appended to workflow IDs.
+	DefaultConfiguration RunConfiguration
+}
+
+func (e RerunExecutor) Run(ctx context.Context, info ScenarioInfo) error {
+	sourceRunID := e.SourceRunID
+	if sourceRunID == "" {
+		sourceRunID = info.ScenarioOptions[SourceRunIDOption]
+	}
+	if sourceRunID == "" {
+		return fmt.Errorf("source run ID must be specified")
+	}
+	executions, err := info.ListRunWorkflows(ctx, sourceRunID)
+	if err != nil {
+		return err
+	} else if len(executions) == 0 {
+		return fmt.Errorf("no workflows found for run %v", sourceRunID)
+	}
+	info.Logger.Infof("Re-running %v workflows of run %v", len(executions), sourceRunID)
+
+	config := e.DefaultConfiguration
+	if config.Iterations == 0 && config.Duration == 0 {
+		config.Iterations = len(executions)
+	}
+	ge := &GenericExecutor{
+		DefaultConfiguration: config,
+		Execute: func(ctx context.Context, run *Run) error {
+			index := run.Iteration - 1
+			execution := executions[index%len(executions)]
+			return run.rerunWorkflow(ctx, sourceRunID, execution, index/len(executions))
+		},
+	}
+	return ge.Run(ctx, info)
+}
+
+func (e RerunExecutor) GetDefaultConfiguration() RunConfiguration {
+	return e.DefaultConfiguration
+}
+
+// ListRunWorkflows lists the workflows of the given run (matched by RunVisibilityQuery) in
+// visibility, in order of start time. Workflows started by other workflows are excluded, and only
+// the first run of each workflow ID is included.
+func (s *ScenarioInfo) ListRunWorkflows(
+	ctx context.Context,
+	runID string,
+) ([]*workflow.WorkflowExecutionInfo, error) {
+	// The prior run is assumed to have been tagged with the run ID if this one is
+	source := ScenarioInfo{IDPrefix: s.IDPrefix, RunID: runID, ScenarioOptions: s.ScenarioOptions}
+	request := &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace: s.Namespace,
+		Query:     source.RunVisibilityQuery(),
+	}
+	first := map[string]*workflow.WorkflowExecutionInfo{}
+	for {
+		resp, err := s.Client.ListWorkflow(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed listing workflows of run %v: %w", runID, err)
+		}
+		for _, execution := range resp.Executions {
+			if execution.ParentExecution != nil {
+				continue
+			}
+			id := execution.Execution.GetWorkflowId()
+			if prev, ok := first[id]; !ok || startTime(execution).Before(startTime(prev)) {
+				first[id] = execution
+			}
+		}
+		if len(resp.NextPageToken) == 0 {
+			break
+		}
+		request.NextPageToken = resp.NextPageToken
+	}
+	executions := make([]*workflow.WorkflowExecutionInfo, 0, len(first))
+	for _, execution := range first {
+		executions = append(executions, execution)
+	}
+	sort.Slice(executions, func(i, j int) bool {
+		if ti, tj := startTime(executions[i]), startTime(executions[j]); !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return executions[i].Execution.GetWorkflowId() < executions[j].Execution.GetWorkflowId()
+	})
+	return executions, nil
+}
+
+func startTime(execution *workflow.WorkflowExecutionInfo) time.Time {
+	if execution.StartTime == nil {
+		return time.Time{}
+	}
+	return *execution.StartTime
+}
+
+// rerunWorkflow starts the prior run's workflow again from its recorded start parameters and waits
+// for it to complete.
+func (r *Run) rerunWorkflow(
+	ctx context.Context,
+	sourceRunID string,
+	execution *workflow.WorkflowExecutionInfo,
+	cycle int,
+) error {
+	sourceID, sourceRunIDOfExecution := execution.Execution.GetWorkflowId(), execution.Execution.GetRunId()
+	iter := r.Client.GetWorkflowHistory(ctx, sourceID, sourceRunIDOfExecution, false,
+		enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	if !iter.HasNext() {
+		return fmt.Errorf("start parameters of workflow %v not recoverable: no history", sourceID)
+	}
+	event, err := iter.Next()
+	if err != nil {
+		return fmt.Errorf("start parameters of workflow %v not recoverable: %w", sourceID, err)
+	}
+	started := event.GetWorkflowExecutionStartedEventAttributes()
+	if started == nil {
+		return fmt.Errorf("start parameters of workflow %v not recoverable: first event is %v",
+			sourceID, event.GetEventType())
+	}
+
+	source := ScenarioInfo{IDPrefix: r.IDPrefix, RunID: sourceRunID}
+	id := r.WorkflowIDPrefix() + strings.TrimPrefix(sourceID, source.WorkflowIDPrefix())
+	if cycle > 0 {
+		id += fmt.Sprintf("-%v", cycle)
+	}
+	taskQueue := started.GetTaskQueue().GetName()
+	if i := strings.Index(taskQueue, ":"+sourceRunID); i >= 0 {
+		taskQueue = r.TaskQueue() + taskQueue[i+len(sourceRunID)+1:]
+	}
+	input := started.Input
+	if started.GetWorkflowType().GetName() == "kitchenSink" {
+		rerunID := func(workflowID string) string {
+			if rest, ok := strings.CutPrefix(workflowID, sourceID); ok {
+				return id + rest
+			}
+			rerun := strings.ReplaceAll(workflowID, sourceRunID, r.RunID)
+			if rerun != workflowID && cycle > 0 {
+				rerun += fmt.Sprintf("-%v", cycle)
+			}
+			return rerun
+		}
+		if input, err = rerunKitchenSinkInput(input, rerunID); err != nil {
+			return fmt.Errorf("failed rewriting input of workflow %v: %w", sourceID, err)
+		}
+	}
+	searchAttributes := started.SearchAttributes
+	if _, tagged := searchAttributes.GetIndexedFields()[RunIDSearchAttribute]; tagged || r.TagsRunID() {
+		if searchAttributes, err = withRunIDSearchAttribute(searchAttributes, r.RunID); err != nil {
+			return err
+		}
+	}
+	request := &workflowservice.StartWorkflowExecutionRequest{
+		Namespace:                r.Namespace,
+		WorkflowId:               id,
+		WorkflowType:             started.WorkflowType,
+		TaskQueue:                &taskqueue.TaskQueue{Name: taskQueue, Kind: enums.TASK_QUEUE_KIND_NORMAL},
+		Input:                    input,
+		WorkflowExecutionTimeout: started.WorkflowExecutionTimeout,
+		WorkflowRunTimeout:       started.WorkflowRunTimeout,
+		WorkflowTaskTimeout:      started.WorkflowTaskTimeout,
+		RequestId:                id,
+		WorkflowIdReusePolicy:    enums.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+		RetryPolicy:              started.RetryPolicy,
+		Memo:                     started.Memo,
+		SearchAttributes:         searchAttributes,
+		Header:                   started.Header,
+	}
+	resp, err := r.Client.WorkflowService().StartWorkflowExecution(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed re-starting workflow %v as %v: %w", sourceID, id, err)
+	}
+	return r.getWorkflowResult(ctx, r.Client.GetWorkflow(ctx, id, resp.RunId), nil)
+}
+
+// withRunIDSearchAttribute returns a copy of the search attributes with RunIDSearchAttribute set to
+// the run ID.
+func withRunIDSearchAttribute(
+	searchAttributes *common.SearchAttributes,
+	runID string,
+) (*common.SearchAttributes, error) {
+	payload, err := converter.GetDefaultDataConverter().ToPayload(runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding run ID search attribute: %w", err)
+	}
+	fields := make(map[string]*common.Payload, len(searchAttributes.GetIndexedFields())+1)
+	for name, value := range searchAttributes.GetIndexedFields() {
+		fields[name] = value
+	}
+	fields[RunIDSearchAttribute] = payload
+	return &common.SearchAttributes{IndexedFields: fields}, nil
+}
+
+// rerunKitchenSinkInput returns the kitchen sink input with the workflow IDs of children, signals
+// and cancels, also in the inputs of children and continue-as-new, mapped by rerunID. Inputs not
+// encoded as JSON kitchensink.WorkflowInput, e.g. by a custom converter or codec, are returned
+// unchanged.
+func rerunKitchenSinkInput(
+	input *common.Payloads,
+	rerunID func(string) string,
+) (*common.Payloads, error) {
+	if len(input.GetPayloads()) != 1 {
+		return input, nil
+	}
+	payload, err := rerunKitchenSinkPayload(input.Payloads[0], rerunID)
+	if err != nil {
+		return nil, err
+	}
+	return &common.Payloads{Payloads: []*common.Payload{payload}}, nil
+}
+
+func rerunKitchenSinkPayload(payload *common.Payload, rerunID func(string) string) (*common.Payload, error) {
+	var workflowInput kitchensink.WorkflowInput
+	if string(payload.GetMetadata()[converter.MetadataEncoding]) != converter.MetadataEncodingProtoJSON ||
+		string(payload.GetMetadata()[converter.MetadataMessageType]) != string(workflowInput.ProtoReflect().Descriptor().FullName()) {
+		return payload, nil
+	}
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &workflowInput); err != nil {
+		return nil, err
+	}
+	for _, actionSet := range workflowInput.InitialActions {
+		if err := rerunKitchenSinkActions(actionSet, rerunID); err != nil {
+			return nil, err
+		}
+	}
+	return converter.GetDefaultDataConverter().ToPayload(&workflowInput)
+}
+
+func rerunKitchenSinkActions(actionSet *kitchensink.ActionSet, rerunID func(string) string) error {
+	for _, action := range actionSet.GetActions() {
+		var inputs []*common.Payload
+		switch variant := action.Variant.(type) {
+		case *kitchensink.Action_ExecChildWorkflow:
+			if variant.ExecChildWorkflow.WorkflowId != "" {
+				variant.ExecChildWorkflow.WorkflowId = rerunID(variant.ExecChildWorkflow.WorkflowId)
+			}
+			inputs = variant.ExecChildWorkflow.Input
+		case *kitchensink.Action_SendSignal:
+			variant.SendSignal.WorkflowId = rerunID(variant.SendSignal.WorkflowId)
+		case *kitchensink.Action_CancelWorkflow:
+			variant.CancelWorkflow.WorkflowId = rerunID(variant.CancelWorkflow.WorkflowId)
+		case *kitchensink.Action_ContinueAsNew:
+			inputs = variant.ContinueAsNew.Arguments
+		case *kitchensink.Action_NestedActionSet:
+			if err := rerunKitchenSinkActions(variant.NestedActionSet, rerunID); err != nil {
+				return err
+			}
+		}
+		for i, input := range inputs {
+			rerun, err := rerunKitchenSinkPayload(input, rerunID)
+			if err != nil {
+				return err
+			}
+			inputs[i] = rerun
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+func priorExecution(id, runID string, startTime time.Time) *workflow.WorkflowExecutionInfo {
+	return &workflow.WorkflowExecutionInfo{
+		Execution: &common.WorkflowExecution{WorkflowId: id, RunId: runID},
+		StartTime: &startTime,
+	}
+}
+
+// newPriorRunClient returns a client whose visibility holds the workflows of run "prior" over two
+// pages, including a child workflow and a continued-as-new run, and whose histories start with the
+// given input on the prior run's task queue.
+func newPriorRunClient(t *testing.T) *FakeClient {
+	start := time.Now()
+	child := priorExecution("w-prior-1-child", "child-run", start.Add(time.Second))
+	child.ParentExecution = &common.WorkflowExecution{WorkflowId: "w-prior-1"}
+	pages := [][]*workflow.WorkflowExecutionInfo{
+		{priorExecution("w-prior-2", "second", start.Add(time.Second)), child},
+		{priorExecution("w-prior-1", "continued", start.Add(2*time.Second)), priorExecution("w-prior-1", "first", start)},
+	}
+	return &FakeClient{
+		OnListWorkflow: func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (
+			*workflowservice.ListWorkflowExecutionsResponse, error) {
+			require.Equal(t, `WorkflowId STARTS_WITH "w-prior-"`, request.Query)
+			if len(request.NextPageToken) == 0 {
+				return &workflowservice.ListWorkflowExecutionsResponse{Executions: pages[0], NextPageToken: []byte("next")}, nil
+			}
+			return &workflowservice.ListWorkflowExecutionsResponse{Executions: pages[1]}, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			input, err := converter.GetDefaultDataConverter().ToPayloads("input of " + workflowID + "/" + runID)
+			require.NoError(t, err)
+			return []*history.HistoryEvent{{
+				EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED,
+				Attributes: &history.HistoryEvent_WorkflowExecutionStartedEventAttributes{
+					WorkflowExecutionStartedEventAttributes: &history.WorkflowExecutionStartedEventAttributes{
+						WorkflowType: &common.WorkflowType{Name: "priorWorkflow"},
+						TaskQueue:    &taskqueue.TaskQueue{Name: "old_scenario:prior-3"},
+						Input:        input,
+					},
+				},
+			}}, nil
+		},
+	}
+}
+
+func TestListRunWorkflows(t *testing.T) {
+	info := NewTestScenarioInfo(newPriorRunClient(t), RunConfiguration{})
+	executions, err := info.ListRunWorkflows(context.Background(), "prior")
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+	require.Equal(t, "w-prior-1", executions[0].Execution.WorkflowId)
+	require.Equal(t, "first", executions[0].Execution.RunId)
+	require.Equal(t, "w-prior-2", executions[1].Execution.WorkflowId)
+}
+
+func TestRerunExecutor(t *testing.T) {
+	fake := newPriorRunClient(t)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.ScenarioOptions = map[string]string{SourceRunIDOption: "prior"}
+	require.NoError(t, RerunExecutor{}.Run(context.Background(), info))
+
+	calls := fake.Calls("StartWorkflowExecution")
+	sort.Slice(calls, func(i, j int) bool { return calls[i].WorkflowID < calls[j].WorkflowID })
+	require.Len(t, calls, 2)
+	for i, sourceID := range []string{"w-prior-1", "w-prior-2"} {
+		request := calls[i].Args[0].(*workflowservice.StartWorkflowExecutionRequest)
+		require.Equal(t, "priorWorkflow", calls[i].Name)
+		require.Equal(t, "w-test-run-"+sourceID[len("w-prior-"):], request.WorkflowId)
+		require.Equal(t, "test:test-run-3", request.TaskQueue.Name)
+		var input string
+		require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(request.Input, &input))
+		runID := "first"
+		if sourceID == "w-prior-2" {
+			runID = "second"
+		}
+		require.Equal(t, "input of "+sourceID+"/"+runID, input)
+	}
+}
+
+func TestRerunExecutorHistoryNotRecoverable(t *testing.T) {
+	fake := newPriorRunClient(t)
+	fake.OnGetWorkflowHistory = nil
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	err := RerunExecutor{SourceRunID: "prior"}.Run(context.Background(), info)
+	require.ErrorContains(t, err, "not recoverable")
+	require.Empty(t, fake.Calls("StartWorkflowExecution"))
+}
+
+func TestRerunExecutorRewritesKitchenSinkWorkflowIDs(t *testing.T) {
+	fake := newPriorRunClient(t)
+	fake.OnGetWorkflowHistory = func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+		workflowInput, err := kitchensink.FanInWorkflowInput(workflowID, 2, 1)
+		require.NoError(t, err)
+		input, err := converter.GetDefaultDataConverter().ToPayloads(workflowInput)
+		require.NoError(t, err)
+		return []*history.HistoryEvent{{
+			EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED,
+			Attributes: &history.HistoryEvent_WorkflowExecutionStartedEventAttributes{
+				WorkflowExecutionStartedEventAttributes: &history.WorkflowExecutionStartedEventAttributes{
+					WorkflowType: &common.WorkflowType{Name: "kitchenSink"},
+					TaskQueue:    &taskqueue.TaskQueue{Name: "old_scenario:prior"},
+					Input:        input,
+				},
+			},
+		}}, nil
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 3, MaxConcurrent: 1})
+	require.NoError(t, RerunExecutor{SourceRunID: "prior"}.Run(context.Background(), info))
+
+	calls := fake.Calls("StartWorkflowExecution")
+	require.Len(t, calls, 3)
+	// The second cycle re-runs the first workflow again
+	for i, id := range []string{"w-test-run-1", "w-test-run-2", "w-test-run-1-1"} {
+		request := calls[i].Args[0].(*workflowservice.StartWorkflowExecutionRequest)
+		require.Equal(t, id, request.WorkflowId)
+		var workflowInput kitchensink.WorkflowInput
+		require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(request.Input, &workflowInput))
+		// Children are started under the new parent's ID and signal it
+		for j, action := range workflowInput.InitialActions[0].Actions {
+			child := action.GetExecChildWorkflow()
+			require.Equal(t, fmt.Sprintf("%v-child-%v", id, j), child.WorkflowId)
+			var childInput kitchensink.WorkflowInput
+			require.NoError(t, converter.GetDefaultDataConverter().FromPayload(child.Input[0], &childInput))
+			require.Equal(t, id, childInput.InitialActions[0].Actions[0].GetSendSignal().WorkflowId)
+		}
+	}
+}
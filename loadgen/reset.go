@@ -0,0 +1,60 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// ErrInvalidResetPoint is wrapped by the error of ResetAndAwait when the event to reset to is not a
+// valid reset point of the workflow.
+var ErrInvalidResetPoint = errors.New("invalid reset point")
+
+// ResetAndAwait resets the workflow run to the given event, which must be a workflow task
+// completed, failed or timed out event, then waits up to the timeout, if not 0, for the new run to
+// complete, see also Run.ResultTimeout. The time from issuing the reset until the new run completes
+// is returned and recorded in the omes_reset_latency timer.
+func (r *Run) ResetAndAwait(
+	ctx context.Context,
+	workflowID, runID string,
+	eventID int64,
+	timeout time.Duration,
+) (time.Duration, error) {
+	if eventID <= 0 {
+		return 0, fmt.Errorf("%w: event ID %v of workflow %v", ErrInvalidResetPoint, eventID, workflowID)
+	}
+	start := time.Now()
+	resp, err := r.Client.ResetWorkflowExecution(ctx, &workflowservice.ResetWorkflowExecutionRequest{
+		Namespace:                 r.Namespace,
+		WorkflowExecution:         &common.WorkflowExecution{WorkflowId: workflowID, RunId: runID},
+		Reason:                    "omes reset",
+		WorkflowTaskFinishEventId: eventID,
+		RequestId:                 fmt.Sprintf("%v-%v-%v", runID, eventID, start.UnixNano()),
+	})
+	var invalidArgument *serviceerror.InvalidArgument
+	if errors.As(err, &invalidArgument) {
+		return time.Since(start), fmt.Errorf("%w: event ID %v of workflow %v: %v",
+			ErrInvalidResetPoint, eventID, workflowID, err)
+	} else if err != nil {
+		return time.Since(start), fmt.Errorf("failed resetting workflow %v: %w", workflowID, err)
+	}
+	awaitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		awaitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	execution := r.Client.GetWorkflow(awaitCtx, workflowID, resp.RunId)
+	if err := r.getWorkflowResult(awaitCtx, execution, nil); err != nil {
+		return time.Since(start), fmt.Errorf("reset run %v of workflow %v did not complete: %w",
+			resp.RunId, workflowID, err)
+	}
+	elapsed := time.Since(start)
+	r.RecordTimer("omes_reset_latency", nil, elapsed)
+	return elapsed, nil
+}
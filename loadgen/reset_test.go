@@ -0,0 +1,72 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+)
+
+func TestResetAndAwait(t *testing.T) {
+	fake := &FakeClient{
+		OnResetWorkflowExecution: func(ctx context.Context, request *workflowservice.ResetWorkflowExecutionRequest) (
+			client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: request.WorkflowExecution.WorkflowId, RunID: "new-run", Delay: 20 * time.Millisecond}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	elapsed, err := info.NewRun(1).ResetAndAwait(context.Background(), "wf", "old-run", 4, time.Second)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+
+	request := fake.Calls("ResetWorkflowExecution")[0].Args[0].(*workflowservice.ResetWorkflowExecutionRequest)
+	require.Equal(t, "old-run", request.WorkflowExecution.RunId)
+	require.Equal(t, int64(4), request.WorkflowTaskFinishEventId)
+	require.NotEmpty(t, request.RequestId)
+	require.Equal(t, "wf", fake.Calls("GetWorkflow")[0].WorkflowID)
+
+	// No timeout waits without limit
+	_, err = info.NewRun(1).ResetAndAwait(context.Background(), "wf", "old-run", 4, 0)
+	require.NoError(t, err)
+}
+
+func TestResetAndAwaitTimeout(t *testing.T) {
+	fake := &FakeClient{
+		OnResetWorkflowExecution: func(ctx context.Context, request *workflowservice.ResetWorkflowExecutionRequest) (
+			client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: "wf", RunID: "new-run", Delay: time.Minute}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.NewRun(1).ResetAndAwait(context.Background(), "wf", "old-run", 4, 20*time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "new-run")
+}
+
+func TestResetAndAwaitInvalidResetPoint(t *testing.T) {
+	fake := &FakeClient{
+		OnResetWorkflowExecution: func(ctx context.Context, request *workflowservice.ResetWorkflowExecutionRequest) (
+			client.WorkflowRun, error) {
+			return nil, serviceerror.NewInvalidArgument("invalid WorkflowTaskFinishEventId")
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.NewRun(1).ResetAndAwait(context.Background(), "wf", "old-run", 3, time.Second)
+	require.ErrorIs(t, err, ErrInvalidResetPoint)
+
+	_, err = info.NewRun(1).ResetAndAwait(context.Background(), "wf", "old-run", 0, time.Second)
+	require.ErrorIs(t, err, ErrInvalidResetPoint)
+	require.Len(t, fake.Calls("ResetWorkflowExecution"), 1)
+
+	fake.OnResetWorkflowExecution = func(ctx context.Context, request *workflowservice.ResetWorkflowExecutionRequest) (
+		client.WorkflowRun, error) {
+		return nil, serviceerror.NewUnavailable("down")
+	}
+	_, err = info.NewRun(1).ResetAndAwait(context.Background(), "wf", "old-run", 3, time.Second)
+	require.False(t, errors.Is(err, ErrInvalidResetPoint))
+}
@@ -0,0 +1,127 @@
+package loadgen
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// resourceSampleInterval is the interval between samples of the harness's own resource usage.
+var resourceSampleInterval = 5 * time.Second
+
+// minGoroutineGrowthSamples and minGoroutineGrowthDuration are the number of samples and the time
+// they must span for goroutine growth to be told from load ramping up.
+var (
+	minGoroutineGrowthSamples  = 8
+	minGoroutineGrowthDuration = time.Minute
+)
+
+// ResourceUsage summarizes the load generator's own resource usage sampled over a run, to catch
+// harness-side leaks in soak runs.
+type ResourceUsage struct {
+	Samples        int          `json:"samples"`
+	Goroutines     ResourceStat `json:"goroutines"`
+	HeapAllocBytes ResourceStat `json:"heapAllocBytes"`
+	// Whether the goroutine count kept growing over the run, see resourceSampler.
+	GoroutinesGrowing bool `json:"goroutinesGrowing"`
+}
+
+// ResourceStat is the minimum, maximum and final value of a sampled resource.
+type ResourceStat struct {
+	Min   uint64 `json:"min"`
+	Max   uint64 `json:"max"`
+	Final uint64 `json:"final"`
+}
+
+func (s *ResourceStat) add(value uint64, first bool) {
+	if first || value < s.Min {
+		s.Min = value
+	}
+	if value > s.Max {
+		s.Max = value
+	}
+	s.Final = value
+}
+
+// resourceSampler periodically samples the goroutine count and heap size of the process. It is safe
+// for concurrent use.
+type resourceSampler struct {
+	lock       sync.Mutex
+	usage      ResourceUsage
+	goroutines []int
+	// Time of the first sample
+	since   time.Time
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// startResourceSampler takes a sample now and then every interval until stopped.
+func startResourceSampler(interval time.Duration) *resourceSampler {
+	s := &resourceSampler{stop: make(chan struct{}), stopped: make(chan struct{})}
+	s.sample()
+	go func() {
+		defer close(s.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.sample()
+			}
+		}
+	}()
+	return s
+}
+
+func (s *resourceSampler) sample() {
+	goroutines := runtime.NumGoroutine()
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	first := s.usage.Samples == 0
+	if first {
+		s.since = time.Now()
+	}
+	s.usage.Samples++
+	s.usage.Goroutines.add(uint64(goroutines), first)
+	s.usage.HeapAllocBytes.add(memStats.HeapAlloc, first)
+	s.goroutines = append(s.goroutines, goroutines)
+}
+
+// Stop stops sampling, takes a final sample and returns the usage summary.
+func (s *resourceSampler) Stop() ResourceUsage {
+	close(s.stop)
+	<-s.stopped
+	s.sample()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	usage := s.usage
+	usage.GoroutinesGrowing = time.Since(s.since) >= minGoroutineGrowthDuration && goroutinesGrowing(s.goroutines)
+	return usage
+}
+
+// goroutinesGrowing returns whether goroutine counts grow unboundedly rather than leveling off once
+// load ramps up: every count in the second half of the samples exceeds every count in the first,
+// and the final count is at least double the first. Requires at least minGoroutineGrowthSamples
+// samples.
+func goroutinesGrowing(counts []int) bool {
+	if len(counts) < minGoroutineGrowthSamples {
+		return false
+	}
+	half := len(counts) / 2
+	firstMax, secondMin := counts[0], counts[half]
+	for _, count := range counts[:half] {
+		if count > firstMax {
+			firstMax = count
+		}
+	}
+	for _, count := range counts[half:] {
+		if count < secondMin {
+			secondMin = count
+		}
+	}
+	return secondMin > firstMax && counts[len(counts)-1] >= 2*counts[0]
+}
@@ -0,0 +1,73 @@
+package loadgen
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceSamplerDetectsLeakedGoroutines(t *testing.T) {
+	prevSamples, prevDuration := minGoroutineGrowthSamples, minGoroutineGrowthDuration
+	minGoroutineGrowthSamples, minGoroutineGrowthDuration = 4, 0
+	t.Cleanup(func() { minGoroutineGrowthSamples, minGoroutineGrowthDuration = prevSamples, prevDuration })
+	// Long interval so only the explicit samples are taken
+	sampler := startResourceSampler(time.Hour)
+	leak := make(chan struct{})
+	var leaked sync.WaitGroup
+	defer func() {
+		close(leak)
+		leaked.Wait()
+	}()
+	spawn := func(n int) {
+		for i := 0; i < n; i++ {
+			leaked.Add(1)
+			go func() {
+				defer leaked.Done()
+				<-leak
+			}()
+		}
+	}
+	for i := 0; i < 5; i++ {
+		spawn(100 * (i + 1))
+		sampler.sample()
+	}
+	usage := sampler.Stop()
+
+	require.Equal(t, 7, usage.Samples)
+	require.Len(t, sampler.goroutines, 7)
+	for i := 1; i < len(sampler.goroutines)-1; i++ {
+		require.Greater(t, sampler.goroutines[i], sampler.goroutines[i-1])
+	}
+	require.GreaterOrEqual(t, usage.Goroutines.Max, usage.Goroutines.Min+1400)
+	require.GreaterOrEqual(t, usage.Goroutines.Final, usage.Goroutines.Min+1400)
+	require.NotZero(t, usage.HeapAllocBytes.Final)
+	require.True(t, usage.GoroutinesGrowing)
+}
+
+func TestResourceSamplerIgnoresShortRuns(t *testing.T) {
+	sampler := startResourceSampler(time.Hour)
+	leak := make(chan struct{})
+	defer close(leak)
+	for i := 0; i < minGoroutineGrowthSamples; i++ {
+		for j := 0; j < 100*(i+1); j++ {
+			go func() { <-leak }()
+		}
+		sampler.sample()
+	}
+	// Growing over enough samples, but too briefly to tell from ramping up
+	usage := sampler.Stop()
+	require.True(t, goroutinesGrowing(sampler.goroutines))
+	require.False(t, usage.GoroutinesGrowing)
+}
+
+func TestGoroutinesGrowing(t *testing.T) {
+	// Too few samples
+	require.False(t, goroutinesGrowing([]int{10, 20, 30, 40}))
+	require.True(t, goroutinesGrowing([]int{10, 20, 30, 40, 50, 60, 70, 80}))
+	// Ramp up then level off is not a leak
+	require.False(t, goroutinesGrowing([]int{10, 200, 210, 205, 200, 210, 205, 210}))
+	// Growth under double is not a leak
+	require.False(t, goroutinesGrowing([]int{100, 110, 120, 130, 140, 150, 160, 170}))
+}
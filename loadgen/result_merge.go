@@ -0,0 +1,121 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadRunResult reads a RunResult from a JSON report file.
+func ReadRunResult(path string) (*RunResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading run result: %w", err)
+	}
+	var result RunResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed parsing run result %v: %w", path, err)
+	}
+	return &result, nil
+}
+
+// MergeRunResults combines the results of runs of the same scenario by several omes instances into
+// one, summing iteration counts and merging latency histograms so that percentiles are those of all
+// iterations. The combined run spans from the earliest start to the latest end. Phases are merged by
+// index and must match, with the concurrency and rate limits of all instances summed. Metadata and
+// resource usage are per instance and left out.
+func MergeRunResults(results ...*RunResult) (*RunResult, error) {
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no run results to merge")
+	}
+	merged := &RunResult{
+		ScenarioName:     results[0].ScenarioName,
+		StartTime:        results[0].StartTime,
+		EndTime:          results[0].EndTime,
+		LatencyHistogram: NewLatencyHistogram(nil),
+	}
+	var runIDs []string
+	for i, result := range results {
+		if result.ScenarioName != merged.ScenarioName {
+			return nil, fmt.Errorf("cannot merge results of scenarios %v and %v", merged.ScenarioName, result.ScenarioName)
+		} else if len(result.Phases) != len(results[0].Phases) {
+			return nil, fmt.Errorf("cannot merge results with different phases")
+		}
+		if !containsString(runIDs, result.RunID) {
+			runIDs = append(runIDs, result.RunID)
+		}
+		if result.StartTime.Before(merged.StartTime) {
+			merged.StartTime = result.StartTime
+		}
+		if result.EndTime.After(merged.EndTime) {
+			merged.EndTime = result.EndTime
+		}
+		merged.IterationsStarted += result.IterationsStarted
+		merged.IterationsCompleted += result.IterationsCompleted
+		merged.IterationsFailed += result.IterationsFailed
+		merged.IterationsAbandoned += result.IterationsAbandoned
+		// Instances run side by side, so their rates add up
+		merged.SteadyStateThroughput += result.SteadyStateThroughput
+		merged.StoppedAtMaxTotalStarts = merged.StoppedAtMaxTotalStarts || result.StoppedAtMaxTotalStarts
+		if result.IterationsInterrupted != nil {
+			if merged.IterationsInterrupted == nil {
+				merged.IterationsInterrupted = &InterruptionSummary{}
+			}
+			merged.IterationsInterrupted.Deadline += result.IterationsInterrupted.Deadline
+			merged.IterationsInterrupted.Cancel += result.IterationsInterrupted.Cancel
+		}
+		if result.NonDeterminism != nil {
+			if merged.NonDeterminism == nil {
+				merged.NonDeterminism = &NonDeterminismSummary{}
+			}
+			merged.NonDeterminism.Iterations += result.NonDeterminism.Iterations
+			for _, id := range result.NonDeterminism.WorkflowIDs {
+				if len(merged.NonDeterminism.WorkflowIDs) < maxNonDeterministicWorkflowIDs {
+					merged.NonDeterminism.WorkflowIDs = append(merged.NonDeterminism.WorkflowIDs, id)
+				}
+			}
+		}
+		if err := mergeLatencyHistogram(merged.LatencyHistogram, result.LatencyHistogram); err != nil {
+			return nil, fmt.Errorf("cannot merge result %d: %w", i, err)
+		}
+	}
+	merged.RunID = strings.Join(runIDs, ",")
+	merged.Duration = merged.EndTime.Sub(merged.StartTime)
+	merged.Latency = merged.LatencyHistogram.Summary()
+
+	for p, phase := range results[0].Phases {
+		mergedPhase := PhaseResult{
+			Name:             phase.Name,
+			Duration:         phase.Duration,
+			LatencyHistogram: NewLatencyHistogram(nil),
+		}
+		for i, result := range results {
+			other := result.Phases[p]
+			if other.Name != phase.Name {
+				return nil, fmt.Errorf("cannot merge results with different phases")
+			}
+			// Each instance runs the phase at its own limits
+			mergedPhase.MaxConcurrent += other.MaxConcurrent
+			mergedPhase.MaxIterationsPerSecond += other.MaxIterationsPerSecond
+			mergedPhase.IterationsStarted += other.IterationsStarted
+			mergedPhase.IterationsCompleted += other.IterationsCompleted
+			mergedPhase.IterationsFailed += other.IterationsFailed
+			mergedPhase.IterationsAbandoned += other.IterationsAbandoned
+			if err := mergeLatencyHistogram(mergedPhase.LatencyHistogram, other.LatencyHistogram); err != nil {
+				return nil, fmt.Errorf("cannot merge phase %v of result %d: %w", phase.Name, i, err)
+			}
+		}
+		mergedPhase.Latency = mergedPhase.LatencyHistogram.Summary()
+		merged.Phases = append(merged.Phases, mergedPhase)
+	}
+	return merged, nil
+}
+
+func mergeLatencyHistogram(into, histogram *LatencyHistogram) error {
+	if histogram == nil {
+		return fmt.Errorf("no latency histogram, result was written by an older omes")
+	}
+	into.Merge(histogram)
+	return nil
+}
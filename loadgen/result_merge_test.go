@@ -0,0 +1,96 @@
+package loadgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeRunResult writes a JSON report of a run with the given latencies in milliseconds.
+func writeRunResult(t *testing.T, runID string, start time.Time, failed int, latencies ...int) string {
+	stats := &runStats{}
+	for i, ms := range latencies {
+		stats.recordStart(0)
+		var err error
+		if i < failed {
+			err = os.ErrDeadlineExceeded
+		}
+		stats.recordEnd(0, time.Duration(ms)*time.Millisecond, err)
+	}
+	stats.recordStart(0)
+	info := &ScenarioInfo{ScenarioName: "merge_test", RunID: runID}
+	path := filepath.Join(t.TempDir(), runID+".json")
+	sink := &FileReportSink{Path: path, Format: ReportFormatJSON}
+	require.NoError(t, sink.WriteReport(context.Background(), stats.result(info, start, start.Add(time.Minute))))
+	return path
+}
+
+func msRange(from, to int) []int {
+	var values []int
+	for ms := from; ms <= to; ms++ {
+		values = append(values, ms)
+	}
+	return values
+}
+
+func TestMergeRunResults(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first, err := ReadRunResult(writeRunResult(t, "first", start, 3, msRange(1, 100)...))
+	require.NoError(t, err)
+	second, err := ReadRunResult(writeRunResult(t, "second", start.Add(30*time.Second), 0, msRange(101, 200)...))
+	require.NoError(t, err)
+
+	merged, err := MergeRunResults(first, second)
+	require.NoError(t, err)
+	require.Equal(t, "merge_test", merged.ScenarioName)
+	require.Equal(t, "first,second", merged.RunID)
+	require.Equal(t, start, merged.StartTime)
+	require.Equal(t, 90*time.Second, merged.Duration)
+	require.Equal(t, 202, merged.IterationsStarted)
+	require.Equal(t, 197, merged.IterationsCompleted)
+	require.Equal(t, 3, merged.IterationsFailed)
+	require.Equal(t, 2, merged.IterationsAbandoned)
+	require.Equal(t, int64(200), merged.LatencyHistogram.Count())
+
+	// Percentiles are those of all 200 latencies, not of either run, within a bucket's width
+	require.Equal(t, time.Millisecond, merged.Latency.Min)
+	require.Equal(t, 200*time.Millisecond, merged.Latency.Max)
+	require.Equal(t, 100500*time.Microsecond, merged.Latency.Mean)
+	for _, expected := range []struct {
+		actual time.Duration
+		exact  time.Duration
+	}{
+		{merged.Latency.P50, 100 * time.Millisecond},
+		{merged.Latency.P90, 180 * time.Millisecond},
+		{merged.Latency.P99, 198 * time.Millisecond},
+	} {
+		require.GreaterOrEqual(t, expected.actual, expected.exact)
+		require.InEpsilon(t, expected.exact, expected.actual, 0.1)
+	}
+	// Each run alone is far off
+	require.Less(t, first.Latency.P90, 100*time.Millisecond)
+	require.Greater(t, second.Latency.P50, 140*time.Millisecond)
+}
+
+func TestMergeRunResultsMismatch(t *testing.T) {
+	start := time.Now()
+	result, err := ReadRunResult(writeRunResult(t, "first", start, 0, 1, 2, 3))
+	require.NoError(t, err)
+
+	other := *result
+	other.ScenarioName = "other"
+	_, err = MergeRunResults(result, &other)
+	require.ErrorContains(t, err, "cannot merge results of scenarios merge_test and other")
+
+	old := *result
+	old.LatencyHistogram = nil
+	_, err = MergeRunResults(result, &old)
+	require.ErrorContains(t, err, "result was written by an older omes")
+
+	_, err = MergeRunResults()
+	require.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ResultSet collects decoded workflow results across iterations to check them against an expected
+// multiset with Verify, regardless of the order iterations complete in. Results are compared by
+// their JSON encoding, ignoring the order of object keys. Safe for concurrent use.
+type ResultSet struct {
+	lock    sync.Mutex
+	results map[string]int
+}
+
+// ResultCount is a JSON-encoded result and the number of times it occurred.
+type ResultCount struct {
+	Result string
+	Count  int
+}
+
+// ResultMismatchError is returned by ResultSet.Verify when the collected results differ from the
+// expected ones.
+type ResultMismatchError struct {
+	// Expected results not collected as many times as expected.
+	Missing []ResultCount
+	// Results collected more times than expected.
+	Unexpected []ResultCount
+}
+
+func (e *ResultMismatchError) Error() string {
+	format := func(counts []ResultCount) string {
+		var s []string
+		for _, c := range counts {
+			s = append(s, fmt.Sprintf("%v x%v", c.Result, c.Count))
+		}
+		return strings.Join(s, ", ")
+	}
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+format(e.Missing))
+	}
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, "unexpected "+format(e.Unexpected))
+	}
+	return "workflow results do not match expected: " + strings.Join(parts, "; ")
+}
+
+// resultKey returns the canonical JSON encoding of the result, with object keys sorted so that
+// structs and maps with the same fields compare equal.
+func resultKey(result interface{}) (string, error) {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed encoding result %v: %w", result, err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return "", fmt.Errorf("failed decoding result %v: %w", result, err)
+	}
+	if b, err = json.Marshal(generic); err != nil {
+		return "", fmt.Errorf("failed encoding result %v: %w", result, err)
+	}
+	return string(b), nil
+}
+
+// Add collects a result.
+func (s *ResultSet) Add(result interface{}) error {
+	key, err := resultKey(result)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.results == nil {
+		s.results = map[string]int{}
+	}
+	s.results[key]++
+	return nil
+}
+
+// Len returns the number of results collected.
+func (s *ResultSet) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var n int
+	for _, count := range s.results {
+		n += count
+	}
+	return n
+}
+
+// Verify checks the collected results are exactly the expected ones, each occurring as many times
+// as it is expected. Returns a *ResultMismatchError listing the missing and unexpected results
+// otherwise.
+func (s *ResultSet) Verify(expected []interface{}) error {
+	remaining := map[string]int{}
+	for _, result := range expected {
+		key, err := resultKey(result)
+		if err != nil {
+			return err
+		}
+		remaining[key]++
+	}
+	s.lock.Lock()
+	for key, count := range s.results {
+		remaining[key] -= count
+	}
+	s.lock.Unlock()
+	var mismatch ResultMismatchError
+	for key, count := range remaining {
+		if count > 0 {
+			mismatch.Missing = append(mismatch.Missing, ResultCount{Result: key, Count: count})
+		} else if count < 0 {
+			mismatch.Unexpected = append(mismatch.Unexpected, ResultCount{Result: key, Count: -count})
+		}
+	}
+	if len(mismatch.Missing) == 0 && len(mismatch.Unexpected) == 0 {
+		return nil
+	}
+	byResult := func(counts []ResultCount) {
+		sort.Slice(counts, func(i, j int) bool { return counts[i].Result < counts[j].Result })
+	}
+	byResult(mismatch.Missing)
+	byResult(mismatch.Unexpected)
+	return &mismatch
+}
@@ -0,0 +1,67 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+type greeting struct {
+	Name  string
+	Count int
+}
+
+func collectResults(t *testing.T, results ...interface{}) *ResultSet {
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: options.ID, Result: args[0]}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	var set ResultSet
+	// Complete in reverse order
+	for i := len(results) - 1; i >= 0; i-- {
+		run := info.NewRun(i + 1)
+		var result greeting
+		require.NoError(t, run.ExecuteAnyWorkflow(context.Background(), run.DefaultStartWorkflowOptions(), "wf", &result, results[i]))
+		require.NoError(t, set.Add(result))
+	}
+	return &set
+}
+
+func TestResultSetMatches(t *testing.T) {
+	set := collectResults(t, greeting{"a", 1}, greeting{"b", 2}, greeting{"a", 1})
+	require.Equal(t, 3, set.Len())
+	require.NoError(t, set.Verify([]interface{}{greeting{"a", 1}, greeting{"a", 1}, greeting{"b", 2}}))
+	// Pointers and equivalent maps compare by their encoding
+	require.NoError(t, set.Verify([]interface{}{
+		&greeting{"b", 2}, map[string]interface{}{"Name": "a", "Count": 1}, greeting{"a", 1},
+	}))
+}
+
+func TestResultSetMissing(t *testing.T) {
+	set := collectResults(t, greeting{"a", 1})
+	err := set.Verify([]interface{}{greeting{"a", 1}, greeting{"a", 1}, greeting{"b", 2}})
+	var mismatch *ResultMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	require.Equal(t, []ResultCount{
+		{Result: `{"Count":1,"Name":"a"}`, Count: 1},
+		{Result: `{"Count":2,"Name":"b"}`, Count: 1},
+	}, mismatch.Missing)
+	require.Empty(t, mismatch.Unexpected)
+	require.ErrorContains(t, err, `missing {"Count":1,"Name":"a"} x1`)
+}
+
+func TestResultSetUnexpected(t *testing.T) {
+	set := collectResults(t, greeting{"a", 1}, greeting{"c", 3}, greeting{"c", 3})
+	err := set.Verify([]interface{}{greeting{"a", 1}, greeting{"b", 2}})
+	var mismatch *ResultMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	require.Equal(t, []ResultCount{{Result: `{"Count":2,"Name":"b"}`, Count: 1}}, mismatch.Missing)
+	require.Equal(t, []ResultCount{{Result: `{"Count":3,"Name":"c"}`, Count: 2}}, mismatch.Unexpected)
+	require.ErrorContains(t, err, `unexpected {"Count":3,"Name":"c"} x2`)
+}
@@ -0,0 +1,62 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/operatorservice/v1"
+)
+
+// RunIDSearchAttribute is the Keyword search attribute workflows are tagged with the run ID in if
+// the TagRunIDOption scenario option is set.
+const RunIDSearchAttribute = "OmesRunId"
+
+// TagRunIDOption is the boolean scenario option making default start options set
+// RunIDSearchAttribute, so that the run's workflows are found by run ID instead of by workflow ID
+// prefix.
+const TagRunIDOption = "tag-run-id"
+
+// TagsRunID returns whether the run's workflows are tagged with RunIDSearchAttribute.
+func (s *ScenarioInfo) TagsRunID() bool {
+	tag, _ := strconv.ParseBool(s.ScenarioOptions[TagRunIDOption])
+	return tag
+}
+
+// RunVisibilityQuery returns the visibility query matching the run's workflows: by
+// RunIDSearchAttribute if they are tagged, by workflow ID prefix otherwise.
+func (s *ScenarioInfo) RunVisibilityQuery() string {
+	if s.TagsRunID() {
+		return fmt.Sprintf("%v = %q", RunIDSearchAttribute, s.RunID)
+	}
+	return fmt.Sprintf("WorkflowId STARTS_WITH %q", s.WorkflowIDPrefix())
+}
+
+// CheckRunIDSearchAttribute fails unless RunIDSearchAttribute is registered as a Keyword search
+// attribute in the namespace.
+func (s *ScenarioInfo) CheckRunIDSearchAttribute(ctx context.Context) error {
+	registered, err := RunIDSearchAttributeRegistered(ctx, s.Client.OperatorService(), s.Namespace)
+	if err != nil {
+		return err
+	} else if !registered {
+		return fmt.Errorf("%v requires the %v Keyword search attribute, register it with: "+
+			"temporal operator search-attribute create --namespace %v --name %v --type Keyword",
+			TagRunIDOption, RunIDSearchAttribute, s.Namespace, RunIDSearchAttribute)
+	}
+	return nil
+}
+
+// RunIDSearchAttributeRegistered returns whether RunIDSearchAttribute is registered as a Keyword
+// search attribute in the namespace.
+func RunIDSearchAttributeRegistered(
+	ctx context.Context,
+	operator operatorservice.OperatorServiceClient,
+	namespace string,
+) (bool, error) {
+	resp, err := operator.ListSearchAttributes(ctx, &operatorservice.ListSearchAttributesRequest{Namespace: namespace})
+	if err != nil {
+		return false, fmt.Errorf("failed listing search attributes: %w", err)
+	}
+	return resp.GetCustomAttributes()[RunIDSearchAttribute] == enums.INDEXED_VALUE_TYPE_KEYWORD, nil
+}
@@ -0,0 +1,100 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/operatorservice/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+func registeredSearchAttributes(attributes map[string]enums.IndexedValueType) func(
+	context.Context, *operatorservice.ListSearchAttributesRequest) (*operatorservice.ListSearchAttributesResponse, error) {
+	return func(ctx context.Context, request *operatorservice.ListSearchAttributesRequest) (
+		*operatorservice.ListSearchAttributesResponse, error) {
+		return &operatorservice.ListSearchAttributesResponse{CustomAttributes: attributes}, nil
+	}
+}
+
+func TestRunIDSearchAttributeUntagged(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	require.False(t, info.TagsRunID())
+	require.Nil(t, info.NewRun(1).DefaultStartWorkflowOptions().SearchAttributes)
+	require.Equal(t, `WorkflowId STARTS_WITH "w-test-run-"`, info.RunVisibilityQuery())
+}
+
+func TestRunIDSearchAttributeTagsStartsAndQueries(t *testing.T) {
+	useFastVisibilityPolling(t)
+	var queries []string
+	fake := &FakeClient{
+		OnListSearchAttributes: registeredSearchAttributes(map[string]enums.IndexedValueType{
+			RunIDSearchAttribute: enums.INDEXED_VALUE_TYPE_KEYWORD,
+		}),
+		OnListWorkflow: func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (
+			*workflowservice.ListWorkflowExecutionsResponse, error) {
+			queries = append(queries, request.Query)
+			return &workflowservice.ListWorkflowExecutionsResponse{}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 2})
+	info.ScenarioOptions = map[string]string{TagRunIDOption: "true"}
+	require.Equal(t, `OmesRunId = "test-run"`, info.RunVisibilityQuery())
+
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+		return run.ExecuteAnyWorkflow(ctx, run.StartWorkflowOptions(), "noop", nil)
+	}}
+	require.NoError(t, executor.Run(context.Background(), info))
+	starts := fake.Calls("ExecuteWorkflow")
+	require.Len(t, starts, 2)
+	for _, start := range starts {
+		require.Equal(t, map[string]interface{}{RunIDSearchAttribute: "test-run"}, start.Options.SearchAttributes)
+	}
+	require.Len(t, fake.Calls("ListSearchAttributes"), 1)
+
+	require.NoError(t, info.NewRun(0).AssertNoRunningWorkflows(context.Background(), time.Second))
+	require.Equal(t, []string{`OmesRunId = "test-run" AND ExecutionStatus = "Running"`}, queries)
+}
+
+func TestRunIDSearchAttributePreflight(t *testing.T) {
+	for _, attributes := range []map[string]enums.IndexedValueType{
+		nil,
+		{RunIDSearchAttribute: enums.INDEXED_VALUE_TYPE_TEXT},
+	} {
+		fake := &FakeClient{OnListSearchAttributes: registeredSearchAttributes(attributes)}
+		info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 1})
+		info.ScenarioOptions = map[string]string{TagRunIDOption: "true"}
+		executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error { return nil }}
+		err := executor.Run(context.Background(), info)
+		require.ErrorContains(t, err, "tag-run-id requires the OmesRunId Keyword search attribute")
+		require.Empty(t, fake.Calls("ExecuteWorkflow"))
+	}
+}
+
+func TestWithRunIDSearchAttribute(t *testing.T) {
+	keyword, err := converter.GetDefaultDataConverter().ToPayload("kept")
+	require.NoError(t, err)
+	prior, err := converter.GetDefaultDataConverter().ToPayload("prior-run")
+	require.NoError(t, err)
+	original := &common.SearchAttributes{IndexedFields: map[string]*common.Payload{
+		"CustomKeyword": keyword, RunIDSearchAttribute: prior,
+	}}
+
+	retagged, err := withRunIDSearchAttribute(original, "new-run")
+	require.NoError(t, err)
+	require.Len(t, retagged.IndexedFields, 2)
+	require.Same(t, keyword, retagged.IndexedFields["CustomKeyword"])
+	var runID string
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayload(retagged.IndexedFields[RunIDSearchAttribute], &runID))
+	require.Equal(t, "new-run", runID)
+	// The original is unchanged
+	require.Same(t, prior, original.IndexedFields[RunIDSearchAttribute])
+
+	tagged, err := withRunIDSearchAttribute(nil, "new-run")
+	require.NoError(t, err)
+	require.Len(t, tagged.IndexedFields, 1)
+}
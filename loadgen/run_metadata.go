@@ -0,0 +1,66 @@
+package loadgen
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// RunMetadata is the reproducibility context of a run, captured at run start. It is included in the
+// JSON report and as a header line of exported latency samples.
+type RunMetadata struct {
+	ScenarioName    string            `json:"scenarioName"`
+	RunID           string            `json:"runId"`
+	ScenarioOptions map[string]string `json:"scenarioOptions,omitempty"`
+	// Effective run configuration, after applying defaults.
+	Configuration RunConfiguration `json:"configuration"`
+	ServerAddress string           `json:"serverAddress,omitempty"`
+	Namespace     string           `json:"namespace,omitempty"`
+	StartTime     time.Time        `json:"startTime"`
+	// Set once the run ends, so not in the latency samples header.
+	EndTime     *time.Time `json:"endTime,omitempty"`
+	OmesVersion string     `json:"omesVersion"`
+}
+
+// newRunMetadata captures the metadata of a run starting now with the given effective configuration.
+func (s *ScenarioInfo) newRunMetadata(config RunConfiguration) RunMetadata {
+	return RunMetadata{
+		ScenarioName:    s.ScenarioName,
+		RunID:           s.RunID,
+		ScenarioOptions: s.ScenarioOptions,
+		Configuration:   config,
+		ServerAddress:   s.ServerAddress,
+		Namespace:       s.Namespace,
+		StartTime:       time.Now(),
+		OmesVersion:     OmesVersion(),
+	}
+}
+
+// OmesVersion returns the module version of the running omes binary, or its VCS revision (suffixed
+// with "-dirty" if modified) when built from a source checkout. Returns "unknown" if there is no
+// build info.
+func OmesVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	if version != "" && version != "(devel)" {
+		return version
+	}
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+	if revision == "" {
+		return "(devel)"
+	}
+	if modified == "true" {
+		revision += "-dirty"
+	}
+	return revision
+}
@@ -0,0 +1,323 @@
+package loadgen
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RunResult is the end-of-run report of a scenario run executed by a [GenericExecutor].
+type RunResult struct {
+	ScenarioName string    `json:"scenarioName"`
+	RunID        string    `json:"runId"`
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+	// Wall clock duration of the run.
+	Duration time.Duration `json:"duration"`
+	// Whether the run aborted early, e.g. on an iteration failure, in which case the result has the
+	// stats accumulated up to then. Not included in the CSV form.
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Error the run aborted with, if Incomplete. Not included in the CSV form.
+	Error string `json:"error,omitempty"`
+	// Number of iterations that were launched.
+	IterationsStarted int `json:"iterationsStarted"`
+	// Number of iterations that completed successfully.
+	IterationsCompleted int `json:"iterationsCompleted"`
+	// Number of iterations that returned an error.
+	IterationsFailed int `json:"iterationsFailed"`
+	// Number of iterations still running when the run ended, e.g. after the grace period of a
+	// duration-limited run.
+	IterationsAbandoned int `json:"iterationsAbandoned"`
+	// Abandoned iterations by what interrupted them: the deadline of a duration-limited run after
+	// its grace period, or canceling the run, e.g. by SIGINT. Not included in the CSV form.
+	IterationsInterrupted *InterruptionSummary `json:"iterationsInterrupted,omitempty"`
+	// Whether the run stopped starting iterations early because it reached
+	// RunConfiguration.MaxTotalStarts. Not included in the CSV form.
+	StoppedAtMaxTotalStarts bool `json:"stoppedAtMaxTotalStarts,omitempty"`
+	// Iterations that failed with a workflow non-determinism error, if any. Not included in the
+	// CSV form.
+	NonDeterminism *NonDeterminismSummary `json:"nonDeterminism,omitempty"`
+	// Completed iterations per second over the run excluding ramp-up and drain, i.e. over the
+	// middle 80% of completions. 0 with fewer than 2 completions. Not included in the CSV form.
+	SteadyStateThroughput float64 `json:"steadyStateThroughput"`
+	// Latency of completed (successful or failed) iterations.
+	Latency LatencySummary `json:"latency"`
+	// Histogram of the latencies of Latency, for combining results with MergeRunResults. Not
+	// included in the CSV form.
+	LatencyHistogram *LatencyHistogram `json:"latencyHistogram,omitempty"`
+	// Per-phase breakdown for phased runs, in phase order. Not included in the CSV form.
+	Phases []PhaseResult `json:"phases,omitempty"`
+	// Reproducibility context of the run. Not included in the CSV form.
+	Metadata *RunMetadata `json:"metadata,omitempty"`
+	// Resource usage of the load generator itself during the run. Not included in the CSV form.
+	ResourceUsage *ResourceUsage `json:"resourceUsage,omitempty"`
+	// Server-side stats of the run's task queues over the run, see
+	// RunConfiguration.TaskQueueStatsInterval. Not included in the CSV form.
+	TaskQueueStats []TaskQueueStatsSnapshot `json:"taskQueueStats,omitempty"`
+	// Summary of the scrapes of the workers' metrics endpoint, see RunConfiguration.WorkerMetricsURL.
+	// Not included in the CSV form.
+	WorkerMetrics *WorkerMetricsSummary `json:"workerMetrics,omitempty"`
+	// Per-endpoint breakdown for runs distributing iterations across ScenarioInfo.Endpoints, in
+	// endpoint order. Not included in the CSV form.
+	Endpoints []EndpointResult `json:"endpoints,omitempty"`
+	// Operations of sessions for SessionExecutor runs, whose iterations are sessions. Not included
+	// in the CSV form.
+	Sessions *SessionSummary `json:"sessions,omitempty"`
+}
+
+// PhaseResult contains the configuration and iteration outcomes of a single phase of a run.
+// Iterations are attributed to the phase in which they started.
+type PhaseResult struct {
+	Name                   string            `json:"name"`
+	Duration               time.Duration     `json:"duration"`
+	MaxConcurrent          int               `json:"maxConcurrent"`
+	MaxIterationsPerSecond float64           `json:"maxIterationsPerSecond,omitempty"`
+	IterationsStarted      int               `json:"iterationsStarted"`
+	IterationsCompleted    int               `json:"iterationsCompleted"`
+	IterationsFailed       int               `json:"iterationsFailed"`
+	IterationsAbandoned    int               `json:"iterationsAbandoned"`
+	Latency                LatencySummary    `json:"latency"`
+	LatencyHistogram       *LatencyHistogram `json:"latencyHistogram,omitempty"`
+}
+
+// LatencySummary contains summary statistics of a set of latency samples.
+type LatencySummary struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+// NewLatencySummary computes summary statistics for the given samples. The samples slice is
+// sorted in place.
+func NewLatencySummary(samples []time.Duration) LatencySummary {
+	if len(samples) == 0 {
+		return LatencySummary{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var total time.Duration
+	for _, s := range samples {
+		total += s
+	}
+	percentile := func(p float64) time.Duration {
+		return samples[int(p*float64(len(samples)-1))]
+	}
+	return LatencySummary{
+		Min:  samples[0],
+		Mean: total / time.Duration(len(samples)),
+		P50:  percentile(0.5),
+		P90:  percentile(0.9),
+		P99:  percentile(0.99),
+		Max:  samples[len(samples)-1],
+	}
+}
+
+// csvHeader returns the column names for the CSV form of the result.
+func (r *RunResult) csvHeader() []string {
+	return []string{
+		"scenario", "run_id", "start_time", "end_time", "duration_ms",
+		"iterations_started", "iterations_completed", "iterations_failed", "iterations_abandoned",
+		"latency_min_ms", "latency_mean_ms", "latency_p50_ms", "latency_p90_ms", "latency_p99_ms",
+		"latency_max_ms",
+	}
+}
+
+// csvRecord returns the values for the CSV form of the result, matching csvHeader.
+func (r *RunResult) csvRecord() []string {
+	return []string{
+		r.ScenarioName, r.RunID, r.StartTime.Format(time.RFC3339Nano), r.EndTime.Format(time.RFC3339Nano),
+		formatMillis(r.Duration),
+		strconv.Itoa(r.IterationsStarted), strconv.Itoa(r.IterationsCompleted), strconv.Itoa(r.IterationsFailed),
+		strconv.Itoa(r.IterationsAbandoned),
+		formatMillis(r.Latency.Min), formatMillis(r.Latency.Mean), formatMillis(r.Latency.P50),
+		formatMillis(r.Latency.P90), formatMillis(r.Latency.P99), formatMillis(r.Latency.Max),
+	}
+}
+
+func formatMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// iterationStats contains iteration outcomes.
+type iterationStats struct {
+	started   int
+	completed int
+	failed    int
+	latencies []time.Duration
+}
+
+func (s *iterationStats) recordEnd(latency time.Duration, err error) {
+	if err != nil {
+		s.failed++
+	} else {
+		s.completed++
+	}
+	s.latencies = append(s.latencies, latency)
+}
+
+// abandoned returns the number of started iterations that did not end.
+func (s *iterationStats) abandoned() int {
+	return s.started - s.completed - s.failed
+}
+
+func (s *iterationStats) latencySummary() LatencySummary {
+	latencies := make([]time.Duration, len(s.latencies))
+	copy(latencies, s.latencies)
+	return NewLatencySummary(latencies)
+}
+
+// runStats accumulates iteration outcomes during a run, overall and per phase if phases are
+// tracked. It is safe for concurrent use.
+type runStats struct {
+	sync.Mutex
+	iterationStats
+	phases         []iterationStats
+	nonDeterminism NonDeterminismSummary
+	// Times of successful completions, for the steady-state throughput.
+	completions []time.Time
+	// Per-endpoint stats by address, if iterations are distributed across endpoints.
+	endpoints map[string]*iterationStats
+}
+
+// trackPhases enables per-phase stats for the given number of phases.
+func (s *runStats) trackPhases(count int) {
+	s.Lock()
+	defer s.Unlock()
+	s.phases = make([]iterationStats, count)
+}
+
+func (s *runStats) recordStart(phase int) {
+	s.Lock()
+	defer s.Unlock()
+	s.started++
+	if phase < len(s.phases) {
+		s.phases[phase].started++
+	}
+}
+
+func (s *runStats) recordEnd(phase int, latency time.Duration, err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.iterationStats.recordEnd(latency, err)
+	if err == nil {
+		s.completions = append(s.completions, time.Now())
+	}
+	if phase < len(s.phases) {
+		s.phases[phase].recordEnd(latency, err)
+	}
+	if workflowID, ok := DetectNonDeterminism(err); ok {
+		s.nonDeterminism.record(workflowID)
+	}
+}
+
+// recordEndpointStart records the start of an iteration on the endpoint with the given address.
+func (s *runStats) recordEndpointStart(address string) {
+	s.Lock()
+	defer s.Unlock()
+	if s.endpoints == nil {
+		s.endpoints = map[string]*iterationStats{}
+	}
+	if s.endpoints[address] == nil {
+		s.endpoints[address] = &iterationStats{}
+	}
+	s.endpoints[address].started++
+}
+
+// recordEndpointEnd records the end of an iteration started with recordEndpointStart.
+func (s *runStats) recordEndpointEnd(address string, latency time.Duration, err error) {
+	s.Lock()
+	defer s.Unlock()
+	if stats := s.endpoints[address]; stats != nil {
+		stats.recordEnd(latency, err)
+	}
+}
+
+// recentLatencyWindow is the number of latest iterations recentLatencyAverage averages.
+const recentLatencyWindow = 20
+
+// recentLatencyAverage returns the average latency of the latest completed iterations, or 0 if
+// none have completed.
+func (s *runStats) recentLatencyAverage() time.Duration {
+	s.Lock()
+	defer s.Unlock()
+	recent := s.latencies
+	if len(recent) > recentLatencyWindow {
+		recent = recent[len(recent)-recentLatencyWindow:]
+	}
+	if len(recent) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, latency := range recent {
+		total += latency
+	}
+	return total / time.Duration(len(recent))
+}
+
+// result builds a RunResult from the accumulated stats. Phase results only contain iteration
+// outcomes, the caller fills in the phase configuration.
+func (s *runStats) result(info *ScenarioInfo, startTime, endTime time.Time) *RunResult {
+	s.Lock()
+	defer s.Unlock()
+	result := &RunResult{
+		ScenarioName:          info.ScenarioName,
+		RunID:                 info.RunID,
+		StartTime:             startTime,
+		EndTime:               endTime,
+		Duration:              endTime.Sub(startTime),
+		IterationsStarted:     s.started,
+		IterationsCompleted:   s.completed,
+		IterationsFailed:      s.failed,
+		IterationsAbandoned:   s.abandoned(),
+		SteadyStateThroughput: steadyStateThroughput(s.completions),
+		Latency:               s.latencySummary(),
+		LatencyHistogram:      NewLatencyHistogram(s.latencies),
+	}
+	if s.nonDeterminism.Iterations > 0 {
+		nonDeterminism := s.nonDeterminism
+		nonDeterminism.WorkflowIDs = append([]string(nil), s.nonDeterminism.WorkflowIDs...)
+		result.NonDeterminism = &nonDeterminism
+	}
+	for _, endpoint := range info.Endpoints {
+		if stats := s.endpoints[endpoint.Address]; stats != nil {
+			result.Endpoints = append(result.Endpoints, EndpointResult{
+				Address:             endpoint.Address,
+				IterationsStarted:   stats.started,
+				IterationsCompleted: stats.completed,
+				IterationsFailed:    stats.failed,
+				Latency:             stats.latencySummary(),
+			})
+		}
+	}
+	for _, phase := range s.phases {
+		result.Phases = append(result.Phases, PhaseResult{
+			IterationsStarted:   phase.started,
+			IterationsCompleted: phase.completed,
+			IterationsFailed:    phase.failed,
+			IterationsAbandoned: phase.abandoned(),
+			Latency:             phase.latencySummary(),
+			LatencyHistogram:    NewLatencyHistogram(phase.latencies),
+		})
+	}
+	return result
+}
+
+// steadyStateThroughput returns the rate of the completions at the given times between the 10th
+// and 90th percentile of completion, leaving out ramp-up and drain, or 0 if undefined.
+func steadyStateThroughput(completions []time.Time) float64 {
+	if len(completions) < 2 {
+		return 0
+	}
+	sorted := make([]time.Time, len(completions))
+	copy(sorted, completions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	first, last := len(sorted)/10, len(sorted)-1-len(sorted)/10
+	elapsed := sorted[last].Sub(sorted[first])
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(last-first) / elapsed.Seconds()
+}
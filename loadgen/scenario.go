@@ -2,9 +2,12 @@ package loadgen
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.temporal.io/api/enums/v1"
 	"go.temporal.io/api/operatorservice/v1"
+	"hash/fnv"
+	"math/rand"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -13,7 +16,10 @@ import (
 
 	"github.com/temporalio/omes/loadgen/kitchensink"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 type Scenario struct {
@@ -39,6 +45,37 @@ type HasDefaultConfiguration interface {
 	GetDefaultConfiguration() RunConfiguration
 }
 
+// HasClientInterceptors is an interface executors can implement to have gRPC interceptors, e.g.
+// for header injection, latency measurement or fault injection, installed on the client the
+// scenario runs with. Interceptors are applied in the returned order with the first outermost.
+type HasClientInterceptors interface {
+	GetClientInterceptors() []grpc.UnaryClientInterceptor
+}
+
+// HasSDKMetricsCapture is an interface executors can implement to have the metrics the SDK emits
+// through the scenario's client captured in ScenarioInfo.SDKMetrics, e.g. to assert on request
+// failure counts after the run.
+type HasSDKMetricsCapture interface {
+	GetCaptureSDKMetrics() bool
+}
+
+// HasPayloadCodecs is an interface executors can implement to have payloads of the scenario's client,
+// e.g. workflow start arguments and results, encoded with codecs after conversion, e.g. to test
+// encryption codecs. Codecs are applied in the returned order when encoding. Workers must be
+// configured with the same codecs, which omes workers are not.
+type HasPayloadCodecs interface {
+	GetPayloadCodecs() []converter.PayloadCodec
+}
+
+// HasDataConverter is an interface executors can implement to have values of the scenario's client,
+// e.g. workflow start arguments and results, converted to payloads by a custom data converter, e.g.
+// to benchmark custom serialization. Payloads are encoded with any HasPayloadCodecs codecs after
+// conversion. A nil converter keeps the default. Workers must be configured with the same
+// converter, which omes workers are not.
+type HasDataConverter interface {
+	GetDataConverter() converter.DataConverter
+}
+
 var registeredScenarios = make(map[string]*Scenario)
 
 // MustRegisterScenario registers a scenario in the global static registry.
@@ -91,10 +128,52 @@ type ScenarioInfo struct {
 	ScenarioOptions map[string]string
 	// The namespace that was used when connecting the client.
 	Namespace string
+	// Address of the server the client is connected to, for run metadata.
+	ServerAddress string
 	// Path to the root of the omes dir
 	RootPath string
+	// Sinks the end-of-run report is written to, if any.
+	ReportSinks []ReportSink
+	// Path of a CSV file to stream every completed iteration's latency to, if set.
+	LatencySamplesPath string
+	// Path of a file to stream every completed iteration's segment breakdown to in folded stack
+	// format, for flame graphs, if set. See Run.BeginSegment.
+	SegmentBreakdownPath string
+	// Path of a file to append every failed iteration's IterationErrorRecord to as a JSON line, for
+	// triaging rare failures of large runs, if set.
+	ErrorLogPath string
+	// Prefix of workflow IDs, followed by the run ID and iteration, to tell apart workflows of
+	// different tools sharing a namespace. Default is DefaultIDPrefix.
+	IDPrefix string
+	// Tracer of GenericExecutor iterations, if any.
+	Tracer IterationTracer
+	// Client for Nexus operations of Run.ExecuteNexusOperation, if any.
+	NexusClient NexusClient
+	// Data converter of Client, for payloads sent outside it, e.g. through the workflow service. The
+	// default data converter if nil.
+	DataConverter converter.DataConverter
+	// Metrics emitted by the SDK through Client, captured if the executor implements
+	// HasSDKMetricsCapture, nil otherwise.
+	SDKMetrics *CapturingMetricsHandler
+	// Endpoints iterations are distributed across round-robin by iteration, if any: Run.Client and
+	// Run.ServerAddress of each iteration are those of its endpoint, while Client remains for calls
+	// outside iterations. Workflow IDs are unaffected, so remain unique across endpoints.
+	// GenericExecutor reports per-endpoint iteration latency in RunResult.Endpoints and the
+	// omes_endpoint_iteration_latency timer tagged with the endpoint address.
+	Endpoints []Endpoint
+}
+
+// dataConverter returns DataConverter, the default data converter if not set.
+func (s *ScenarioInfo) dataConverter() converter.DataConverter {
+	if s.DataConverter == nil {
+		return converter.GetDefaultDataConverter()
+	}
+	return s.DataConverter
 }
 
+// DefaultIDPrefix is the default ScenarioInfo.IDPrefix.
+const DefaultIDPrefix = "w"
+
 func (s *ScenarioInfo) ScenarioOptionInt(name string, defaultValue int) int {
 	v := s.ScenarioOptions[name]
 	if v == "" {
@@ -107,27 +186,291 @@ func (s *ScenarioInfo) ScenarioOptionInt(name string, defaultValue int) int {
 	return i
 }
 
+// Seed returns the seed for randomness in this run, taken from the "seed" scenario option if set,
+// otherwise derived from the run ID so that a run is reproducible.
+func (s *ScenarioInfo) Seed() int64 {
+	if v := s.ScenarioOptions["seed"]; v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			panic(err)
+		}
+		return seed
+	}
+	h := fnv.New64a()
+	h.Write([]byte(s.RunID))
+	return int64(h.Sum64())
+}
+
+// ScenarioOptionDuration gets the named scenario option parsed as a duration, or the default value
+// if the option is not set. Panics if the option cannot be parsed.
+func (s *ScenarioInfo) ScenarioOptionDuration(name string, defaultValue time.Duration) time.Duration {
+	v := s.ScenarioOptions[name]
+	if v == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
 const DefaultIterations = 10
 const DefaultMaxConcurrent = 10
 
+const DefaultGracePeriod = 30 * time.Second
+
+const DefaultIterationRetries = 3
+
 type RunConfiguration struct {
 	// Number of iterations to run of this scenario (mutually exclusive with Duration).
-	Iterations int
+	Iterations int `json:"iterations,omitempty"`
 	// Duration limit of this scenario (mutually exclusive with Iterations). If
 	// neither iterations nor duration is set, default is DefaultIterations.
-	Duration time.Duration
+	Duration time.Duration `json:"duration,omitempty"`
 	// Maximum number of instances of the Execute method to run concurrently.
 	// Default is DefaultMaxConcurrent.
-	MaxConcurrent int
+	MaxConcurrent int `json:"maxConcurrent,omitempty"`
+	// Maximum time to wait for a workflow result in the Run execute helpers, independent of the run
+	// context. Can be overridden with the "result-timeout" scenario option. Default is no limit.
+	ResultTimeout time.Duration `json:"resultTimeout,omitempty"`
+	// How long to wait for in-flight iterations after the duration of a duration-limited run
+	// elapses before abandoning them. Default is DefaultGracePeriod, negative for none.
+	GracePeriod time.Duration `json:"gracePeriod,omitempty"`
+	// Maximum number of iterations to start per second. Default is no limit.
+	MaxIterationsPerSecond float64 `json:"maxIterationsPerSecond,omitempty"`
+	// Do not start iterations that are estimated to end after the run's duration limit by more
+	// than DeadlineTolerance. The estimate is the average latency of recent iterations. Only
+	// applies to duration-limited (including phased) runs.
+	SkipLateIterations bool `json:"skipLateIterations,omitempty"`
+	// Tolerance for SkipLateIterations.
+	DeadlineTolerance time.Duration `json:"deadlineTolerance,omitempty"`
+	// Dispatch iterations in a shuffled order, a permutation seeded by ScenarioInfo.Seed so that it
+	// is reproducible. Only applies to iteration-limited runs.
+	ShuffleIterations bool `json:"shuffleIterations,omitempty"`
+	// Ordered phases to run in sequence (mutually exclusive with Iterations and Duration). Each
+	// phase starts iterations for its duration with its own concurrency and rate. Iterations still
+	// running at the end of a phase carry over into the next one.
+	Phases []RunPhase `json:"phases,omitempty"`
+	// How often to log progress, including the throughput of completed iterations over the last
+	// ThroughputWindow. Default is no progress logging.
+	ProgressInterval time.Duration `json:"progressInterval,omitempty"`
+	// Window of the throughput logged with progress. Default is DefaultThroughputWindow.
+	ThroughputWindow time.Duration `json:"throughputWindow,omitempty"`
+	// Also set the omes_throughput gauge to the windowed throughput when logging progress.
+	ThroughputGauge bool `json:"throughputGauge,omitempty"`
+	// Hold back new iterations while the workflow task backlog of the run's task queue exceeds
+	// this, see BacklogThrottle. Default is no limit.
+	MaxBacklog int64 `json:"maxBacklog,omitempty"`
+	// Run iterations in bursts of this size: start a batch, wait for all its iterations to
+	// complete (or BatchTimeout), pause for BatchPause, then start the next. Iterations within a
+	// batch are still limited by MaxConcurrent and MaxIterationsPerSecond. Default is no batching.
+	BatchSize int `json:"batchSize,omitempty"`
+	// Pause between batches of BatchSize.
+	BatchPause time.Duration `json:"batchPause,omitempty"`
+	// Maximum time to wait for a batch to complete before pausing and starting the next, leaving
+	// its stragglers running. Default is no limit.
+	BatchTimeout time.Duration `json:"batchTimeout,omitempty"`
+	// Maximum number of times to retry an iteration failing with an error matching RetryableErrors.
+	// Default is DefaultIterationRetries if RetryableErrors is set.
+	IterationRetries int `json:"iterationRetries,omitempty"`
+	// Patterns of error messages of failed iterations to retry, matched as substrings or, when
+	// prefixed with "regex:", as regular expressions. Default is no retries.
+	RetryableErrors []string `json:"retryableErrors,omitempty"`
+	// Path of a CSV file of iteration start offsets to replay, see ArrivalTrace. Used as the
+	// GenericExecutor.Scheduler if none is set.
+	ArrivalTrace string `json:"arrivalTrace,omitempty"`
+	// Maximum number of started workflows to await concurrently for executors starting and awaiting
+	// them separately, see GenericExecutor.Start. Starts are limited by MaxConcurrent independently.
+	// Default is MaxConcurrent.
+	MaxConcurrentAwaits int `json:"maxConcurrentAwaits,omitempty"`
+	// Hard cap on the number of iterations, and so typically workflows, started over the whole run,
+	// e.g. to bound storage use of long duration-limited runs. Once reached, no more iterations are
+	// started even if the duration has not elapsed. Default is no cap.
+	MaxTotalStarts int `json:"maxTotalStarts,omitempty"`
+	// Before starting iterations, wait up to this long for workflow pollers on each of the run's
+	// task queues (see ScenarioInfo.RunTaskQueues), failing with the task queues lacking pollers.
+	// Default is not to wait.
+	AwaitPollersTimeout time.Duration `json:"awaitPollersTimeout,omitempty"`
+	// Lower the start rate while the schedule-to-start latency of the run's workflow tasks exceeds this,
+	// see ScheduleToStartBackpressure. Default is no limit.
+	MaxScheduleToStartLatency time.Duration `json:"maxScheduleToStartLatency,omitempty"`
+	// Interval of snapshots of the server-side stats of the run's task queues, included in the
+	// report as a time series. Default is no snapshots.
+	TaskQueueStatsInterval time.Duration `json:"taskQueueStatsInterval,omitempty"`
+	// Fail the run, after writing its report, if its steady-state throughput of completed iterations
+	// per second is below this, see RunResult.SteadyStateThroughput. Default is no floor.
+	MinThroughput float64 `json:"minThroughput,omitempty"`
+	// Time each iteration keeps its MaxConcurrent slot after completing, before the next iteration
+	// can start in it, modeling user think time in closed-loop load. Not counted in iteration latency.
+	ThinkTime time.Duration `json:"thinkTime,omitempty"`
+	// Random extra think time of up to this duration per iteration, see ThinkTime.
+	ThinkTimeJitter time.Duration `json:"thinkTimeJitter,omitempty"`
+	// Interval at which GenericExecutor checks the health of the cluster, pausing new starts while
+	// it is unhealthy, see HealthPause. Disabled if zero.
+	HealthCheckInterval time.Duration `json:"healthCheckInterval,omitempty"`
+	// URL of a Prometheus metrics endpoint of the workers, e.g. "http://localhost:9090/metrics", scraped
+	// every WorkerMetricsInterval during the run and summarized in RunResult.WorkerMetrics, see
+	// WorkerMetricsSummary. Default is no scraping.
+	WorkerMetricsURL string `json:"workerMetricsUrl,omitempty"`
+	// Interval of scrapes of WorkerMetricsURL. Default is DefaultWorkerMetricsInterval.
+	WorkerMetricsInterval time.Duration `json:"workerMetricsInterval,omitempty"`
+}
+
+// RunPhase is a single phase of a run, see RunConfiguration.Phases.
+type RunPhase struct {
+	// Name of the phase used in logs and reports. Default is the phase's index.
+	Name string `json:"name,omitempty"`
+	// Duration of the phase. Required.
+	Duration time.Duration `json:"duration,omitempty"`
+	// Maximum number of iterations to run concurrently during the phase. Default is the run's
+	// MaxConcurrent.
+	MaxConcurrent int `json:"maxConcurrent,omitempty"`
+	// Maximum number of iterations to start per second during the phase. Default is the run's
+	// MaxIterationsPerSecond.
+	MaxIterationsPerSecond float64 `json:"maxIterationsPerSecond,omitempty"`
 }
 
 func (r *RunConfiguration) ApplyDefaults() {
-	if r.Iterations == 0 && r.Duration == 0 {
+	if r.Iterations == 0 && r.Duration == 0 && len(r.Phases) == 0 {
 		r.Iterations = DefaultIterations
 	}
 	if r.MaxConcurrent == 0 {
 		r.MaxConcurrent = DefaultMaxConcurrent
 	}
+	if r.GracePeriod == 0 {
+		r.GracePeriod = DefaultGracePeriod
+	}
+	if r.IterationRetries == 0 && len(r.RetryableErrors) > 0 {
+		r.IterationRetries = DefaultIterationRetries
+	}
+	// Copy so defaults are not applied to a shared slice
+	r.Phases = append([]RunPhase(nil), r.Phases...)
+	for i := range r.Phases {
+		if r.Phases[i].Name == "" {
+			r.Phases[i].Name = strconv.Itoa(i)
+		}
+		if r.Phases[i].MaxConcurrent == 0 {
+			r.Phases[i].MaxConcurrent = r.MaxConcurrent
+		}
+		if r.Phases[i].MaxIterationsPerSecond == 0 {
+			r.Phases[i].MaxIterationsPerSecond = r.MaxIterationsPerSecond
+		}
+	}
+}
+
+// EffectiveRunConfiguration layers the overrides, typically from CLI flags, on top of the scenario
+// defaults and applies ApplyDefaults last. Each set (non-zero) override field wins over the
+// default, except that iterations, duration and phases are taken together from the overrides if
+// any of them is set there, since they are mutually exclusive.
+func EffectiveRunConfiguration(defaults, overrides RunConfiguration) RunConfiguration {
+	config := LayerRunConfiguration(defaults, overrides)
+	config.ApplyDefaults()
+	return config
+}
+
+// LayerRunConfiguration layers the overrides on top of the defaults like
+// EffectiveRunConfiguration, without applying ApplyDefaults.
+func LayerRunConfiguration(defaults, overrides RunConfiguration) RunConfiguration {
+	config := overrides
+	if config.Duration == 0 && config.Iterations == 0 && len(config.Phases) == 0 {
+		config.Duration, config.Iterations, config.Phases = defaults.Duration, defaults.Iterations, defaults.Phases
+	}
+	if config.MaxConcurrent == 0 {
+		config.MaxConcurrent = defaults.MaxConcurrent
+	}
+	if config.GracePeriod == 0 {
+		config.GracePeriod = defaults.GracePeriod
+	}
+	if config.MaxIterationsPerSecond == 0 {
+		config.MaxIterationsPerSecond = defaults.MaxIterationsPerSecond
+	}
+	if config.ResultTimeout == 0 {
+		config.ResultTimeout = defaults.ResultTimeout
+	}
+	if !config.SkipLateIterations {
+		config.SkipLateIterations = defaults.SkipLateIterations
+	}
+	if config.DeadlineTolerance == 0 {
+		config.DeadlineTolerance = defaults.DeadlineTolerance
+	}
+	if !config.ShuffleIterations {
+		config.ShuffleIterations = defaults.ShuffleIterations
+	}
+	if config.ProgressInterval == 0 {
+		config.ProgressInterval = defaults.ProgressInterval
+	}
+	if config.ThroughputWindow == 0 {
+		config.ThroughputWindow = defaults.ThroughputWindow
+	}
+	if !config.ThroughputGauge {
+		config.ThroughputGauge = defaults.ThroughputGauge
+	}
+	if config.MaxBacklog == 0 {
+		config.MaxBacklog = defaults.MaxBacklog
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.BatchPause == 0 {
+		config.BatchPause = defaults.BatchPause
+	}
+	if config.BatchTimeout == 0 {
+		config.BatchTimeout = defaults.BatchTimeout
+	}
+	if config.IterationRetries == 0 {
+		config.IterationRetries = defaults.IterationRetries
+	}
+	if len(config.RetryableErrors) == 0 {
+		config.RetryableErrors = defaults.RetryableErrors
+	}
+	if config.ArrivalTrace == "" {
+		config.ArrivalTrace = defaults.ArrivalTrace
+	}
+	if config.MaxConcurrentAwaits == 0 {
+		config.MaxConcurrentAwaits = defaults.MaxConcurrentAwaits
+	}
+	if config.MaxTotalStarts == 0 {
+		config.MaxTotalStarts = defaults.MaxTotalStarts
+	}
+	if config.AwaitPollersTimeout == 0 {
+		config.AwaitPollersTimeout = defaults.AwaitPollersTimeout
+	}
+	if config.MaxScheduleToStartLatency == 0 {
+		config.MaxScheduleToStartLatency = defaults.MaxScheduleToStartLatency
+	}
+	if config.TaskQueueStatsInterval == 0 {
+		config.TaskQueueStatsInterval = defaults.TaskQueueStatsInterval
+	}
+	if config.MinThroughput == 0 {
+		config.MinThroughput = defaults.MinThroughput
+	}
+	if config.ThinkTime == 0 {
+		config.ThinkTime = defaults.ThinkTime
+	}
+	if config.ThinkTimeJitter == 0 {
+		config.ThinkTimeJitter = defaults.ThinkTimeJitter
+	}
+	if config.HealthCheckInterval == 0 {
+		config.HealthCheckInterval = defaults.HealthCheckInterval
+	}
+	if config.WorkerMetricsURL == "" {
+		config.WorkerMetricsURL = defaults.WorkerMetricsURL
+	}
+	if config.WorkerMetricsInterval == 0 {
+		config.WorkerMetricsInterval = defaults.WorkerMetricsInterval
+	}
+	return config
+}
+
+// TotalDuration returns the duration of the run, which is the sum of the phase durations for a
+// phased run. Returns 0 for runs limited by iterations.
+func (r *RunConfiguration) TotalDuration() time.Duration {
+	total := r.Duration
+	for _, phase := range r.Phases {
+		total += phase.Duration
+	}
+	return total
 }
 
 // Run represents an individual scenario run (many may be in a single instance (of possibly many) of a scenario).
@@ -137,15 +480,53 @@ type Run struct {
 	// Each run should have a unique iteration.
 	Iteration int
 	Logger    *zap.SugaredLogger
+	// Parameters of the iteration, see GenericExecutor.AdjustNext.
+	Params IterationParams
+	// Attempt of the iteration, from 1, see RunConfiguration.IterationRetries. Workflow IDs of retries
+	// are suffixed with it.
+	Attempt int
+	// Segments of the iteration, see BeginSegment.
+	segments *iterationSegments
 }
 
 // NewRun creates a new run.
 func (s *ScenarioInfo) NewRun(iteration int) *Run {
-	return &Run{
+	run := &Run{
 		ScenarioInfo: s,
 		Iteration:    iteration,
 		Logger:       s.Logger.With("iteration", iteration),
+		Attempt:      1,
+		segments:     newIterationSegments(),
+	}
+	if len(s.Endpoints) > 0 {
+		run.useEndpoint(s.endpointFor(iteration))
 	}
+	return run
+}
+
+// Rand returns a random source for this iteration, seeded by the scenario seed (see
+// ScenarioInfo.Seed) and the iteration, so that values drawn by an iteration are reproducible. Each
+// call returns a new source starting over.
+func (r *Run) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(int64(iterationHash(r.Seed(), r.Iteration))))
+}
+
+// OverrideScenarioOptions layers the given options over the scenario options for this run only,
+// leaving the shared ScenarioInfo and other runs unchanged.
+func (r *Run) OverrideScenarioOptions(overrides map[string]string) {
+	if len(overrides) == 0 {
+		return
+	}
+	options := make(map[string]string, len(r.ScenarioOptions)+len(overrides))
+	for k, v := range r.ScenarioOptions {
+		options[k] = v
+	}
+	for k, v := range overrides {
+		options[k] = v
+	}
+	info := *r.ScenarioInfo
+	info.ScenarioOptions = options
+	r.ScenarioInfo = &info
 }
 
 // TaskQueueForRun returns a default task queue name for the given scenario name and run ID.
@@ -157,18 +538,48 @@ func (r *Run) TaskQueue() string {
 	return TaskQueueForRun(r.ScenarioName, r.RunID)
 }
 
-// DefaultStartWorkflowOptions gets default start workflow info.
+// WorkflowIDPrefix returns the prefix shared by the IDs of all workflows started with
+// DefaultStartWorkflowOptions for this scenario run, i.e. "<IDPrefix>-<RunID>-", or the fixed
+// prefix of the WorkflowIDTemplateOption template if set. Panics if the template is invalid, which
+// ValidateStartOptions reports before the run.
+func (s *ScenarioInfo) WorkflowIDPrefix() string {
+	if prefix, ok := s.templatedWorkflowIDPrefix(); ok {
+		return prefix
+	}
+	idPrefix := s.IDPrefix
+	if idPrefix == "" {
+		idPrefix = DefaultIDPrefix
+	}
+	return fmt.Sprintf("%s-%s-", idPrefix, s.RunID)
+}
+
+// DefaultStartWorkflowOptions gets default start workflow info. Workflow IDs follow the
+// WorkflowIDTemplateOption template if set, and are suffixed with the attempt for retries, so that a
+// retry does not collide with the workflow of a previous attempt still running. Workflows are tagged
+// with RunIDSearchAttribute if TagsRunID. Panics with a *WorkflowIDTemplateError if the template
+// fails to render the iteration's ID.
 func (r *Run) DefaultStartWorkflowOptions() client.StartWorkflowOptions {
-	return client.StartWorkflowOptions{
+	options := client.StartWorkflowOptions{
 		TaskQueue:                                TaskQueueForRun(r.ScenarioName, r.RunID),
-		ID:                                       fmt.Sprintf("w-%s-%d", r.RunID, r.Iteration),
+		ID:                                       fmt.Sprintf("%s%d", r.WorkflowIDPrefix(), r.Iteration),
 		WorkflowExecutionErrorWhenAlreadyStarted: true,
 	}
+	if id, ok := r.templatedWorkflowID(); ok {
+		options.ID = id
+	}
+	if r.Attempt > 1 {
+		options.ID = fmt.Sprintf("%s-attempt-%d", options.ID, r.Attempt)
+	}
+	if r.TagsRunID() {
+		options.SearchAttributes = map[string]interface{}{RunIDSearchAttribute: r.RunID}
+	}
+	return options
 }
 
-// DefaultKitchenSinkWorkflowOptions gets the default kitchen sink workflow info.
+// DefaultKitchenSinkWorkflowOptions gets the default kitchen sink workflow info, started with
+// StartWorkflowOptions.
 func (r *Run) DefaultKitchenSinkWorkflowOptions() KitchenSinkWorkflowOptions {
-	return KitchenSinkWorkflowOptions{StartOptions: r.DefaultStartWorkflowOptions()}
+	return KitchenSinkWorkflowOptions{StartOptions: r.StartWorkflowOptions()}
 }
 
 type KitchenSinkWorkflowOptions struct {
@@ -226,7 +637,7 @@ func (r *Run) ExecuteKitchenSinkWorkflow(ctx context.Context, options *KitchenSi
 		}()
 	}
 
-	executeErr := handle.Get(cancelCtx, nil)
+	executeErr := r.getWorkflowResult(cancelCtx, handle, nil)
 	if executeErr != nil {
 		return fmt.Errorf("failed to execute kitchen sink workflow: %w", executeErr)
 	}
@@ -244,8 +655,41 @@ func (r *Run) ExecuteAnyWorkflow(ctx context.Context, options client.StartWorkfl
 	if err != nil {
 		return err
 	}
-	if err := execution.Get(ctx, valuePtr); err != nil {
+	if err := r.getWorkflowResult(ctx, execution, valuePtr); err != nil {
 		return fmt.Errorf("workflow execution failed (ID: %s, run ID: %s): %w", execution.GetID(), execution.GetRunID(), err)
 	}
 	return nil
 }
+
+// ErrResultTimeout is returned (wrapped) by the Run execute helpers when a workflow result is not
+// available within the configured result timeout.
+var ErrResultTimeout = errors.New("timed out waiting for workflow result")
+
+// ResultTimeout returns the maximum time to wait for a workflow result, or 0 if there is no limit.
+func (r *Run) ResultTimeout() time.Duration {
+	return r.ScenarioOptionDuration("result-timeout", r.Configuration.ResultTimeout)
+}
+
+// getWorkflowResult waits for the workflow result, bounded by the result timeout if one is set. If
+// the workflow had workflow task failures, the last one is included in the returned error, unless
+// the workflow failed with an application error or was canceled, which explain themselves.
+func (r *Run) getWorkflowResult(ctx context.Context, execution client.WorkflowRun, valuePtr interface{}) error {
+	getCtx := ctx
+	timeout := r.ResultTimeout()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		getCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := execution.Get(getCtx, valuePtr)
+	var canceled *temporal.CanceledError
+	var applicationErr *temporal.ApplicationError
+	if err == nil || ctx.Err() != nil || errors.As(err, &canceled) || errors.As(err, &applicationErr) {
+		return err
+	}
+	// Only a result timeout if the parent context is still alive
+	if getCtx.Err() != nil {
+		err = fmt.Errorf("%w after %v", ErrResultTimeout, timeout)
+	}
+	return r.withWorkflowTaskFailure(ctx, execution.GetID(), execution.GetRunID(), err)
+}
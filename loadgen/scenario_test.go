@@ -0,0 +1,139 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+)
+
+// delayedWorkflowRun completes after the delay, or fails when the context is done first.
+type delayedWorkflowRun struct {
+	client.WorkflowRun
+	id    string
+	delay time.Duration
+}
+
+func (d *delayedWorkflowRun) GetID() string    { return d.id }
+func (d *delayedWorkflowRun) GetRunID() string { return "run-" + d.id }
+
+func (d *delayedWorkflowRun) Get(ctx context.Context, valuePtr interface{}) error {
+	select {
+	case <-time.After(d.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type delayedWorkflowClient struct {
+	FakeClient
+	delays map[string]time.Duration
+}
+
+func (d *delayedWorkflowClient) ExecuteWorkflow(
+	ctx context.Context,
+	options client.StartWorkflowOptions,
+	workflow interface{},
+	args ...interface{},
+) (client.WorkflowRun, error) {
+	return &delayedWorkflowRun{id: options.ID, delay: d.delays[options.ID]}, nil
+}
+
+func TestExecuteAnyWorkflowResultTimeout(t *testing.T) {
+	info := &ScenarioInfo{
+		RunID:  "result-timeout",
+		Logger: zap.NewNop().Sugar(),
+		Client: &delayedWorkflowClient{delays: map[string]time.Duration{
+			"w-result-timeout-1": time.Hour,
+			"w-result-timeout-2": time.Millisecond,
+		}},
+		Configuration: RunConfiguration{ResultTimeout: 20 * time.Millisecond},
+	}
+	ctx := context.Background()
+
+	slow := info.NewRun(1)
+	err := slow.ExecuteAnyWorkflow(ctx, slow.DefaultStartWorkflowOptions(), "wf", nil)
+	require.True(t, errors.Is(err, ErrResultTimeout))
+	require.NoError(t, ctx.Err())
+
+	fast := info.NewRun(2)
+	require.NoError(t, fast.ExecuteAnyWorkflow(ctx, fast.DefaultStartWorkflowOptions(), "wf", nil))
+}
+
+func TestResultTimeoutScenarioOption(t *testing.T) {
+	info := &ScenarioInfo{
+		Logger:          zap.NewNop().Sugar(),
+		Configuration:   RunConfiguration{ResultTimeout: time.Minute},
+		ScenarioOptions: map[string]string{"result-timeout": "5s"},
+	}
+	require.Equal(t, 5*time.Second, info.NewRun(1).ResultTimeout())
+}
+
+func TestEffectiveRunConfiguration(t *testing.T) {
+	defaults := RunConfiguration{
+		Duration:               time.Minute,
+		MaxConcurrent:          5,
+		GracePeriod:            time.Minute,
+		MaxIterationsPerSecond: 2,
+		ResultTimeout:          time.Second,
+		DeadlineTolerance:      time.Second,
+	}
+
+	// No overrides keeps defaults
+	require.Equal(t, defaults, EffectiveRunConfiguration(defaults, RunConfiguration{}))
+
+	// Each override wins over its default
+	config := EffectiveRunConfiguration(defaults, RunConfiguration{
+		MaxConcurrent:          10,
+		MaxIterationsPerSecond: 3,
+		ResultTimeout:          time.Hour,
+		SkipLateIterations:     true,
+		DeadlineTolerance:      time.Millisecond,
+	})
+	require.Equal(t, RunConfiguration{
+		Duration:               time.Minute,
+		MaxConcurrent:          10,
+		GracePeriod:            time.Minute,
+		MaxIterationsPerSecond: 3,
+		ResultTimeout:          time.Hour,
+		SkipLateIterations:     true,
+		DeadlineTolerance:      time.Millisecond,
+	}, config)
+
+	// Iterations override replaces default duration instead of conflicting with it
+	config = EffectiveRunConfiguration(defaults, RunConfiguration{Iterations: 7})
+	require.Equal(t, 7, config.Iterations)
+	require.Zero(t, config.Duration)
+
+	// Duration override replaces default iterations and phases
+	config = EffectiveRunConfiguration(
+		RunConfiguration{Iterations: 3, Phases: []RunPhase{{Duration: time.Second}}},
+		RunConfiguration{Duration: time.Hour})
+	require.Equal(t, time.Hour, config.Duration)
+	require.Zero(t, config.Iterations)
+	require.Empty(t, config.Phases)
+
+	// ApplyDefaults runs last
+	config = EffectiveRunConfiguration(RunConfiguration{}, RunConfiguration{
+		Phases: []RunPhase{{Duration: time.Second}},
+	})
+	require.Equal(t, DefaultMaxConcurrent, config.MaxConcurrent)
+	require.Equal(t, DefaultMaxConcurrent, config.Phases[0].MaxConcurrent)
+	require.Equal(t, DefaultGracePeriod, config.GracePeriod)
+	require.Zero(t, config.Iterations)
+	config = EffectiveRunConfiguration(RunConfiguration{}, RunConfiguration{})
+	require.Equal(t, DefaultIterations, config.Iterations)
+}
+
+func TestSeed(t *testing.T) {
+	info := ScenarioInfo{RunID: "seed-test"}
+	require.Equal(t, info.Seed(), (&ScenarioInfo{RunID: "seed-test"}).Seed())
+	require.NotEqual(t, info.Seed(), (&ScenarioInfo{RunID: "other"}).Seed())
+	info.ScenarioOptions = map[string]string{"seed": "42"}
+	require.Equal(t, int64(42), info.Seed())
+}
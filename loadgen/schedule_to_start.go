@@ -0,0 +1,185 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// scheduleToStartPollInterval is the interval between latency reads of a
+// ScheduleToStartBackpressure.
+var scheduleToStartPollInterval = 5 * time.Second
+
+// minBackpressureRate is the lowest start rate ScheduleToStartBackpressure lowers to.
+const minBackpressureRate = 0.1
+
+// ScheduleToStartSource reads the current schedule-to-start latency of the run's workflow tasks.
+type ScheduleToStartSource interface {
+	ScheduleToStartLatency(ctx context.Context, info *ScenarioInfo) (time.Duration, error)
+}
+
+// ScheduleToStartSourceFunc is a function implementing ScheduleToStartSource.
+type ScheduleToStartSourceFunc func(ctx context.Context, info *ScenarioInfo) (time.Duration, error)
+
+func (f ScheduleToStartSourceFunc) ScheduleToStartLatency(ctx context.Context, info *ScenarioInfo) (time.Duration, error) {
+	return f(ctx, info)
+}
+
+// HistoryScheduleToStart is a ScheduleToStartSource reading the latency of the first workflow task
+// of the run's most recently started workflows from their histories, taking the highest. A task
+// not started yet counts with the time since it was scheduled.
+type HistoryScheduleToStart struct {
+	// Number of workflows read. Default is 5.
+	Sample int
+}
+
+func (h HistoryScheduleToStart) ScheduleToStartLatency(ctx context.Context, info *ScenarioInfo) (time.Duration, error) {
+	sample := h.Sample
+	if sample <= 0 {
+		sample = 5
+	}
+	resp, err := info.Client.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace: info.Namespace,
+		PageSize:  int32(sample),
+		Query:     info.RunVisibilityQuery(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed listing workflows: %w", err)
+	}
+	var highest time.Duration
+	for _, execution := range resp.Executions {
+		latency, err := firstWorkflowTaskScheduleToStart(ctx, info, execution.Execution.GetWorkflowId(),
+			execution.Execution.GetRunId())
+		if err != nil {
+			return 0, err
+		} else if latency > highest {
+			highest = latency
+		}
+	}
+	return highest, nil
+}
+
+func firstWorkflowTaskScheduleToStart(ctx context.Context, info *ScenarioInfo, workflowID, runID string) (time.Duration, error) {
+	iter := info.Client.GetWorkflowHistory(ctx, workflowID, runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	var scheduled *time.Time
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return 0, fmt.Errorf("failed reading history of workflow %v: %w", workflowID, err)
+		}
+		switch event.EventType {
+		case enums.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED:
+			if scheduled == nil {
+				scheduled = event.EventTime
+			}
+		case enums.EVENT_TYPE_WORKFLOW_TASK_STARTED:
+			if scheduled != nil && event.EventTime != nil {
+				return event.EventTime.Sub(*scheduled), nil
+			}
+		}
+	}
+	if scheduled == nil {
+		return 0, nil
+	}
+	return time.Since(*scheduled), nil
+}
+
+// ScheduleToStartBackpressure lowers the start rate while the schedule-to-start latency of the
+// run's workflow tasks exceeds MaxLatency, a sign of saturated workers. The latency is read every 5
+// seconds once started. Each read above MaxLatency multiplies the rate limit by Factor, starting
+// from the current throughput, and each read within it divides the limit by Factor until it is
+// back to the rate it was first lowered from, when it is lifted. The limit is recorded in the
+// omes_start_rate_limit gauge. It is safe for concurrent use.
+type ScheduleToStartBackpressure struct {
+	MaxLatency time.Duration
+	// Source of the latency. Default is HistoryScheduleToStart.
+	Source ScheduleToStartSource
+	// Factor in (0, 1) to lower the rate limit by. Default is 0.5.
+	Factor float64
+
+	lock sync.Mutex
+	// Current limit, 0 if not limited, and the rate it was first lowered from.
+	limit, unlimitedRate float64
+}
+
+// Start reads the latency once, then keeps reading it and updating the limit in the background
+// until the context is done. The current rate is the rate of starts to lower the limit from when
+// first lowered. Failed reads are logged and leave the limit unchanged.
+func (b *ScheduleToStartBackpressure) Start(ctx context.Context, info *ScenarioInfo, currentRate func() float64) {
+	b.poll(ctx, info, currentRate)
+	go func() {
+		ticker := time.NewTicker(scheduleToStartPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.poll(ctx, info, currentRate)
+			}
+		}
+	}()
+}
+
+func (b *ScheduleToStartBackpressure) poll(ctx context.Context, info *ScenarioInfo, currentRate func() float64) {
+	source := b.Source
+	if source == nil {
+		source = HistoryScheduleToStart{}
+	}
+	latency, err := source.ScheduleToStartLatency(ctx, info)
+	if err != nil {
+		if ctx.Err() == nil {
+			info.Logger.Warnf("Failed reading schedule-to-start latency for backpressure: %v", err)
+		}
+		return
+	}
+	b.update(info, latency, currentRate())
+}
+
+func (b *ScheduleToStartBackpressure) update(info *ScenarioInfo, latency time.Duration, currentRate float64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	factor := b.Factor
+	if factor <= 0 || factor >= 1 {
+		factor = 0.5
+	}
+	if latency > b.MaxLatency {
+		if b.limit == 0 {
+			b.unlimitedRate = currentRate
+			b.limit = currentRate
+		}
+		b.limit *= factor
+		if b.limit < minBackpressureRate {
+			b.limit = minBackpressureRate
+		}
+		info.Logger.Infof("Lowering start rate to %.2f/s, schedule-to-start latency of %v exceeds %v",
+			b.limit, latency, b.MaxLatency)
+	} else if b.limit > 0 {
+		b.limit /= factor
+		if b.limit >= b.unlimitedRate {
+			b.limit = 0
+			info.Logger.Infof("Lifting start rate limit, schedule-to-start latency of %v is within %v",
+				latency, b.MaxLatency)
+		} else {
+			info.Logger.Infof("Raising start rate to %.2f/s, schedule-to-start latency of %v is within %v",
+				b.limit, latency, b.MaxLatency)
+		}
+	} else {
+		return
+	}
+	info.RecordGauge("omes_start_rate_limit", nil, b.limit)
+}
+
+// Limit returns the start rate to use instead of the given one, which is 0 if unlimited.
+func (b *ScheduleToStartBackpressure) Limit(rate float64) float64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.limit > 0 && (rate == 0 || b.limit < rate) {
+		return b.limit
+	}
+	return rate
+}
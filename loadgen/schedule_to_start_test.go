@@ -0,0 +1,119 @@
+package loadgen
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+func TestScheduleToStartBackpressureAdjustsRate(t *testing.T) {
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	info.MetricsHandler = handler
+	backpressure := &ScheduleToStartBackpressure{MaxLatency: time.Second}
+
+	// Within the threshold, nothing changes
+	backpressure.update(&info, 500*time.Millisecond, 100)
+	require.Equal(t, 0.0, backpressure.Limit(0))
+	require.Equal(t, 40.0, backpressure.Limit(40))
+
+	// Crossing the threshold halves the current rate on each read
+	backpressure.update(&info, 3*time.Second, 100)
+	require.Equal(t, 50.0, backpressure.Limit(0))
+	backpressure.update(&info, 3*time.Second, 50)
+	require.Equal(t, 25.0, backpressure.Limit(0))
+	// A lower configured rate takes precedence
+	require.Equal(t, 10.0, backpressure.Limit(10))
+
+	// Back within the threshold, the rate recovers until the limit is lifted
+	backpressure.update(&info, 500*time.Millisecond, 25)
+	require.Equal(t, 50.0, backpressure.Limit(0))
+	backpressure.update(&info, 500*time.Millisecond, 50)
+	require.Equal(t, 0.0, backpressure.Limit(0))
+
+	var limits []float64
+	for _, metric := range *handler.recorded {
+		require.Equal(t, "omes_start_rate_limit", metric.name)
+		limits = append(limits, metric.value)
+	}
+	require.Equal(t, []float64{50, 25, 50, 0}, limits)
+}
+
+func TestRunScheduleToStartBackpressure(t *testing.T) {
+	prev := scheduleToStartPollInterval
+	scheduleToStartPollInterval = time.Millisecond
+	t.Cleanup(func() { scheduleToStartPollInterval = prev })
+
+	var latency int64 = int64(5 * time.Second)
+	var reads int32
+	var started int32
+	executor := &GenericExecutor{
+		ScheduleToStartSource: ScheduleToStartSourceFunc(func(ctx context.Context, info *ScenarioInfo) (time.Duration, error) {
+			atomic.AddInt32(&reads, 1)
+			return time.Duration(atomic.LoadInt64(&latency)), nil
+		}),
+		Execute: func(ctx context.Context, run *Run) error {
+			atomic.AddInt32(&started, 1)
+			return nil
+		},
+	}
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{
+		Duration:                  500 * time.Millisecond,
+		MaxConcurrent:             10,
+		MaxScheduleToStartLatency: time.Second,
+	})
+	require.NoError(t, executor.Run(context.Background(), info))
+	// Lowered to the minimum rate before the first start, so only it starts
+	require.Equal(t, int32(1), atomic.LoadInt32(&started))
+	require.Greater(t, atomic.LoadInt32(&reads), int32(1))
+
+	// Without saturation starts are not held back
+	atomic.StoreInt64(&latency, 0)
+	atomic.StoreInt32(&started, 0)
+	require.NoError(t, executor.Run(context.Background(), info))
+	require.Greater(t, atomic.LoadInt32(&started), int32(100))
+}
+
+func TestHistoryScheduleToStart(t *testing.T) {
+	scheduled := time.Now().Add(-time.Minute)
+	started := scheduled.Add(2 * time.Second)
+	pending := time.Now().Add(-3 * time.Second)
+	fake := &FakeClient{
+		OnListWorkflow: func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (
+			*workflowservice.ListWorkflowExecutionsResponse, error) {
+			require.Equal(t, int32(5), request.PageSize)
+			require.Equal(t, `WorkflowId STARTS_WITH "w-test-run-"`, request.Query)
+			return &workflowservice.ListWorkflowExecutionsResponse{Executions: []*workflow.WorkflowExecutionInfo{
+				{Execution: &common.WorkflowExecution{WorkflowId: "started"}},
+				{Execution: &common.WorkflowExecution{WorkflowId: "pending"}},
+			}}, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			if workflowID == "started" {
+				return []*history.HistoryEvent{
+					{EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, EventTime: &scheduled},
+					{EventType: enums.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED, EventTime: &scheduled},
+					{EventType: enums.EVENT_TYPE_WORKFLOW_TASK_STARTED, EventTime: &started},
+				}, nil
+			}
+			return []*history.HistoryEvent{
+				{EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, EventTime: &pending},
+				{EventType: enums.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED, EventTime: &pending},
+			}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	latency, err := HistoryScheduleToStart{}.ScheduleToStartLatency(context.Background(), &info)
+	require.NoError(t, err)
+	// The pending task's latency is higher than the started one's
+	require.GreaterOrEqual(t, latency, 3*time.Second)
+	require.Less(t, latency, 10*time.Second)
+}
@@ -0,0 +1,130 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+// schedulePollInterval is the interval between schedule descriptions when waiting for actions.
+var schedulePollInterval = time.Second
+
+// Schedules creates and operates Temporal Schedules for a run, tracking the schedules it creates so
+// that Cleanup can delete those left behind. It is safe for concurrent use.
+type Schedules struct {
+	lock    sync.Mutex
+	created map[string]bool
+}
+
+// ScheduleID returns the ID of the run's schedule with the given name.
+func (s *Schedules) ScheduleID(info *ScenarioInfo, name string) string {
+	return info.WorkflowIDPrefix() + "schedule-" + name
+}
+
+// Create creates a schedule. A workflow action without a task queue runs on the run's task queue,
+// and without an ID gets "<schedule ID>-workflow". If a schedule with the ID already exists it is
+// reused as is.
+func (s *Schedules) Create(ctx context.Context, run *Run, options client.ScheduleOptions) (client.ScheduleHandle, error) {
+	if action, ok := options.Action.(*client.ScheduleWorkflowAction); ok {
+		actionCopy := *action
+		if actionCopy.TaskQueue == "" {
+			actionCopy.TaskQueue = run.TaskQueue()
+		}
+		if actionCopy.ID == "" {
+			actionCopy.ID = options.ID + "-workflow"
+		}
+		options.Action = &actionCopy
+	}
+	handle, err := run.Client.ScheduleClient().Create(ctx, options)
+	if errors.Is(err, temporal.ErrScheduleAlreadyRunning) {
+		run.Logger.Infof("Schedule %v already exists, reusing it", options.ID)
+		handle, err = run.Client.ScheduleClient().GetHandle(ctx, options.ID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed creating schedule %v: %w", options.ID, err)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.created == nil {
+		s.created = map[string]bool{}
+	}
+	s.created[options.ID] = true
+	return handle, nil
+}
+
+// Trigger immediately runs the schedule's action once.
+func (s *Schedules) Trigger(ctx context.Context, run *Run, id string) error {
+	err := run.Client.ScheduleClient().GetHandle(ctx, id).Trigger(ctx, client.ScheduleTriggerOptions{})
+	if err != nil {
+		return fmt.Errorf("failed triggering schedule %v: %w", id, err)
+	}
+	return nil
+}
+
+// Pause pauses the schedule with the given note.
+func (s *Schedules) Pause(ctx context.Context, run *Run, id, note string) error {
+	err := run.Client.ScheduleClient().GetHandle(ctx, id).Pause(ctx, client.SchedulePauseOptions{Note: note})
+	if err != nil {
+		return fmt.Errorf("failed pausing schedule %v: %w", id, err)
+	}
+	return nil
+}
+
+// Delete deletes the schedule. Workflows it started are not affected.
+func (s *Schedules) Delete(ctx context.Context, run *Run, id string) error {
+	if err := run.Client.ScheduleClient().GetHandle(ctx, id).Delete(ctx); err != nil {
+		return fmt.Errorf("failed deleting schedule %v: %w", id, err)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.created, id)
+	return nil
+}
+
+// WaitForActions waits until the schedule has taken at least the given number of actions.
+func (s *Schedules) WaitForActions(ctx context.Context, run *Run, id string, actions int) error {
+	handle := run.Client.ScheduleClient().GetHandle(ctx, id)
+	for {
+		description, err := handle.Describe(ctx)
+		if err != nil {
+			return fmt.Errorf("failed describing schedule %v: %w", id, err)
+		}
+		if description.Info.NumActions >= actions {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("schedule %v took %v of %v actions: %w",
+				id, description.Info.NumActions, actions, ctx.Err())
+		case <-time.After(schedulePollInterval):
+		}
+	}
+}
+
+// Cleanup deletes all schedules created and not yet deleted, attempting every schedule even if
+// some fail.
+func (s *Schedules) Cleanup(ctx context.Context, info *ScenarioInfo) error {
+	s.lock.Lock()
+	ids := make([]string, 0, len(s.created))
+	for id := range s.created {
+		ids = append(ids, id)
+	}
+	s.lock.Unlock()
+	sort.Strings(ids)
+	var errs []error
+	for _, id := range ids {
+		if err := s.Delete(ctx, info.NewRun(0), id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(ids) > 0 {
+		info.Logger.Infof("Deleted %v of %v leftover schedules", len(ids)-len(errs), len(ids))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,80 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+func TestSchedulesLifecycle(t *testing.T) {
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	ctx := context.Background()
+	var schedules Schedules
+	id := schedules.ScheduleID(&info, "a")
+	require.Equal(t, "w-test-run-schedule-a", id)
+
+	options := client.ScheduleOptions{ID: id, Action: &client.ScheduleWorkflowAction{Workflow: "wf"}}
+	_, err := schedules.Create(ctx, run, options)
+	require.NoError(t, err)
+	// Conflict is reused
+	handle, err := schedules.Create(ctx, run, options)
+	require.NoError(t, err)
+	require.Equal(t, id, handle.GetID())
+
+	require.NoError(t, schedules.Trigger(ctx, run, id))
+	require.NoError(t, schedules.WaitForActions(ctx, run, id, 1))
+	require.NoError(t, schedules.Pause(ctx, run, id, "done"))
+	description, err := handle.Describe(ctx)
+	require.NoError(t, err)
+	require.True(t, description.Schedule.State.Paused)
+	action := description.Schedule.Action.(*client.ScheduleWorkflowAction)
+	require.Equal(t, run.TaskQueue(), action.TaskQueue)
+	require.Equal(t, id+"-workflow", action.ID)
+
+	require.NoError(t, schedules.Delete(ctx, run, id))
+	require.Error(t, schedules.Delete(ctx, run, id))
+	// Nothing left to clean up
+	require.NoError(t, schedules.Cleanup(ctx, &info))
+
+	var methods []string
+	for _, call := range fake.Calls() {
+		require.Equal(t, id, call.Name)
+		methods = append(methods, call.Method)
+	}
+	require.Equal(t, []string{
+		"CreateSchedule", "CreateSchedule", "TriggerSchedule", "DescribeSchedule", "PauseSchedule",
+		"DescribeSchedule", "DeleteSchedule", "DeleteSchedule",
+	}, methods)
+}
+
+func TestSchedulesWaitForActionsAndCleanup(t *testing.T) {
+	prev := schedulePollInterval
+	schedulePollInterval = time.Millisecond
+	t.Cleanup(func() { schedulePollInterval = prev })
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	ctx := context.Background()
+	var schedules Schedules
+	for _, name := range []string{"a", "b"} {
+		_, err := schedules.Create(ctx, run, client.ScheduleOptions{ID: schedules.ScheduleID(&info, name)})
+		require.NoError(t, err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err := schedules.WaitForActions(waitCtx, run, schedules.ScheduleID(&info, "a"), 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "took 0 of 1 actions")
+
+	require.NoError(t, schedules.Cleanup(ctx, &info))
+	deletes := fake.Calls("DeleteSchedule")
+	require.Len(t, deletes, 2)
+	require.Equal(t, "w-test-run-schedule-a", deletes[0].Name)
+	require.Equal(t, "w-test-run-schedule-b", deletes[1].Name)
+}
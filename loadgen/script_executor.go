@@ -0,0 +1,246 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/sdk/client"
+)
+
+// ScriptFileOption is the scenario option giving the path of the script for ScriptExecutor.
+const ScriptFileOption = "script-file"
+
+// Script is a declarative list of steps run by each iteration of a ScriptExecutor. It lets load
+// shapes be defined in JSON, e.g.:
+//
+//	{"steps": [
+//	  {"type": "start", "workflow": "myWorkflow", "args": ["hello"]},
+//	  {"type": "signal", "name": "mySignal", "args": [{"count": 3}]},
+//	  {"type": "sleep", "duration": "1s"},
+//	  {"type": "query", "name": "myQuery"},
+//	  {"type": "await"}
+//	]}
+type Script struct {
+	Steps []ScriptStep `json:"steps"`
+}
+
+// ScriptStep is one step of a script. Type selects the step, the other fields are interpreted by
+// type.
+type ScriptStep struct {
+	// Type is one of:
+	//   - "start": starts Workflow with Args. Signal, query and await steps target the most
+	//     recently started workflow of the iteration.
+	//   - "signal": signals the workflow with signal Name and at most one argument.
+	//   - "query": queries the workflow with query Name and Args, ignoring the result.
+	//   - "sleep": sleeps for Duration.
+	//   - "await": waits for the workflow to complete, failing the iteration if it fails.
+	Type string `json:"type"`
+	// Workflow type to start. Defaults to the kitchen sink workflow.
+	Workflow string `json:"workflow,omitempty"`
+	// Signal or query name.
+	Name string `json:"name,omitempty"`
+	// Arguments as JSON values. When starting the kitchen sink workflow or sending it a
+	// do_actions_signal, the single argument is instead the kitchen sink WorkflowInput or
+	// DoSignalActions message in protobuf JSON form.
+	Args []json.RawMessage `json:"args,omitempty"`
+	// Sleep duration in Go duration format, e.g. "500ms".
+	Duration string `json:"duration,omitempty"`
+}
+
+// ParseScript parses and validates a JSON script.
+func ParseScript(data []byte) (*Script, error) {
+	var script Script
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&script); err != nil {
+		return nil, fmt.Errorf("failed parsing script: %w", err)
+	}
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+	return &script, nil
+}
+
+// LoadScript reads, parses and validates a JSON script file.
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading script: %w", err)
+	}
+	return ParseScript(data)
+}
+
+// Validate checks every step is well formed and that steps targeting a workflow follow a start.
+func (s *Script) Validate() error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("script has no steps")
+	}
+	started := false
+	for i, step := range s.Steps {
+		var err error
+		switch step.Type {
+		case "start":
+			started = true
+			_, err = step.args()
+		case "signal", "query":
+			if step.Name == "" {
+				err = fmt.Errorf("name required")
+			} else if step.Type == "signal" && len(step.Args) > 1 {
+				err = fmt.Errorf("signal takes at most one argument")
+			} else {
+				_, err = step.args()
+			}
+		case "await":
+		case "sleep":
+			_, err = time.ParseDuration(step.Duration)
+		default:
+			err = fmt.Errorf("unknown step type %q", step.Type)
+		}
+		if err == nil && !started && step.Type != "sleep" {
+			err = fmt.Errorf("no workflow started before %v step", step.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid script step %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ScriptExecutor runs a Script on every iteration.
+type ScriptExecutor struct {
+	// Script to run. If unset, it is loaded from the file given by the script-file scenario
+	// option.
+	Script *Script
+
+	DefaultConfiguration RunConfiguration
+}
+
+func (s ScriptExecutor) Run(ctx context.Context, info ScenarioInfo) error {
+	script := s.Script
+	if script == nil {
+		path := info.ScenarioOptions[ScriptFileOption]
+		if path == "" {
+			return fmt.Errorf("script executor requires the %v scenario option", ScriptFileOption)
+		}
+		var err error
+		if script, err = LoadScript(path); err != nil {
+			return err
+		}
+	} else if err := script.Validate(); err != nil {
+		return err
+	}
+	ge := &GenericExecutor{
+		DefaultConfiguration: s.DefaultConfiguration,
+		Execute: func(ctx context.Context, run *Run) error {
+			return run.ExecuteScript(ctx, script)
+		},
+	}
+	return ge.Run(ctx, info)
+}
+
+func (s ScriptExecutor) GetDefaultConfiguration() RunConfiguration {
+	return s.DefaultConfiguration
+}
+
+// ExecuteScript runs the steps of the script in order. The first workflow started uses the
+// iteration's default workflow ID, later ones have their start count appended.
+func (r *Run) ExecuteScript(ctx context.Context, script *Script) error {
+	var execution client.WorkflowRun
+	starts := 0
+	for i, step := range script.Steps {
+		var err error
+		switch step.Type {
+		case "start":
+			options := r.StartWorkflowOptions()
+			if starts > 0 {
+				options.ID += "-" + strconv.Itoa(starts)
+			}
+			starts++
+			var args []interface{}
+			if args, err = step.args(); err == nil {
+				execution, err = r.Client.ExecuteWorkflow(ctx, options, step.workflow(), args...)
+			}
+		case "signal":
+			var args []interface{}
+			if args, err = step.args(); err == nil {
+				var arg interface{}
+				if len(args) > 0 {
+					arg = args[0]
+				}
+				err = r.Client.SignalWorkflow(ctx, execution.GetID(), execution.GetRunID(), step.Name, arg)
+			}
+		case "query":
+			var args []interface{}
+			if args, err = step.args(); err == nil {
+				_, err = r.Client.QueryWorkflow(ctx, execution.GetID(), execution.GetRunID(), step.Name, args...)
+			}
+		case "sleep":
+			duration, _ := time.ParseDuration(step.Duration)
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+			case <-time.After(duration):
+			}
+		case "await":
+			err = r.getWorkflowResult(ctx, execution, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("script step %d (%v) failed: %w", i, step.Type, err)
+		}
+	}
+	return nil
+}
+
+func (s ScriptStep) workflow() string {
+	if s.Workflow == "" {
+		return "kitchenSink"
+	}
+	return s.Workflow
+}
+
+func isKitchenSinkStart(step ScriptStep) bool {
+	return step.workflow() == "kitchenSink"
+}
+
+func isKitchenSinkSignal(step ScriptStep) bool {
+	return step.Name == "do_actions_signal"
+}
+
+// args decodes the step arguments, as kitchen sink protos where applicable.
+func (s ScriptStep) args() ([]interface{}, error) {
+	switch {
+	case s.Type == "start" && isKitchenSinkStart(s):
+		return s.kitchenSinkArg(&kitchensink.WorkflowInput{})
+	case s.Type == "signal" && isKitchenSinkSignal(s):
+		return s.kitchenSinkArg(&kitchensink.DoSignal_DoSignalActions{})
+	}
+	args := make([]interface{}, len(s.Args))
+	for i, arg := range s.Args {
+		if err := json.Unmarshal(arg, &args[i]); err != nil {
+			return nil, fmt.Errorf("invalid argument %d: %w", i, err)
+		}
+	}
+	return args, nil
+}
+
+func (s ScriptStep) kitchenSinkArg(message proto.Message) ([]interface{}, error) {
+	switch len(s.Args) {
+	case 0:
+		return []interface{}{message}, nil
+	case 1:
+		if err := jsonpb.Unmarshal(bytes.NewReader(s.Args[0]), message); err != nil {
+			return nil, fmt.Errorf("invalid kitchen sink argument: %w", err)
+		}
+		return []interface{}{message}, nil
+	default:
+		return nil, fmt.Errorf("kitchen sink takes a single argument, got %v", len(s.Args))
+	}
+}
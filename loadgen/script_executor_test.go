@@ -0,0 +1,77 @@
+package loadgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+)
+
+const testScript = `{"steps": [
+	{"type": "start", "workflow": "greeter", "args": ["hello", 2]},
+	{"type": "signal", "name": "greet", "args": [{"name": "temporal"}]},
+	{"type": "sleep", "duration": "1ms"},
+	{"type": "query", "name": "greetings"},
+	{"type": "await"},
+	{"type": "start", "args": [{"initialActions": [{"actions": [{"timer": {"milliseconds": 5}}]}]}]},
+	{"type": "signal", "name": "do_actions_signal", "args": [{"doActions": {"concurrent": true}}]}
+]}`
+
+func TestScriptExecutor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.json")
+	require.NoError(t, os.WriteFile(path, []byte(testScript), 0644))
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 2})
+	info.ScenarioOptions = map[string]string{ScriptFileOption: path}
+	require.NoError(t, ScriptExecutor{}.Run(context.Background(), info))
+
+	starts := fake.Calls("ExecuteWorkflow")
+	require.Len(t, starts, 4)
+	var ids []string
+	for _, start := range starts {
+		ids = append(ids, start.WorkflowID)
+	}
+	require.ElementsMatch(t, []string{"w-test-run-1", "w-test-run-1-1", "w-test-run-2", "w-test-run-2-1"}, ids)
+
+	for _, start := range starts {
+		if start.Name == "greeter" {
+			require.Equal(t, []interface{}{"hello", float64(2)}, start.Args)
+			continue
+		}
+		require.Equal(t, "kitchenSink", start.Name)
+		input := start.Args[0].(*kitchensink.WorkflowInput)
+		require.Equal(t, uint64(5), input.InitialActions[0].Actions[0].GetTimer().GetMilliseconds())
+	}
+
+	signals := fake.Calls("SignalWorkflow")
+	require.Len(t, signals, 4)
+	for _, signal := range signals {
+		if signal.Name == "greet" {
+			require.Equal(t, []interface{}{map[string]interface{}{"name": "temporal"}}, signal.Args)
+			continue
+		}
+		require.Equal(t, "do_actions_signal", signal.Name)
+		actions := signal.Args[0].(*kitchensink.DoSignal_DoSignalActions)
+		require.True(t, actions.GetDoActions().Concurrent)
+	}
+	require.Len(t, fake.Calls("QueryWorkflow"), 2)
+}
+
+func TestParseScriptInvalid(t *testing.T) {
+	for script, expected := range map[string]string{
+		`{"steps": []}`: "no steps",
+		`{"steps": [{"type": "signal", "name": "s"}]}`:                                    "no workflow started",
+		`{"steps": [{"type": "start"}, {"type": "query"}]}`:                               "name required",
+		`{"steps": [{"type": "sleep", "duration": "soon"}]}`:                              "invalid duration",
+		`{"steps": [{"type": "jump"}]}`:                                                   "unknown step type",
+		`{"steps": [{"type": "start", "args": [{"bogus": 1}]}]}`:                          "invalid kitchen sink argument",
+		`{"steps": [{"type": "start", "workflow": "w", "argz": []}]}`:                     "unknown field",
+		`{"steps": [{"type": "start"}, {"type": "signal", "name": "s", "args": [1, 2]}]}`: "at most one argument",
+	} {
+		_, err := ParseScript([]byte(script))
+		require.ErrorContains(t, err, expected, script)
+	}
+}
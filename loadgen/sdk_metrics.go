@@ -0,0 +1,234 @@
+package loadgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+// CapturedMetric is a metric series recorded by a CapturingMetricsHandler.
+type CapturedMetric struct {
+	Kind string
+	Name string
+	Tags map[string]string
+	// Sum of counter increments.
+	Count int64
+	// Last gauge value.
+	Value float64
+	// Number, sum, minimum and maximum of timer recordings, kept instead of the recordings to bound
+	// memory over long runs.
+	Recordings  int64
+	DurationSum time.Duration
+	DurationMin time.Duration
+	DurationMax time.Duration
+}
+
+// CapturingMetricsHandler is a client.MetricsHandler recording every counter, gauge and timer
+// update, per metric name and tags, for assertions after a run. Updates are passed on to the
+// delegate handler if any. It is safe for concurrent use.
+type CapturingMetricsHandler struct {
+	delegate client.MetricsHandler
+	tags     map[string]string
+	// Sorted tags, appended to metric names to key their series.
+	tagsKey string
+	store   *capturedMetrics
+}
+
+type capturedMetrics struct {
+	lock   sync.Mutex
+	series map[string]*CapturedMetric
+}
+
+// NewCapturingMetricsHandler creates a capturing handler passing updates on to the delegate, which
+// may be nil.
+func NewCapturingMetricsHandler(delegate client.MetricsHandler) *CapturingMetricsHandler {
+	return &CapturingMetricsHandler{
+		delegate: delegate,
+		store:    &capturedMetrics{series: map[string]*CapturedMetric{}},
+	}
+}
+
+func (h *CapturingMetricsHandler) WithTags(tags map[string]string) client.MetricsHandler {
+	merged := make(map[string]string, len(h.tags)+len(tags))
+	for k, v := range h.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	child := &CapturingMetricsHandler{tags: merged, tagsKey: capturedTagsKey(merged), store: h.store}
+	if h.delegate != nil {
+		child.delegate = h.delegate.WithTags(tags)
+	}
+	return child
+}
+
+func (h *CapturingMetricsHandler) Counter(name string) client.MetricsCounter {
+	var delegate client.MetricsCounter
+	if h.delegate != nil {
+		delegate = h.delegate.Counter(name)
+	}
+	return capturedCounter{h.series("counter", name), h.store, delegate}
+}
+
+func (h *CapturingMetricsHandler) Gauge(name string) client.MetricsGauge {
+	var delegate client.MetricsGauge
+	if h.delegate != nil {
+		delegate = h.delegate.Gauge(name)
+	}
+	return capturedGauge{h.series("gauge", name), h.store, delegate}
+}
+
+func (h *CapturingMetricsHandler) Timer(name string) client.MetricsTimer {
+	var delegate client.MetricsTimer
+	if h.delegate != nil {
+		delegate = h.delegate.Timer(name)
+	}
+	return capturedTimer{h.series("timer", name), h.store, delegate}
+}
+
+func capturedTagsKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var key strings.Builder
+	for _, k := range keys {
+		key.WriteString(" " + k + "=" + tags[k])
+	}
+	return key.String()
+}
+
+// series returns the series of the metric with the handler's tags, creating it if needed.
+func (h *CapturingMetricsHandler) series(kind, name string) *CapturedMetric {
+	key := kind + " " + name + h.tagsKey
+	h.store.lock.Lock()
+	defer h.store.lock.Unlock()
+	series, ok := h.store.series[key]
+	if !ok {
+		series = &CapturedMetric{Kind: kind, Name: name, Tags: h.tags}
+		h.store.series[key] = series
+	}
+	return series
+}
+
+type capturedCounter struct {
+	series   *CapturedMetric
+	store    *capturedMetrics
+	delegate client.MetricsCounter
+}
+
+func (c capturedCounter) Inc(incr int64) {
+	c.store.lock.Lock()
+	c.series.Count += incr
+	c.store.lock.Unlock()
+	if c.delegate != nil {
+		c.delegate.Inc(incr)
+	}
+}
+
+type capturedGauge struct {
+	series   *CapturedMetric
+	store    *capturedMetrics
+	delegate client.MetricsGauge
+}
+
+func (g capturedGauge) Update(value float64) {
+	g.store.lock.Lock()
+	g.series.Value = value
+	g.store.lock.Unlock()
+	if g.delegate != nil {
+		g.delegate.Update(value)
+	}
+}
+
+type capturedTimer struct {
+	series   *CapturedMetric
+	store    *capturedMetrics
+	delegate client.MetricsTimer
+}
+
+func (t capturedTimer) Record(d time.Duration) {
+	t.store.lock.Lock()
+	if t.series.Recordings == 0 || d < t.series.DurationMin {
+		t.series.DurationMin = d
+	}
+	if d > t.series.DurationMax {
+		t.series.DurationMax = d
+	}
+	t.series.Recordings++
+	t.series.DurationSum += d
+	t.store.lock.Unlock()
+	if t.delegate != nil {
+		t.delegate.Record(d)
+	}
+}
+
+// Find returns copies of the captured series of the named metric whose tags include all the given
+// tags, in no particular order.
+func (h *CapturingMetricsHandler) Find(name string, tags map[string]string) []CapturedMetric {
+	h.store.lock.Lock()
+	defer h.store.lock.Unlock()
+	var found []CapturedMetric
+	for _, series := range h.store.series {
+		if series.Name != name || !hasTags(series.Tags, tags) {
+			continue
+		}
+		found = append(found, *series)
+	}
+	return found
+}
+
+func hasTags(tags, subset map[string]string) bool {
+	for k, v := range subset {
+		if tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// CounterTotal returns the sum of the named counter over all series with the given tags.
+func (h *CapturingMetricsHandler) CounterTotal(name string, tags map[string]string) int64 {
+	var total int64
+	for _, series := range h.Find(name, tags) {
+		total += series.Count
+	}
+	return total
+}
+
+// TimerCount returns the number of recordings of the named timer over all series with the given
+// tags.
+func (h *CapturingMetricsHandler) TimerCount(name string, tags map[string]string) int {
+	var count int
+	for _, series := range h.Find(name, tags) {
+		count += int(series.Recordings)
+	}
+	return count
+}
+
+// AssertCounterAtLeast returns an error if the total of the named counter over all series with the
+// given tags is below min.
+func (h *CapturingMetricsHandler) AssertCounterAtLeast(name string, tags map[string]string, min int64) error {
+	if total := h.CounterTotal(name, tags); total < min {
+		return fmt.Errorf("SDK metric %v%v totals %v, expected at least %v", name, formatTags(tags), total, min)
+	}
+	return nil
+}
+
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
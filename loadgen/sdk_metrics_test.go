@@ -0,0 +1,46 @@
+package loadgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapturingMetricsHandler(t *testing.T) {
+	delegate := newRecordingMetricsHandler()
+	handler := NewCapturingMetricsHandler(delegate)
+	tagged := handler.WithTags(map[string]string{"namespace": "default"})
+	tagged.WithTags(map[string]string{"operation": "StartWorkflowExecution"}).Counter("temporal_request").Inc(2)
+	tagged.WithTags(map[string]string{"operation": "SignalWorkflowExecution"}).Counter("temporal_request").Inc(1)
+	tagged.Gauge("temporal_sticky_cache_size").Update(3)
+	tagged.Gauge("temporal_sticky_cache_size").Update(5)
+	tagged.Timer("temporal_workflow_endtoend_latency").Record(time.Second)
+	tagged.Timer("temporal_workflow_endtoend_latency").Record(3 * time.Second)
+
+	require.Equal(t, int64(3), handler.CounterTotal("temporal_request", map[string]string{"namespace": "default"}))
+	require.Equal(t, int64(2), handler.CounterTotal("temporal_request",
+		map[string]string{"operation": "StartWorkflowExecution"}))
+	require.Zero(t, handler.CounterTotal("temporal_request", map[string]string{"namespace": "other"}))
+	gauges := handler.Find("temporal_sticky_cache_size", nil)
+	require.Len(t, gauges, 1)
+	require.Equal(t, 5.0, gauges[0].Value)
+	require.Equal(t, 2, handler.TimerCount("temporal_workflow_endtoend_latency", nil))
+	timers := handler.Find("temporal_workflow_endtoend_latency", nil)
+	require.Len(t, timers, 1)
+	require.Equal(t, 4*time.Second, timers[0].DurationSum)
+	require.Equal(t, time.Second, timers[0].DurationMin)
+	require.Equal(t, 3*time.Second, timers[0].DurationMax)
+	require.NoError(t, handler.AssertCounterAtLeast("temporal_request", nil, 3))
+	require.EqualError(t, handler.AssertCounterAtLeast("temporal_request", map[string]string{"namespace": "default"}, 4),
+		"SDK metric temporal_request{namespace=default} totals 3, expected at least 4")
+
+	// Updates still reach the delegate
+	require.Len(t, *delegate.recorded, 6)
+	require.Equal(t, recordedMetric{
+		kind:  "counter",
+		name:  "temporal_request",
+		tags:  map[string]string{"namespace": "default", "operation": "StartWorkflowExecution"},
+		value: 2,
+	}, (*delegate.recorded)[0])
+}
@@ -0,0 +1,148 @@
+package loadgen
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OtherSegment is the name of the time of an iteration outside any of its segments in its
+// breakdown.
+const OtherSegment = "other"
+
+// SegmentDuration is the time spent in a segment of an iteration, see Run.BeginSegment. Nested
+// segments have the names of their enclosing segments joined by ";" as Path, and their time is
+// excluded from that of the enclosing segments.
+type SegmentDuration struct {
+	Path     string
+	Duration time.Duration
+}
+
+// iterationSegments records the segments of an iteration.
+type iterationSegments struct {
+	lock  sync.Mutex
+	stack []string
+	// Self time per segment path, in order of first occurrence
+	paths     []string
+	durations map[string]time.Duration
+	// Start of the innermost open segment's current stretch of self time
+	since time.Time
+}
+
+func newIterationSegments() *iterationSegments {
+	return &iterationSegments{durations: map[string]time.Duration{}}
+}
+
+// BeginSegment starts a segment of the iteration with the given name, e.g. "start", "await" or
+// "verify", and returns the function ending it. Segments begun before the previous one ended are
+// nested in it. Segments must be ended in reverse order of beginning, so they suit sequential
+// iteration code, not concurrent branches. Segment times are exported in the iteration's
+// breakdown, see ScenarioInfo.SegmentBreakdownPath.
+func (r *Run) BeginSegment(name string) (end func()) {
+	p := r.segments
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.accrue(time.Now())
+	p.stack = append(p.stack, name)
+	depth := len(p.stack)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			p.accrue(time.Now())
+			if len(p.stack) >= depth {
+				p.stack = p.stack[:depth-1]
+			}
+		})
+	}
+}
+
+// Segment runs the function as a segment of the iteration with the given name, see BeginSegment.
+func (r *Run) Segment(name string, fn func() error) error {
+	end := r.BeginSegment(name)
+	defer end()
+	return fn()
+}
+
+// accrue adds the self time of the innermost open segment up to now.
+func (p *iterationSegments) accrue(now time.Time) {
+	if len(p.stack) > 0 {
+		path := strings.Join(p.stack, ";")
+		if _, ok := p.durations[path]; !ok {
+			p.paths = append(p.paths, path)
+		}
+		p.durations[path] += now.Sub(p.since)
+	}
+	p.since = now
+}
+
+// SegmentBreakdown returns the time spent in each segment of the iteration so far, in order of
+// first occurrence. With the total time of the iteration, the time outside any segment is added as
+// OtherSegment, so that the durations sum to the total.
+func (r *Run) SegmentBreakdown(total time.Duration) []SegmentDuration {
+	var breakdown []SegmentDuration
+	var inSegments time.Duration
+	p := r.segments
+	p.lock.Lock()
+	for _, path := range p.paths {
+		breakdown = append(breakdown, SegmentDuration{Path: path, Duration: p.durations[path]})
+		inSegments += p.durations[path]
+	}
+	p.lock.Unlock()
+	if other := total - inSegments; other > 0 {
+		breakdown = append(breakdown, SegmentDuration{Path: OtherSegment, Duration: other})
+	}
+	return breakdown
+}
+
+// segmentBreakdownFile streams the segment breakdown of every completed iteration to a file in the
+// folded stack format of flame graph tools, one
+// "<scenario>;<iteration>;<segment path> <microseconds>" line per segment. Aggregate over
+// iterations by dropping the iteration frame. It is safe for concurrent use.
+type segmentBreakdownFile struct {
+	lock sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+	err  error
+}
+
+func createSegmentBreakdownFile(path string) (*segmentBreakdownFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating segment breakdown file: %w", err)
+	}
+	return &segmentBreakdownFile{file: file, buf: bufio.NewWriter(file)}, nil
+}
+
+func (f *segmentBreakdownFile) record(run *Run, total time.Duration) {
+	breakdown := run.SegmentBreakdown(total)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, segment := range breakdown {
+		if f.err != nil {
+			return
+		}
+		_, f.err = fmt.Fprintf(f.buf, "%s;iteration-%d;%s %d\n",
+			run.ScenarioName, run.Iteration, segment.Path, segment.Duration.Microseconds())
+	}
+}
+
+// Close flushes and closes the file, returning the first error that occurred while writing.
+func (f *segmentBreakdownFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.buf.Flush(); f.err == nil {
+		f.err = err
+	}
+	if err := f.file.Close(); f.err == nil {
+		f.err = err
+	}
+	if f.err != nil {
+		return fmt.Errorf("failed writing segment breakdown: %w", f.err)
+	}
+	return nil
+}
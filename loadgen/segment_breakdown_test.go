@@ -0,0 +1,100 @@
+package loadgen
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSegmentBreakdown(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	run := info.NewRun(1)
+	start := time.Now()
+	endStart := run.BeginSegment("start")
+	time.Sleep(10 * time.Millisecond)
+	endStart()
+	err := run.Segment("await", func() error {
+		time.Sleep(10 * time.Millisecond)
+		return run.Segment("verify", func() error {
+			time.Sleep(10 * time.Millisecond)
+			return errors.New("boom")
+		})
+	})
+	require.EqualError(t, err, "boom")
+	// Ending twice has no effect
+	endStart()
+	time.Sleep(5 * time.Millisecond)
+	total := time.Since(start)
+
+	breakdown := run.SegmentBreakdown(total)
+	var paths []string
+	var sum time.Duration
+	for _, segment := range breakdown {
+		paths = append(paths, segment.Path)
+		sum += segment.Duration
+		if segment.Path != OtherSegment {
+			require.GreaterOrEqual(t, segment.Duration, 10*time.Millisecond, segment.Path)
+		}
+	}
+	require.Equal(t, []string{"start", "await", "await;verify", OtherSegment}, paths)
+	require.Equal(t, total, sum)
+	// Time outside segments is only the trailing sleep, with some scheduling slack
+	require.GreaterOrEqual(t, breakdown[3].Duration, 5*time.Millisecond)
+	require.Less(t, breakdown[3].Duration, 50*time.Millisecond)
+}
+
+func TestGenericExecutorExportsSegmentBreakdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "segments.folded")
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 4, MaxConcurrent: 2})
+	info.SegmentBreakdownPath = path
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			if err := run.Segment("start", func() error {
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			}); err != nil {
+				return err
+			}
+			return run.Segment("await", func() error {
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+		},
+	}
+	require.NoError(t, executor.Run(context.Background(), info))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	totals := map[string]time.Duration{}
+	segments := map[string]time.Duration{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		stack, value, ok := strings.Cut(scanner.Text(), " ")
+		require.True(t, ok, scanner.Text())
+		micros, err := strconv.ParseInt(value, 10, 64)
+		require.NoError(t, err)
+		frames := strings.SplitN(stack, ";", 3)
+		require.Len(t, frames, 3)
+		require.Equal(t, "test", frames[0])
+		totals[frames[1]] += time.Duration(micros) * time.Microsecond
+		if frames[2] != OtherSegment {
+			segments[frames[1]] += time.Duration(micros) * time.Microsecond
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, totals, 4)
+	for iteration, total := range totals {
+		// Segments cover the iteration but for executor overhead, and with it sum to the total
+		require.GreaterOrEqual(t, segments[iteration], 15*time.Millisecond, iteration)
+		require.InDelta(t, float64(total), float64(segments[iteration]), float64(20*time.Millisecond), iteration)
+	}
+}
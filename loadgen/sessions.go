@@ -0,0 +1,131 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/sdk/client"
+)
+
+// SessionSummary reports the operations of a SessionExecutor run. The latency of whole sessions
+// is the run's RunResult.Latency, since each iteration is a session.
+type SessionSummary struct {
+	OperationsPerSession int `json:"operationsPerSession"`
+	// Operations that completed successfully.
+	Operations       int            `json:"operations"`
+	OperationLatency LatencySummary `json:"operationLatency"`
+}
+
+// SessionExecutor is an Executor for session-shaped workloads: each iteration is a session that
+// starts a workflow, performs a number of operations on it one after the other, then closes it.
+// Sessions are the unit of concurrency, i.e. RunConfiguration.Iterations and MaxConcurrent count
+// sessions. Operation latencies are recorded in the omes_session_operation_latency timer and
+// summarized in RunResult.Sessions.
+type SessionExecutor struct {
+	// Starts the workflow of the session. Default starts a kitchen sink workflow that runs until told
+	// otherwise by signal.
+	Start func(ctx context.Context, run *Run) (client.WorkflowRun, error)
+	// Performs the operation with the given index, from 0, on the workflow of the session. Default
+	// updates the kitchen sink's "last_write" state key with "do_actions_update".
+	Operation func(ctx context.Context, run *Run, execution client.WorkflowRun, operation int) error
+	// Closes the session. Default signals the kitchen sink workflow to complete and awaits it.
+	Close func(ctx context.Context, run *Run, execution client.WorkflowRun) error
+	// Operations per session. Default is the "operations-per-session" scenario option, or 5.
+	OperationsPerSession int
+	// Default configuration if any.
+	DefaultConfiguration RunConfiguration
+}
+
+func (s *SessionExecutor) GetDefaultConfiguration() RunConfiguration {
+	return s.DefaultConfiguration
+}
+
+// sessionStats accumulates the operation latencies of a SessionExecutor run.
+type sessionStats struct {
+	lock      sync.Mutex
+	latencies []time.Duration
+}
+
+// Run implements Executor.
+func (s *SessionExecutor) Run(ctx context.Context, info ScenarioInfo) error {
+	operations := s.OperationsPerSession
+	if operations <= 0 {
+		operations = info.ScenarioOptionInt("operations-per-session", 5)
+	}
+	if operations <= 0 {
+		return fmt.Errorf("operations per session must be positive")
+	}
+	start, operation, closeSession := s.Start, s.Operation, s.Close
+	if start == nil {
+		start = startSessionWorkflow
+	}
+	if operation == nil {
+		operation = updateSessionWorkflow
+	}
+	if closeSession == nil {
+		closeSession = closeSessionWorkflow
+	}
+	stats := &sessionStats{}
+	executor := &GenericExecutor{
+		DefaultConfiguration: s.DefaultConfiguration,
+		Execute: func(ctx context.Context, run *Run) error {
+			execution, err := start(ctx, run)
+			if err != nil {
+				return fmt.Errorf("failed to start session workflow: %w", err)
+			}
+			for i := 0; i < operations; i++ {
+				operationStart := time.Now()
+				if err := operation(ctx, run, execution, i); err != nil {
+					return fmt.Errorf("operation %v of session workflow %v failed: %w", i, execution.GetID(), err)
+				}
+				latency := time.Since(operationStart)
+				run.RecordTimer("omes_session_operation_latency", nil, latency)
+				stats.lock.Lock()
+				stats.latencies = append(stats.latencies, latency)
+				stats.lock.Unlock()
+			}
+			if err := closeSession(ctx, run, execution); err != nil {
+				return fmt.Errorf("failed to close session workflow %v: %w", execution.GetID(), err)
+			}
+			return nil
+		},
+		completeResult: func(result *RunResult) {
+			stats.lock.Lock()
+			defer stats.lock.Unlock()
+			result.Sessions = &SessionSummary{
+				OperationsPerSession: operations,
+				Operations:           len(stats.latencies),
+				OperationLatency:     NewLatencySummary(append([]time.Duration(nil), stats.latencies...)),
+			}
+		},
+	}
+	return executor.Run(ctx, info)
+}
+
+func startSessionWorkflow(ctx context.Context, run *Run) (client.WorkflowRun, error) {
+	workflow, args := defaultWorkflowAndArgs()
+	return run.Client.ExecuteWorkflow(ctx, run.StartWorkflowOptions(), workflow, args...)
+}
+
+func updateSessionWorkflow(ctx context.Context, run *Run, execution client.WorkflowRun, operation int) error {
+	handle, err := run.Client.UpdateWorkflow(ctx, execution.GetID(), execution.GetRunID(), "do_actions_update",
+		lastWriteUpdate(operation))
+	if err != nil {
+		return err
+	}
+	return handle.Get(ctx, nil)
+}
+
+func closeSessionWorkflow(ctx context.Context, run *Run, execution client.WorkflowRun) error {
+	err := run.Client.SignalWorkflow(ctx, execution.GetID(), execution.GetRunID(), "do_actions_signal",
+		&kitchensink.DoSignal_DoSignalActions{
+			Variant: &kitchensink.DoSignal_DoSignalActions_DoActions{DoActions: kitchensink.EmptyResultActionSet()},
+		})
+	if err != nil {
+		return err
+	}
+	return run.getWorkflowResult(ctx, execution, nil)
+}
@@ -0,0 +1,78 @@
+package loadgen
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+func TestSessionExecutorOperationsPerSession(t *testing.T) {
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 4, MaxConcurrent: 2})
+	info.ScenarioOptions = map[string]string{"operations-per-session": "3"}
+	var result *RunResult
+	info.ReportSinks = []ReportSink{reportSinkFunc(func(r *RunResult) { result = r })}
+	require.NoError(t, (&SessionExecutor{}).Run(context.Background(), info))
+
+	// Each session started its workflow, updated it 3 times, then signaled it to complete
+	require.Len(t, fake.Calls("ExecuteWorkflow"), 4)
+	updates := map[string]int{}
+	for _, call := range fake.Calls("UpdateWorkflow") {
+		require.Equal(t, "do_actions_update", call.Name)
+		updates[call.WorkflowID]++
+	}
+	require.Equal(t, map[string]int{"w-test-run-1": 3, "w-test-run-2": 3, "w-test-run-3": 3, "w-test-run-4": 3}, updates)
+	require.Len(t, fake.Calls("SignalWorkflow"), 4)
+
+	require.NotNil(t, result)
+	require.Equal(t, 4, result.IterationsCompleted)
+	require.Equal(t, &SessionSummary{
+		OperationsPerSession: 3,
+		Operations:           12,
+		OperationLatency:     result.Sessions.OperationLatency,
+	}, result.Sessions)
+	require.Greater(t, result.Sessions.OperationLatency.Max, time.Duration(0))
+}
+
+func TestSessionExecutorConcurrency(t *testing.T) {
+	var lock sync.Mutex
+	var open, maxOpen int
+	operations := map[int]int{}
+	executor := &SessionExecutor{
+		Start: func(ctx context.Context, run *Run) (client.WorkflowRun, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			open++
+			if open > maxOpen {
+				maxOpen = open
+			}
+			return &FakeWorkflowRun{ID: "session"}, nil
+		},
+		Operation: func(ctx context.Context, run *Run, execution client.WorkflowRun, operation int) error {
+			lock.Lock()
+			defer lock.Unlock()
+			require.Equal(t, operations[run.Iteration], operation)
+			operations[run.Iteration]++
+			return nil
+		},
+		Close: func(ctx context.Context, run *Run, execution client.WorkflowRun) error {
+			time.Sleep(10 * time.Millisecond)
+			lock.Lock()
+			defer lock.Unlock()
+			open--
+			return nil
+		},
+		OperationsPerSession: 2,
+	}
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 12, MaxConcurrent: 3})
+	require.NoError(t, executor.Run(context.Background(), info))
+	require.Equal(t, 3, maxOpen)
+	require.Len(t, operations, 12)
+	for iteration, count := range operations {
+		require.Equal(t, 2, count, "session %v", iteration)
+	}
+}
@@ -0,0 +1,120 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+)
+
+// SignalOrderViolationError is returned by Run.ExecuteSignalOrderWorkflow when the workflow did not
+// observe the signals in the order they were sent.
+type SignalOrderViolationError struct {
+	WorkflowID string
+	// Number of signals sent, numbered from 1.
+	Sent int
+	// Numbers of the signals in the order the workflow observed them.
+	Observed []int
+}
+
+func (e *SignalOrderViolationError) Error() string {
+	for i, seq := range e.Observed {
+		if seq != i+1 {
+			return fmt.Sprintf("workflow %v observed signal %v at position %v of the %v sent, observed order: %v",
+				e.WorkflowID, seq, i+1, e.Sent, e.Observed)
+		}
+	}
+	return fmt.Sprintf("workflow %v observed %v of the %v signals sent, observed order: %v",
+		e.WorkflowID, len(e.Observed), e.Sent, e.Observed)
+}
+
+// ExecuteSignalOrderWorkflow starts a kitchen sink workflow, sends it the given number of signals
+// numbered from 1 one after the other, then queries the order the workflow observed them in until
+// it observed as many (see kitchensink.OrderedSignalName), requiring a Go worker. The workflow is
+// then signaled to complete and awaited. If the observed order differs from the sent order, or not
+// all signals are observed within the timeout, a *SignalOrderViolationError with the workflow ID is
+// returned and counted in the omes_signal_order_violations counter. The time from the first signal
+// until all were observed is recorded in the omes_signal_order_latency timer.
+func (r *Run) ExecuteSignalOrderWorkflow(ctx context.Context, signals int, timeout time.Duration) error {
+	if signals <= 0 {
+		return fmt.Errorf("signal order requires at least one signal")
+	}
+	execution, err := r.Client.ExecuteWorkflow(ctx, r.StartWorkflowOptions(), "kitchenSink", &kitchensink.WorkflowInput{})
+	if err != nil {
+		return fmt.Errorf("failed to start kitchen sink workflow: %w", err)
+	}
+	start := time.Now()
+	for seq := 1; seq <= signals; seq++ {
+		err := r.Client.SignalWorkflow(ctx, execution.GetID(), execution.GetRunID(), kitchensink.OrderedSignalName, seq)
+		if err != nil {
+			return fmt.Errorf("failed sending signal %v of %v to workflow %v: %w", seq, signals, execution.GetID(), err)
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	var observed []int
+	for {
+		if observed, err = r.observedSignalOrder(ctx, execution.GetID(), execution.GetRunID()); err != nil {
+			return err
+		} else if len(observed) >= signals || !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(stateQueryPollInterval):
+		}
+	}
+	inOrder := len(observed) == signals
+	for i, seq := range observed {
+		inOrder = inOrder && seq == i+1
+	}
+	var violation error
+	if !inOrder {
+		violation = &SignalOrderViolationError{WorkflowID: execution.GetID(), Sent: signals, Observed: observed}
+		r.RecordCounter("omes_signal_order_violations", nil, 1)
+	} else {
+		r.RecordTimer("omes_signal_order_latency", nil, time.Since(start))
+	}
+
+	err = r.Client.SignalWorkflow(ctx, execution.GetID(), execution.GetRunID(), "do_actions_signal",
+		&kitchensink.DoSignal_DoSignalActions{
+			Variant: &kitchensink.DoSignal_DoSignalActions_DoActions{DoActions: kitchensink.EmptyResultActionSet()},
+		})
+	if err == nil {
+		err = r.getWorkflowResult(ctx, execution, nil)
+	}
+	if err != nil && violation == nil {
+		return fmt.Errorf("failed completing workflow %v: %w", execution.GetID(), err)
+	}
+	return violation
+}
+
+// observedSignalOrder queries the numbers of the ordered signals the kitchen sink workflow observed,
+// in order.
+func (r *Run) observedSignalOrder(ctx context.Context, workflowID, runID string) ([]int, error) {
+	value, err := r.Client.QueryWorkflow(ctx, workflowID, runID, "report_state")
+	if err != nil {
+		return nil, fmt.Errorf("failed querying state of workflow %v: %w", workflowID, err)
+	}
+	var state kitchensink.WorkflowState
+	if err := value.Get(&state); err != nil {
+		return nil, fmt.Errorf("failed decoding state of workflow %v: %w", workflowID, err)
+	}
+	received := state.Kvs[kitchensink.OrderedSignalsKey]
+	if received == "" {
+		return nil, nil
+	}
+	var observed []int
+	for _, field := range strings.Split(received, ",") {
+		seq, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ordered signals %q in state of workflow %v", received, workflowID)
+		}
+		observed = append(observed, seq)
+	}
+	return observed, nil
+}
@@ -0,0 +1,97 @@
+package loadgen
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+)
+
+// signalOrderClient returns a fake client whose kitchen sink workflows accumulate ordered signals
+// in their state, in the order given by reorder from the order received.
+func signalOrderClient(reorder func([]string) []string) *FakeClient {
+	var lock sync.Mutex
+	var received []string
+	return &FakeClient{
+		OnSignalWorkflow: func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+			if signalName == kitchensink.OrderedSignalName {
+				lock.Lock()
+				defer lock.Unlock()
+				received = append(received, strconv.Itoa(arg.(int)))
+			}
+			return nil
+		},
+		OnQueryWorkflow: func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			observed := reorder(append([]string(nil), received...))
+			return &kitchensink.WorkflowState{Kvs: map[string]string{
+				kitchensink.OrderedSignalsKey: strings.Join(observed, ","),
+			}}, nil
+		},
+	}
+}
+
+func TestExecuteSignalOrderWorkflow(t *testing.T) {
+	fake := signalOrderClient(func(received []string) []string { return received })
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	require.NoError(t, info.NewRun(1).ExecuteSignalOrderWorkflow(context.Background(), 5, time.Second))
+
+	signals := fake.Calls("SignalWorkflow")
+	require.Len(t, signals, 6)
+	for i, signal := range signals[:5] {
+		require.Equal(t, kitchensink.OrderedSignalName, signal.Name)
+		require.Equal(t, i+1, signal.Args[0])
+	}
+	require.Equal(t, "do_actions_signal", signals[5].Name)
+	require.Len(t, *handler.recorded, 1)
+	require.Equal(t, "omes_signal_order_latency", (*handler.recorded)[0].name)
+}
+
+func TestExecuteSignalOrderWorkflowViolation(t *testing.T) {
+	// The workflow observes the second and third signals swapped
+	fake := signalOrderClient(func(received []string) []string {
+		if len(received) >= 3 {
+			received[1], received[2] = received[2], received[1]
+		}
+		return received
+	})
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	err := info.NewRun(1).ExecuteSignalOrderWorkflow(context.Background(), 4, time.Second)
+	var violation *SignalOrderViolationError
+	require.ErrorAs(t, err, &violation)
+	require.Equal(t, &SignalOrderViolationError{WorkflowID: "w-test-run-1", Sent: 4, Observed: []int{1, 3, 2, 4}}, violation)
+	require.EqualError(t, err,
+		"workflow w-test-run-1 observed signal 3 at position 2 of the 4 sent, observed order: [1 3 2 4]")
+	require.Equal(t, []recordedMetric{{
+		kind: "counter", name: "omes_signal_order_violations", tags: map[string]string{"scenario": "test"}, value: 1,
+	}}, *handler.recorded)
+	// The workflow is still completed
+	require.Equal(t, "do_actions_signal", fake.Calls("SignalWorkflow")[4].Name)
+}
+
+func TestExecuteSignalOrderWorkflowMissingSignals(t *testing.T) {
+	prev := stateQueryPollInterval
+	stateQueryPollInterval = time.Millisecond
+	t.Cleanup(func() { stateQueryPollInterval = prev })
+
+	// The workflow never observes the last signal
+	fake := signalOrderClient(func(received []string) []string {
+		if len(received) == 3 {
+			return received[:2]
+		}
+		return received
+	})
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	err := info.NewRun(1).ExecuteSignalOrderWorkflow(context.Background(), 3, 20*time.Millisecond)
+	require.EqualError(t, err, "workflow w-test-run-1 observed 2 of the 3 signals sent, observed order: [1 2]")
+}
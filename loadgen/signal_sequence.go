@@ -0,0 +1,57 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+// SequenceSignal is a signal of a sequence sent by Run.SignalSequenceAndAwait.
+type SequenceSignal struct {
+	Name string
+	Arg  interface{}
+	// Delay before sending the signal, after the previous one was sent.
+	Delay time.Duration
+}
+
+// SignalSequenceAndAwait sends the signals to the started workflow one at a time in order, then
+// waits for the workflow to complete, putting its result in valuePtr if not nil. The time from
+// starting the sequence until completion is returned and recorded in the
+// omes_signal_sequence_latency timer. If a signal fails to send, the workflow is terminated since it
+// would otherwise wait for the rest of the sequence forever.
+func (r *Run) SignalSequenceAndAwait(
+	ctx context.Context,
+	execution client.WorkflowRun,
+	signals []SequenceSignal,
+	valuePtr interface{},
+) (time.Duration, error) {
+	start := time.Now()
+	for i, signal := range signals {
+		if signal.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return time.Since(start), ctx.Err()
+			case <-time.After(signal.Delay):
+			}
+		}
+		err := r.Client.SignalWorkflow(ctx, execution.GetID(), execution.GetRunID(), signal.Name, signal.Arg)
+		if err != nil {
+			err = fmt.Errorf("failed sending signal %v (%v of %v) to workflow %v: %w",
+				signal.Name, i+1, len(signals), execution.GetID(), err)
+			r.Logger.Error(err)
+			if termErr := r.Client.TerminateWorkflow(
+				ctx, execution.GetID(), execution.GetRunID(), "signal sequence failed"); termErr != nil {
+				r.Logger.Warnf("Failed terminating workflow %v: %v", execution.GetID(), termErr)
+			}
+			return time.Since(start), err
+		}
+	}
+	if err := r.getWorkflowResult(ctx, execution, valuePtr); err != nil {
+		return time.Since(start), fmt.Errorf("workflow %v failed after signal sequence: %w", execution.GetID(), err)
+	}
+	elapsed := time.Since(start)
+	r.RecordTimer("omes_signal_sequence_latency", nil, elapsed)
+	return elapsed, nil
+}
@@ -0,0 +1,57 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalSequenceAndAwait(t *testing.T) {
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	execution, err := fake.ExecuteWorkflow(context.Background(), run.StartWorkflowOptions(), "wf")
+	require.NoError(t, err)
+	elapsed, err := run.SignalSequenceAndAwait(context.Background(), execution, []SequenceSignal{
+		{Name: "first", Arg: 1},
+		{Name: "second", Arg: 2, Delay: 20 * time.Millisecond},
+		{Name: "third", Arg: 3},
+	}, nil)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+
+	var names []string
+	for _, call := range fake.Calls("SignalWorkflow") {
+		require.Equal(t, "w-test-run-1", call.WorkflowID)
+		names = append(names, call.Name)
+	}
+	require.Equal(t, []string{"first", "second", "third"}, names)
+	require.Empty(t, fake.Calls("TerminateWorkflow"))
+}
+
+func TestSignalSequenceFailsMidSequence(t *testing.T) {
+	fake := &FakeClient{
+		OnSignalWorkflow: func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+			if signalName == "second" {
+				return errors.New("signal rejected")
+			}
+			return nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	execution, err := fake.ExecuteWorkflow(context.Background(), run.StartWorkflowOptions(), "wf")
+	require.NoError(t, err)
+	_, err = run.SignalSequenceAndAwait(context.Background(), execution, []SequenceSignal{
+		{Name: "first"}, {Name: "second"}, {Name: "third"},
+	}, nil)
+	require.ErrorContains(t, err, "failed sending signal second (2 of 3)")
+	require.ErrorContains(t, err, "signal rejected")
+	require.Len(t, fake.Calls("SignalWorkflow"), 2)
+	terminations := fake.Calls("TerminateWorkflow")
+	require.Len(t, terminations, 1)
+	require.Equal(t, "w-test-run-1", terminations[0].WorkflowID)
+}
@@ -0,0 +1,151 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultSignalStormConcurrency is the default maximum of signals of a SignalStorm in flight.
+const DefaultSignalStormConcurrency = 10
+
+// maxSignalStormErrors bounds the distinct errors reported by Run.SignalStorm.
+const maxSignalStormErrors = 5
+
+// SignalStorm is a burst of signals sent to a single workflow by Run.SignalStorm, for benchmarking
+// signal buffering.
+type SignalStorm struct {
+	WorkflowID string
+	// Run of the workflow to signal. Default is the latest.
+	RunID      string
+	SignalName string
+	// Returns the argument of the i-th signal, from 0. Default is i.
+	Arg func(i int) interface{}
+	// Number of signals to send.
+	Count int
+	// Target signals per second. Default is no limit.
+	Rate float64
+	// Maximum signals in flight, so that slow signals do not hold back the rate. Default is
+	// DefaultSignalStormConcurrency.
+	Concurrency int
+}
+
+// SignalStormResult is the outcome of a SignalStorm.
+type SignalStormResult struct {
+	Sent   int
+	Failed int
+	// Time from the first signal until the last was sent or failed.
+	Duration time.Duration
+	// Signals sent per second achieved.
+	Rate float64
+}
+
+// SignalStorm sends storm.Count signals to the workflow, paced at storm.Rate. Signals are sent
+// concurrently, so the rate holds as long as storm.Concurrency signals in flight keep up with it.
+// Sending continues past failed signals, which are aggregated into the returned error with the
+// count of each distinct error. The achieved signals per second is returned and recorded in the
+// omes_signal_storm_rate gauge.
+func (r *Run) SignalStorm(ctx context.Context, storm SignalStorm) (SignalStormResult, error) {
+	concurrency := storm.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultSignalStormConcurrency
+	}
+	arg := storm.Arg
+	if arg == nil {
+		arg = func(i int) interface{} { return i }
+	}
+	var lock sync.Mutex
+	var result SignalStormResult
+	errorCounts := map[string]int{}
+	start := time.Now()
+	ctxErr := runPaced(ctx, storm.Count, storm.Rate, concurrency, func(i int) {
+		err := r.Client.SignalWorkflow(ctx, storm.WorkflowID, storm.RunID, storm.SignalName, arg(i))
+		lock.Lock()
+		defer lock.Unlock()
+		if err != nil {
+			result.Failed++
+			errorCounts[err.Error()]++
+		} else {
+			result.Sent++
+		}
+	})
+	result.Duration = time.Since(start)
+	if result.Duration > 0 {
+		result.Rate = float64(result.Sent) / result.Duration.Seconds()
+	}
+	r.RecordGauge("omes_signal_storm_rate", nil, result.Rate)
+	r.Logger.Debugf("Sent %v signal(s) %v to workflow %v in %v, %.2f signals/sec",
+		result.Sent, storm.SignalName, storm.WorkflowID, result.Duration, result.Rate)
+
+	if ctxErr != nil {
+		return result, fmt.Errorf("signal storm to workflow %v interrupted after %v of %v signals: %w",
+			storm.WorkflowID, result.Sent+result.Failed, storm.Count, ctxErr)
+	} else if result.Failed > 0 {
+		return result, fmt.Errorf("%v of %v signals %v to workflow %v failed: %w",
+			result.Failed, storm.Count, storm.SignalName, storm.WorkflowID, aggregateErrors(errorCounts))
+	}
+	return result, nil
+}
+
+// runPaced calls fn with i from 0 to count-1, each in its own goroutine, on the schedule of the given
+// rate per second if positive, or as soon as one of the given number of concurrency slots frees up if
+// behind it. It waits for the calls to return, and returns the context error if the context ended
+// before every call was made.
+func runPaced(ctx context.Context, count int, rate float64, concurrency int, fn func(i int)) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	slots := make(chan struct{}, concurrency)
+	start := time.Now()
+	for i := 0; i < count; i++ {
+		if rate > 0 {
+			next := start.Add(time.Duration(float64(i) * float64(time.Second) / rate))
+			if wait := time.Until(next); wait > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(wait):
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case slots <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(i)
+		}(i)
+	}
+	return nil
+}
+
+// aggregateErrors joins the most frequent distinct errors, with their counts.
+func aggregateErrors(counts map[string]int) error {
+	messages := make([]string, 0, len(counts))
+	for message := range counts {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if counts[messages[i]] != counts[messages[j]] {
+			return counts[messages[i]] > counts[messages[j]]
+		}
+		return messages[i] < messages[j]
+	})
+	var errs []error
+	for i, message := range messages {
+		if i == maxSignalStormErrors {
+			errs = append(errs, fmt.Errorf("and %v more distinct error(s)", len(messages)-i))
+			break
+		}
+		errs = append(errs, fmt.Errorf("%v (x%v)", message, counts[message]))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalStorm(t *testing.T) {
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	result, err := run.SignalStorm(context.Background(), SignalStorm{
+		WorkflowID: "target",
+		SignalName: "storm",
+		Count:      50,
+		Rate:       500,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 50, result.Sent)
+	require.Zero(t, result.Failed)
+	// The last signal is due after 49 intervals of 2ms
+	require.GreaterOrEqual(t, result.Duration, 98*time.Millisecond)
+	require.Less(t, result.Duration, 500*time.Millisecond)
+	require.InDelta(t, 500, result.Rate, 100)
+
+	calls := fake.Calls("SignalWorkflow")
+	require.Len(t, calls, 50)
+	var args []interface{}
+	for _, call := range calls {
+		require.Equal(t, "target", call.WorkflowID)
+		require.Equal(t, "storm", call.Name)
+		args = append(args, call.Args[0])
+	}
+	expected := make([]interface{}, 50)
+	for i := range expected {
+		expected[i] = i
+	}
+	require.ElementsMatch(t, expected, args)
+}
+
+func TestSignalStormUnlimitedRate(t *testing.T) {
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	result, err := info.NewRun(1).SignalStorm(context.Background(), SignalStorm{
+		WorkflowID: "target",
+		SignalName: "storm",
+		Count:      200,
+		Arg:        func(i int) interface{} { return "signal" },
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, result.Sent)
+	require.Less(t, result.Duration, 500*time.Millisecond)
+	require.Len(t, fake.Calls("SignalWorkflow"), 200)
+}
+
+func TestSignalStormAggregatesErrors(t *testing.T) {
+	fake := &FakeClient{
+		OnSignalWorkflow: func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+			switch i := arg.(int); {
+			case i%5 == 0:
+				return errors.New("resource exhausted")
+			case i == 7:
+				return errors.New("unavailable")
+			}
+			return nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	result, err := info.NewRun(1).SignalStorm(context.Background(), SignalStorm{
+		WorkflowID: "target",
+		SignalName: "storm",
+		Count:      20,
+		Rate:       1000,
+	})
+	require.EqualError(t, err, "5 of 20 signals storm to workflow target failed: "+
+		"resource exhausted (x4)\nunavailable (x1)")
+	require.Equal(t, 15, result.Sent)
+	require.Equal(t, 5, result.Failed)
+	// Sending continued past failures
+	require.Len(t, fake.Calls("SignalWorkflow"), 20)
+}
+
+func TestSignalStormInterrupted(t *testing.T) {
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := info.NewRun(1).SignalStorm(ctx, SignalStorm{
+		WorkflowID: "target",
+		SignalName: "storm",
+		Count:      100,
+		Rate:       100,
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Greater(t, result.Sent, 0)
+	require.Less(t, result.Sent, 100)
+}
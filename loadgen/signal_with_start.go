@@ -0,0 +1,131 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+)
+
+// SignalWithStartDedupError is returned by SignalWithStartDedup.Verify when the signal-with-starts
+// created more than one workflow or not all their signals were delivered.
+type SignalWithStartDedupError struct {
+	// Distinct run IDs the signal-with-starts returned, sorted.
+	RunIDs []string
+	// Successful signal-with-starts.
+	Sent int
+	// Signals in the history of the workflow, if a single one was created.
+	Delivered int
+}
+
+func (e *SignalWithStartDedupError) Error() string {
+	if len(e.RunIDs) != 1 {
+		return fmt.Sprintf("%v signal-with-starts created %v workflows (run IDs %v), expected 1",
+			e.Sent, len(e.RunIDs), strings.Join(e.RunIDs, ", "))
+	}
+	return fmt.Sprintf("%v of %v signals of signal-with-starts delivered", e.Delivered, e.Sent)
+}
+
+// SignalWithStartDedup verifies the deduplication of SignalWithStart under concurrency: iterations
+// call Signal concurrently, each signal-with-starting the same workflow ID, and Verify checks that
+// exactly one workflow was created, i.e. every call returned the same run ID, and that its history
+// has every signal. Signal-with-start latencies are recorded in the omes_signal_with_start_latency
+// timer. The workflow is left running, to be removed with cleanup-scenario. It is safe for
+// concurrent use.
+type SignalWithStartDedup struct {
+	// Workflow ID targeted by every iteration. Default is "<WorkflowIDPrefix>signal-with-start".
+	WorkflowID string
+	// Workflow to start.
+	WorkflowSpec
+	// Signal sent, with the argument returned for the iteration. Default is the kitchen sink's
+	// "do_actions_signal" setting the "last_write" state key to the iteration.
+	SignalName string
+	SignalArg  func(run *Run) interface{}
+
+	lock   sync.Mutex
+	id     string
+	runIDs map[string]int
+}
+
+// Signal signal-with-starts the workflow for the iteration and records the run ID returned.
+func (d *SignalWithStartDedup) Signal(ctx context.Context, run *Run) error {
+	options := run.StartWorkflowOptions()
+	options.ID = d.workflowID(run.ScenarioInfo)
+	options.WorkflowExecutionErrorWhenAlreadyStarted = false
+	workflow, args := d.workflowAndArgs()
+	signalName, arg := d.SignalName, interface{}(lastWriteSignal(run.Iteration))
+	if signalName == "" {
+		signalName = "do_actions_signal"
+	}
+	if d.SignalArg != nil {
+		arg = d.SignalArg(run)
+	}
+	start := time.Now()
+	execution, err := run.Client.SignalWithStartWorkflow(ctx, options.ID, signalName, arg, options, workflow, args...)
+	if err != nil {
+		return fmt.Errorf("failed to signal-with-start workflow %v: %w", options.ID, err)
+	}
+	run.RecordTimer("omes_signal_with_start_latency", nil, time.Since(start))
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.runIDs == nil {
+		d.runIDs = map[string]int{}
+	}
+	d.runIDs[execution.GetRunID()]++
+	return nil
+}
+
+func (d *SignalWithStartDedup) workflowID(info *ScenarioInfo) string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.id == "" {
+		d.id = d.WorkflowID
+		if d.id == "" {
+			d.id = info.WorkflowIDPrefix() + "signal-with-start"
+		}
+	}
+	return d.id
+}
+
+// Verify returns a *SignalWithStartDedupError if the signal-with-starts so far created more than one
+// workflow, or if the history of the workflow lacks any of their signals. Call it once iterations
+// are done, e.g. after the run.
+func (d *SignalWithStartDedup) Verify(ctx context.Context, info *ScenarioInfo) error {
+	d.lock.Lock()
+	dedupErr := &SignalWithStartDedupError{}
+	for runID, sent := range d.runIDs {
+		dedupErr.RunIDs = append(dedupErr.RunIDs, runID)
+		dedupErr.Sent += sent
+	}
+	d.lock.Unlock()
+	sort.Strings(dedupErr.RunIDs)
+	if dedupErr.Sent == 0 {
+		return nil
+	} else if len(dedupErr.RunIDs) != 1 {
+		return dedupErr
+	}
+
+	workflowID, signalName := d.workflowID(info), d.SignalName
+	if signalName == "" {
+		signalName = "do_actions_signal"
+	}
+	iter := info.Client.GetWorkflowHistory(ctx, workflowID, dedupErr.RunIDs[0], false,
+		enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("failed reading history of workflow %v: %w", workflowID, err)
+		}
+		if attrs := event.GetWorkflowExecutionSignaledEventAttributes(); attrs != nil && attrs.SignalName == signalName {
+			dedupErr.Delivered++
+		}
+	}
+	if dedupErr.Delivered != dedupErr.Sent {
+		return dedupErr
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// signalWithStartServer simulates the server side of signal-with-start, counting created workflows
+// and delivered signals. Without dedup, every call creates a new workflow.
+type signalWithStartServer struct {
+	dedup bool
+	// Signals to drop.
+	drop int
+
+	lock      sync.Mutex
+	runs      map[string]*FakeWorkflowRun
+	created   int
+	delivered int
+}
+
+func (s *signalWithStartServer) client() *FakeClient {
+	return &FakeClient{
+		OnSignalWorkflow: func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if s.drop > 0 {
+				s.drop--
+			} else {
+				s.delivered++
+			}
+			return nil
+		},
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if run, ok := s.runs[options.ID]; ok && s.dedup {
+				return run, nil
+			}
+			s.created++
+			run := &FakeWorkflowRun{ID: options.ID, RunID: fmt.Sprintf("run-%v", s.created)}
+			if s.runs == nil {
+				s.runs = map[string]*FakeWorkflowRun{}
+			}
+			s.runs[options.ID] = run
+			return run, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			var events []*history.HistoryEvent
+			for i := 0; i < s.delivered; i++ {
+				events = append(events, &history.HistoryEvent{
+					EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED,
+					Attributes: &history.HistoryEvent_WorkflowExecutionSignaledEventAttributes{
+						WorkflowExecutionSignaledEventAttributes: &history.WorkflowExecutionSignaledEventAttributes{
+							SignalName: "do_actions_signal",
+						},
+					},
+				})
+			}
+			return events, nil
+		},
+	}
+}
+
+// runSignalWithStartDedup runs iterations signal-with-starting concurrently and returns the
+// verification error.
+func runSignalWithStartDedup(t *testing.T, server *signalWithStartServer) error {
+	fake := server.client()
+	dedup := &SignalWithStartDedup{}
+	executor := &GenericExecutor{Execute: dedup.Signal}
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 20, MaxConcurrent: 10})
+	require.NoError(t, executor.Run(context.Background(), info))
+	for _, call := range fake.Calls("SignalWithStartWorkflow") {
+		require.Equal(t, "w-test-run-signal-with-start", call.WorkflowID)
+	}
+	return dedup.Verify(context.Background(), &info)
+}
+
+func TestSignalWithStartDedup(t *testing.T) {
+	server := &signalWithStartServer{dedup: true}
+	require.NoError(t, runSignalWithStartDedup(t, server))
+	require.Equal(t, 1, server.created)
+	require.Equal(t, 20, server.delivered)
+}
+
+func TestSignalWithStartDedupMultipleWorkflows(t *testing.T) {
+	server := &signalWithStartServer{}
+	err := runSignalWithStartDedup(t, server)
+	var dedupErr *SignalWithStartDedupError
+	require.ErrorAs(t, err, &dedupErr)
+	require.Len(t, dedupErr.RunIDs, 20)
+	require.Equal(t, 20, dedupErr.Sent)
+	require.ErrorContains(t, err, "20 signal-with-starts created 20 workflows")
+}
+
+func TestSignalWithStartDedupLostSignals(t *testing.T) {
+	server := &signalWithStartServer{dedup: true, drop: 2}
+	err := runSignalWithStartDedup(t, server)
+	var dedupErr *SignalWithStartDedupError
+	require.ErrorAs(t, err, &dedupErr)
+	require.Equal(t, &SignalWithStartDedupError{RunIDs: []string{"run-1"}, Sent: 20, Delivered: 18}, dedupErr)
+	require.EqualError(t, err, "18 of 20 signals of signal-with-starts delivered")
+}
@@ -0,0 +1,147 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqliteBusyTimeout is how long a SQLiteReportSink waits for other writers of the same database to
+// release it.
+const sqliteBusyTimeout = 30 * time.Second
+
+// sqliteReportSchema creates the tables of a SQLiteReportSink: one row per run in runs, and one row
+// per run and phase in latency_summaries, with an empty phase for the whole run.
+const sqliteReportSchema = `CREATE TABLE IF NOT EXISTS runs (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  scenario TEXT NOT NULL,
+  run_id TEXT NOT NULL,
+  start_time TEXT NOT NULL,
+  end_time TEXT NOT NULL,
+  duration_ms REAL NOT NULL,
+  iterations_started INTEGER NOT NULL,
+  iterations_completed INTEGER NOT NULL,
+  iterations_failed INTEGER NOT NULL,
+  iterations_abandoned INTEGER NOT NULL,
+  steady_state_throughput REAL NOT NULL,
+  namespace TEXT,
+  server_address TEXT,
+  omes_version TEXT,
+  metadata TEXT
+);
+CREATE TABLE IF NOT EXISTS latency_summaries (
+  run INTEGER NOT NULL REFERENCES runs(id),
+  phase TEXT NOT NULL,
+  min_ms REAL NOT NULL,
+  mean_ms REAL NOT NULL,
+  p50_ms REAL NOT NULL,
+  p90_ms REAL NOT NULL,
+  p99_ms REAL NOT NULL,
+  max_ms REAL NOT NULL,
+  PRIMARY KEY (run, phase)
+);
+`
+
+// SQLiteReportSink appends the report to a SQLite database file, creating it and its schema if
+// absent, for querying runs over time. Writes are done in an immediate transaction waiting for other
+// writers, so that concurrent runs can share the database. Requires the sqlite3 command line tool.
+type SQLiteReportSink struct {
+	Path string
+	// Path of the sqlite3 command line tool. Default is "sqlite3" on the PATH.
+	SQLite3Path string
+}
+
+// WriteReport implements [ReportSink.WriteReport].
+func (s *SQLiteReportSink) WriteReport(ctx context.Context, result *RunResult) error {
+	script, err := sqliteReportScript(result)
+	if err != nil {
+		return err
+	}
+	sqlite3 := s.SQLite3Path
+	if sqlite3 == "" {
+		sqlite3 = "sqlite3"
+	}
+	cmd := exec.CommandContext(ctx, sqlite3, "-bail", s.Path)
+	cmd.Stdin = strings.NewReader(script)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed writing report to SQLite database %v: %w: %s", s.Path, err,
+			strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// sqliteReportScript returns the sqlite3 script inserting the result.
+func sqliteReportScript(result *RunResult) (string, error) {
+	var namespace, serverAddress, omesVersion, metadata interface{}
+	if result.Metadata != nil {
+		namespace, serverAddress, omesVersion = result.Metadata.Namespace, result.Metadata.ServerAddress,
+			result.Metadata.OmesVersion
+		b, err := json.Marshal(result.Metadata)
+		if err != nil {
+			return "", fmt.Errorf("failed encoding run metadata: %w", err)
+		}
+		metadata = string(b)
+	}
+	runValues, err := sqlValues(result.ScenarioName, result.RunID, result.StartTime.Format(time.RFC3339Nano),
+		result.EndTime.Format(time.RFC3339Nano), result.Duration, result.IterationsStarted,
+		result.IterationsCompleted, result.IterationsFailed, result.IterationsAbandoned,
+		result.SteadyStateThroughput, namespace, serverAddress, omesVersion, metadata)
+	if err != nil {
+		return "", err
+	}
+	var script strings.Builder
+	fmt.Fprintf(&script, ".timeout %d\nBEGIN IMMEDIATE;\n%s", sqliteBusyTimeout.Milliseconds(), sqliteReportSchema)
+	fmt.Fprintf(&script, "INSERT INTO runs (scenario, run_id, start_time, end_time, duration_ms, "+
+		"iterations_started, iterations_completed, iterations_failed, iterations_abandoned, "+
+		"steady_state_throughput, namespace, server_address, omes_version, metadata) VALUES (%s);\n", runValues)
+	writeLatency := func(phase string, latency LatencySummary) error {
+		values, err := sqlValues(phase, latency.Min, latency.Mean, latency.P50, latency.P90, latency.P99, latency.Max)
+		if err != nil {
+			return err
+		}
+		// The run inserted last by this transaction, which holds the write lock
+		fmt.Fprintf(&script, "INSERT INTO latency_summaries VALUES ((SELECT max(id) FROM runs), %s);\n", values)
+		return nil
+	}
+	if err := writeLatency("", result.Latency); err != nil {
+		return "", err
+	}
+	for _, phase := range result.Phases {
+		if err := writeLatency(phase.Name, phase.Latency); err != nil {
+			return "", err
+		}
+	}
+	script.WriteString("COMMIT;\n")
+	return script.String(), nil
+}
+
+// sqlValues formats the values as a comma-separated list of SQL literals. Durations are in
+// milliseconds and nil is NULL. Values of other types than those of the report are an error.
+func sqlValues(values ...interface{}) (string, error) {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+			literals[i] = "NULL"
+		case string:
+			literals[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		case int:
+			literals[i] = strconv.Itoa(v)
+		case float64:
+			literals[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		case time.Duration:
+			literals[i] = formatMillis(v)
+		default:
+			return "", fmt.Errorf("unsupported SQL value type %T", value)
+		}
+	}
+	return strings.Join(literals, ", "), nil
+}
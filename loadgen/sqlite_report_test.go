@@ -0,0 +1,116 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+)
+
+func requireSQLite3(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+}
+
+// querySQLite returns the rows of the query, with columns separated by "|".
+func querySQLite(t *testing.T, path, query string) []string {
+	output, err := exec.Command("sqlite3", path, query).CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+func TestSQLiteReportSink(t *testing.T) {
+	requireSQLite3(t)
+	path := filepath.Join(t.TempDir(), "results.db")
+	result := testRunResult()
+	result.Metadata = &RunMetadata{Namespace: "default", ScenarioOptions: map[string]string{"note": "it's"}}
+	result.Phases = []PhaseResult{{Name: "ramp", Latency: LatencySummary{Min: time.Millisecond, Max: 2 * time.Millisecond}}}
+	sink := &SQLiteReportSink{Path: path}
+	require.NoError(t, sink.WriteReport(context.Background(), result))
+	require.NoError(t, sink.WriteReport(context.Background(), result))
+
+	require.Equal(t, []string{
+		"1|my_scenario|my-run|2023-01-01T00:00:00Z|60000.0|3|2|1|0|default",
+		"2|my_scenario|my-run|2023-01-01T00:00:00Z|60000.0|3|2|1|0|default",
+	}, querySQLite(t, path, "SELECT id, scenario, run_id, start_time, duration_ms, iterations_started, "+
+		"iterations_completed, iterations_failed, iterations_abandoned, namespace FROM runs"))
+	require.Equal(t, []string{`{"note":"it's"}`}, querySQLite(t, path,
+		"SELECT DISTINCT json_extract(metadata, '$.scenarioOptions') FROM runs"))
+	require.Equal(t, []string{
+		"1||1000.0|2000.0|2000.0|3000.0",
+		"1|ramp|1.0|0.0|0.0|2.0",
+		"2||1000.0|2000.0|2000.0|3000.0",
+		"2|ramp|1.0|0.0|0.0|2.0",
+	}, querySQLite(t, path, "SELECT run, phase, min_ms, mean_ms, p90_ms, max_ms FROM latency_summaries ORDER BY run, phase"))
+}
+
+func TestSQLiteReportSinkConcurrentRuns(t *testing.T) {
+	requireSQLite3(t)
+	path := filepath.Join(t.TempDir(), "results.db")
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := testRunResult()
+			result.RunID = fmt.Sprintf("run-%v", i)
+			result.Latency.Max = time.Duration(i) * time.Millisecond
+			errs[i] = (&SQLiteReportSink{Path: path}).WriteReport(context.Background(), result)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"10|10"}, querySQLite(t, path,
+		"SELECT count(DISTINCT run_id), count(*) FROM runs"))
+	// Every latency summary belongs to the run that wrote it
+	require.Equal(t, []string{"10"}, querySQLite(t, path, "SELECT count(*) FROM latency_summaries l "+
+		"JOIN runs r ON l.run = r.id WHERE r.run_id = 'run-' || CAST(l.max_ms AS INTEGER)"))
+}
+
+func TestSQLiteReportSinkFailure(t *testing.T) {
+	requireSQLite3(t)
+	sink := &SQLiteReportSink{Path: filepath.Join(t.TempDir(), "missing", "results.db")}
+	require.ErrorContains(t, sink.WriteReport(context.Background(), testRunResult()), "unable to open database")
+}
+
+func TestSQLValues(t *testing.T) {
+	values, err := sqlValues(nil, "it's", 3, 1.5, 1500*time.Microsecond)
+	require.NoError(t, err)
+	require.Equal(t, "NULL, 'it''s', 3, 1.5, 1.500", values)
+	_, err = sqlValues(int64(3))
+	require.EqualError(t, err, "unsupported SQL value type int64")
+}
+
+func TestGenericExecutorWritesSQLiteReport(t *testing.T) {
+	requireSQLite3(t)
+	path := filepath.Join(t.TempDir(), "results.db")
+	info := ScenarioInfo{
+		ScenarioName:   "report_test",
+		RunID:          "run",
+		MetricsHandler: client.MetricsNopHandler,
+		Logger:         zap.NewNop().Sugar(),
+		ReportSinks:    []ReportSink{&SQLiteReportSink{Path: path}},
+	}
+	executor := &GenericExecutor{
+		Execute:              func(ctx context.Context, run *Run) error { return nil },
+		DefaultConfiguration: RunConfiguration{Iterations: 4},
+	}
+	require.NoError(t, executor.Run(context.Background(), info))
+	require.Equal(t, []string{"report_test|run|4|4|4"}, querySQLite(t, path,
+		"SELECT scenario, run_id, iterations_started, iterations_completed, "+
+			"json_extract(metadata, '$.configuration.iterations') FROM runs"))
+	require.Equal(t, []string{"1|"}, querySQLite(t, path, "SELECT run, phase FROM latency_summaries"))
+}
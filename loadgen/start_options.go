@@ -0,0 +1,231 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// StartOption modifies workflow start options, see [Run.StartWorkflowOptions].
+type StartOption func(*client.StartWorkflowOptions)
+
+// StartWorkflowOptions gets the default start workflow options with the given options applied in
+// order. If the run targets a build ID, it is recorded via WithTargetBuildID first, and the timeouts
+// of the scenario options are set by TimeoutStartOption first. Panics if the scenario options are
+// invalid, which ValidateStartOptions reports before the run.
+func (r *Run) StartWorkflowOptions(opts ...StartOption) client.StartWorkflowOptions {
+	options := r.DefaultStartWorkflowOptions()
+	if buildID := r.TargetBuildID(); buildID != "" {
+		WithTargetBuildID(buildID)(&options)
+	}
+	timeouts, err := r.TimeoutStartOption()
+	if err != nil {
+		panic(fmt.Sprintf("invalid start options, see ValidateStartOptions: %v", err))
+	}
+	timeouts(&options)
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithStartDelay delays dispatch of the first workflow task by the given duration. Servers that
+// do not support start delay ignore it and dispatch immediately.
+func WithStartDelay(delay time.Duration) StartOption {
+	return func(options *client.StartWorkflowOptions) {
+		options.StartDelay = delay
+	}
+}
+
+// CheckStartDelaySupported fails unless the server honours start delay. It starts a kitchen sink
+// workflow with a start delay on the run's task queue, checks that its first workflow task is backed
+// off by the delay, and terminates it.
+func (s *ScenarioInfo) CheckStartDelaySupported(ctx context.Context) error {
+	options := s.NewRun(0).StartWorkflowOptions(WithStartDelay(time.Minute))
+	options.ID = s.WorkflowIDPrefix() + "start-delay-probe"
+	run, err := s.Client.ExecuteWorkflow(ctx, options, "kitchenSink",
+		&kitchensink.WorkflowInput{InitialActions: []*kitchensink.ActionSet{kitchensink.EmptyResultActionSet()}})
+	if err != nil {
+		return fmt.Errorf("failed to start start delay probe workflow: %w", err)
+	}
+	defer func() {
+		// Already completed if the server dispatched it immediately
+		if err := s.Client.TerminateWorkflow(ctx, run.GetID(), run.GetRunID(), "start delay probe"); err != nil {
+			s.Logger.Debugf("Failed to terminate start delay probe workflow %v: %v", run.GetID(), err)
+		}
+	}()
+	iter := s.Client.GetWorkflowHistory(ctx, run.GetID(), run.GetRunID(), false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	if !iter.HasNext() {
+		return fmt.Errorf("start delay probe workflow %v has no history", run.GetID())
+	}
+	event, err := iter.Next()
+	if err != nil {
+		return fmt.Errorf("failed reading history of start delay probe workflow %v: %w", run.GetID(), err)
+	}
+	backoff := event.GetWorkflowExecutionStartedEventAttributes().GetFirstWorkflowTaskBackoff()
+	if backoff == nil || *backoff <= 0 {
+		return fmt.Errorf("server does not support start delay, the first workflow task of probe workflow %v "+
+			"was not delayed", run.GetID())
+	}
+	return nil
+}
+
+// Scenario options setting workflow timeouts, see [ScenarioInfo.TimeoutStartOption].
+const (
+	WorkflowExecutionTimeoutOption = "workflow-execution-timeout"
+	WorkflowRunTimeoutOption       = "workflow-run-timeout"
+	// WorkflowTaskTimeoutOption sets the start-to-close timeout of workflow tasks. The timeout after
+	// which a task scheduled on a worker's sticky queue is retried on the normal task queue, off the
+	// sticky path (see StickyTaskStats), is a worker option, --worker-sticky-schedule-to-start-timeout.
+	WorkflowTaskTimeoutOption = "workflow-task-timeout"
+)
+
+// WithTimeouts sets the workflow execution, run and task timeouts. Zero leaves a timeout unchanged.
+func WithTimeouts(execution, run, task time.Duration) StartOption {
+	return func(options *client.StartWorkflowOptions) {
+		if execution > 0 {
+			options.WorkflowExecutionTimeout = execution
+		}
+		if run > 0 {
+			options.WorkflowRunTimeout = run
+		}
+		if task > 0 {
+			options.WorkflowTaskTimeout = task
+		}
+	}
+}
+
+// TimeoutStartOption returns a start option setting the workflow timeouts given by the
+// workflow-execution-timeout, workflow-run-timeout and workflow-task-timeout scenario options.
+// Unset options leave the server defaults. Fails if an option is not a non-negative duration.
+// Options set to a range are only supported per iteration by [Run.TimeoutStartOption], which
+// [Run.StartWorkflowOptions] applies.
+func (s *ScenarioInfo) TimeoutStartOption() (StartOption, error) {
+	ranges, err := s.timeoutRanges()
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range ranges {
+		if r.Min != r.Max {
+			return nil, fmt.Errorf("%v scenario option is a range, which requires a start option per iteration",
+				timeoutOptions[i])
+		}
+	}
+	return WithTimeouts(ranges[0].Min, ranges[1].Min, ranges[2].Min), nil
+}
+
+// TimeoutStartOption is like [ScenarioInfo.TimeoutStartOption], but each option may also be an
+// inclusive range "<min>..<max>", e.g. workflow-execution-timeout=1m..5m, for which the iteration
+// picks a random timeout within the range with [Run.Rand], so that mixed timeouts are reproducible.
+// [Run.StartWorkflowOptions] applies it, so ranges given with --option take effect.
+func (r *Run) TimeoutStartOption() (StartOption, error) {
+	ranges, err := r.timeoutRanges()
+	if err != nil {
+		return nil, err
+	}
+	random := r.Rand()
+	return WithTimeouts(ranges[0].Pick(random), ranges[1].Pick(random), ranges[2].Pick(random)), nil
+}
+
+// timeoutOptions are the scenario options of the workflow execution, run and task timeouts.
+var timeoutOptions = [3]string{WorkflowExecutionTimeoutOption, WorkflowRunTimeoutOption, WorkflowTaskTimeoutOption}
+
+// ValidateStartOptions checks the scenario options applied by [Run.StartWorkflowOptions], i.e. the
+// workflow ID template and timeouts, for executors to fail before starting any workflow.
+func (s *ScenarioInfo) ValidateStartOptions() error {
+	if _, err := s.WorkflowIDTemplate(); err != nil {
+		return err
+	}
+	_, err := s.timeoutRanges()
+	return err
+}
+
+// timeoutRanges parses the workflow execution, run and task timeout scenario options (see
+// timeoutOptions), returning the range of each, zero if unset.
+func (s *ScenarioInfo) timeoutRanges() (ranges [3]DurationRange, err error) {
+	for i, name := range timeoutOptions {
+		v := s.ScenarioOptions[name]
+		if v == "" {
+			continue
+		}
+		r, err := ParseDurationRange(v)
+		if err != nil {
+			return ranges, fmt.Errorf("invalid %v scenario option: %w", name, err)
+		} else if r.Min < 0 {
+			return ranges, fmt.Errorf("invalid %v scenario option: %v is negative", name, r.Min)
+		}
+		ranges[i] = r
+	}
+	return ranges, nil
+}
+
+// DurationRange is an inclusive range of durations, see [ParseDurationRange].
+type DurationRange struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// ParseDurationRange parses a duration, a range of that single duration, or an inclusive range
+// "<min>..<max>" such as "1m..5m". Fails if min is greater than max.
+func ParseDurationRange(s string) (DurationRange, error) {
+	minText, maxText, isRange := strings.Cut(s, "..")
+	min, err := time.ParseDuration(minText)
+	if err != nil {
+		return DurationRange{}, err
+	}
+	max := min
+	if isRange {
+		if max, err = time.ParseDuration(maxText); err != nil {
+			return DurationRange{}, err
+		} else if min > max {
+			return DurationRange{}, fmt.Errorf("range minimum %v is greater than its maximum %v", min, max)
+		}
+	}
+	return DurationRange{Min: min, Max: max}, nil
+}
+
+// Pick returns a duration drawn uniformly from the range.
+func (d DurationRange) Pick(random *rand.Rand) time.Duration {
+	if d.Max <= d.Min {
+		return d.Min
+	}
+	return d.Min + time.Duration(random.Int63n(int64(d.Max-d.Min)+1))
+}
+
+// ParentClosePolicyOption is the scenario option setting the parent close policy of child
+// workflows, see [ScenarioInfo.ParentClosePolicy].
+const ParentClosePolicyOption = "parent-close-policy"
+
+// ParseParentClosePolicy parses a parent close policy name: terminate, abandon or request-cancel,
+// case-insensitive, with or without the PARENT_CLOSE_POLICY_ prefix.
+func ParseParentClosePolicy(s string) (kitchensink.ParentClosePolicy, error) {
+	name := strings.ReplaceAll(strings.ToUpper(s), "-", "_")
+	if !strings.HasPrefix(name, "PARENT_CLOSE_POLICY_") {
+		name = "PARENT_CLOSE_POLICY_" + name
+	}
+	policy, ok := kitchensink.ParentClosePolicy_value[name]
+	if !ok || policy == int32(kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_UNSPECIFIED) {
+		return 0, fmt.Errorf("unknown parent close policy %q (expected terminate, abandon or request-cancel)", s)
+	}
+	return kitchensink.ParentClosePolicy(policy), nil
+}
+
+// ParentClosePolicy returns the parent close policy given by the parent-close-policy scenario
+// option, terminate if unset as for the server.
+func (s *ScenarioInfo) ParentClosePolicy() (kitchensink.ParentClosePolicy, error) {
+	v := s.ScenarioOptions[ParentClosePolicyOption]
+	if v == "" {
+		return kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_TERMINATE, nil
+	}
+	policy, err := ParseParentClosePolicy(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v scenario option: %w", ParentClosePolicyOption, err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,226 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+)
+
+// startRecordingClient records the options of every started workflow.
+type startRecordingClient struct {
+	FakeClient
+}
+
+func (s *startRecordingClient) started() []client.StartWorkflowOptions {
+	var started []client.StartWorkflowOptions
+	for _, call := range s.Calls("ExecuteWorkflow") {
+		started = append(started, *call.Options)
+	}
+	return started
+}
+
+func newStartOptionsTestRun(c client.Client) *Run {
+	info := &ScenarioInfo{ScenarioName: "start_options", RunID: "run", Logger: zap.NewNop().Sugar(), Client: c}
+	return info.NewRun(1)
+}
+
+func TestStartWorkflowOptionsWithStartDelay(t *testing.T) {
+	c := &startRecordingClient{}
+	run := newStartOptionsTestRun(c)
+	options := run.StartWorkflowOptions(WithStartDelay(3 * time.Second))
+	require.NoError(t, run.ExecuteAnyWorkflow(context.Background(), options, "wf", nil))
+	require.Len(t, c.started(), 1)
+	require.Equal(t, 3*time.Second, c.started()[0].StartDelay)
+	require.Equal(t, "w-run-1", c.started()[0].ID)
+	require.Equal(t, run.TaskQueue(), c.started()[0].TaskQueue)
+}
+
+func TestCheckStartDelaySupported(t *testing.T) {
+	for _, supported := range []bool{true, false} {
+		var backoff time.Duration
+		if supported {
+			backoff = time.Minute
+		}
+		fake := &FakeClient{
+			OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+				return []*history.HistoryEvent{{
+					EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED,
+					Attributes: &history.HistoryEvent_WorkflowExecutionStartedEventAttributes{
+						WorkflowExecutionStartedEventAttributes: &history.WorkflowExecutionStartedEventAttributes{
+							FirstWorkflowTaskBackoff: &backoff,
+						},
+					},
+				}}, nil
+			},
+		}
+		info := NewTestScenarioInfo(fake, RunConfiguration{})
+		err := info.CheckStartDelaySupported(context.Background())
+		if supported {
+			require.NoError(t, err)
+		} else {
+			require.ErrorContains(t, err, "does not support start delay")
+		}
+		started := fake.Calls("ExecuteWorkflow")
+		require.Len(t, started, 1)
+		require.Equal(t, "w-test-run-start-delay-probe", started[0].WorkflowID)
+		require.Equal(t, time.Minute, started[0].Options.StartDelay)
+		require.Len(t, fake.Calls("TerminateWorkflow"), 1)
+	}
+}
+
+func TestTimeoutStartOption(t *testing.T) {
+	c := &startRecordingClient{}
+	run := newStartOptionsTestRun(c)
+	run.ScenarioOptions = map[string]string{
+		WorkflowExecutionTimeoutOption: "1m",
+		WorkflowRunTimeoutOption:       "30s",
+		WorkflowTaskTimeoutOption:      "2s",
+	}
+	timeouts, err := run.TimeoutStartOption()
+	require.NoError(t, err)
+	require.NoError(t, run.ExecuteAnyWorkflow(context.Background(), run.StartWorkflowOptions(timeouts), "wf", nil))
+	require.Len(t, c.started(), 1)
+	require.Equal(t, time.Minute, c.started()[0].WorkflowExecutionTimeout)
+	require.Equal(t, 30*time.Second, c.started()[0].WorkflowRunTimeout)
+	require.Equal(t, 2*time.Second, c.started()[0].WorkflowTaskTimeout)
+
+	// Unset options leave the defaults
+	run.ScenarioOptions = map[string]string{WorkflowRunTimeoutOption: "5s"}
+	timeouts, err = run.TimeoutStartOption()
+	require.NoError(t, err)
+	options := run.StartWorkflowOptions(timeouts)
+	require.Zero(t, options.WorkflowExecutionTimeout)
+	require.Equal(t, 5*time.Second, options.WorkflowRunTimeout)
+	require.Zero(t, options.WorkflowTaskTimeout)
+}
+
+func TestTimeoutStartOptionInvalid(t *testing.T) {
+	run := newStartOptionsTestRun(&FakeClient{})
+	run.ScenarioOptions = map[string]string{WorkflowTaskTimeoutOption: "-1s"}
+	_, err := run.TimeoutStartOption()
+	require.ErrorContains(t, err, "workflow-task-timeout")
+	run.ScenarioOptions = map[string]string{WorkflowExecutionTimeoutOption: "soon"}
+	_, err = run.TimeoutStartOption()
+	require.ErrorContains(t, err, "workflow-execution-timeout")
+}
+
+func TestParentClosePolicy(t *testing.T) {
+	run := newStartOptionsTestRun(&FakeClient{})
+	policy, err := run.ParentClosePolicy()
+	require.NoError(t, err)
+	require.Equal(t, kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_TERMINATE, policy)
+	for value, expected := range map[string]kitchensink.ParentClosePolicy{
+		"abandon":                            kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_ABANDON,
+		"request-cancel":                     kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_REQUEST_CANCEL,
+		"PARENT_CLOSE_POLICY_REQUEST_CANCEL": kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_REQUEST_CANCEL,
+		"Terminate":                          kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_TERMINATE,
+	} {
+		run.ScenarioOptions = map[string]string{ParentClosePolicyOption: value}
+		policy, err := run.ParentClosePolicy()
+		require.NoError(t, err)
+		require.Equal(t, expected, policy, value)
+	}
+	for _, value := range []string{"unspecified", "orphan"} {
+		run.ScenarioOptions = map[string]string{ParentClosePolicyOption: value}
+		_, err := run.ParentClosePolicy()
+		require.ErrorContains(t, err, "parent-close-policy")
+	}
+}
+
+func TestStartWorkflowOptionsAppliesTimeouts(t *testing.T) {
+	// Timeouts of the scenario options apply without a start option, e.g. from --option
+	run := newStartOptionsTestRun(&FakeClient{})
+	run.ScenarioOptions = map[string]string{WorkflowTaskTimeoutOption: "3s"}
+	require.NoError(t, run.ValidateStartOptions())
+	require.Equal(t, 3*time.Second, run.StartWorkflowOptions().WorkflowTaskTimeout)
+	require.Equal(t, 3*time.Second, run.DefaultKitchenSinkWorkflowOptions().StartOptions.WorkflowTaskTimeout)
+	// Given options override them
+	require.Equal(t, time.Second, run.StartWorkflowOptions(WithTimeouts(0, 0, time.Second)).WorkflowTaskTimeout)
+
+	run.ScenarioOptions = map[string]string{WorkflowTaskTimeoutOption: "-3s"}
+	require.ErrorContains(t, run.ValidateStartOptions(), "invalid workflow-task-timeout scenario option")
+	require.Panics(t, func() { run.StartWorkflowOptions() })
+}
+
+func TestTimeoutStartOptionRange(t *testing.T) {
+	info := &ScenarioInfo{ScenarioName: "start_options", RunID: "run", Logger: zap.NewNop().Sugar(),
+		ScenarioOptions: map[string]string{
+			WorkflowExecutionTimeoutOption: "1m..5m",
+			WorkflowRunTimeoutOption:       "30s",
+			"seed":                         "42",
+		}}
+	picked := map[time.Duration]bool{}
+	for iteration := 1; iteration <= 50; iteration++ {
+		options := info.NewRun(iteration).StartWorkflowOptions()
+		require.GreaterOrEqual(t, options.WorkflowExecutionTimeout, time.Minute)
+		require.LessOrEqual(t, options.WorkflowExecutionTimeout, 5*time.Minute)
+		require.Equal(t, 30*time.Second, options.WorkflowRunTimeout)
+		picked[options.WorkflowExecutionTimeout] = true
+
+		// The same seed and iteration pick the same timeout
+		require.Equal(t, options.WorkflowExecutionTimeout,
+			info.NewRun(iteration).StartWorkflowOptions().WorkflowExecutionTimeout)
+		timeouts, err := info.NewRun(iteration).TimeoutStartOption()
+		require.NoError(t, err)
+		require.Equal(t, options.WorkflowExecutionTimeout,
+			info.NewRun(iteration).StartWorkflowOptions(timeouts).WorkflowExecutionTimeout)
+	}
+	require.Greater(t, len(picked), 1)
+
+	// A range needs an iteration to pick from it
+	_, err := info.TimeoutStartOption()
+	require.ErrorContains(t, err, "workflow-execution-timeout scenario option is a range")
+}
+
+func TestTimeoutRangeFromScenarioOption(t *testing.T) {
+	// As given with --option to a kitchen sink scenario
+	c := &startRecordingClient{}
+	info := NewTestScenarioInfo(c, RunConfiguration{Iterations: 20})
+	info.ScenarioOptions = map[string]string{WorkflowExecutionTimeoutOption: "1m..5m"}
+	executor := KitchenSinkExecutor{TestInput: &kitchensink.TestInput{WorkflowInput: &kitchensink.WorkflowInput{}}}
+	require.NoError(t, executor.Run(context.Background(), info))
+	picked := map[time.Duration]bool{}
+	for _, options := range c.started() {
+		require.GreaterOrEqual(t, options.WorkflowExecutionTimeout, time.Minute)
+		require.LessOrEqual(t, options.WorkflowExecutionTimeout, 5*time.Minute)
+		picked[options.WorkflowExecutionTimeout] = true
+	}
+	require.Len(t, c.started(), 20)
+	require.Greater(t, len(picked), 1)
+
+	info.ScenarioOptions = map[string]string{WorkflowExecutionTimeoutOption: "5m..1m"}
+	require.ErrorContains(t, executor.Run(context.Background(), info), "invalid workflow-execution-timeout scenario option")
+}
+
+func TestParseDurationRange(t *testing.T) {
+	r, err := ParseDurationRange("2s")
+	require.NoError(t, err)
+	require.Equal(t, DurationRange{Min: 2 * time.Second, Max: 2 * time.Second}, r)
+	r, err = ParseDurationRange("1s..1m")
+	require.NoError(t, err)
+	require.Equal(t, DurationRange{Min: time.Second, Max: time.Minute}, r)
+	_, err = ParseDurationRange("1m..1s")
+	require.EqualError(t, err, "range minimum 1m0s is greater than its maximum 1s")
+	_, err = ParseDurationRange("1s..soon")
+	require.Error(t, err)
+
+	run := newStartOptionsTestRun(&FakeClient{})
+	run.ScenarioOptions = map[string]string{WorkflowTaskTimeoutOption: "5s..1s"}
+	_, err = run.TimeoutStartOption()
+	require.ErrorContains(t, err, "invalid workflow-task-timeout scenario option")
+}
+
+func TestRunRand(t *testing.T) {
+	info := &ScenarioInfo{RunID: "run", Logger: zap.NewNop().Sugar(), ScenarioOptions: map[string]string{"seed": "7"}}
+	require.Equal(t, info.NewRun(3).Rand().Int63(), info.NewRun(3).Rand().Int63())
+	require.NotEqual(t, info.NewRun(3).Rand().Int63(), info.NewRun(4).Rand().Int63())
+	other := &ScenarioInfo{RunID: "run", Logger: zap.NewNop().Sugar(), ScenarioOptions: map[string]string{"seed": "8"}}
+	require.NotEqual(t, info.NewRun(3).Rand().Int63(), other.NewRun(3).Rand().Int63())
+}
@@ -0,0 +1,99 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+)
+
+// stateQueryPollInterval is the interval between queries of Run.SignalWhenState.
+var stateQueryPollInterval = 100 * time.Millisecond
+
+// ErrWorkflowAlreadyFinished is returned (wrapped) by Run.SignalWhenState when the workflow
+// finishes before it is signaled.
+var ErrWorkflowAlreadyFinished = errors.New("workflow already finished")
+
+// StateSignal is a signal sent by Run.SignalWhenState once the workflow reaches a state.
+type StateSignal struct {
+	// Query returning the workflow's state.
+	QueryType string
+	QueryArgs []interface{}
+	// Reports whether the state returned by the query is the one to signal in.
+	Reached func(state converter.EncodedValue) (bool, error)
+	// Signal to send once the state is reached.
+	SignalName string
+	SignalArg  interface{}
+	// Maximum time to wait for the state. Default is no limit.
+	Timeout time.Duration
+}
+
+// SignalWhenState queries the started workflow until its state satisfies signal.Reached, then sends
+// the signal. If the workflow finishes before it is signaled, whether while its state is polled or
+// between the last query and the signal, an error wrapping ErrWorkflowAlreadyFinished is returned,
+// so that scenarios can tell this expected race apart from failures.
+func (r *Run) SignalWhenState(ctx context.Context, execution client.WorkflowRun, signal StateSignal) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if signal.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, signal.Timeout)
+		defer cancel()
+	}
+	// Watch for the workflow finishing while polling its state
+	finished := make(chan error, 1)
+	go func() { finished <- execution.Get(ctx, nil) }()
+	notSignaled := func(err error) error {
+		if ctx.Err() != nil {
+			return fmt.Errorf("workflow %v did not reach state to send signal %v: %w",
+				execution.GetID(), signal.SignalName, ctx.Err())
+		} else if err != nil {
+			return fmt.Errorf("%w: workflow %v failed before signal %v: %v",
+				ErrWorkflowAlreadyFinished, execution.GetID(), signal.SignalName, err)
+		}
+		return fmt.Errorf("%w: workflow %v completed before signal %v",
+			ErrWorkflowAlreadyFinished, execution.GetID(), signal.SignalName)
+	}
+
+	for {
+		select {
+		case err := <-finished:
+			return notSignaled(err)
+		default:
+		}
+		state, err := r.Client.QueryWorkflow(ctx, execution.GetID(), execution.GetRunID(), signal.QueryType, signal.QueryArgs...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return notSignaled(nil)
+			}
+			return fmt.Errorf("failed querying %v of workflow %v: %w", signal.QueryType, execution.GetID(), err)
+		}
+		reached, err := signal.Reached(state)
+		if err != nil {
+			return fmt.Errorf("failed checking state of workflow %v: %w", execution.GetID(), err)
+		} else if reached {
+			break
+		}
+		select {
+		case err := <-finished:
+			return notSignaled(err)
+		case <-ctx.Done():
+			return notSignaled(nil)
+		case <-time.After(stateQueryPollInterval):
+		}
+	}
+
+	err := r.Client.SignalWorkflow(ctx, execution.GetID(), execution.GetRunID(), signal.SignalName, signal.SignalArg)
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		// The workflow finished after the last query
+		return fmt.Errorf("%w: workflow %v finished before signal %v: %v",
+			ErrWorkflowAlreadyFinished, execution.GetID(), signal.SignalName, err)
+	} else if err != nil {
+		return fmt.Errorf("failed sending signal %v to workflow %v: %w", signal.SignalName, execution.GetID(), err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package loadgen
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+)
+
+func useFastStateQueries(t *testing.T) {
+	prev := stateQueryPollInterval
+	stateQueryPollInterval = time.Millisecond
+	t.Cleanup(func() { stateQueryPollInterval = prev })
+}
+
+// stepReached reports whether the queried step is at least the given one.
+func stepReached(step int) func(converter.EncodedValue) (bool, error) {
+	return func(state converter.EncodedValue) (bool, error) {
+		var current int
+		if err := state.Get(&current); err != nil {
+			return false, err
+		}
+		return current >= step, nil
+	}
+}
+
+func TestSignalWhenState(t *testing.T) {
+	useFastStateQueries(t)
+	var queries int32
+	fake := &FakeClient{
+		OnQueryWorkflow: func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error) {
+			// The workflow advances a step per query
+			return atomic.AddInt32(&queries, 1), nil
+		},
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: options.ID, RunID: "run", Delay: time.Minute}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	execution, err := fake.ExecuteWorkflow(context.Background(), run.StartWorkflowOptions(), "wf")
+	require.NoError(t, err)
+
+	require.NoError(t, run.SignalWhenState(context.Background(), execution, StateSignal{
+		QueryType: "step", Reached: stepReached(3), SignalName: "go", SignalArg: "now",
+	}))
+	require.Len(t, fake.Calls("QueryWorkflow"), 3)
+	signals := fake.Calls("SignalWorkflow")
+	require.Len(t, signals, 1)
+	require.Equal(t, "go", signals[0].Name)
+	require.Equal(t, []interface{}{"now"}, signals[0].Args)
+}
+
+func TestSignalWhenStateWorkflowAlreadyFinished(t *testing.T) {
+	useFastStateQueries(t)
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	run := info.NewRun(1)
+
+	// Finishes while its state is polled
+	fake := &FakeClient{
+		OnQueryWorkflow: func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error) {
+			return 0, nil
+		},
+	}
+	run.Client = fake
+	err := run.SignalWhenState(context.Background(), &FakeWorkflowRun{ID: "w", RunID: "r", Delay: 20 * time.Millisecond},
+		StateSignal{QueryType: "step", Reached: stepReached(1), SignalName: "go"})
+	require.ErrorIs(t, err, ErrWorkflowAlreadyFinished)
+	require.ErrorContains(t, err, "workflow w completed before signal go")
+	require.Empty(t, fake.Calls("SignalWorkflow"))
+
+	// Finishes between the query and the signal
+	fake = &FakeClient{
+		OnQueryWorkflow: func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error) {
+			return 1, nil
+		},
+		OnSignalWorkflow: func(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+			return serviceerror.NewNotFound("workflow execution already completed")
+		},
+	}
+	run.Client = fake
+	err = run.SignalWhenState(context.Background(), &FakeWorkflowRun{ID: "w", RunID: "r", Delay: time.Minute},
+		StateSignal{QueryType: "step", Reached: stepReached(1), SignalName: "go"})
+	require.ErrorIs(t, err, ErrWorkflowAlreadyFinished)
+	require.ErrorContains(t, err, "workflow execution already completed")
+}
+
+func TestSignalWhenStateTimeout(t *testing.T) {
+	useFastStateQueries(t)
+	fake := &FakeClient{
+		OnQueryWorkflow: func(ctx context.Context, workflowID, runID, queryType string, args ...interface{}) (interface{}, error) {
+			return 0, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	err := run.SignalWhenState(context.Background(), &FakeWorkflowRun{ID: "w", RunID: "r", Delay: time.Minute},
+		StateSignal{QueryType: "step", Reached: stepReached(1), SignalName: "go", Timeout: 20 * time.Millisecond})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotErrorIs(t, err, ErrWorkflowAlreadyFinished)
+	require.Empty(t, fake.Calls("SignalWorkflow"))
+}
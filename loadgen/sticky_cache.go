@@ -0,0 +1,205 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+)
+
+const (
+	// ControlWorkflowType is the workflow type of the control workflows through which worker
+	// processes receive control signals for a scenario run.
+	ControlWorkflowType = "omesControl"
+	// FlushStickyCacheSignal is the control signal asking the worker to purge its sticky workflow
+	// cache.
+	FlushStickyCacheSignal = "omes_flush_sticky_cache"
+)
+
+// controlWorkflowTimeout bounds control workflows, which complete once they handled their signals,
+// e.g. those of worker processes that stopped polling since and never run them.
+const controlWorkflowTimeout = time.Minute
+
+// ControlTaskQueue returns the task queue on which the worker process of the given identity,
+// polling the given task queue, runs its control workflows.
+func ControlTaskQueue(taskQueue, workerIdentity string) string {
+	return fmt.Sprintf("%v-control-%v", taskQueue, workerIdentity)
+}
+
+// ControlWorkflowID returns the ID of the control workflow of the worker process of the given
+// identity for the given run ID.
+func ControlWorkflowID(runID, workerIdentity string) string {
+	return fmt.Sprintf("omes-control-%v-%v", runID, workerIdentity)
+}
+
+// SendControlSignal sends the named control signal to every worker process polling the run's task
+// queue, through a control workflow started if needed on the control task queue of each (see
+// ControlTaskQueue). Fails without sending any signal if no worker polls the task queue, or if a
+// worker does not poll its control task queue, since only the Go worker handles control signals.
+// Returns the time the signals were sent.
+func (s *ScenarioInfo) SendControlSignal(ctx context.Context, signalName string) (time.Time, error) {
+	taskQueue := TaskQueueForRun(s.ScenarioName, s.RunID)
+	identities, err := s.workerIdentities(ctx, taskQueue)
+	if err != nil {
+		return time.Time{}, err
+	} else if len(identities) == 0 {
+		return time.Time{}, fmt.Errorf("failed sending control signal %v: no worker polls task queue %v",
+			signalName, taskQueue)
+	}
+	var uncontrolled []string
+	for _, identity := range identities {
+		controlPollers, err := s.workerIdentities(ctx, ControlTaskQueue(taskQueue, identity))
+		if err != nil {
+			return time.Time{}, err
+		} else if len(controlPollers) == 0 {
+			uncontrolled = append(uncontrolled, identity)
+		}
+	}
+	if len(uncontrolled) > 0 {
+		return time.Time{}, fmt.Errorf("failed sending control signal %v: workers %v do not poll their control "+
+			"task queue, only the Go worker handles control signals", signalName, strings.Join(uncontrolled, ", "))
+	}
+	sentAt := time.Now()
+	for _, identity := range identities {
+		_, err := s.Client.SignalWithStartWorkflow(
+			ctx,
+			ControlWorkflowID(s.RunID, identity),
+			signalName,
+			nil,
+			client.StartWorkflowOptions{
+				ID:                       ControlWorkflowID(s.RunID, identity),
+				TaskQueue:                ControlTaskQueue(taskQueue, identity),
+				WorkflowExecutionTimeout: controlWorkflowTimeout,
+			},
+			ControlWorkflowType,
+		)
+		if err != nil {
+			return sentAt, fmt.Errorf("failed sending control signal %v to worker %v: %w", signalName, identity, err)
+		}
+	}
+	return sentAt, nil
+}
+
+// workerIdentities returns the sorted identities of the workflow pollers of the task queue.
+func (s *ScenarioInfo) workerIdentities(ctx context.Context, taskQueue string) ([]string, error) {
+	resp, err := s.Client.WorkflowService().DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+		Namespace:     s.Namespace,
+		TaskQueue:     &taskqueue.TaskQueue{Name: taskQueue},
+		TaskQueueType: enums.TASK_QUEUE_TYPE_WORKFLOW,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed describing task queue %v: %w", taskQueue, err)
+	}
+	seen := map[string]bool{}
+	var identities []string
+	for _, poller := range resp.GetPollers() {
+		if !seen[poller.GetIdentity()] {
+			seen[poller.GetIdentity()] = true
+			identities = append(identities, poller.GetIdentity())
+		}
+	}
+	sort.Strings(identities)
+	return identities, nil
+}
+
+// StickyCacheFlushTracker measures the impact of flushing worker sticky caches mid-run. Iteration
+// latencies are separated into those of iterations started before and after the flush and
+// recorded in the omes_sticky_flush_latency timer tagged with sticky_flush "pre" or "post". It is
+// safe for concurrent use.
+type StickyCacheFlushTracker struct {
+	lock      sync.Mutex
+	flushedAt time.Time
+	pre       []time.Duration
+	post      []time.Duration
+}
+
+// Flush asks every worker process to flush its sticky cache via control workflows and marks the
+// flush time.
+func (t *StickyCacheFlushTracker) Flush(ctx context.Context, info *ScenarioInfo) error {
+	sentAt, err := info.SendControlSignal(ctx, FlushStickyCacheSignal)
+	if err != nil {
+		return err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.flushedAt = sentAt
+	info.Logger.Infof("Requested sticky cache flush at %v", sentAt)
+	return nil
+}
+
+// Record records the latency of an iteration started at the given time.
+func (t *StickyCacheFlushTracker) Record(info *ScenarioInfo, startedAt time.Time, latency time.Duration) {
+	t.lock.Lock()
+	phase := "pre"
+	if !t.flushedAt.IsZero() && !startedAt.Before(t.flushedAt) {
+		phase = "post"
+		t.post = append(t.post, latency)
+	} else {
+		t.pre = append(t.pre, latency)
+	}
+	t.lock.Unlock()
+	info.RecordTimer("omes_sticky_flush_latency", map[string]string{"sticky_flush": phase}, latency)
+}
+
+// Summary returns latency summaries of the iterations started before and after the flush.
+func (t *StickyCacheFlushTracker) Summary() (pre LatencySummary, post LatencySummary) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return NewLatencySummary(append([]time.Duration(nil), t.pre...)),
+		NewLatencySummary(append([]time.Duration(nil), t.post...))
+}
+
+// Sticky cache metrics emitted by SDK workers.
+const (
+	stickyCacheHitMetric            = "temporal_sticky_cache_hit"
+	stickyCacheMissMetric           = "temporal_sticky_cache_miss"
+	stickyCacheForcedEvictionMetric = "temporal_sticky_cache_total_forced_eviction"
+)
+
+// StickyTaskStats counts the workflow tasks of workers that hit the sticky path, i.e. found their
+// workflow in the sticky cache, and those that did not and replayed its history.
+type StickyTaskStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	// Workflows evicted from the sticky cache to make room for others.
+	ForcedEvictions int64 `json:"forcedEvictions"`
+}
+
+// HitRatio returns the fraction of workflow tasks that hit the sticky path, 0 without tasks.
+func (s StickyTaskStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewStickyTaskStats returns the sticky path stats of the SDK worker metrics captured by the handler,
+// over the series with the given tags, e.g. a task_queue.
+func NewStickyTaskStats(metrics *CapturingMetricsHandler, tags map[string]string) StickyTaskStats {
+	return StickyTaskStats{
+		Hits:            metrics.CounterTotal(stickyCacheHitMetric, tags),
+		Misses:          metrics.CounterTotal(stickyCacheMissMetric, tags),
+		ForcedEvictions: metrics.CounterTotal(stickyCacheForcedEvictionMetric, tags),
+	}
+}
+
+// StickyTaskStats returns the sticky path stats of workers using the scenario's client, e.g. started
+// in process with worker.New(info.Client, ...), whose SDK metrics are captured if the executor
+// implements HasSDKMetricsCapture. They are also recorded in the omes_sticky_task_hit and
+// omes_sticky_task_miss gauges. Fails if SDK metrics are not captured.
+func (s *ScenarioInfo) StickyTaskStats(tags map[string]string) (StickyTaskStats, error) {
+	if s.SDKMetrics == nil {
+		return StickyTaskStats{}, fmt.Errorf("SDK metrics are not captured, sticky task stats require HasSDKMetricsCapture")
+	}
+	stats := NewStickyTaskStats(s.SDKMetrics, tags)
+	s.RecordGauge("omes_sticky_task_hit", nil, float64(stats.Hits))
+	s.RecordGauge("omes_sticky_task_miss", nil, float64(stats.Misses))
+	return stats, nil
+}
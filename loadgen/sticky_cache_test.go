@@ -0,0 +1,138 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+)
+
+// controlledWorkersClient returns a client whose run task queue "sticky:run" is polled by two
+// worker processes, one with several pollers, each also polling its control task queue unless
+// listed as uncontrolled.
+func controlledWorkersClient(uncontrolled ...string) *FakeClient {
+	identities := []string{"2@host", "1@host", "2@host"}
+	isUncontrolled := map[string]bool{}
+	for _, identity := range uncontrolled {
+		isUncontrolled[identity] = true
+	}
+	return &FakeClient{
+		OnDescribeTaskQueue: func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+			*workflowservice.DescribeTaskQueueResponse, error) {
+			resp := &workflowservice.DescribeTaskQueueResponse{}
+			for _, identity := range identities {
+				switch request.TaskQueue.Name {
+				case "sticky:run":
+				case ControlTaskQueue("sticky:run", identity):
+					if isUncontrolled[identity] {
+						continue
+					}
+				default:
+					continue
+				}
+				resp.Pollers = append(resp.Pollers, &taskqueue.PollerInfo{Identity: identity})
+			}
+			return resp, nil
+		},
+	}
+}
+
+func TestStickyCacheFlushTracker(t *testing.T) {
+	fakeClient := controlledWorkersClient()
+	handler := newRecordingMetricsHandler()
+	info := &ScenarioInfo{
+		ScenarioName:   "sticky",
+		RunID:          "run",
+		Logger:         zap.NewNop().Sugar(),
+		Client:         fakeClient,
+		MetricsHandler: handler,
+	}
+	var tracker StickyCacheFlushTracker
+	tracker.Record(info, time.Now(), 10*time.Millisecond)
+	tracker.Record(info, time.Now(), 20*time.Millisecond)
+	require.NoError(t, tracker.Flush(context.Background(), info))
+	tracker.Record(info, time.Now(), 50*time.Millisecond)
+
+	// Each worker process is signaled on its own control task queue
+	var calls []FakeClientCall
+	for _, call := range fakeClient.Calls("SignalWithStartWorkflow") {
+		call.Args = nil
+		calls = append(calls, call)
+	}
+	require.Equal(t, []FakeClientCall{{
+		Method:     "SignalWithStartWorkflow",
+		WorkflowID: "omes-control-run-1@host",
+		Name:       FlushStickyCacheSignal,
+		Options: &client.StartWorkflowOptions{ID: "omes-control-run-1@host", TaskQueue: "sticky:run-control-1@host",
+			WorkflowExecutionTimeout: controlWorkflowTimeout},
+	}, {
+		Method:     "SignalWithStartWorkflow",
+		WorkflowID: "omes-control-run-2@host",
+		Name:       FlushStickyCacheSignal,
+		Options: &client.StartWorkflowOptions{ID: "omes-control-run-2@host", TaskQueue: "sticky:run-control-2@host",
+			WorkflowExecutionTimeout: controlWorkflowTimeout},
+	}}, calls)
+
+	pre, post := tracker.Summary()
+	require.Equal(t, 20*time.Millisecond, pre.Max)
+	require.Equal(t, 50*time.Millisecond, post.Min)
+	var phases []string
+	for _, m := range *handler.recorded {
+		phases = append(phases, m.tags["sticky_flush"])
+	}
+	require.Equal(t, []string{"pre", "pre", "post"}, phases)
+}
+
+func TestStickyCacheFlushWithoutWorkers(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	var tracker StickyCacheFlushTracker
+	require.ErrorContains(t, tracker.Flush(context.Background(), &info), "no worker polls task queue test:test-run")
+}
+
+func TestStickyCacheFlushWithoutControlledWorkers(t *testing.T) {
+	fakeClient := controlledWorkersClient("2@host")
+	info := &ScenarioInfo{ScenarioName: "sticky", RunID: "run", Logger: zap.NewNop().Sugar(), Client: fakeClient}
+	var tracker StickyCacheFlushTracker
+	err := tracker.Flush(context.Background(), info)
+	require.ErrorContains(t, err, "workers 2@host do not poll their control task queue, only the Go worker handles")
+	require.Empty(t, fakeClient.Calls("SignalWithStartWorkflow"))
+}
+
+func TestStickyTaskStats(t *testing.T) {
+	handler := NewCapturingMetricsHandler(nil)
+	// As emitted by workers of two task queues
+	first := handler.WithTags(map[string]string{"task_queue": "first"})
+	first.Counter("temporal_sticky_cache_hit").Inc(6)
+	first.Counter("temporal_sticky_cache_miss").Inc(2)
+	first.Counter("temporal_sticky_cache_total_forced_eviction").Inc(1)
+	second := handler.WithTags(map[string]string{"task_queue": "second"})
+	second.Counter("temporal_sticky_cache_hit").Inc(1)
+	second.Counter("temporal_sticky_cache_miss").Inc(3)
+	handler.Counter("temporal_workflow_task_execution_latency").Inc(100)
+
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	_, err := info.StickyTaskStats(nil)
+	require.ErrorContains(t, err, "SDK metrics are not captured")
+
+	recording := newRecordingMetricsHandler()
+	info.MetricsHandler = recording
+	info.SDKMetrics = handler
+	stats, err := info.StickyTaskStats(nil)
+	require.NoError(t, err)
+	require.Equal(t, StickyTaskStats{Hits: 7, Misses: 5, ForcedEvictions: 1}, stats)
+	require.InDelta(t, 7.0/12, stats.HitRatio(), 1e-9)
+	require.Equal(t, []recordedMetric{
+		{kind: "gauge", name: "omes_sticky_task_hit", tags: map[string]string{"scenario": "test"}, value: 7},
+		{kind: "gauge", name: "omes_sticky_task_miss", tags: map[string]string{"scenario": "test"}, value: 5},
+	}, *recording.recorded)
+
+	stats = NewStickyTaskStats(handler, map[string]string{"task_queue": "second"})
+	require.Equal(t, StickyTaskStats{Hits: 1, Misses: 3}, stats)
+	require.Equal(t, 0.25, stats.HitRatio())
+	require.Zero(t, StickyTaskStats{}.HitRatio())
+}
@@ -0,0 +1,113 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// workflowTaskFailureLookupTimeout bounds fetching history for a failed workflow's task failures.
+const workflowTaskFailureLookupTimeout = 10 * time.Second
+
+// workflowTaskFailureLookupPageSize is the number of history events per page read by
+// LastWorkflowTaskFailure, which usually finds what it needs in the last few.
+const workflowTaskFailureLookupPageSize = 20
+
+// workflowTaskFailureLookups limits concurrent lookups of workflow task failures, so that many
+// failing iterations do not load the server with history reads. Failures beyond the limit are
+// returned without lookup.
+var workflowTaskFailureLookups = make(chan struct{}, 4)
+
+// WorkflowTaskFailureError wraps a workflow result error with the last workflow task failure found
+// in the workflow's history, e.g. a panic or nondeterminism error in workflow code that the
+// result error alone does not show.
+type WorkflowTaskFailureError struct {
+	WorkflowID string
+	RunID      string
+	Cause      enums.WorkflowTaskFailedCause
+	Message    string
+	StackTrace string
+	Err        error
+}
+
+func (e *WorkflowTaskFailureError) Error() string {
+	msg := fmt.Sprintf("%v (last workflow task failure, cause %v: %v)", e.Err, e.Cause, e.Message)
+	if e.StackTrace != "" {
+		msg += "\n" + e.StackTrace
+	}
+	return msg
+}
+
+func (e *WorkflowTaskFailureError) Unwrap() error {
+	return e.Err
+}
+
+// LastWorkflowTaskFailure returns the attributes of the last workflow task failed event in the
+// history of the given workflow since its last completed workflow task, or nil if there is none.
+// History is read newest first, only as far back as needed.
+func (r *Run) LastWorkflowTaskFailure(
+	ctx context.Context,
+	workflowID string,
+	runID string,
+) (*history.WorkflowTaskFailedEventAttributes, error) {
+	request := &workflowservice.GetWorkflowExecutionHistoryReverseRequest{
+		Namespace:       r.Namespace,
+		Execution:       &common.WorkflowExecution{WorkflowId: workflowID, RunId: runID},
+		MaximumPageSize: workflowTaskFailureLookupPageSize,
+	}
+	for {
+		resp, err := r.Client.WorkflowService().GetWorkflowExecutionHistoryReverse(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading history of workflow %v: %w", workflowID, err)
+		}
+		for _, event := range resp.GetHistory().GetEvents() {
+			if attrs := event.GetWorkflowTaskFailedEventAttributes(); attrs != nil {
+				return attrs, nil
+			} else if event.GetEventType() == enums.EVENT_TYPE_WORKFLOW_TASK_COMPLETED {
+				// Earlier failures did not keep the workflow from making progress
+				return nil, nil
+			}
+		}
+		if len(resp.GetNextPageToken()) == 0 {
+			return nil, nil
+		}
+		request.NextPageToken = resp.GetNextPageToken()
+	}
+}
+
+// withWorkflowTaskFailure wraps the error of a failed workflow in a WorkflowTaskFailureError if the
+// workflow had workflow task failures, and logs the failure. The error is returned as is if there
+// are none, they cannot be fetched, or too many lookups are in progress, see
+// workflowTaskFailureLookups.
+func (r *Run) withWorkflowTaskFailure(ctx context.Context, workflowID, runID string, err error) error {
+	select {
+	case workflowTaskFailureLookups <- struct{}{}:
+		defer func() { <-workflowTaskFailureLookups }()
+	default:
+		r.Logger.Debugf("Not looking up workflow task failures of workflow %v, too many lookups in progress",
+			workflowID)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, workflowTaskFailureLookupTimeout)
+	defer cancel()
+	attrs, lookupErr := r.LastWorkflowTaskFailure(ctx, workflowID, runID)
+	if lookupErr != nil {
+		r.Logger.Warnf("Could not look up workflow task failures: %v", lookupErr)
+		return err
+	} else if attrs == nil {
+		return err
+	}
+	taskErr := &WorkflowTaskFailureError{WorkflowID: workflowID, RunID: runID, Cause: attrs.Cause, Err: err}
+	if attrs.Failure != nil {
+		taskErr.Message = attrs.Failure.Message
+		taskErr.StackTrace = attrs.Failure.StackTrace
+	}
+	r.Logger.Errorw("Workflow had workflow task failure", "workflowId", workflowID, "runId", runID,
+		"cause", attrs.Cause, "message", taskErr.Message, "stackTrace", taskErr.StackTrace)
+	return taskErr
+}
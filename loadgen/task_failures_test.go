@@ -0,0 +1,141 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/failure/v1"
+	"go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+func taskFailedEvent(message, stackTrace string) *history.HistoryEvent {
+	return &history.HistoryEvent{
+		EventType: enums.EVENT_TYPE_WORKFLOW_TASK_FAILED,
+		Attributes: &history.HistoryEvent_WorkflowTaskFailedEventAttributes{
+			WorkflowTaskFailedEventAttributes: &history.WorkflowTaskFailedEventAttributes{
+				Cause:   enums.WORKFLOW_TASK_FAILED_CAUSE_WORKFLOW_WORKER_UNHANDLED_FAILURE,
+				Failure: &failure.Failure{Message: message, StackTrace: stackTrace},
+			},
+		},
+	}
+}
+
+func TestWorkflowResultIncludesTaskFailure(t *testing.T) {
+	workflowErr := errors.New("workflow terminated")
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: options.ID, RunID: "run", Err: workflowErr}, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return []*history.HistoryEvent{
+				{EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED},
+				taskFailedEvent("first panic", ""),
+				taskFailedEvent("panic: boom", "main.go:12"),
+				{EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED},
+			}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	err := run.ExecuteAnyWorkflow(context.Background(), run.DefaultStartWorkflowOptions(), "wf", nil)
+
+	var taskErr *WorkflowTaskFailureError
+	require.True(t, errors.As(err, &taskErr))
+	require.Equal(t, "panic: boom", taskErr.Message)
+	require.Equal(t, enums.WORKFLOW_TASK_FAILED_CAUSE_WORKFLOW_WORKER_UNHANDLED_FAILURE, taskErr.Cause)
+	require.ErrorIs(t, err, workflowErr)
+	require.ErrorContains(t, err, "panic: boom")
+	require.ErrorContains(t, err, "main.go:12")
+}
+
+func TestWorkflowResultWithoutTaskFailure(t *testing.T) {
+	workflowErr := errors.New("workflow failed")
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: options.ID, RunID: "run", Err: workflowErr}, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return nil, errors.New("history unavailable")
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+	err := run.ExecuteAnyWorkflow(context.Background(), run.DefaultStartWorkflowOptions(), "wf", nil)
+	var taskErr *WorkflowTaskFailureError
+	require.False(t, errors.As(err, &taskErr))
+	require.ErrorIs(t, err, workflowErr)
+}
+
+func TestLastWorkflowTaskFailureReadsOnlyRecentHistory(t *testing.T) {
+	events := []*history.HistoryEvent{{EventType: enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED}}
+	events = append(events, taskFailedEvent("recovered", ""))
+	events = append(events, &history.HistoryEvent{EventType: enums.EVENT_TYPE_WORKFLOW_TASK_COMPLETED})
+	for i := 0; i < 100; i++ {
+		events = append(events, &history.HistoryEvent{EventType: enums.EVENT_TYPE_ACTIVITY_TASK_COMPLETED})
+	}
+	fake := &FakeClient{
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return events, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+
+	// Failures before a completed workflow task are not the cause
+	attrs, err := run.LastWorkflowTaskFailure(context.Background(), "wf", "run")
+	require.NoError(t, err)
+	require.Nil(t, attrs)
+	require.Len(t, fake.Calls("GetWorkflowExecutionHistoryReverse"), 6)
+
+	// Only the page with the last failure is read
+	events = append(events, taskFailedEvent("panic: boom", ""))
+	attrs, err = run.LastWorkflowTaskFailure(context.Background(), "wf", "run")
+	require.NoError(t, err)
+	require.Equal(t, "panic: boom", attrs.Failure.Message)
+	require.Len(t, fake.Calls("GetWorkflowExecutionHistoryReverse"), 7)
+}
+
+func TestWorkflowResultTaskFailureLookupSkipped(t *testing.T) {
+	var workflowErr error
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return &FakeWorkflowRun{ID: options.ID, RunID: "run", Err: workflowErr}, nil
+		},
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return []*history.HistoryEvent{taskFailedEvent("panic: boom", "")}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	run := info.NewRun(1)
+
+	// Application errors explain the failure themselves
+	workflowErr = temporal.NewApplicationError("failed", "test")
+	err := run.ExecuteAnyWorkflow(context.Background(), run.DefaultStartWorkflowOptions(), "wf", nil)
+	require.ErrorIs(t, err, workflowErr)
+	require.Empty(t, fake.Calls("GetWorkflowExecutionHistoryReverse"))
+
+	// No lookup while too many are in progress
+	workflowErr = errors.New("workflow timed out")
+	for i := 0; i < cap(workflowTaskFailureLookups); i++ {
+		workflowTaskFailureLookups <- struct{}{}
+	}
+	err = run.ExecuteAnyWorkflow(context.Background(), run.DefaultStartWorkflowOptions(), "wf", nil)
+	var taskErr *WorkflowTaskFailureError
+	require.False(t, errors.As(err, &taskErr))
+	require.Empty(t, fake.Calls("GetWorkflowExecutionHistoryReverse"))
+
+	for i := 0; i < cap(workflowTaskFailureLookups); i++ {
+		<-workflowTaskFailureLookups
+	}
+	err = run.ExecuteAnyWorkflow(context.Background(), run.DefaultStartWorkflowOptions(), "wf", nil)
+	require.True(t, errors.As(err, &taskErr))
+	require.Len(t, fake.Calls("GetWorkflowExecutionHistoryReverse"), 1)
+}
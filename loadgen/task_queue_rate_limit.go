@@ -0,0 +1,123 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/failure/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// rateLimitPollTimeout bounds the activity poll carrying a new rate limit to the server. The poll
+// is expected to time out without a task, the server applies the limit on receiving it.
+var rateLimitPollTimeout = time.Second
+
+// defaultTaskQueueRate is the activity dispatch rate the server reports for task queues no limit
+// was requested for.
+const defaultTaskQueueRate = 100000
+
+// ErrTaskQueueRateLimitUnsupported is returned (wrapped) by SetTaskQueueRateLimit when the server
+// does not report or does not apply the task queue's dispatch rate.
+var ErrTaskQueueRateLimitUnsupported = errors.New("server does not support setting the task queue rate limit")
+
+// SetTaskQueueRateLimit sets the server-side activity dispatch rate limit of the run's task queue
+// to perSecond, returning a function restoring the previous limit. There is no API setting the
+// limit directly, so it is carried by an activity poll the way workers request it, then checked in
+// the task queue's status. It must be called before activities are scheduled on the task queue, a
+// task dispatched to the poll is failed back to the server for retry and fails the call. There is
+// no API removing a limit either, so if the task queue had none, i.e. reported the server's default
+// rate of 100000/s or 0, restoring leaves the limit set until the server unloads the task queue
+// rather than requesting the default as an explicit limit. Workers request their own limit on every poll (see
+// the worker's --task-queue-activities-per-second), overriding this one unless they request the
+// same. Returns an error wrapping ErrTaskQueueRateLimitUnsupported if the server does not report
+// the rate or did not apply it.
+func (s *ScenarioInfo) SetTaskQueueRateLimit(
+	ctx context.Context,
+	perSecond float64,
+) (restore func(context.Context) error, err error) {
+	taskQueue := TaskQueueForRun(s.ScenarioName, s.RunID)
+	previous, err := s.taskQueueRateLimit(ctx, taskQueue)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyTaskQueueRateLimit(ctx, taskQueue, perSecond); err != nil {
+		return nil, err
+	}
+	s.Logger.Infof("Set activity dispatch rate limit of task queue %v to %v/s, was %v/s", taskQueue, perSecond, previous)
+	return func(ctx context.Context) error {
+		if previous == 0 || previous >= defaultTaskQueueRate {
+			s.Logger.Infof("Task queue %v had no activity dispatch rate limit, leaving %v/s until it is unloaded",
+				taskQueue, perSecond)
+			return nil
+		}
+		if err := s.applyTaskQueueRateLimit(ctx, taskQueue, previous); err != nil {
+			return fmt.Errorf("failed restoring rate limit of task queue %v to %v/s: %w", taskQueue, previous, err)
+		}
+		return nil
+	}, nil
+}
+
+// taskQueueRateLimit returns the activity dispatch rate of the task queue reported by the server.
+func (s *ScenarioInfo) taskQueueRateLimit(ctx context.Context, taskQueue string) (float64, error) {
+	resp, err := s.Client.WorkflowService().DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+		Namespace:              s.Namespace,
+		TaskQueue:              &taskqueue.TaskQueue{Name: taskQueue},
+		TaskQueueType:          enums.TASK_QUEUE_TYPE_ACTIVITY,
+		IncludeTaskQueueStatus: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed describing task queue %v: %w", taskQueue, err)
+	} else if resp.GetTaskQueueStatus() == nil {
+		return 0, fmt.Errorf("task queue %v has no status: %w", taskQueue, ErrTaskQueueRateLimitUnsupported)
+	}
+	return resp.GetTaskQueueStatus().GetRatePerSecond(), nil
+}
+
+// applyTaskQueueRateLimit sends the rate limit with an activity poll, then checks the server
+// applied it.
+func (s *ScenarioInfo) applyTaskQueueRateLimit(ctx context.Context, taskQueue string, perSecond float64) error {
+	pollCtx, cancel := context.WithTimeout(ctx, rateLimitPollTimeout)
+	defer cancel()
+	resp, err := s.Client.WorkflowService().PollActivityTaskQueue(pollCtx, &workflowservice.PollActivityTaskQueueRequest{
+		Namespace:         s.Namespace,
+		TaskQueue:         &taskqueue.TaskQueue{Name: taskQueue, Kind: enums.TASK_QUEUE_KIND_NORMAL},
+		Identity:          "omes-rate-limit",
+		TaskQueueMetadata: &taskqueue.TaskQueueMetadata{MaxTasksPerSecond: &types.DoubleValue{Value: perSecond}},
+	})
+	var unimplemented *serviceerror.Unimplemented
+	if errors.As(err, &unimplemented) {
+		return fmt.Errorf("%v: %w", err, ErrTaskQueueRateLimitUnsupported)
+	} else if err != nil && pollCtx.Err() == nil {
+		return fmt.Errorf("failed polling task queue %v: %w", taskQueue, err)
+	} else if len(resp.GetTaskToken()) > 0 {
+		// Hand the task back instead of leaving it to time out
+		_, failErr := s.Client.WorkflowService().RespondActivityTaskFailed(ctx, &workflowservice.RespondActivityTaskFailedRequest{
+			Namespace: s.Namespace,
+			TaskToken: resp.GetTaskToken(),
+			Failure: &failure.Failure{
+				Message:     "activity task dispatched to omes rate limit poll",
+				FailureInfo: &failure.Failure_ApplicationFailureInfo{ApplicationFailureInfo: &failure.ApplicationFailureInfo{}},
+			},
+			Identity: "omes-rate-limit",
+		})
+		if failErr != nil {
+			s.Logger.Warnf("Failed handing back activity %v dispatched to the rate limit poll: %v", resp.GetActivityId(), failErr)
+		}
+		return fmt.Errorf("task queue %v dispatched activity %v to the rate limit poll, set the limit before scheduling activities",
+			taskQueue, resp.GetActivityId())
+	}
+	applied, err := s.taskQueueRateLimit(ctx, taskQueue)
+	if err != nil {
+		return err
+	} else if applied != perSecond {
+		return fmt.Errorf("task queue %v reports %v/s after requesting %v/s: %w",
+			taskQueue, applied, perSecond, ErrTaskQueueRateLimitUnsupported)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package loadgen
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+func useFastRateLimitPolls(t *testing.T) {
+	prev := rateLimitPollTimeout
+	rateLimitPollTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { rateLimitPollTimeout = prev })
+}
+
+// rateLimitServer fakes a server applying the rate limit of activity polls to the task queue
+// status.
+type rateLimitServer struct {
+	lock sync.Mutex
+	rate float64
+}
+
+func (s *rateLimitServer) install(fake *FakeClient) {
+	fake.OnDescribeTaskQueue = func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+		*workflowservice.DescribeTaskQueueResponse, error) {
+		if request.TaskQueueType != enums.TASK_QUEUE_TYPE_ACTIVITY || !request.IncludeTaskQueueStatus {
+			panic("unexpected describe request")
+		}
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return &workflowservice.DescribeTaskQueueResponse{
+			TaskQueueStatus: &taskqueue.TaskQueueStatus{RatePerSecond: s.rate},
+		}, nil
+	}
+	fake.OnPollActivityTaskQueue = func(ctx context.Context, request *workflowservice.PollActivityTaskQueueRequest) (
+		*workflowservice.PollActivityTaskQueueResponse, error) {
+		s.lock.Lock()
+		s.rate = request.GetTaskQueueMetadata().GetMaxTasksPerSecond().GetValue()
+		s.lock.Unlock()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+}
+
+func TestSetTaskQueueRateLimitAndRestore(t *testing.T) {
+	useFastRateLimitPolls(t)
+	server := &rateLimitServer{rate: 100000}
+	fake := &FakeClient{}
+	server.install(fake)
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+
+	restore, err := info.SetTaskQueueRateLimit(context.Background(), 5)
+	require.NoError(t, err)
+	require.Equal(t, 5.0, server.rate)
+	polls := fake.Calls("PollActivityTaskQueue")
+	require.Len(t, polls, 1)
+	require.Equal(t, "test:test-run", polls[0].Name)
+
+	// Without a limit before, none is requested back
+	require.NoError(t, restore(context.Background()))
+	require.Equal(t, 5.0, server.rate)
+	require.Len(t, fake.Calls("PollActivityTaskQueue"), 1)
+
+	// A previous limit is restored
+	restore, err = info.SetTaskQueueRateLimit(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, server.rate)
+	require.NoError(t, restore(context.Background()))
+	require.Equal(t, 5.0, server.rate)
+	require.Len(t, fake.Calls("PollActivityTaskQueue"), 3)
+}
+
+func TestSetTaskQueueRateLimitUnsupported(t *testing.T) {
+	useFastRateLimitPolls(t)
+	// No task queue status reported
+	fake := &FakeClient{}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.SetTaskQueueRateLimit(context.Background(), 5)
+	require.ErrorIs(t, err, ErrTaskQueueRateLimitUnsupported)
+	require.Empty(t, fake.Calls("PollActivityTaskQueue"))
+
+	// Rate reported but not applied by polls
+	server := &rateLimitServer{rate: 100000}
+	server.install(fake)
+	fake.OnPollActivityTaskQueue = func(ctx context.Context, request *workflowservice.PollActivityTaskQueueRequest) (
+		*workflowservice.PollActivityTaskQueueResponse, error) {
+		return &workflowservice.PollActivityTaskQueueResponse{}, nil
+	}
+	_, err = info.SetTaskQueueRateLimit(context.Background(), 5)
+	require.ErrorIs(t, err, ErrTaskQueueRateLimitUnsupported)
+
+	// Polls not implemented
+	fake.OnPollActivityTaskQueue = func(ctx context.Context, request *workflowservice.PollActivityTaskQueueRequest) (
+		*workflowservice.PollActivityTaskQueueResponse, error) {
+		return nil, serviceerror.NewUnimplemented("not implemented")
+	}
+	_, err = info.SetTaskQueueRateLimit(context.Background(), 5)
+	require.ErrorIs(t, err, ErrTaskQueueRateLimitUnsupported)
+}
+
+func TestSetTaskQueueRateLimitFailsOnDispatchedTask(t *testing.T) {
+	useFastRateLimitPolls(t)
+	server := &rateLimitServer{rate: 100000}
+	fake := &FakeClient{}
+	server.install(fake)
+	fake.OnPollActivityTaskQueue = func(ctx context.Context, request *workflowservice.PollActivityTaskQueueRequest) (
+		*workflowservice.PollActivityTaskQueueResponse, error) {
+		return &workflowservice.PollActivityTaskQueueResponse{TaskToken: []byte("token"), ActivityId: "1"}, nil
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.SetTaskQueueRateLimit(context.Background(), 5)
+	require.ErrorContains(t, err, "dispatched activity 1")
+	// The task is failed back to the server
+	responses := fake.Calls("RespondActivityTaskFailed")
+	require.Len(t, responses, 1)
+	require.Equal(t, []byte("token"), responses[0].Args[0].(*workflowservice.RespondActivityTaskFailedRequest).TaskToken)
+}
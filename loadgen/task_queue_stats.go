@@ -0,0 +1,142 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// TaskQueueStatsSnapshot is the server-side state of a task queue of the run at a point in time,
+// from DescribeTaskQueue.
+type TaskQueueStatsSnapshot struct {
+	Time      time.Time `json:"time"`
+	TaskQueue string    `json:"taskQueue"`
+	// "workflow" or "activity".
+	TaskQueueType string `json:"taskQueueType"`
+	Pollers       int    `json:"pollers"`
+	// Sum of the poll rates of the pollers.
+	PollerRatePerSecond float64 `json:"pollerRatePerSecond"`
+	// Task queue status, unset if the server did not return it.
+	BacklogCountHint *int64   `json:"backlogCountHint,omitempty"`
+	RatePerSecond    *float64 `json:"ratePerSecond,omitempty"`
+}
+
+// taskQueueStatsTypes are the task queue types snapshotted by a taskQueueStatsRecorder.
+var taskQueueStatsTypes = map[enums.TaskQueueType]string{
+	enums.TASK_QUEUE_TYPE_WORKFLOW: "workflow",
+	enums.TASK_QUEUE_TYPE_ACTIVITY: "activity",
+}
+
+// taskQueueStatsRecorder periodically snapshots the stats of the run's workflow and activity task
+// queues, see RunConfiguration.TaskQueueStatsInterval. It is safe for concurrent use.
+type taskQueueStatsRecorder struct {
+	info       *ScenarioInfo
+	taskQueues []string
+
+	lock      sync.Mutex
+	snapshots []TaskQueueStatsSnapshot
+	// Whether the server did not return a task queue status, logged once.
+	missingStatus bool
+	// Whether the server does not support DescribeTaskQueue, ending the snapshots.
+	unsupported bool
+	stop        chan struct{}
+	stopped     chan struct{}
+}
+
+// startTaskQueueStatsRecorder takes snapshots now and then every interval until stopped.
+func startTaskQueueStatsRecorder(ctx context.Context, info *ScenarioInfo, interval time.Duration) *taskQueueStatsRecorder {
+	r := &taskQueueStatsRecorder{
+		info:       info,
+		taskQueues: info.RunTaskQueues(),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go func() {
+		defer close(r.stopped)
+		r.snapshot(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.snapshot(ctx)
+			}
+		}
+	}()
+	return r
+}
+
+func (r *taskQueueStatsRecorder) snapshot(ctx context.Context) {
+	for _, taskQueue := range r.taskQueues {
+		for _, taskQueueType := range []enums.TaskQueueType{enums.TASK_QUEUE_TYPE_WORKFLOW, enums.TASK_QUEUE_TYPE_ACTIVITY} {
+			if r.isUnsupported() {
+				return
+			}
+			r.snapshotTaskQueue(ctx, taskQueue, taskQueueType)
+		}
+	}
+}
+
+func (r *taskQueueStatsRecorder) snapshotTaskQueue(ctx context.Context, taskQueue string, taskQueueType enums.TaskQueueType) {
+	resp, err := r.info.Client.WorkflowService().DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+		Namespace:              r.info.Namespace,
+		TaskQueue:              &taskqueue.TaskQueue{Name: taskQueue},
+		TaskQueueType:          taskQueueType,
+		IncludeTaskQueueStatus: true,
+	})
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var unimplemented *serviceerror.Unimplemented
+	if errors.As(err, &unimplemented) {
+		r.info.Logger.Warnf("Not recording task queue stats, server does not support describing task queues: %v", err)
+		r.unsupported = true
+		return
+	} else if err != nil {
+		if ctx.Err() == nil {
+			r.info.Logger.Warnf("Failed describing task queue %v for stats: %v", taskQueue, err)
+		}
+		return
+	}
+	snapshot := TaskQueueStatsSnapshot{
+		Time:          time.Now(),
+		TaskQueue:     taskQueue,
+		TaskQueueType: taskQueueStatsTypes[taskQueueType],
+		Pollers:       len(resp.GetPollers()),
+	}
+	for _, poller := range resp.GetPollers() {
+		snapshot.PollerRatePerSecond += poller.GetRatePerSecond()
+	}
+	if status := resp.GetTaskQueueStatus(); status != nil {
+		backlog, rate := status.GetBacklogCountHint(), status.GetRatePerSecond()
+		snapshot.BacklogCountHint, snapshot.RatePerSecond = &backlog, &rate
+	} else if !r.missingStatus {
+		r.info.Logger.Infof("Server did not return task queue status, recording task queue stats without backlog and rate")
+		r.missingStatus = true
+	}
+	r.snapshots = append(r.snapshots, snapshot)
+}
+
+func (r *taskQueueStatsRecorder) isUnsupported() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.unsupported
+}
+
+// Stop stops taking snapshots and returns them in order.
+func (r *taskQueueStatsRecorder) Stop() []TaskQueueStatsSnapshot {
+	close(r.stop)
+	<-r.stopped
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.snapshots
+}
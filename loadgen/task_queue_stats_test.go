@@ -0,0 +1,104 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// runWithTaskQueueStats runs iterations for about 50ms, snapshotting task queue stats every 10ms.
+func runWithTaskQueueStats(t *testing.T, fake *FakeClient) RunResult {
+	var buf bytes.Buffer
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 5, MaxConcurrent: 1, TaskQueueStatsInterval: 10 * time.Millisecond})
+	info.ReportSinks = []ReportSink{&WriterReportSink{Writer: &buf}}
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+	require.NoError(t, executor.Run(context.Background(), info))
+	var result RunResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	return result
+}
+
+func TestTaskQueueStatsTimeSeries(t *testing.T) {
+	var lock sync.Mutex
+	backlogs := map[enums.TaskQueueType]int64{}
+	fake := &FakeClient{
+		OnDescribeTaskQueue: func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+			*workflowservice.DescribeTaskQueueResponse, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			// Backlogs grow with every snapshot, the activity backlog twice as fast
+			backlogs[request.TaskQueueType] += int64(request.TaskQueueType)
+			return &workflowservice.DescribeTaskQueueResponse{
+				Pollers: []*taskqueue.PollerInfo{{RatePerSecond: 1.5}, {RatePerSecond: 2.5}},
+				TaskQueueStatus: &taskqueue.TaskQueueStatus{
+					BacklogCountHint: backlogs[request.TaskQueueType],
+					RatePerSecond:    100,
+				},
+			}, nil
+		},
+	}
+	result := runWithTaskQueueStats(t, fake)
+
+	series := map[string][]TaskQueueStatsSnapshot{}
+	for _, snapshot := range result.TaskQueueStats {
+		require.Equal(t, "test:test-run", snapshot.TaskQueue)
+		series[snapshot.TaskQueueType] = append(series[snapshot.TaskQueueType], snapshot)
+	}
+	require.Len(t, series, 2)
+	for taskQueueType, multiplier := range map[string]int64{"workflow": 1, "activity": 2} {
+		snapshots := series[taskQueueType]
+		require.GreaterOrEqual(t, len(snapshots), 3, taskQueueType)
+		for i, snapshot := range snapshots {
+			require.Equal(t, 2, snapshot.Pollers)
+			require.Equal(t, 4.0, snapshot.PollerRatePerSecond)
+			require.Equal(t, int64(i+1)*multiplier, *snapshot.BacklogCountHint)
+			require.Equal(t, 100.0, *snapshot.RatePerSecond)
+			if i > 0 {
+				require.True(t, snapshot.Time.After(snapshots[i-1].Time))
+			}
+		}
+	}
+	require.Len(t, fake.Calls("DescribeTaskQueue"), len(result.TaskQueueStats))
+}
+
+func TestTaskQueueStatsWithoutStatus(t *testing.T) {
+	fake := &FakeClient{
+		OnDescribeTaskQueue: func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+			*workflowservice.DescribeTaskQueueResponse, error) {
+			return &workflowservice.DescribeTaskQueueResponse{Pollers: []*taskqueue.PollerInfo{{}}}, nil
+		},
+	}
+	result := runWithTaskQueueStats(t, fake)
+	require.NotEmpty(t, result.TaskQueueStats)
+	for _, snapshot := range result.TaskQueueStats {
+		require.Equal(t, 1, snapshot.Pollers)
+		require.Nil(t, snapshot.BacklogCountHint)
+		require.Nil(t, snapshot.RatePerSecond)
+	}
+}
+
+func TestTaskQueueStatsUnsupported(t *testing.T) {
+	fake := &FakeClient{
+		OnDescribeTaskQueue: func(ctx context.Context, request *workflowservice.DescribeTaskQueueRequest) (
+			*workflowservice.DescribeTaskQueueResponse, error) {
+			return nil, serviceerror.NewUnimplemented("not supported")
+		},
+	}
+	result := runWithTaskQueueStats(t, fake)
+	require.Empty(t, result.TaskQueueStats)
+	// Snapshots stop at the first unsupported call
+	require.Len(t, fake.Calls("DescribeTaskQueue"), 1)
+	require.Equal(t, 5, result.IterationsCompleted)
+}
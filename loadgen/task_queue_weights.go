@@ -0,0 +1,85 @@
+package loadgen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TaskQueueWeights distributes iterations across the run's task queues suffixed "-0" to "-<n-1>"
+// (as served by workers with --task-queue-suffix-index-end) in proportion to per-queue weights, to
+// simulate uneven partitioning.
+type TaskQueueWeights struct {
+	// Cumulative weights normalized so the last is 1.
+	cumulative []float64
+}
+
+// NewTaskQueueWeights creates a distribution with the given weight per task queue index. Weights
+// must be non-negative with a positive sum.
+func NewTaskQueueWeights(weights []float64) (*TaskQueueWeights, error) {
+	var total float64
+	cumulative := make([]float64, len(weights))
+	for i, weight := range weights {
+		if weight < 0 {
+			return nil, fmt.Errorf("task queue weight %v is negative", weight)
+		}
+		total += weight
+		cumulative[i] = total
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("task queue weights must sum to a positive value")
+	}
+	for i := range cumulative {
+		cumulative[i] /= total
+	}
+	return &TaskQueueWeights{cumulative: cumulative}, nil
+}
+
+// ParseTaskQueueWeights parses comma-separated weights, e.g. "80,20".
+func ParseTaskQueueWeights(s string) (*TaskQueueWeights, error) {
+	var weights []float64
+	for _, field := range strings.Split(s, ",") {
+		weight, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task queue weight %q: %w", field, err)
+		}
+		weights = append(weights, weight)
+	}
+	return NewTaskQueueWeights(weights)
+}
+
+// Count returns the number of task queues.
+func (w *TaskQueueWeights) Count() int {
+	return len(w.cumulative)
+}
+
+// Index returns the task queue index for the iteration. It is a pure function of the seed and
+// iteration, so a run with the same seed distributes iterations identically regardless of
+// concurrency.
+func (w *TaskQueueWeights) Index(seed int64, iteration int) int {
+	value := iterationUniform(seed, iteration)
+	return sort.Search(len(w.cumulative), func(i int) bool { return w.cumulative[i] > value })
+}
+
+// iterationUniform returns a uniform value in [0, 1) that is a pure function of the seed and
+// iteration.
+func iterationUniform(seed int64, iteration int) float64 {
+	// Top 53 bits as a uniform value in [0, 1)
+	return float64(iterationHash(seed, iteration)>>11) / (1 << 53)
+}
+
+// iterationHash returns a well-mixed value that is a pure function of the seed and iteration.
+func iterationHash(seed int64, iteration int) uint64 {
+	// SplitMix64 of the seed offset by the iteration
+	x := uint64(seed) + uint64(iteration)*0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// WeightedTaskQueue returns the run's task queue for this iteration chosen by the weights using the
+// scenario seed.
+func (r *Run) WeightedTaskQueue(weights *TaskQueueWeights) string {
+	return fmt.Sprintf("%v-%v", r.TaskQueue(), weights.Index(r.Seed(), r.Iteration))
+}
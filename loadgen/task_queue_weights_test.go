@@ -0,0 +1,48 @@
+package loadgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskQueueWeightsDistribution(t *testing.T) {
+	weights, err := ParseTaskQueueWeights("80, 0, 20")
+	require.NoError(t, err)
+	require.Equal(t, 3, weights.Count())
+	const iterations = 20000
+	counts := make([]int, weights.Count())
+	for i := 0; i < iterations; i++ {
+		counts[weights.Index(42, i)]++
+	}
+	require.InDelta(t, 0.8, float64(counts[0])/iterations, 0.02)
+	require.Zero(t, counts[1])
+	require.InDelta(t, 0.2, float64(counts[2])/iterations, 0.02)
+
+	// Reproducible for a seed, different across seeds
+	different := 0
+	for i := 0; i < 100; i++ {
+		require.Equal(t, weights.Index(42, i), weights.Index(42, i))
+		if weights.Index(42, i) != weights.Index(43, i) {
+			different++
+		}
+	}
+	require.NotZero(t, different)
+}
+
+func TestWeightedTaskQueue(t *testing.T) {
+	weights, err := NewTaskQueueWeights([]float64{0, 1})
+	require.NoError(t, err)
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	run := info.NewRun(7)
+	require.Equal(t, run.TaskQueue()+"-1", run.WeightedTaskQueue(weights))
+}
+
+func TestTaskQueueWeightsInvalid(t *testing.T) {
+	_, err := ParseTaskQueueWeights("1,-1")
+	require.ErrorContains(t, err, "negative")
+	_, err = ParseTaskQueueWeights("0,0")
+	require.ErrorContains(t, err, "positive")
+	_, err = ParseTaskQueueWeights("1,a")
+	require.ErrorContains(t, err, "invalid task queue weight")
+}
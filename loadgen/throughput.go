@@ -0,0 +1,65 @@
+package loadgen
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultThroughputWindow is the default window of the throughput reported with progress.
+const DefaultThroughputWindow = 30 * time.Second
+
+// ThroughputWindow calculates the rate of events over a sliding window of whole seconds. Events
+// are counted in per-second buckets updated with atomic operations only, so recording is cheap
+// under high concurrency. It is safe for concurrent use.
+type ThroughputWindow struct {
+	seconds int64
+	// Ring of buckets indexed by Unix second, each packing the second it counts (upper 32 bits)
+	// and its count (lower 32 bits) so a bucket is reset and incremented in one operation.
+	buckets []uint64
+}
+
+// NewThroughputWindow creates a throughput calculator over the given window, rounded up to whole
+// seconds.
+func NewThroughputWindow(window time.Duration) *ThroughputWindow {
+	seconds := int64((window + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	// One extra bucket for the second in progress
+	return &ThroughputWindow{seconds: seconds, buckets: make([]uint64, seconds+1)}
+}
+
+// Window returns the window the rate is calculated over.
+func (w *ThroughputWindow) Window() time.Duration {
+	return time.Duration(w.seconds) * time.Second
+}
+
+// Record records an event at the given time.
+func (w *ThroughputWindow) Record(t time.Time) {
+	second := uint64(uint32(t.Unix()))
+	bucket := &w.buckets[t.Unix()%int64(len(w.buckets))]
+	for {
+		old := atomic.LoadUint64(bucket)
+		updated := second<<32 | 1
+		if old>>32 == second {
+			updated = old + 1
+		}
+		if atomic.CompareAndSwapUint64(bucket, old, updated) {
+			return
+		}
+	}
+}
+
+// Rate returns the events per second over the window of whole seconds before the given time. The
+// second in progress is excluded since it is incomplete.
+func (w *ThroughputWindow) Rate(now time.Time) float64 {
+	var total uint64
+	for ago := int64(1); ago <= w.seconds; ago++ {
+		unix := now.Unix() - ago
+		value := atomic.LoadUint64(&w.buckets[unix%int64(len(w.buckets))])
+		if value>>32 == uint64(uint32(unix)) {
+			total += value & 0xffffffff
+		}
+	}
+	return float64(total) / float64(w.seconds)
+}
@@ -0,0 +1,57 @@
+package loadgen
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThroughputWindowRate(t *testing.T) {
+	w := NewThroughputWindow(10 * time.Second)
+	start := time.Unix(1_700_000_000, 0)
+	// 5/sec for the first 10 seconds, then 20/sec for the next 10, checking the rate as time
+	// progresses
+	rates := map[int]float64{}
+	for second := 0; second <= 25; second++ {
+		rates[second] = w.Rate(start.Add(time.Duration(second)*time.Second + 500*time.Millisecond))
+		perSecond := 0
+		if second < 10 {
+			perSecond = 5
+		} else if second < 20 {
+			perSecond = 20
+		}
+		for i := 0; i < perSecond; i++ {
+			w.Record(start.Add(time.Duration(second)*time.Second + time.Duration(i)*time.Millisecond))
+		}
+	}
+	require.Equal(t, 0.0, rates[0])
+	// Window not yet full
+	require.Equal(t, 2.5, rates[5])
+	require.Equal(t, 5.0, rates[10])
+	// Half the window at each rate
+	require.Equal(t, 12.5, rates[15])
+	require.Equal(t, 20.0, rates[20])
+	// Buckets reused for later seconds do not count stale events
+	require.Equal(t, 10.0, rates[25])
+	require.Equal(t, 0.0, w.Rate(start.Add(time.Minute)))
+}
+
+func TestThroughputWindowConcurrent(t *testing.T) {
+	w := NewThroughputWindow(time.Second)
+	second := time.Unix(1_700_000_000, 0)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				w.Record(second)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 8000.0, w.Rate(second.Add(time.Second)))
+	require.Equal(t, time.Second, w.Window())
+}
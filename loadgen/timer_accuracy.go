@@ -0,0 +1,98 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/enums/v1"
+)
+
+// TimerDrifts returns the drift of every fired timer of the workflow, from history timestamps: the
+// time between its start and fire events minus its duration, i.e. how late the timer fired.
+func (r *Run) TimerDrifts(ctx context.Context, workflowID, runID string) ([]time.Duration, error) {
+	type startedTimer struct {
+		at       time.Time
+		duration time.Duration
+	}
+	started := map[string]startedTimer{}
+	var drifts []time.Duration
+	iter := r.Client.GetWorkflowHistory(ctx, workflowID, runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading history of workflow %v: %w", workflowID, err)
+		}
+		if attrs := event.GetTimerStartedEventAttributes(); attrs != nil {
+			if event.EventTime == nil || attrs.StartToFireTimeout == nil {
+				return nil, fmt.Errorf("history of workflow %v lacks time or duration of timer %v", workflowID, attrs.TimerId)
+			}
+			started[attrs.TimerId] = startedTimer{at: *event.EventTime, duration: *attrs.StartToFireTimeout}
+		} else if attrs := event.GetTimerFiredEventAttributes(); attrs != nil {
+			timer, ok := started[attrs.TimerId]
+			if !ok || event.EventTime == nil {
+				return nil, fmt.Errorf("history of workflow %v lacks start or fire time of timer %v", workflowID, attrs.TimerId)
+			}
+			drifts = append(drifts, event.EventTime.Sub(timer.at)-timer.duration)
+		}
+	}
+	return drifts, nil
+}
+
+// TimerAccuracy measures timer drift under load: iterations call Execute, each running a workflow
+// with timers of a known duration and recording how late they fired, see Run.TimerDrifts. Drifts
+// are recorded in the omes_timer_drift timer and summarized by Summary. It is safe for concurrent
+// use.
+type TimerAccuracy struct {
+	// Duration of the timers. Default is 1s.
+	Duration time.Duration
+	// Timers per workflow, run concurrently. Default is 1.
+	Timers int
+
+	lock   sync.Mutex
+	drifts []time.Duration
+}
+
+// Execute executes a kitchen sink workflow running the timers, then records their drifts.
+func (a *TimerAccuracy) Execute(ctx context.Context, run *Run) error {
+	duration, timers := a.Duration, a.Timers
+	if duration <= 0 {
+		duration = time.Second
+	}
+	if timers <= 0 {
+		timers = 1
+	}
+	execution, err := run.Client.ExecuteWorkflow(ctx, run.StartWorkflowOptions(), "kitchenSink",
+		&kitchensink.WorkflowInput{InitialActions: []*kitchensink.ActionSet{
+			kitchensink.TimersActionSet(timers, duration),
+			kitchensink.EmptyResultActionSet(),
+		}})
+	if err != nil {
+		return fmt.Errorf("failed to start kitchen sink workflow: %w", err)
+	}
+	if err := run.getWorkflowResult(ctx, execution, nil); err != nil {
+		return fmt.Errorf("kitchen sink workflow failed: %w", err)
+	}
+	drifts, err := run.TimerDrifts(ctx, execution.GetID(), execution.GetRunID())
+	if err != nil {
+		return err
+	} else if len(drifts) != timers {
+		return fmt.Errorf("workflow %v fired %v of %v timers", execution.GetID(), len(drifts), timers)
+	}
+	for _, drift := range drifts {
+		run.RecordTimer("omes_timer_drift", nil, drift)
+	}
+	a.lock.Lock()
+	a.drifts = append(a.drifts, drifts...)
+	a.lock.Unlock()
+	return nil
+}
+
+// Summary returns a summary of the drifts recorded so far, including the p99.
+func (a *TimerAccuracy) Summary() LatencySummary {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return NewLatencySummary(append([]time.Duration(nil), a.drifts...))
+}
@@ -0,0 +1,91 @@
+package loadgen
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/history/v1"
+)
+
+func timerStartedEvent(id string, at time.Time, duration time.Duration) *history.HistoryEvent {
+	event := historyEvent(enums.EVENT_TYPE_TIMER_STARTED, at)
+	event.Attributes = &history.HistoryEvent_TimerStartedEventAttributes{
+		TimerStartedEventAttributes: &history.TimerStartedEventAttributes{TimerId: id, StartToFireTimeout: &duration},
+	}
+	return event
+}
+
+func timerFiredEvent(id string, at time.Time) *history.HistoryEvent {
+	event := historyEvent(enums.EVENT_TYPE_TIMER_FIRED, at)
+	event.Attributes = &history.HistoryEvent_TimerFiredEventAttributes{
+		TimerFiredEventAttributes: &history.TimerFiredEventAttributes{TimerId: id},
+	}
+	return event
+}
+
+func TestTimerAccuracy(t *testing.T) {
+	start := time.Now()
+	fake := &FakeClient{
+		// Timers of workflow "w-test-run-<n>" fire n and 2n milliseconds late
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			n, err := strconv.Atoi(strings.TrimPrefix(workflowID, "w-test-run-"))
+			require.NoError(t, err)
+			late := time.Duration(n) * time.Millisecond
+			return []*history.HistoryEvent{
+				historyEvent(enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, start),
+				timerStartedEvent("1", start, time.Second),
+				timerStartedEvent("2", start, time.Second),
+				timerFiredEvent("1", start.Add(time.Second+late)),
+				timerFiredEvent("2", start.Add(time.Second+2*late)),
+			}, nil
+		},
+	}
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{Iterations: 5})
+	info.MetricsHandler = handler
+	accuracy := &TimerAccuracy{Timers: 2}
+	require.NoError(t, (&GenericExecutor{Execute: accuracy.Execute}).Run(context.Background(), info))
+
+	summary := accuracy.Summary()
+	require.Equal(t, time.Millisecond, summary.Min)
+	require.Equal(t, 10*time.Millisecond, summary.Max)
+	require.Equal(t, 8*time.Millisecond, summary.P99)
+	var drifts int
+	for _, metric := range *handler.recorded {
+		if metric.name == "omes_timer_drift" {
+			drifts++
+		}
+	}
+	require.Equal(t, 10, drifts)
+}
+
+func TestTimerAccuracyMissingTimers(t *testing.T) {
+	start := time.Now()
+	fake := &FakeClient{
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return []*history.HistoryEvent{
+				timerStartedEvent("1", start, time.Second),
+				timerFiredEvent("1", start.Add(time.Second)),
+			}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	err := (&TimerAccuracy{Timers: 3}).Execute(context.Background(), info.NewRun(1))
+	require.ErrorContains(t, err, "fired 1 of 3 timers")
+}
+
+func TestTimerDriftsRequiresStart(t *testing.T) {
+	fake := &FakeClient{
+		OnGetWorkflowHistory: func(ctx context.Context, workflowID, runID string) ([]*history.HistoryEvent, error) {
+			return []*history.HistoryEvent{timerFiredEvent("1", time.Now())}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.NewRun(1).TimerDrifts(context.Background(), "wf", "run")
+	require.ErrorContains(t, err, "lacks start or fire time of timer 1")
+}
@@ -0,0 +1,27 @@
+package loadgen
+
+import (
+	"context"
+	"strconv"
+)
+
+// IterationTracer traces the iterations of a GenericExecutor run, e.g. by opening an OpenTelemetry
+// span per iteration, as the tracer of the --tracing-otlp-endpoint flag does. Set it with
+// ScenarioInfo.Tracer.
+type IterationTracer interface {
+	// StartIteration is called before an iteration executes with the attributes "scenario",
+	// "run_id", "iteration" and "workflow_id" (the iteration's default workflow ID). It returns the
+	// context to execute the iteration with, which can carry the trace to the client, and a
+	// function called with the iteration's error, nil on success, once it ends.
+	StartIteration(ctx context.Context, attributes map[string]string) (context.Context, func(error))
+}
+
+// traceAttributes returns the attributes the iteration is traced with.
+func (r *Run) traceAttributes() map[string]string {
+	return map[string]string{
+		"scenario":    r.ScenarioName,
+		"run_id":      r.RunID,
+		"iteration":   strconv.Itoa(r.Iteration),
+		"workflow_id": r.DefaultStartWorkflowOptions().ID,
+	}
+}
@@ -0,0 +1,72 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordedSpan struct {
+	attributes map[string]string
+	err        error
+	ended      bool
+}
+
+type spanKey struct{}
+
+// recordingTracer is an in-memory IterationTracer.
+type recordingTracer struct {
+	lock  sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) StartIteration(
+	ctx context.Context,
+	attributes map[string]string,
+) (context.Context, func(error)) {
+	span := &recordedSpan{attributes: attributes}
+	r.lock.Lock()
+	r.spans = append(r.spans, span)
+	r.lock.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), func(err error) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		span.err, span.ended = err, true
+	}
+}
+
+func TestGenericExecutorTracesIterations(t *testing.T) {
+	tracer := &recordingTracer{}
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 4, MaxConcurrent: 1})
+	info.Tracer = tracer
+	err := (&GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			if ctx.Value(spanKey{}) == nil {
+				return errors.New("iteration context not traced")
+			}
+			if run.Iteration == 4 {
+				return errors.New("deliberate fail from test")
+			}
+			return nil
+		},
+	}).Run(context.Background(), info)
+	require.ErrorContains(t, err, "deliberate fail")
+
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+	require.Len(t, tracer.spans, 4)
+	for i, span := range tracer.spans {
+		require.True(t, span.ended)
+		require.Equal(t, map[string]string{
+			"scenario":    "test",
+			"run_id":      "test-run",
+			"iteration":   []string{"1", "2", "3", "4"}[i],
+			"workflow_id": []string{"w-test-run-1", "w-test-run-2", "w-test-run-3", "w-test-run-4"}[i],
+		}, span.attributes)
+	}
+	require.NoError(t, tracer.spans[0].err)
+	require.ErrorContains(t, tracer.spans[3].err, "deliberate fail")
+}
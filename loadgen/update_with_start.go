@@ -0,0 +1,89 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/serviceerror"
+)
+
+// UpdateWithStart is an update sent by Run.UpdateWithStart, to a workflow started by the call if it
+// is not running.
+type UpdateWithStart struct {
+	// ID of the workflow. Default is the iteration's default workflow ID.
+	WorkflowID string
+	// Workflow to start.
+	WorkflowSpec
+	// Update sent. Default is the kitchen sink's "do_actions_update" setting the "last_write" state
+	// key to the iteration.
+	UpdateName string
+	UpdateArgs []interface{}
+}
+
+// UpdateWithStart sends the update to its workflow, starting the workflow first if it is not
+// running, and waits for the update result into valuePtr, which may be nil. It returns whether the
+// workflow was created by the call rather than already running. The SDK in use predates atomic
+// update-with-start, so the start and update are separate calls: a workflow closing in between
+// fails the update. The latency of both calls and the update result is recorded in the
+// omes_update_with_start_latency timer, tagged with "workflow" "created" or "existing".
+func (r *Run) UpdateWithStart(ctx context.Context, update UpdateWithStart, valuePtr interface{}) (created bool, err error) {
+	options := r.StartWorkflowOptions()
+	if update.WorkflowID != "" {
+		options.ID = update.WorkflowID
+	}
+	options.WorkflowExecutionErrorWhenAlreadyStarted = true
+	workflow, workflowArgs := update.workflowAndArgs()
+	updateName, updateArgs := update.UpdateName, update.UpdateArgs
+	if updateName == "" {
+		updateName = "do_actions_update"
+		if updateArgs == nil {
+			updateArgs = []interface{}{lastWriteUpdate(r.Iteration)}
+		}
+	}
+
+	start := time.Now()
+	var runID string
+	execution, err := r.Client.ExecuteWorkflow(ctx, options, workflow, workflowArgs...)
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &alreadyStarted) {
+		runID = alreadyStarted.RunId
+	} else if err != nil {
+		return false, fmt.Errorf("failed to start workflow %v: %w", options.ID, err)
+	} else {
+		created, runID = true, execution.GetRunID()
+	}
+	handle, err := r.Client.UpdateWorkflow(ctx, options.ID, runID, updateName, updateArgs...)
+	if err == nil {
+		err = handle.Get(ctx, valuePtr)
+	}
+	if err != nil {
+		return created, fmt.Errorf("failed updating workflow %v: %w", options.ID, err)
+	}
+	tags := map[string]string{"workflow": "existing"}
+	if created {
+		tags["workflow"] = "created"
+	}
+	r.RecordTimer("omes_update_with_start_latency", tags, time.Since(start))
+	return created, nil
+}
+
+// lastWriteUpdate returns a kitchen sink update setting the "last_write" state key to the iteration.
+func lastWriteUpdate(iteration int) *kitchensink.DoActionsUpdate {
+	return &kitchensink.DoActionsUpdate{
+		Variant: &kitchensink.DoActionsUpdate_DoActions{
+			DoActions: &kitchensink.ActionSet{
+				Actions: []*kitchensink.Action{{
+					Variant: &kitchensink.Action_SetWorkflowState{
+						SetWorkflowState: &kitchensink.WorkflowState{
+							Kvs: map[string]string{"last_write": strconv.Itoa(iteration)},
+						},
+					},
+				}},
+			},
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+func TestUpdateWithStartCreatesWorkflow(t *testing.T) {
+	var updatedRunID string
+	fake := &FakeClient{
+		OnUpdateWorkflow: func(ctx context.Context, workflowID, runID, updateName string, args ...interface{}) (interface{}, error) {
+			updatedRunID = runID
+			return "updated", nil
+		},
+	}
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	var result string
+	created, err := info.NewRun(3).UpdateWithStart(context.Background(), UpdateWithStart{}, &result)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, "updated", result)
+	require.Equal(t, "run-w-test-run-3", updatedRunID)
+
+	starts := fake.Calls("ExecuteWorkflow")
+	require.Len(t, starts, 1)
+	require.Equal(t, "w-test-run-3", starts[0].WorkflowID)
+	require.True(t, starts[0].Options.WorkflowExecutionErrorWhenAlreadyStarted)
+	updates := fake.Calls("UpdateWorkflow")
+	require.Len(t, updates, 1)
+	require.Equal(t, "do_actions_update", updates[0].Name)
+	require.Equal(t, map[string]string{"last_write": "3"}, updates[0].Args[0].(*kitchensink.DoActionsUpdate).
+		GetDoActions().Actions[0].GetSetWorkflowState().Kvs)
+	require.Len(t, *handler.recorded, 1)
+	require.Equal(t, "omes_update_with_start_latency", (*handler.recorded)[0].name)
+	require.Equal(t, "created", (*handler.recorded)[0].tags["workflow"])
+}
+
+func TestUpdateWithStartExistingWorkflow(t *testing.T) {
+	var updatedRunID string
+	fake := &FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return nil, serviceerror.NewWorkflowExecutionAlreadyStarted("already started", "", "existing-run")
+		},
+		OnUpdateWorkflow: func(ctx context.Context, workflowID, runID, updateName string, args ...interface{}) (interface{}, error) {
+			updatedRunID = runID
+			return nil, nil
+		},
+	}
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	created, err := info.NewRun(1).UpdateWithStart(context.Background(),
+		UpdateWithStart{WorkflowID: "entity", UpdateName: "my_update", UpdateArgs: []interface{}{"arg"}}, nil)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, "existing-run", updatedRunID)
+	updates := fake.Calls("UpdateWorkflow")
+	require.Len(t, updates, 1)
+	require.Equal(t, "entity", updates[0].WorkflowID)
+	require.Equal(t, []interface{}{"arg"}, updates[0].Args)
+	require.Equal(t, "existing", (*handler.recorded)[0].tags["workflow"])
+
+	// Other start errors fail without updating
+	fake.OnExecuteWorkflow = func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+		args ...interface{}) (client.WorkflowRun, error) {
+		return nil, serviceerror.NewUnavailable("unavailable")
+	}
+	_, err = info.NewRun(2).UpdateWithStart(context.Background(), UpdateWithStart{}, nil)
+	require.ErrorContains(t, err, "failed to start workflow")
+	require.Len(t, fake.Calls("UpdateWorkflow"), 1)
+}
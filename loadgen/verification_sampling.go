@@ -0,0 +1,68 @@
+package loadgen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// Scenario options configuring VerificationSampling, see ScenarioInfo.VerificationSampling.
+const (
+	VerifySampleRateOption  = "verify-sample-rate"
+	VerifySampleCountOption = "verify-sample-count"
+)
+
+// VerificationSampling selects the share of a run's workflows inspected by expensive verification
+// such as VerifyHistoryInvariants. The zero value selects all workflows.
+type VerificationSampling struct {
+	// Fraction of workflows in (0, 1], rounded up to a whole workflow.
+	Rate float64
+	// Fixed number of workflows. Takes precedence over Rate if set.
+	Count int
+}
+
+// VerificationSampling returns the sampling given by the verify-sample-rate and
+// verify-sample-count scenario options.
+func (s *ScenarioInfo) VerificationSampling() (VerificationSampling, error) {
+	var sampling VerificationSampling
+	if v := s.ScenarioOptions[VerifySampleRateOption]; v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return sampling, fmt.Errorf("%v must be a fraction in (0, 1], got %q", VerifySampleRateOption, v)
+		}
+		sampling.Rate = rate
+	}
+	if v := s.ScenarioOptions[VerifySampleCountOption]; v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil || count <= 0 {
+			return sampling, fmt.Errorf("%v must be a positive integer, got %q", VerifySampleCountOption, v)
+		}
+		sampling.Count = count
+	}
+	return sampling, nil
+}
+
+// Size returns the number of workflows sampled out of total.
+func (v VerificationSampling) Size(total int) int {
+	size := total
+	if v.Count > 0 {
+		size = v.Count
+	} else if v.Rate > 0 {
+		size = int(math.Ceil(v.Rate * float64(total)))
+	}
+	if size > total {
+		size = total
+	}
+	return size
+}
+
+// Indexes returns the sorted indexes of the workflows sampled out of total, chosen reproducibly
+// from the seed.
+func (v VerificationSampling) Indexes(seed int64, total int) []int {
+	size := v.Size(total)
+	indexes := rand.New(rand.NewSource(seed)).Perm(total)[:size]
+	sort.Ints(indexes)
+	return indexes
+}
@@ -0,0 +1,52 @@
+package loadgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationSamplingSize(t *testing.T) {
+	require.Equal(t, 1000, VerificationSampling{}.Size(1000))
+	require.Equal(t, 100, VerificationSampling{Rate: 0.1}.Size(1000))
+	// Rounds up to at least one workflow
+	require.Equal(t, 1, VerificationSampling{Rate: 0.1}.Size(3))
+	require.Equal(t, 25, VerificationSampling{Rate: 0.1, Count: 25}.Size(1000))
+	require.Equal(t, 10, VerificationSampling{Count: 25}.Size(10))
+}
+
+func TestVerificationSamplingIndexes(t *testing.T) {
+	sampling := VerificationSampling{Rate: 0.05}
+	indexes := sampling.Indexes(42, 2000)
+	require.Len(t, indexes, 100)
+	require.Equal(t, indexes, sampling.Indexes(42, 2000))
+	require.NotEqual(t, indexes, sampling.Indexes(43, 2000))
+	seen := map[int]bool{}
+	for i, index := range indexes {
+		require.False(t, seen[index])
+		seen[index] = true
+		require.True(t, index >= 0 && index < 2000)
+		if i > 0 {
+			require.Greater(t, index, indexes[i-1])
+		}
+	}
+}
+
+func TestScenarioInfoVerificationSampling(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	sampling, err := info.VerificationSampling()
+	require.NoError(t, err)
+	require.Equal(t, VerificationSampling{}, sampling)
+
+	info.ScenarioOptions = map[string]string{VerifySampleRateOption: "0.25", VerifySampleCountOption: "10"}
+	sampling, err = info.VerificationSampling()
+	require.NoError(t, err)
+	require.Equal(t, VerificationSampling{Rate: 0.25, Count: 10}, sampling)
+
+	info.ScenarioOptions = map[string]string{VerifySampleRateOption: "1.5"}
+	_, err = info.VerificationSampling()
+	require.ErrorContains(t, err, VerifySampleRateOption)
+	info.ScenarioOptions = map[string]string{VerifySampleCountOption: "-1"}
+	_, err = info.VerificationSampling()
+	require.ErrorContains(t, err, VerifySampleCountOption)
+}
@@ -0,0 +1,134 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+// BuildIDMemoKey is the memo key WithTargetBuildID records the targeted build ID under.
+const BuildIDMemoKey = "omesBuildId"
+
+// BuildIDOption is the scenario option giving the worker build ID load is directed at, see
+// ScenarioInfo.TargetBuildID.
+const BuildIDOption = "build-id"
+
+// buildIDRevertTimeout bounds reverting the build ID pinned for a run, which happens after the
+// run's context may be done.
+const buildIDRevertTimeout = 10 * time.Second
+
+// ErrVersioningUnsupported is returned when the server or namespace does not support worker
+// versioning.
+var ErrVersioningUnsupported = errors.New("worker versioning not supported")
+
+// TargetBuildID returns the worker build ID that load should be directed at, from the BuildIDOption
+// scenario option. Empty if load is not pinned to a version.
+func (s *ScenarioInfo) TargetBuildID() string {
+	return s.ScenarioOptions[BuildIDOption]
+}
+
+// WithTargetBuildID records the build ID the workflow is meant to run on in its memo under
+// BuildIDMemoKey. Start requests have no build ID of their own; new workflows run on the default
+// version set of their task queue, which [ScenarioInfo.PinBuildID] sets. The memo lets the
+// targeted version be checked against the version that actually processed the workflow.
+func WithTargetBuildID(buildID string) StartOption {
+	return func(options *client.StartWorkflowOptions) {
+		memo := make(map[string]interface{}, len(options.Memo)+1)
+		for k, v := range options.Memo {
+			memo[k] = v
+		}
+		memo[BuildIDMemoKey] = buildID
+		options.Memo = memo
+	}
+}
+
+// PinBuildID makes the given build ID the default for new workflows on the run's task queue,
+// adding it in a new default version set if the task queue does not know it yet. Returns an error
+// wrapping ErrVersioningUnsupported if the server does not support worker versioning.
+//
+// The returned function reverts the task queue to its previous default build ID. Build IDs cannot
+// be removed, so reverting leaves a task queue that had no default build ID with the pinned one,
+// and the pinned build ID remains known to the task queue either way.
+func (s *ScenarioInfo) PinBuildID(ctx context.Context, buildID string) (revert func(context.Context) error, err error) {
+	taskQueue := TaskQueueForRun(s.ScenarioName, s.RunID)
+	sets, err := s.Client.GetWorkerBuildIdCompatibility(ctx, &client.GetWorkerBuildIdCompatibilityOptions{
+		TaskQueue: taskQueue,
+	})
+	if err != nil {
+		return nil, versioningError(fmt.Errorf("failed getting build IDs of task queue %v: %w", taskQueue, err))
+	}
+	var previous string
+	if sets != nil {
+		previous = sets.Default()
+	}
+	if previous == buildID {
+		return func(context.Context) error { return nil }, nil
+	}
+	if err := s.setDefaultBuildID(ctx, taskQueue, buildID, setContainsBuildID(sets, buildID)); err != nil {
+		return nil, err
+	}
+	s.Logger.Infof("Pinned task queue %v to build ID %v", taskQueue, buildID)
+	return func(ctx context.Context) error {
+		if previous == "" {
+			s.Logger.Infof("Not reverting build ID of task queue %v, it had no default build ID before", taskQueue)
+			return nil
+		}
+		if err := s.setDefaultBuildID(ctx, taskQueue, previous, true); err != nil {
+			return err
+		}
+		s.Logger.Infof("Reverted task queue %v to build ID %v", taskQueue, previous)
+		return nil
+	}, nil
+}
+
+// setDefaultBuildID makes the build ID the default of the task queue, promoting it if known to the
+// task queue, adding it in a new default set otherwise.
+func (s *ScenarioInfo) setDefaultBuildID(ctx context.Context, taskQueue, buildID string, known bool) error {
+	var updates []*client.UpdateWorkerBuildIdCompatibilityOptions
+	if known {
+		updates = []*client.UpdateWorkerBuildIdCompatibilityOptions{
+			{TaskQueue: taskQueue, Operation: &client.BuildIDOpPromoteSet{BuildID: buildID}},
+			{TaskQueue: taskQueue, Operation: &client.BuildIDOpPromoteIDWithinSet{BuildID: buildID}},
+		}
+	} else {
+		updates = []*client.UpdateWorkerBuildIdCompatibilityOptions{
+			{TaskQueue: taskQueue, Operation: &client.BuildIDOpAddNewIDInNewDefaultSet{BuildID: buildID}},
+		}
+	}
+	for _, update := range updates {
+		if err := s.Client.UpdateWorkerBuildIdCompatibility(ctx, update); err != nil {
+			return versioningError(fmt.Errorf("failed setting build ID %v on task queue %v: %w", buildID, taskQueue, err))
+		}
+	}
+	return nil
+}
+
+func setContainsBuildID(sets *client.WorkerBuildIDVersionSets, buildID string) bool {
+	if sets == nil {
+		return false
+	}
+	for _, set := range sets.Sets {
+		for _, id := range set.BuildIDs {
+			if id == buildID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// versioningError wraps the error with ErrVersioningUnsupported if it is the server rejecting
+// versioning APIs, which it does when they are not implemented or disabled for the namespace.
+func versioningError(err error) error {
+	var unimplemented *serviceerror.Unimplemented
+	var permissionDenied *serviceerror.PermissionDenied
+	var failedPrecondition *serviceerror.FailedPrecondition
+	if errors.As(err, &unimplemented) || errors.As(err, &permissionDenied) || errors.As(err, &failedPrecondition) {
+		return fmt.Errorf("%w: %w", ErrVersioningUnsupported, err)
+	}
+	return err
+}
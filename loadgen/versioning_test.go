@@ -0,0 +1,191 @@
+package loadgen
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+)
+
+// versioningClient keeps build ID version sets in memory.
+type versioningClient struct {
+	startRecordingClient
+	sets    client.WorkerBuildIDVersionSets
+	updates []interface{}
+	err     error
+}
+
+func (v *versioningClient) GetWorkerBuildIdCompatibility(
+	ctx context.Context,
+	options *client.GetWorkerBuildIdCompatibilityOptions,
+) (*client.WorkerBuildIDVersionSets, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	return &v.sets, nil
+}
+
+func (v *versioningClient) UpdateWorkerBuildIdCompatibility(
+	ctx context.Context,
+	options *client.UpdateWorkerBuildIdCompatibilityOptions,
+) error {
+	v.updates = append(v.updates, options.Operation)
+	switch op := options.Operation.(type) {
+	case *client.BuildIDOpAddNewIDInNewDefaultSet:
+		appendVersionSet(&v.sets, op.BuildID)
+	case *client.BuildIDOpPromoteSet:
+		// The default set is the last
+		for i, set := range v.sets.Sets {
+			if containsString(set.BuildIDs, op.BuildID) {
+				v.sets.Sets = append(append(v.sets.Sets[:i:i], v.sets.Sets[i+1:]...), set)
+				break
+			}
+		}
+	case *client.BuildIDOpPromoteIDWithinSet:
+		// The default build ID of a set is its last
+		for _, set := range v.sets.Sets {
+			for i, id := range set.BuildIDs {
+				if id == op.BuildID {
+					set.BuildIDs = append(append(set.BuildIDs[:i:i], set.BuildIDs[i+1:]...), id)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// appendVersionSet appends a new default set with the given build IDs. The set type is not exported
+// by the SDK so it is created via reflection.
+func appendVersionSet(sets *client.WorkerBuildIDVersionSets, buildIDs ...string) {
+	setsValue := reflect.ValueOf(&sets.Sets).Elem()
+	set := reflect.New(setsValue.Type().Elem().Elem())
+	set.Elem().FieldByName("BuildIDs").Set(reflect.ValueOf(buildIDs))
+	setsValue.Set(reflect.Append(setsValue, set))
+}
+
+func TestStartWorkflowOptionsTargetBuildID(t *testing.T) {
+	c := &versioningClient{}
+	info := &ScenarioInfo{
+		ScenarioName:    "versioning",
+		RunID:           "run",
+		Logger:          zap.NewNop().Sugar(),
+		Client:          c,
+		ScenarioOptions: map[string]string{"build-id": "v2"},
+	}
+	run := info.NewRun(1)
+	options := run.StartWorkflowOptions(WithStartDelay(0))
+	require.NoError(t, run.ExecuteAnyWorkflow(context.Background(), options, "wf", nil))
+	require.Len(t, c.started(), 1)
+	require.Equal(t, "v2", c.started()[0].Memo[BuildIDMemoKey])
+
+	// Explicit option overrides and keeps other memo values
+	options = run.StartWorkflowOptions(func(o *client.StartWorkflowOptions) {
+		o.Memo = map[string]interface{}{"other": "value"}
+	}, WithTargetBuildID("v3"))
+	require.Equal(t, map[string]interface{}{"other": "value", BuildIDMemoKey: "v3"}, options.Memo)
+}
+
+func TestPinBuildID(t *testing.T) {
+	c := &versioningClient{}
+	info := &ScenarioInfo{ScenarioName: "versioning", RunID: "run", Logger: zap.NewNop().Sugar(), Client: c}
+
+	// New build ID is added as default
+	_, err := info.PinBuildID(context.Background(), "v1")
+	require.NoError(t, err)
+	require.Len(t, c.updates, 1)
+	require.Equal(t, "v1", c.sets.Default())
+
+	// Already default is a no-op
+	_, err = info.PinBuildID(context.Background(), "v1")
+	require.NoError(t, err)
+	require.Len(t, c.updates, 1)
+
+	// Known non-default build ID is promoted
+	appendVersionSet(&c.sets, "v2")
+	_, err = info.PinBuildID(context.Background(), "v1")
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		&client.BuildIDOpAddNewIDInNewDefaultSet{BuildID: "v1"},
+		&client.BuildIDOpPromoteSet{BuildID: "v1"},
+		&client.BuildIDOpPromoteIDWithinSet{BuildID: "v1"},
+	}, c.updates)
+}
+
+func TestPinBuildIDUnsupported(t *testing.T) {
+	c := &versioningClient{err: serviceerror.NewPermissionDenied("Worker versioning is disabled", "")}
+	info := ScenarioInfo{
+		ScenarioName:    "versioning",
+		RunID:           "run",
+		Logger:          zap.NewNop().Sugar(),
+		Client:          c,
+		MetricsHandler:  client.MetricsNopHandler,
+		ScenarioOptions: map[string]string{"build-id": "v1"},
+	}
+	executor := &GenericExecutor{
+		Execute:              func(ctx context.Context, run *Run) error { return nil },
+		DefaultConfiguration: RunConfiguration{Iterations: 1},
+	}
+	err := executor.Run(context.Background(), info)
+	require.ErrorIs(t, err, ErrVersioningUnsupported)
+}
+
+func TestPinBuildIDRevert(t *testing.T) {
+	c := &versioningClient{}
+	info := &ScenarioInfo{ScenarioName: "versioning", RunID: "run", Logger: zap.NewNop().Sugar(), Client: c}
+
+	// Without a previous default, there is nothing to revert to
+	revert, err := info.PinBuildID(context.Background(), "v1")
+	require.NoError(t, err)
+	require.NoError(t, revert(context.Background()))
+	require.Equal(t, "v1", c.sets.Default())
+	require.Len(t, c.updates, 1)
+
+	// The previous default is promoted back
+	revert, err = info.PinBuildID(context.Background(), "v2")
+	require.NoError(t, err)
+	require.Equal(t, "v2", c.sets.Default())
+	require.NoError(t, revert(context.Background()))
+	require.Equal(t, "v1", c.sets.Default())
+	require.Equal(t, []interface{}{
+		&client.BuildIDOpAddNewIDInNewDefaultSet{BuildID: "v1"},
+		&client.BuildIDOpAddNewIDInNewDefaultSet{BuildID: "v2"},
+		&client.BuildIDOpPromoteSet{BuildID: "v1"},
+		&client.BuildIDOpPromoteIDWithinSet{BuildID: "v1"},
+	}, c.updates)
+
+	// Pinning the current default reverts nothing
+	revert, err = info.PinBuildID(context.Background(), "v1")
+	require.NoError(t, err)
+	require.NoError(t, revert(context.Background()))
+	require.Len(t, c.updates, 4)
+}
+
+func TestRunRevertsPinnedBuildID(t *testing.T) {
+	c := &versioningClient{}
+	appendVersionSet(&c.sets, "v1")
+	info := ScenarioInfo{
+		ScenarioName:    "versioning",
+		RunID:           "run",
+		Logger:          zap.NewNop().Sugar(),
+		Client:          c,
+		MetricsHandler:  client.MetricsNopHandler,
+		ScenarioOptions: map[string]string{BuildIDOption: "v2"},
+	}
+	var defaults []string
+	executor := &GenericExecutor{
+		Execute: func(ctx context.Context, run *Run) error {
+			defaults = append(defaults, c.sets.Default())
+			return nil
+		},
+		DefaultConfiguration: RunConfiguration{Iterations: 2, MaxConcurrent: 1},
+	}
+	require.NoError(t, executor.Run(context.Background(), info))
+	// Load ran on the pinned build ID, and the previous default was restored after
+	require.Equal(t, []string{"v2", "v2"}, defaults)
+	require.Equal(t, "v1", c.sets.Default())
+}
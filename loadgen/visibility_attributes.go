@@ -0,0 +1,197 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/operatorservice/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+)
+
+// ErrAttributesNotPropagated is returned (wrapped) when a workflow's search attributes or memo do
+// not show in visibility within the timeout.
+var ErrAttributesNotPropagated = errors.New("search attributes not propagated to visibility")
+
+// SearchAttributeType returns the indexed value type of a search attribute holding the value: a
+// string is a Keyword, an integer an Int, a float64 a Double, a bool a Bool and a time.Time a
+// Datetime.
+func SearchAttributeType(value interface{}) (enums.IndexedValueType, error) {
+	switch value.(type) {
+	case string:
+		return enums.INDEXED_VALUE_TYPE_KEYWORD, nil
+	case int, int32, int64:
+		return enums.INDEXED_VALUE_TYPE_INT, nil
+	case float64:
+		return enums.INDEXED_VALUE_TYPE_DOUBLE, nil
+	case bool:
+		return enums.INDEXED_VALUE_TYPE_BOOL, nil
+	case time.Time:
+		return enums.INDEXED_VALUE_TYPE_DATETIME, nil
+	default:
+		return 0, fmt.Errorf("unsupported search attribute value type %T", value)
+	}
+}
+
+// CheckSearchAttributesRegistered fails unless each of the search attributes is registered in the
+// namespace with the type of its value (see SearchAttributeType), listing the commands registering
+// those missing.
+func (s *ScenarioInfo) CheckSearchAttributesRegistered(ctx context.Context, attributes map[string]interface{}) error {
+	resp, err := s.Client.OperatorService().ListSearchAttributes(ctx,
+		&operatorservice.ListSearchAttributesRequest{Namespace: s.Namespace})
+	if err != nil {
+		return fmt.Errorf("failed listing search attributes: %w", err)
+	}
+	var missing []string
+	for _, name := range sortedKeys(attributes) {
+		valueType, err := SearchAttributeType(attributes[name])
+		if err != nil {
+			return fmt.Errorf("search attribute %v: %w", name, err)
+		}
+		if registered, ok := resp.GetCustomAttributes()[name]; ok && registered != valueType {
+			return fmt.Errorf("search attribute %v is registered as %v, not %v", name, registered, valueType)
+		} else if !ok && resp.GetSystemAttributes()[name] != valueType {
+			missing = append(missing, fmt.Sprintf("temporal operator search-attribute create --namespace %v --name %v --type %v",
+				s.Namespace, name, valueType))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%v search attribute(s) not registered, register them with: %v",
+			len(missing), strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+// StartWithVisibleAttributes starts the workflow, with the search attributes and memo of the
+// options, then waits for them to show in visibility with AwaitVisibleAttributes. Returns the time
+// from the start until they were visible.
+func (r *Run) StartWithVisibleAttributes(
+	ctx context.Context,
+	options client.StartWorkflowOptions,
+	timeout time.Duration,
+	workflow interface{},
+	args ...interface{},
+) (time.Duration, error) {
+	start := time.Now()
+	execution, err := r.Client.ExecuteWorkflow(ctx, options, workflow, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start workflow: %w", err)
+	}
+	return r.AwaitVisibleAttributes(ctx, execution.GetID(), start, options.SearchAttributes, options.Memo, timeout)
+}
+
+// AwaitVisibleAttributes polls visibility until the workflow is found by a query on each of the
+// search attributes, i.e. they are indexed and searchable, then checks its memo has the given
+// fields. Returns the time since the given start, also recorded in the
+// omes_search_attribute_propagation_latency timer. If the workflow is not found within the
+// timeout, an error wrapping ErrAttributesNotPropagated is returned and the
+// omes_search_attribute_propagation_timeout counter incremented. Search attributes must be
+// registered, see CheckSearchAttributesRegistered.
+func (r *Run) AwaitVisibleAttributes(
+	ctx context.Context,
+	workflowID string,
+	start time.Time,
+	searchAttributes map[string]interface{},
+	memo map[string]interface{},
+	timeout time.Duration,
+) (time.Duration, error) {
+	query, err := searchAttributesQuery(workflowID, searchAttributes)
+	if err != nil {
+		return 0, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := r.Client.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Namespace: r.Namespace,
+			PageSize:  1,
+			Query:     query,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list workflow %v in visibility: %w", workflowID, err)
+		}
+		if len(resp.Executions) > 0 {
+			propagation := time.Since(start)
+			if err := checkMemo(workflowID, resp.Executions[0].GetMemo().GetFields(), memo); err != nil {
+				return 0, err
+			}
+			r.RecordTimer("omes_search_attribute_propagation_latency", nil, propagation)
+			return propagation, nil
+		}
+		if time.Now().Add(visibilityPropagationPollInterval).After(deadline) {
+			r.RecordCounter("omes_search_attribute_propagation_timeout", nil, 1)
+			return 0, fmt.Errorf("workflow %v not found by its search attributes after %v: %w",
+				workflowID, time.Since(start), ErrAttributesNotPropagated)
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(visibilityPropagationPollInterval):
+		}
+	}
+}
+
+// searchAttributesQuery returns the visibility query matching the workflow by ID and each search
+// attribute's value.
+func searchAttributesQuery(workflowID string, searchAttributes map[string]interface{}) (string, error) {
+	clauses := []string{fmt.Sprintf("WorkflowId = %q", workflowID)}
+	for _, name := range sortedKeys(searchAttributes) {
+		var literal string
+		switch v := searchAttributes[name].(type) {
+		case string:
+			literal = strconv.Quote(v)
+		case int:
+			literal = strconv.Itoa(v)
+		case int32:
+			literal = strconv.FormatInt(int64(v), 10)
+		case int64:
+			literal = strconv.FormatInt(v, 10)
+		case float64:
+			literal = strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			literal = strconv.FormatBool(v)
+		case time.Time:
+			literal = strconv.Quote(v.UTC().Format(time.RFC3339Nano))
+		default:
+			return "", fmt.Errorf("search attribute %v has unsupported value type %T", name, v)
+		}
+		clauses = append(clauses, fmt.Sprintf("%v = %v", name, literal))
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// checkMemo fails unless the memo fields of the workflow in visibility have the expected values,
+// compared by their encoding with the default data converter.
+func checkMemo(workflowID string, fields map[string]*common.Payload, expected map[string]interface{}) error {
+	for _, name := range sortedKeys(expected) {
+		want, err := converter.GetDefaultDataConverter().ToPayload(expected[name])
+		if err != nil {
+			return fmt.Errorf("failed encoding memo field %v: %w", name, err)
+		}
+		got, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("workflow %v in visibility lacks memo field %v", workflowID, name)
+		} else if !bytes.Equal(got.GetData(), want.GetData()) {
+			return fmt.Errorf("memo field %v of workflow %v in visibility is %s, expected %s",
+				name, workflowID, got.GetData(), want.GetData())
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/operatorservice/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// attributeVisibilityClient returns a fake client whose visibility returns the workflows matching the
+// expected query, with the given memo, once the delay has passed since they started.
+func attributeVisibilityClient(t *testing.T, delay time.Duration, expectedQuery string, memo map[string]interface{}) *FakeClient {
+	var started time.Time
+	fields := map[string]*common.Payload{}
+	for name, value := range memo {
+		payload, err := converter.GetDefaultDataConverter().ToPayload(value)
+		require.NoError(t, err)
+		fields[name] = payload
+	}
+	fake := &FakeClient{
+		OnListWorkflow: func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (
+			*workflowservice.ListWorkflowExecutionsResponse, error) {
+			if request.Query != expectedQuery || time.Since(started) < delay {
+				return &workflowservice.ListWorkflowExecutionsResponse{}, nil
+			}
+			return &workflowservice.ListWorkflowExecutionsResponse{Executions: []*workflow.WorkflowExecutionInfo{{
+				Memo: &common.Memo{Fields: fields},
+			}}}, nil
+		},
+	}
+	started = time.Now()
+	return fake
+}
+
+func TestStartWithVisibleAttributes(t *testing.T) {
+	prev := visibilityPropagationPollInterval
+	visibilityPropagationPollInterval = time.Millisecond
+	t.Cleanup(func() { visibilityPropagationPollInterval = prev })
+
+	fake := attributeVisibilityClient(t, 20*time.Millisecond,
+		`WorkflowId = "w-test-run-1" AND CustomBool = true AND CustomDouble = 1.5 AND CustomInt = 42 AND `+
+			`CustomKeyword = "omes" AND CustomTime = "2024-01-02T03:04:05Z"`,
+		map[string]interface{}{"iteration": 1})
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	run := info.NewRun(1)
+	options := run.StartWorkflowOptions()
+	options.SearchAttributes = map[string]interface{}{
+		"CustomKeyword": "omes",
+		"CustomInt":     42,
+		"CustomDouble":  1.5,
+		"CustomBool":    true,
+		"CustomTime":    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	options.Memo = map[string]interface{}{"iteration": 1}
+	propagation, err := run.StartWithVisibleAttributes(context.Background(), options, time.Second, "wf")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, propagation, 20*time.Millisecond)
+	require.Len(t, fake.Calls("ExecuteWorkflow"), 1)
+	require.Len(t, *handler.recorded, 1)
+	require.Equal(t, "omes_search_attribute_propagation_latency", (*handler.recorded)[0].name)
+}
+
+func TestAwaitVisibleAttributesTimeout(t *testing.T) {
+	prev := visibilityPropagationPollInterval
+	visibilityPropagationPollInterval = time.Millisecond
+	t.Cleanup(func() { visibilityPropagationPollInterval = prev })
+
+	fake := attributeVisibilityClient(t, time.Hour, `WorkflowId = "w" AND CustomKeyword = "omes"`, nil)
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	_, err := info.NewRun(1).AwaitVisibleAttributes(context.Background(), "w", time.Now(),
+		map[string]interface{}{"CustomKeyword": "omes"}, nil, 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrAttributesNotPropagated)
+	require.Equal(t, "omes_search_attribute_propagation_timeout", (*handler.recorded)[0].name)
+}
+
+func TestAwaitVisibleAttributesMemoMismatch(t *testing.T) {
+	fake := attributeVisibilityClient(t, 0, `WorkflowId = "w"`, map[string]interface{}{"iteration": 2})
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	_, err := info.NewRun(1).AwaitVisibleAttributes(context.Background(), "w", time.Now(), nil,
+		map[string]interface{}{"iteration": 1}, time.Second)
+	require.EqualError(t, err, "memo field iteration of workflow w in visibility is 2, expected 1")
+	_, err = info.NewRun(1).AwaitVisibleAttributes(context.Background(), "w", time.Now(), nil,
+		map[string]interface{}{"other": 1}, time.Second)
+	require.EqualError(t, err, "workflow w in visibility lacks memo field other")
+}
+
+func TestCheckSearchAttributesRegistered(t *testing.T) {
+	fake := &FakeClient{
+		OnListSearchAttributes: func(ctx context.Context, request *operatorservice.ListSearchAttributesRequest) (
+			*operatorservice.ListSearchAttributesResponse, error) {
+			return &operatorservice.ListSearchAttributesResponse{
+				CustomAttributes: map[string]enums.IndexedValueType{
+					"CustomKeyword": enums.INDEXED_VALUE_TYPE_KEYWORD,
+					"CustomInt":     enums.INDEXED_VALUE_TYPE_INT,
+				},
+				SystemAttributes: map[string]enums.IndexedValueType{
+					"WorkflowType": enums.INDEXED_VALUE_TYPE_KEYWORD,
+				},
+			}, nil
+		},
+	}
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	require.NoError(t, info.CheckSearchAttributesRegistered(context.Background(),
+		map[string]interface{}{"CustomKeyword": "a", "CustomInt": int64(1), "WorkflowType": "wf"}))
+
+	err := info.CheckSearchAttributesRegistered(context.Background(),
+		map[string]interface{}{"CustomKeyword": "a", "CustomBool": true, "CustomTime": time.Now()})
+	require.EqualError(t, err, "2 search attribute(s) not registered, register them with: "+
+		"temporal operator search-attribute create --namespace default --name CustomBool --type Bool; "+
+		"temporal operator search-attribute create --namespace default --name CustomTime --type Datetime")
+
+	err = info.CheckSearchAttributesRegistered(context.Background(), map[string]interface{}{"CustomInt": "1"})
+	require.EqualError(t, err, "search attribute CustomInt is registered as Int, not Keyword")
+	err = info.CheckSearchAttributesRegistered(context.Background(), map[string]interface{}{"CustomList": []string{}})
+	require.EqualError(t, err, "search attribute CustomList: unsupported search attribute value type []string")
+
+	fake.OnListSearchAttributes = func(ctx context.Context, request *operatorservice.ListSearchAttributesRequest) (
+		*operatorservice.ListSearchAttributesResponse, error) {
+		return nil, errors.New("unavailable")
+	}
+	err = info.CheckSearchAttributesRegistered(context.Background(), map[string]interface{}{"CustomInt": 1})
+	require.EqualError(t, err, "failed listing search attributes: unavailable")
+}
@@ -0,0 +1,85 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// visibilityPropagationPollInterval is the interval between visibility queries when measuring
+// visibility propagation, short enough for the measurement to be meaningful.
+var visibilityPropagationPollInterval = 100 * time.Millisecond
+
+// ErrVisibilityNotPropagated is returned when a workflow's completion does not show in visibility
+// within the timeout.
+var ErrVisibilityNotPropagated = errors.New("workflow completion not propagated to visibility")
+
+// AwaitVisibleCompletion polls visibility until the given workflow run shows as closed, and returns
+// the time since it completed at the given time, also recorded in the
+// omes_visibility_propagation_latency timer. If it does not show within the timeout, an error
+// wrapping ErrVisibilityNotPropagated is returned and the omes_visibility_propagation_timeout
+// counter incremented.
+func (r *Run) AwaitVisibleCompletion(
+	ctx context.Context,
+	workflowID, runID string,
+	completed time.Time,
+	timeout time.Duration,
+) (time.Duration, error) {
+	deadline := time.Now().Add(timeout)
+	query := fmt.Sprintf("WorkflowId = %q AND RunId = %q AND ExecutionStatus != \"Running\"", workflowID, runID)
+	for {
+		resp, err := r.Client.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Namespace: r.Namespace,
+			PageSize:  1,
+			Query:     query,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list workflow %v in visibility: %w", workflowID, err)
+		}
+		if len(resp.Executions) > 0 {
+			propagation := time.Since(completed)
+			r.RecordTimer("omes_visibility_propagation_latency", nil, propagation)
+			return propagation, nil
+		}
+		if time.Now().Add(visibilityPropagationPollInterval).After(deadline) {
+			r.RecordCounter("omes_visibility_propagation_timeout", nil, 1)
+			return 0, fmt.Errorf("workflow %v not closed in visibility after %v: %w",
+				workflowID, time.Since(completed), ErrVisibilityNotPropagated)
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(visibilityPropagationPollInterval):
+		}
+	}
+}
+
+// ExecuteAnyWorkflowUntilVisible executes the workflow like ExecuteAnyWorkflow, then waits for its
+// completion to show in visibility with AwaitVisibleCompletion. Returns the latency from the start
+// until the completion was visible, also recorded in the omes_visible_completion_latency timer.
+func (r *Run) ExecuteAnyWorkflowUntilVisible(
+	ctx context.Context,
+	options client.StartWorkflowOptions,
+	workflow interface{},
+	visibilityTimeout time.Duration,
+	args ...interface{},
+) (time.Duration, error) {
+	start := time.Now()
+	execution, err := r.Client.ExecuteWorkflow(ctx, options, workflow, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start workflow: %w", err)
+	}
+	if err := r.getWorkflowResult(ctx, execution, nil); err != nil {
+		return 0, err
+	}
+	if _, err := r.AwaitVisibleCompletion(ctx, execution.GetID(), execution.GetRunID(), time.Now(), visibilityTimeout); err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	r.RecordTimer("omes_visible_completion_latency", nil, latency)
+	return latency, nil
+}
@@ -0,0 +1,74 @@
+package loadgen
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// delayedVisibilityClient shows a workflow as closed in visibility only after the given number of
+// queries.
+func delayedVisibilityClient(queries *[]string, closedAfter int) *FakeClient {
+	var count int32
+	return &FakeClient{
+		OnListWorkflow: func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (
+			*workflowservice.ListWorkflowExecutionsResponse, error) {
+			*queries = append(*queries, request.Query)
+			if int(atomic.AddInt32(&count, 1)) <= closedAfter {
+				return &workflowservice.ListWorkflowExecutionsResponse{}, nil
+			}
+			return &workflowservice.ListWorkflowExecutionsResponse{
+				Executions: []*workflow.WorkflowExecutionInfo{{}},
+			}, nil
+		},
+	}
+}
+
+func useFastPropagationPolling(t *testing.T) {
+	prev := visibilityPropagationPollInterval
+	visibilityPropagationPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { visibilityPropagationPollInterval = prev })
+}
+
+func TestExecuteAnyWorkflowUntilVisible(t *testing.T) {
+	useFastPropagationPolling(t)
+	var queries []string
+	fake := delayedVisibilityClient(&queries, 3)
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(fake, RunConfiguration{})
+	info.MetricsHandler = handler
+	run := info.NewRun(1)
+
+	latency, err := run.ExecuteAnyWorkflowUntilVisible(context.Background(), run.StartWorkflowOptions(), "noop", time.Second)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, latency, 30*time.Millisecond)
+	require.Len(t, queries, 4)
+	require.Equal(t, `WorkflowId = "w-test-run-1" AND RunId = "run-w-test-run-1" AND ExecutionStatus != "Running"`, queries[0])
+
+	recorded := *handler.recorded
+	require.Len(t, recorded, 2)
+	require.Equal(t, "omes_visibility_propagation_latency", recorded[0].name)
+	require.GreaterOrEqual(t, recorded[0].value, 0.03)
+	require.Equal(t, "omes_visible_completion_latency", recorded[1].name)
+	require.Equal(t, latency.Seconds(), recorded[1].value)
+}
+
+func TestAwaitVisibleCompletionTimeout(t *testing.T) {
+	useFastPropagationPolling(t)
+	var queries []string
+	handler := newRecordingMetricsHandler()
+	info := NewTestScenarioInfo(delayedVisibilityClient(&queries, 1000), RunConfiguration{})
+	info.MetricsHandler = handler
+
+	_, err := info.NewRun(1).AwaitVisibleCompletion(context.Background(), "w", "r", time.Now(), 50*time.Millisecond)
+	require.ErrorIs(t, err, ErrVisibilityNotPropagated)
+	require.NotEmpty(t, queries)
+	require.Equal(t, []recordedMetric{{
+		kind: "counter", name: "omes_visibility_propagation_timeout", tags: map[string]string{"scenario": "test"}, value: 1,
+	}}, *handler.recorded)
+}
@@ -0,0 +1,249 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+// DefaultWorkerMetricsInterval is the default RunConfiguration.WorkerMetricsInterval.
+const DefaultWorkerMetricsInterval = 10 * time.Second
+
+// workerMetricsScrapeTimeout bounds each scrape of a worker metrics endpoint.
+var workerMetricsScrapeTimeout = 5 * time.Second
+
+// Metrics of the Temporal SDKs summarized from worker metrics endpoints. The poll latency histogram
+// is in seconds, and only its series with a Poll* operation label are polls.
+const (
+	workerTaskSlotsUsedMetric      = "temporal_worker_task_slots_used"
+	workerTaskSlotsAvailableMetric = "temporal_worker_task_slots_available"
+	workerPollLatencyMetric        = "temporal_long_request_latency"
+)
+
+// WorkerMetricsSummary summarizes the scrapes of a worker metrics endpoint over a run, see
+// RunConfiguration.WorkerMetricsURL.
+type WorkerMetricsSummary struct {
+	URL     string `json:"url"`
+	Scrapes int    `json:"scrapes"`
+	// Scrapes that failed, e.g. because the endpoint was unreachable.
+	FailedScrapes int `json:"failedScrapes"`
+	// Task slots in use and available, summed across worker types, if the workers report them.
+	TaskSlotsUsed      *WorkerMetricStat `json:"taskSlotsUsed,omitempty"`
+	TaskSlotsAvailable *WorkerMetricStat `json:"taskSlotsAvailable,omitempty"`
+	// Mean latency of the task queue polls completed between the first and last successful scrapes.
+	// Zero if none did.
+	MeanPollLatency time.Duration `json:"meanPollLatency,omitempty"`
+}
+
+// WorkerMetricStat is the minimum, maximum and final value of a scraped worker metric.
+type WorkerMetricStat struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Final float64 `json:"final"`
+}
+
+func addWorkerMetricStat(stat **WorkerMetricStat, value float64) {
+	if *stat == nil {
+		*stat = &WorkerMetricStat{Min: value, Max: value}
+	}
+	if value < (*stat).Min {
+		(*stat).Min = value
+	}
+	if value > (*stat).Max {
+		(*stat).Max = value
+	}
+	(*stat).Final = value
+}
+
+// workerMetricsScraper periodically scrapes a Prometheus metrics endpoint of the workers. An
+// unreachable endpoint is logged once and counted, without failing the run. It is safe for
+// concurrent use.
+type workerMetricsScraper struct {
+	info *ScenarioInfo
+	url  string
+
+	lock    sync.Mutex
+	summary WorkerMetricsSummary
+	// Poll latency histogram sum and count of the first and last successful scrapes.
+	firstPolls, lastPolls *pollLatencyTotals
+	// Whether a failed scrape was logged.
+	warned  bool
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+type pollLatencyTotals struct {
+	seconds float64
+	count   float64
+}
+
+// startWorkerMetricsScraper scrapes now and then every interval until stopped.
+func startWorkerMetricsScraper(ctx context.Context, info *ScenarioInfo, url string, interval time.Duration) *workerMetricsScraper {
+	s := &workerMetricsScraper{
+		info:    info,
+		url:     url,
+		summary: WorkerMetricsSummary{URL: url},
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		defer close(s.stopped)
+		s.scrape(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.scrape(ctx)
+			}
+		}
+	}()
+	return s
+}
+
+func (s *workerMetricsScraper) scrape(ctx context.Context) {
+	scrapeCtx, cancel := context.WithTimeout(ctx, workerMetricsScrapeTimeout)
+	defer cancel()
+	samples, err := scrapePrometheusMetrics(scrapeCtx, s.url)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err != nil && ctx.Err() != nil {
+		// Interrupted by the end of the run, Stop scrapes a final time
+		return
+	} else if err != nil {
+		s.summary.FailedScrapes++
+		if !s.warned {
+			s.info.Logger.Warnf("Failed scraping worker metrics, continuing the run without them: %v", err)
+			s.warned = true
+		}
+		return
+	}
+	s.summary.Scrapes++
+	var slotsUsed, slotsAvailable float64
+	var hasSlotsUsed, hasSlotsAvailable bool
+	polls := &pollLatencyTotals{}
+	for _, sample := range samples {
+		switch sample.name {
+		case workerTaskSlotsUsedMetric:
+			slotsUsed, hasSlotsUsed = slotsUsed+sample.value, true
+		case workerTaskSlotsAvailableMetric:
+			slotsAvailable, hasSlotsAvailable = slotsAvailable+sample.value, true
+		case workerPollLatencyMetric + "_sum":
+			if strings.HasPrefix(sample.labels["operation"], "Poll") {
+				polls.seconds += sample.value
+			}
+		case workerPollLatencyMetric + "_count":
+			if strings.HasPrefix(sample.labels["operation"], "Poll") {
+				polls.count += sample.value
+			}
+		}
+	}
+	if hasSlotsUsed {
+		addWorkerMetricStat(&s.summary.TaskSlotsUsed, slotsUsed)
+	}
+	if hasSlotsAvailable {
+		addWorkerMetricStat(&s.summary.TaskSlotsAvailable, slotsAvailable)
+	}
+	if s.firstPolls == nil {
+		s.firstPolls = polls
+	}
+	s.lastPolls = polls
+}
+
+// Stop stops scraping, scrapes a final time and returns the summary.
+func (s *workerMetricsScraper) Stop() WorkerMetricsSummary {
+	close(s.stop)
+	<-s.stopped
+	s.scrape(context.Background())
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	summary := s.summary
+	if s.firstPolls != nil {
+		if count := s.lastPolls.count - s.firstPolls.count; count > 0 {
+			summary.MeanPollLatency = time.Duration((s.lastPolls.seconds - s.firstPolls.seconds) / count * float64(time.Second))
+		}
+	}
+	return summary
+}
+
+// prometheusSample is a sample of the Prometheus text exposition format.
+type prometheusSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// scrapePrometheusMetrics gets the samples of a Prometheus metrics endpoint.
+func scrapePrometheusMetrics(ctx context.Context, url string) ([]prometheusSample, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid worker metrics URL %v: %w", url, err)
+	}
+	req.Header.Set("Accept", "text/plain")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed scraping %v: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scraping %v returned status %v", url, resp.Status)
+	}
+	samples, err := parsePrometheusText(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing metrics of %v: %w", url, err)
+	}
+	return samples, nil
+}
+
+// parsePrometheusText parses the samples of the Prometheus text exposition format, sorted by metric
+// name. Histograms and summaries are flattened into their _sum and _count samples.
+func parsePrometheusText(r io.Reader) ([]prometheusSample, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var samples []prometheusSample
+	for _, name := range names {
+		for _, metric := range families[name].GetMetric() {
+			labels := make(map[string]string, len(metric.GetLabel()))
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			sample := func(name string, value float64) {
+				samples = append(samples, prometheusSample{name: name, labels: labels, value: value})
+			}
+			switch {
+			case metric.Gauge != nil:
+				sample(name, metric.GetGauge().GetValue())
+			case metric.Counter != nil:
+				sample(name, metric.GetCounter().GetValue())
+			case metric.Untyped != nil:
+				sample(name, metric.GetUntyped().GetValue())
+			case metric.Histogram != nil:
+				sample(name+"_sum", metric.GetHistogram().GetSampleSum())
+				sample(name+"_count", float64(metric.GetHistogram().GetSampleCount()))
+			case metric.Summary != nil:
+				sample(name+"_sum", metric.GetSummary().GetSampleSum())
+				sample(name+"_count", float64(metric.GetSummary().GetSampleCount()))
+			}
+		}
+	}
+	return samples, nil
+}
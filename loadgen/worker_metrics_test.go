@@ -0,0 +1,107 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// workerMetricsServer serves worker metrics whose task slots used and poll count grow with each
+// scrape, returning the number of scrapes so far.
+func workerMetricsServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var scrapes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := scrapes.Add(1)
+		fmt.Fprintf(w, `# HELP temporal_worker_task_slots_used Task slots used
+# TYPE temporal_worker_task_slots_used gauge
+temporal_worker_task_slots_used{worker_type="WorkflowWorker"} %d
+temporal_worker_task_slots_used{worker_type="ActivityWorker"} 1
+temporal_worker_task_slots_available{worker_type="WorkflowWorker"} %d
+temporal_long_request_latency_sum{operation="PollWorkflowTaskQueue",namespace="default"} %v
+temporal_long_request_latency_count{operation="PollWorkflowTaskQueue",namespace="default"} %d
+temporal_long_request_latency_sum{operation="GetWorkflowExecutionHistory"} 100
+temporal_long_request_latency_count{operation="GetWorkflowExecutionHistory"} 1
+`, n, 10-n, 0.2*float64(n), 10*n)
+	}))
+	t.Cleanup(server.Close)
+	return server, &scrapes
+}
+
+func TestWorkerMetricsScraper(t *testing.T) {
+	server, scrapes := workerMetricsServer(t)
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	scraper := startWorkerMetricsScraper(context.Background(), &info, server.URL, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return scrapes.Load() >= 3 }, time.Second, time.Millisecond)
+	summary := scraper.Stop()
+
+	n := float64(scrapes.Load())
+	require.Equal(t, server.URL, summary.URL)
+	require.Equal(t, int(n), summary.Scrapes)
+	require.Zero(t, summary.FailedScrapes)
+	require.Equal(t, &WorkerMetricStat{Min: 2, Max: n + 1, Final: n + 1}, summary.TaskSlotsUsed)
+	require.Equal(t, &WorkerMetricStat{Min: 10 - n, Max: 9, Final: 10 - n}, summary.TaskSlotsAvailable)
+	// Every poll between scrapes took 20ms, other long requests are not polls
+	require.InDelta(t, 20*time.Millisecond, summary.MeanPollLatency, float64(time.Microsecond))
+}
+
+func TestWorkerMetricsScraperUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	summary := startWorkerMetricsScraper(context.Background(), &info, url, time.Hour).Stop()
+	require.Equal(t, WorkerMetricsSummary{URL: url, FailedScrapes: 2}, summary)
+
+	// Non-OK statuses fail scrapes too
+	server = httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	summary = startWorkerMetricsScraper(context.Background(), &info, server.URL, time.Hour).Stop()
+	require.Equal(t, WorkerMetricsSummary{URL: server.URL, FailedScrapes: 2}, summary)
+}
+
+func TestWorkerMetricsInReport(t *testing.T) {
+	server, _ := workerMetricsServer(t)
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 2, WorkerMetricsURL: server.URL})
+	var result *RunResult
+	info.ReportSinks = []ReportSink{reportSinkFunc(func(r *RunResult) { result = r })}
+	executor := &GenericExecutor{Execute: func(ctx context.Context, run *Run) error { return nil }}
+	require.NoError(t, executor.Run(context.Background(), info))
+	require.NotNil(t, result.WorkerMetrics)
+	require.Equal(t, server.URL, result.WorkerMetrics.URL)
+	require.GreaterOrEqual(t, result.WorkerMetrics.Scrapes, 1)
+	require.Zero(t, result.WorkerMetrics.FailedScrapes)
+	require.NotNil(t, result.WorkerMetrics.TaskSlotsUsed)
+}
+
+func TestParsePrometheusText(t *testing.T) {
+	samples, err := parsePrometheusText(strings.NewReader(`# TYPE a counter
+a 1
+b{x="1",y="q\"uo}te\\"} 2.5 1700000000000
+
+c{} +Inf
+# TYPE d histogram
+d_bucket{op="x",le="+Inf"} 4
+d_sum{op="x"} 0.5
+d_count{op="x"} 4
+`))
+	require.NoError(t, err)
+	require.Len(t, samples, 5)
+	require.Equal(t, prometheusSample{name: "a", labels: map[string]string{}, value: 1}, samples[0])
+	require.Equal(t, prometheusSample{name: "b", labels: map[string]string{"x": "1", "y": `q"uo}te\`}, value: 2.5},
+		samples[1])
+	require.Equal(t, "c", samples[2].name)
+	require.Contains(t, samples, prometheusSample{name: "d_sum", labels: map[string]string{"op": "x"}, value: 0.5})
+	require.Contains(t, samples, prometheusSample{name: "d_count", labels: map[string]string{"op": "x"}, value: 4})
+
+	_, err = parsePrometheusText(strings.NewReader("a{x=\"1\" 1\n"))
+	require.Error(t, err)
+	_, err = parsePrometheusText(strings.NewReader("a one\n"))
+	require.Error(t, err)
+}
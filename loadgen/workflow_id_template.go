@@ -0,0 +1,201 @@
+package loadgen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// WorkflowIDTemplateOption is the scenario option replacing the "<IDPrefix>-<RunID>-<Iteration>"
+// workflow IDs of DefaultStartWorkflowOptions with a Go template rendered with
+// WorkflowIDTemplateData, e.g. "orders/{{.RunID}}/{{.Shard}}/{{.Iteration}}". The template must
+// render distinct IDs for distinct iterations, and start with a fixed part including the run ID,
+// which becomes the WorkflowIDPrefix that cleanup, visibility queries and other IDs of the run use.
+const WorkflowIDTemplateOption = "workflow-id-template"
+
+// WorkflowIDShardsOption is the integer scenario option setting the number of shards of
+// WorkflowIDTemplateData.Shard. Default is 1.
+const WorkflowIDShardsOption = "workflow-id-shards"
+
+// WorkflowIDTemplateData are the variables of a WorkflowIDTemplateOption template.
+type WorkflowIDTemplateData struct {
+	RunID     string
+	Iteration int
+	// (Iteration - 1) modulo the WorkflowIDShardsOption scenario option.
+	Shard int
+	// Unix milliseconds when the ID is rendered.
+	Timestamp int64
+}
+
+// workflowIDTemplateCheckRunID is the run ID templates are rendered with by
+// ParseWorkflowIDTemplate to check their prefix includes the run ID.
+const workflowIDTemplateCheckRunID = "omes-workflow-id-template-check"
+
+// workflowIDTemplateSamples are values templates are rendered with to find their fixed prefix,
+// varying every variable and the first digit of each number.
+var workflowIDTemplateSamples = []WorkflowIDTemplateData{
+	{Iteration: 1, Shard: 0, Timestamp: 1000000000000},
+	{Iteration: 2, Shard: 1, Timestamp: 2111111111111},
+	{Iteration: 10, Shard: 7, Timestamp: 9999999999999},
+	{Iteration: 987654321, Shard: 35, Timestamp: 3456789012345},
+}
+
+var parsedWorkflowIDTemplates sync.Map
+
+// ParseWorkflowIDTemplate parses a WorkflowIDTemplateOption template, failing unless it renders
+// distinct IDs for distinct iterations and its fixed prefix includes the run ID.
+func ParseWorkflowIDTemplate(text string) (*template.Template, error) {
+	if parsed, ok := parsedWorkflowIDTemplates.Load(text); ok {
+		return parsed.(*template.Template), nil
+	}
+	tmpl, err := template.New("workflow-id").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow ID template: %w", err)
+	}
+	first, err := renderWorkflowID(tmpl, WorkflowIDTemplateData{RunID: workflowIDTemplateCheckRunID, Iteration: 1})
+	if err != nil {
+		return nil, err
+	}
+	second, err := renderWorkflowID(tmpl, WorkflowIDTemplateData{RunID: workflowIDTemplateCheckRunID, Iteration: 2})
+	if err != nil {
+		return nil, err
+	} else if first == second {
+		return nil, fmt.Errorf("workflow ID template %q must include {{.Iteration}} for IDs to be unique", text)
+	}
+	prefix, err := workflowIDTemplatePrefix(tmpl, workflowIDTemplateCheckRunID)
+	if err != nil {
+		return nil, err
+	} else if !strings.Contains(prefix, workflowIDTemplateCheckRunID) {
+		return nil, fmt.Errorf("workflow ID template %q must start with a fixed part including {{.RunID}}, "+
+			"for IDs to be unique across runs and found by prefix", text)
+	}
+	parsedWorkflowIDTemplates.Store(text, tmpl)
+	return tmpl, nil
+}
+
+func renderWorkflowID(tmpl *template.Template, data WorkflowIDTemplateData) (string, error) {
+	var id strings.Builder
+	if err := tmpl.Execute(&id, data); err != nil {
+		return "", fmt.Errorf("failed rendering workflow ID template: %w", err)
+	} else if id.Len() == 0 {
+		return "", errors.New("workflow ID template renders an empty ID")
+	}
+	return id.String(), nil
+}
+
+// workflowIDTemplatePrefix returns the prefix shared by all IDs the template renders for the run.
+func workflowIDTemplatePrefix(tmpl *template.Template, runID string) (string, error) {
+	var prefix string
+	for i, sample := range workflowIDTemplateSamples {
+		sample.RunID = runID
+		id, err := renderWorkflowID(tmpl, sample)
+		if err != nil {
+			return "", err
+		}
+		if i == 0 {
+			prefix = id
+		}
+		for !strings.HasPrefix(id, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix, nil
+}
+
+// WorkflowIDTemplate returns the parsed WorkflowIDTemplateOption template of the run, nil if not
+// set.
+func (s *ScenarioInfo) WorkflowIDTemplate() (*template.Template, error) {
+	text := s.ScenarioOptions[WorkflowIDTemplateOption]
+	if text == "" {
+		return nil, nil
+	}
+	if s.ScenarioOptionInt(WorkflowIDShardsOption, 1) <= 0 {
+		return nil, fmt.Errorf("%v must be positive", WorkflowIDShardsOption)
+	}
+	return ParseWorkflowIDTemplate(text)
+}
+
+// WorkflowIDTemplateError is what DefaultStartWorkflowOptions panics with when the run's
+// WorkflowIDTemplateOption template, although valid, fails to render the iteration's workflow ID.
+// GenericExecutor fails the iteration with it instead, if the panic is in the goroutine of
+// GenericExecutor.Execute or Start.
+type WorkflowIDTemplateError struct {
+	Iteration int
+	Err       error
+}
+
+func (e *WorkflowIDTemplateError) Error() string {
+	return fmt.Sprintf("workflow ID of iteration %v: %v", e.Iteration, e.Err)
+}
+
+func (e *WorkflowIDTemplateError) Unwrap() error {
+	return e.Err
+}
+
+// catchWorkflowIDTemplateError calls the function, returning the *WorkflowIDTemplateError it panics
+// with as error. Other panics are passed on.
+func catchWorkflowIDTemplateError(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			idErr, ok := r.(*WorkflowIDTemplateError)
+			if !ok {
+				panic(r)
+			}
+			err = idErr
+		}
+	}()
+	return f()
+}
+
+// templatedWorkflowID renders the iteration's workflow ID with the run's WorkflowIDTemplateOption
+// template, returning false if there is none. Panics if the template is invalid, which
+// ValidateStartOptions reports before the run, or with a *WorkflowIDTemplateError if it fails to
+// render for the iteration.
+func (r *Run) templatedWorkflowID() (string, bool) {
+	tmpl, err := r.WorkflowIDTemplate()
+	if err != nil {
+		panic(fmt.Sprintf("invalid workflow ID template, see ValidateStartOptions: %v", err))
+	} else if tmpl == nil {
+		return "", false
+	}
+	shards := r.ScenarioOptionInt(WorkflowIDShardsOption, 1)
+	id, err := renderWorkflowID(tmpl, WorkflowIDTemplateData{
+		RunID:     r.RunID,
+		Iteration: r.Iteration,
+		Shard:     ((r.Iteration-1)%shards + shards) % shards,
+		Timestamp: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		panic(&WorkflowIDTemplateError{Iteration: r.Iteration, Err: err})
+	}
+	return id, true
+}
+
+// workflowIDTemplatePrefixes caches the prefixes of templatedWorkflowIDPrefix per template and run
+// ID.
+var workflowIDTemplatePrefixes sync.Map
+
+// templatedWorkflowIDPrefix returns the fixed prefix of the run's WorkflowIDTemplateOption
+// template, returning false if there is none. Panics if the template is invalid, which
+// ValidateStartOptions reports before the run.
+func (s *ScenarioInfo) templatedWorkflowIDPrefix() (string, bool) {
+	tmpl, err := s.WorkflowIDTemplate()
+	if err != nil {
+		panic(fmt.Sprintf("invalid workflow ID template, see ValidateStartOptions: %v", err))
+	} else if tmpl == nil {
+		return "", false
+	}
+	key := [2]string{s.ScenarioOptions[WorkflowIDTemplateOption], s.RunID}
+	if prefix, ok := workflowIDTemplatePrefixes.Load(key); ok {
+		return prefix.(string), true
+	}
+	prefix, err := workflowIDTemplatePrefix(tmpl, s.RunID)
+	if err != nil {
+		panic(fmt.Sprintf("invalid workflow ID template, see ValidateStartOptions: %v", err))
+	}
+	workflowIDTemplatePrefixes.Store(key, prefix)
+	return prefix, true
+}
@@ -0,0 +1,105 @@
+package loadgen
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowIDTemplate(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	info.ScenarioOptions = map[string]string{
+		WorkflowIDTemplateOption: "orders/{{.RunID}}/shard-{{.Shard}}/{{.Iteration}}",
+		WorkflowIDShardsOption:   "4",
+	}
+	require.Equal(t, "orders/test-run/shard-0/1", info.NewRun(1).DefaultStartWorkflowOptions().ID)
+	require.Equal(t, "orders/test-run/shard-1/6", info.NewRun(6).DefaultStartWorkflowOptions().ID)
+	require.Equal(t, "orders/test-run/shard-3/12", info.NewRun(12).DefaultStartWorkflowOptions().ID)
+	require.Equal(t, "orders/test-run/shard-", info.WorkflowIDPrefix())
+	require.Equal(t, `WorkflowId STARTS_WITH "orders/test-run/shard-"`, info.RunVisibilityQuery())
+
+	// Timestamps are Unix milliseconds
+	info.ScenarioOptions = map[string]string{WorkflowIDTemplateOption: "{{.RunID}}-{{.Iteration}}-{{.Timestamp}}"}
+	before := time.Now().UnixMilli()
+	id := info.NewRun(3).DefaultStartWorkflowOptions().ID
+	require.Regexp(t, `^test-run-3-\d+$`, id)
+	timestamp, err := strconv.ParseInt(id[len("test-run-3-"):], 10, 64)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, timestamp, before)
+	require.Equal(t, "test-run-", info.WorkflowIDPrefix())
+
+	// Without a template, IDs are unchanged
+	info.ScenarioOptions = nil
+	require.Equal(t, "w-test-run-1", info.NewRun(1).DefaultStartWorkflowOptions().ID)
+	require.Equal(t, "w-test-run-", info.WorkflowIDPrefix())
+}
+
+func TestParseWorkflowIDTemplateRequiresUniqueness(t *testing.T) {
+	for text, expected := range map[string]string{
+		"{{.RunID}}/{{.Shard}}/{{.Iteration}}":        "",
+		"x-{{.RunID}}-{{printf \"%05d\" .Iteration}}": "",
+		"{{.RunID}}-{{.Timestamp}}":                   "must include {{.Iteration}}",
+		"{{.RunID}}-{{.Shard}}":                       "must include {{.Iteration}}",
+		"{{.Iteration}}-{{.RunID}}":                   "must start with a fixed part including {{.RunID}}",
+		"w-{{.Iteration}}":                            "must start with a fixed part including {{.RunID}}",
+		"{{.RunID}}-{{.Iteration":                     "invalid workflow ID template",
+		"{{.RunID}}-{{.Missing}}-{{.Iteration}}":      "failed rendering workflow ID template",
+	} {
+		_, err := ParseWorkflowIDTemplate(text)
+		if expected == "" {
+			require.NoError(t, err, text)
+		} else {
+			require.ErrorContains(t, err, expected, text)
+		}
+	}
+}
+
+func TestGenericExecutorValidatesWorkflowIDTemplate(t *testing.T) {
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{Iterations: 1})
+	info.ScenarioOptions = map[string]string{WorkflowIDTemplateOption: "{{.RunID}}"}
+	err := (&GenericExecutor{Execute: func(context.Context, *Run) error { return nil }}).Run(context.Background(), info)
+	require.ErrorContains(t, err, "invalid scenario: workflow ID template")
+
+	info.ScenarioOptions = map[string]string{WorkflowIDTemplateOption: "{{.RunID}}-{{.Iteration}}", WorkflowIDShardsOption: "0"}
+	err = (&GenericExecutor{Execute: func(context.Context, *Run) error { return nil }}).Run(context.Background(), info)
+	require.ErrorContains(t, err, "workflow-id-shards must be positive")
+}
+
+func TestWorkflowIDTemplateErrorsSurface(t *testing.T) {
+	// Invalid templates fail validation, which all executors get via ValidateStartOptions, instead of
+	// falling back to default IDs
+	info := NewTestScenarioInfo(&FakeClient{}, RunConfiguration{})
+	info.ScenarioOptions = map[string]string{WorkflowIDTemplateOption: "{{.RunID}}"}
+	require.ErrorContains(t, info.ValidateStartOptions(), "must include {{.Iteration}}")
+	require.Panics(t, func() { info.NewRun(1).DefaultStartWorkflowOptions() })
+	require.Panics(t, func() { info.WorkflowIDPrefix() })
+
+	// Templates valid for the checked iterations but failing to render for another panic with the
+	// error
+	info.ScenarioOptions = map[string]string{
+		WorkflowIDTemplateOption: "{{.RunID}}-{{.Iteration}}{{if eq .Iteration 5}}{{index .RunID 100}}{{end}}",
+	}
+	require.NoError(t, info.ValidateStartOptions())
+	require.Equal(t, "test-run-4", info.NewRun(4).DefaultStartWorkflowOptions().ID)
+	var panicked any
+	func() {
+		defer func() { panicked = recover() }()
+		info.NewRun(5).DefaultStartWorkflowOptions()
+	}()
+	require.IsType(t, &WorkflowIDTemplateError{}, panicked)
+	require.ErrorContains(t, panicked.(error), "workflow ID of iteration 5: failed rendering workflow ID template")
+	require.ErrorContains(t, panicked.(error), "index out of range: 100")
+
+	// Which fails the iteration of a GenericExecutor run instead of the process
+	info.Configuration = RunConfiguration{Iterations: 6, MaxConcurrent: 1}
+	err := (&GenericExecutor{Execute: func(ctx context.Context, run *Run) error {
+		_ = run.DefaultStartWorkflowOptions()
+		return nil
+	}}).Run(context.Background(), info)
+	var idErr *WorkflowIDTemplateError
+	require.ErrorAs(t, err, &idErr)
+	require.Equal(t, 5, idErr.Iteration)
+}
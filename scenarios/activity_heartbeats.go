@@ -0,0 +1,26 @@
+package scenarios
+
+import (
+	"context"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration executes a workflow running an activity that heartbeats every interval for a " +
+			"duration, then completes, verifying heartbeats reach the server. Heartbeat timeouts are counted in " +
+			"omes_activity_heartbeat_timeout. Requires the Go worker. The SDK sends heartbeats at most every 80% of the " +
+			"heartbeat timeout. Additional options: heartbeat-interval (default 1s), heartbeat-duration (default 10s), " +
+			"heartbeat-timeout (default twice the interval).",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				interval := run.ScenarioOptionDuration("heartbeat-interval", time.Second)
+				duration := run.ScenarioOptionDuration("heartbeat-duration", 10*time.Second)
+				timeout := run.ScenarioOptionDuration("heartbeat-timeout", 2*interval)
+				return run.ExecuteHeartbeatWorkflow(ctx, interval, duration, timeout)
+			},
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package scenarios
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+)
+
+func TestActivityHeartbeats(t *testing.T) {
+	var inputs []*kitchensink.WorkflowInput
+	heartbeat := time.Now()
+	fake := &loadgen.FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			inputs = append(inputs, args[0].(*kitchensink.WorkflowInput))
+			return &loadgen.FakeWorkflowRun{ID: options.ID, Delay: 100 * time.Millisecond}, nil
+		},
+		OnDescribeWorkflowExecution: func(ctx context.Context, workflowID, runID string) (
+			*workflowservice.DescribeWorkflowExecutionResponse, error) {
+			return &workflowservice.DescribeWorkflowExecutionResponse{
+				PendingActivities: []*workflow.PendingActivityInfo{{LastHeartbeatTime: &heartbeat}},
+			}, nil
+		},
+	}
+	info := loadgen.NewTestScenarioInfo(fake, loadgen.RunConfiguration{Iterations: 2, MaxConcurrent: 1})
+	info.ScenarioOptions = map[string]string{"heartbeat-interval": "20ms", "heartbeat-duration": "80ms"}
+
+	start := time.Now()
+	require.NoError(t, loadgen.GetScenario("activity_heartbeats").Executor.Run(context.Background(), info))
+	// Each iteration waits for its workflow to complete
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.NotEmpty(t, fake.Calls("DescribeWorkflowExecution"))
+
+	require.Len(t, inputs, 2)
+	activity := inputs[0].InitialActions[0].Actions[0].GetExecActivity()
+	require.Equal(t, kitchensink.HeartbeatActivityType, activity.GetGeneric().GetType())
+	require.Equal(t, 40*time.Millisecond, activity.GetHeartbeatTimeout().AsDuration())
+	require.Equal(t, int32(1), activity.GetRetryPolicy().GetMaximumAttempts())
+	var input kitchensink.HeartbeatActivityInput
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayload(activity.GetGeneric().GetArguments()[0], &input))
+	require.Equal(t, kitchensink.HeartbeatActivityInput{Interval: 20 * time.Millisecond, Duration: 80 * time.Millisecond}, input)
+	require.NotNil(t, inputs[0].InitialActions[0].Actions[1].GetReturnResult())
+}
+
+func TestActivityHeartbeatsNotRecorded(t *testing.T) {
+	fake := &loadgen.FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			return &loadgen.FakeWorkflowRun{ID: options.ID, Delay: 50 * time.Millisecond}, nil
+		},
+	}
+	info := loadgen.NewTestScenarioInfo(fake, loadgen.RunConfiguration{Iterations: 1})
+	info.ScenarioOptions = map[string]string{"heartbeat-interval": "10ms", "heartbeat-duration": "40ms"}
+	err := loadgen.GetScenario("activity_heartbeats").Executor.Run(context.Background(), info)
+	require.ErrorContains(t, err, "no heartbeat of the activity of workflow w-test-run-1 was recorded")
+}
@@ -0,0 +1,24 @@
+package scenarios
+
+import (
+	"context"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration executes a workflow running an activity that fails activity-failures times " +
+			"(default 3) before succeeding, retried every activity-retry-interval (default 100ms), then verifies " +
+			"from history that it was retried that many times. Retries are counted in omes_activity_retries. " +
+			"Requires the Go worker.",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				failures := run.ScenarioOptionInt("activity-failures", 3)
+				interval := run.ScenarioOptionDuration("activity-retry-interval", 100*time.Millisecond)
+				return run.ExecuteActivityRetryWorkflow(ctx, failures, interval)
+			},
+		},
+	})
+}
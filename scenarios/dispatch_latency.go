@@ -0,0 +1,21 @@
+package scenarios
+
+import (
+	"context"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration executes a kitchen sink workflow that completes in its first workflow task " +
+			"and records the time from start to first workflow task completion from history in the " +
+			"omes_dispatch_latency metric, isolating dispatch latency from execution.",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				_, err := run.ExecuteDispatchLatencyWorkflow(ctx)
+				return err
+			},
+		},
+	})
+}
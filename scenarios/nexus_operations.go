@@ -0,0 +1,38 @@
+package scenarios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration invokes the nexus-operation operation of the nexus-service service " +
+			"operations times (default 1) in sequence, waiting for asynchronous operations to complete. " +
+			"Requires --nexus-endpoint.",
+		Executor: loadgen.ExecutorFunc(func(ctx context.Context, info loadgen.ScenarioInfo) error {
+			if info.NexusClient == nil {
+				return errors.New("nexus_operations requires --nexus-endpoint")
+			}
+			service, operation := info.ScenarioOptions["nexus-service"], info.ScenarioOptions["nexus-operation"]
+			if service == "" || operation == "" {
+				return errors.New("nexus_operations requires the nexus-service and nexus-operation options")
+			}
+			executor := &loadgen.GenericExecutor{
+				Execute: func(ctx context.Context, run *loadgen.Run) error {
+					for i := 0; i < run.ScenarioOptionInt("operations", 1); i++ {
+						input := fmt.Sprintf("%v-%v", run.DefaultStartWorkflowOptions().ID, i)
+						if _, err := run.ExecuteNexusOperation(ctx, service, operation, input, nil); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			}
+			return executor.Run(ctx, info)
+		}),
+	})
+}
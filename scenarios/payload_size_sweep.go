@@ -0,0 +1,14 @@
+package scenarios
+
+import (
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Starts kitchen sink workflows with increasingly large arguments until starts are rejected " +
+			"for their size, then bisects to log the largest size accepted, also recorded in the " +
+			"omes_payload_size_largest_succeeded metric. Iteration and duration options are ignored.",
+		Executor: loadgen.PayloadSizeSweep{},
+	})
+}
@@ -0,0 +1,13 @@
+package scenarios
+
+import "github.com/temporalio/omes/loadgen"
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Re-executes the workflows of a prior run found in visibility, one per iteration, from " +
+			"their recorded start parameters. Only starts are re-issued, see loadgen.RerunExecutor for " +
+			"limitations. Workers must serve the workflows of the prior run. Additional options: " +
+			"source-run-id (required).",
+		Executor: loadgen.RerunExecutor{},
+	})
+}
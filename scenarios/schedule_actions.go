@@ -0,0 +1,63 @@
+package scenarios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/sdk/client"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration creates a schedule of a kitchen sink workflow that completes immediately, " +
+			"triggers it, waits for it to have fired fire-count times, then pauses and deletes it. Schedules " +
+			"left behind by failed iterations are deleted at the end of the run. Additional options: " +
+			"schedule-interval (default 10s), fire-count (default 1), fire-timeout (default 1m).",
+		Executor: loadgen.ExecutorFunc(func(ctx context.Context, info loadgen.ScenarioInfo) error {
+			var schedules loadgen.Schedules
+			executor := &loadgen.GenericExecutor{
+				Execute: func(ctx context.Context, run *loadgen.Run) error {
+					return executeScheduleIteration(ctx, run, &schedules)
+				},
+			}
+			err := executor.Run(ctx, info)
+			return errors.Join(err, schedules.Cleanup(ctx, &info))
+		}),
+	})
+}
+
+func executeScheduleIteration(ctx context.Context, run *loadgen.Run, schedules *loadgen.Schedules) error {
+	id := schedules.ScheduleID(run.ScenarioInfo, strconv.Itoa(run.Iteration))
+	_, err := schedules.Create(ctx, run, client.ScheduleOptions{
+		ID: id,
+		Spec: client.ScheduleSpec{
+			Intervals: []client.ScheduleIntervalSpec{{Every: run.ScenarioOptionDuration("schedule-interval", 10*time.Second)}},
+		},
+		Action: &client.ScheduleWorkflowAction{
+			Workflow: "kitchenSink",
+			Args: []interface{}{&kitchensink.WorkflowInput{
+				InitialActions: []*kitchensink.ActionSet{kitchensink.EmptyResultActionSet()},
+			}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := schedules.Trigger(ctx, run, id); err != nil {
+		return err
+	}
+	fireCtx, cancel := context.WithTimeout(ctx, run.ScenarioOptionDuration("fire-timeout", time.Minute))
+	defer cancel()
+	if err := schedules.WaitForActions(fireCtx, run, id, run.ScenarioOptionInt("fire-count", 1)); err != nil {
+		return fmt.Errorf("schedule did not fire: %w", err)
+	}
+	if err := schedules.Pause(ctx, run, id, "omes iteration done"); err != nil {
+		return err
+	}
+	return schedules.Delete(ctx, run, id)
+}
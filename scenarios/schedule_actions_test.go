@@ -0,0 +1,19 @@
+package scenarios
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen"
+)
+
+func TestScheduleActions(t *testing.T) {
+	fake := &loadgen.FakeClient{}
+	info := loadgen.NewTestScenarioInfo(fake, loadgen.RunConfiguration{Iterations: 3})
+	require.NoError(t, loadgen.GetScenario("schedule_actions").Executor.Run(context.Background(), info))
+	require.Len(t, fake.Calls("CreateSchedule"), 3)
+	require.Len(t, fake.Calls("TriggerSchedule"), 3)
+	require.Len(t, fake.Calls("PauseSchedule"), 3)
+	require.Len(t, fake.Calls("DeleteSchedule"), 3)
+}
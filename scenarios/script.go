@@ -0,0 +1,13 @@
+package scenarios
+
+import (
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration runs the steps of a declarative JSON script of workflow starts, signals, " +
+			"queries, sleeps and awaits, see loadgen.Script. Options: script-file (required).",
+		Executor: loadgen.ScriptExecutor{},
+	})
+}
@@ -0,0 +1,29 @@
+package scenarios
+
+import (
+	"context"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration starts a set of workflows waiting on a timer, then cancels them all " +
+			"concurrently, waiting for each to close as canceled. Cancellation request latency is recorded in " +
+			"the omes_cancel_ack_latency metric and latency until canceled in omes_cancel_to_canceled_latency. " +
+			"Workflows completing before their cancellation lands are not failures. Additional options: " +
+			"cancel-workflows (default 10), cancel-rate (cancellations per second, default no limit), " +
+			"cancel-concurrency (default 10), cancel-timeout (default 1m).",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				_, err := run.CancellationStorm(ctx, loadgen.CancellationStorm{
+					Workflows:   run.ScenarioOptionInt("cancel-workflows", 10),
+					Rate:        float64(run.ScenarioOptionInt("cancel-rate", 0)),
+					Concurrency: run.ScenarioOptionInt("cancel-concurrency", loadgen.DefaultCancellationStormConcurrency),
+					Timeout:     run.ScenarioOptionDuration("cancel-timeout", loadgen.DefaultCancellationStormTimeout),
+				})
+				return err
+			},
+		},
+	})
+}
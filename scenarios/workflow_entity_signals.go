@@ -0,0 +1,51 @@
+package scenarios
+
+import (
+	"context"
+	"strings"
+
+	"github.com/temporalio/omes/loadgen"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration signals one of a fixed set of long-lived kitchen sink entity workflows to run a " +
+			"noop activity, starting the entities on first use. Signal latency is recorded in the " +
+			"omes_entity_signal_latency metric. Additional options: entity-count (default 10), entity-ids " +
+			"(comma-separated IDs of running entities to attach to instead). Started entities are terminated at " +
+			"the end of the run.",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				entities := entitiesOf(run.ScenarioInfo)
+				return entities.Signal(ctx, run, "do_actions_signal", &kitchensink.DoSignal_DoSignalActions{
+					Variant: &kitchensink.DoSignal_DoSignalActions_DoActions{
+						DoActions: &kitchensink.ActionSet{
+							Actions: []*kitchensink.Action{{
+								Variant: &kitchensink.Action_ExecActivity{
+									ExecActivity: &kitchensink.ExecuteActivityAction{
+										ActivityType:        &kitchensink.ExecuteActivityAction_Noop{},
+										StartToCloseTimeout: &durationpb.Duration{Seconds: 5},
+									},
+								},
+							}},
+						},
+					},
+				})
+			},
+			Teardown: func(ctx context.Context, info *loadgen.ScenarioInfo) error {
+				entities := entitiesOf(info)
+				return entities.Terminate(ctx, info)
+			},
+		},
+	})
+}
+
+func entitiesOf(info *loadgen.ScenarioInfo) loadgen.Entities {
+	entities := loadgen.Entities{Count: info.ScenarioOptionInt("entity-count", 10)}
+	if ids := info.ScenarioOptions["entity-ids"]; ids != "" {
+		entities.IDs = strings.Split(ids, ",")
+	}
+	return entities
+}
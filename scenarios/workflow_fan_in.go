@@ -0,0 +1,23 @@
+package scenarios
+
+import (
+	"context"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration executes a workflow starting many children that each signal a result back to " +
+			"it, then waits for all results and completes, verifying from its history that every child's result " +
+			"arrived. Latency is recorded in the omes_fan_in_latency metric. Requires the Go worker. Additional " +
+			"options: fan-in-children (default 100), fan-in-result-size (bytes, default 256).",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				return run.ExecuteFanInWorkflow(ctx,
+					run.ScenarioOptionInt("fan-in-children", 100),
+					run.ScenarioOptionInt("fan-in-result-size", 256))
+			},
+		},
+	})
+}
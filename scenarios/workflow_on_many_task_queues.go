@@ -3,6 +3,7 @@ package scenarios
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/temporalio/omes/loadgen"
 	"github.com/temporalio/omes/loadgen/kitchensink"
@@ -12,7 +13,11 @@ func init() {
 	loadgen.MustRegisterScenario(loadgen.Scenario{
 		Description: "Each iteration executes a single workflow on one of the task queues. " +
 			"Workers must be started with --task-queue-suffix-index-end as one less than task queue count here. " +
-			"Additional options: task-queue-count (required).",
+			"Additional options: task-queue-count (required unless task-queue-weights is set), " +
+			"task-queue-weights (comma-separated relative weights per task queue, e.g. 80,20, instead of " +
+			"round-robin; iterations are assigned reproducibly from the seed), " +
+			"task-queue-hash (true to assign by hashing the workflow ID instead of round-robin, to simulate " +
+			"real key distributions reproducibly).",
 		Executor: loadgen.KitchenSinkExecutor{
 			TestInput: &kitchensink.TestInput{
 				WorkflowInput: &kitchensink.WorkflowInput{
@@ -22,16 +27,33 @@ func init() {
 				},
 			},
 			PrepareTestInput: func(ctx context.Context, opts loadgen.ScenarioInfo, params *kitchensink.TestInput) error {
+				if weights := opts.ScenarioOptions["task-queue-weights"]; weights != "" {
+					_, err := loadgen.ParseTaskQueueWeights(weights)
+					return err
+				}
 				// Require task queue count
-				if opts.ScenarioOptionInt("task-queue-count", 0) == 0 {
+				if opts.ScenarioOptionInt(loadgen.TaskQueueCountOption, 0) == 0 {
 					return fmt.Errorf("task-queue-count option required")
 				}
 				return nil
 			},
 			UpdateWorkflowOptions: func(ctx context.Context, run *loadgen.Run, options *loadgen.KitchenSinkWorkflowOptions) error {
+				if weights := run.ScenarioOptions["task-queue-weights"]; weights != "" {
+					parsed, err := loadgen.ParseTaskQueueWeights(weights)
+					if err != nil {
+						return err
+					}
+					options.StartOptions.TaskQueue = run.WeightedTaskQueue(parsed)
+					return nil
+				}
+				count := run.ScenarioInfo.ScenarioOptionInt(loadgen.TaskQueueCountOption, 0)
+				if hashed, _ := strconv.ParseBool(run.ScenarioOptions["task-queue-hash"]); hashed {
+					loadgen.WithHashedTaskQueue(count)(&options.StartOptions)
+					return nil
+				}
 				// Add suffix to the task queue based on modulus of iteration
 				options.StartOptions.TaskQueue +=
-					fmt.Sprintf("-%v", run.Iteration%run.ScenarioInfo.ScenarioOptionInt("task-queue-count", 0))
+					fmt.Sprintf("-%v", run.Iteration%count)
 				return nil
 			},
 		},
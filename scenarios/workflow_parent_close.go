@@ -0,0 +1,29 @@
+package scenarios
+
+import (
+	"context"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration executes a workflow starting children under a parent close policy, terminates " +
+			"it once the children started, then verifies the children were terminated, canceled or abandoned as " +
+			"the policy requires. The time until they were is recorded in the omes_parent_close_latency metric. " +
+			"Requires the Go or Java worker. Additional options: parent-close-policy (terminate, abandon or " +
+			"request-cancel, default terminate), parent-close-children (default 10), parent-close-timeout " +
+			"(default 1m).",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				policy, err := run.ParentClosePolicy()
+				if err != nil {
+					return err
+				}
+				return run.ExecuteParentCloseWorkflow(ctx, run.ScenarioOptionInt("parent-close-children", 10), policy,
+					run.ScenarioOptionDuration("parent-close-timeout", time.Minute))
+			},
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package scenarios
+
+import (
+	"context"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Starts a fixed pool of long-lived kitchen sink workflows once, then each iteration queries one " +
+			"of them, benchmarking the read path without new starts. Query latency is recorded in the " +
+			"omes_query_latency metric. Additional options: query-target-count (default 5). The pool is " +
+			"terminated at the end of the run.",
+		Executor: loadgen.ExecutorFunc(func(ctx context.Context, info loadgen.ScenarioInfo) error {
+			// Per run, the pool's workflow IDs are the run's
+			targets := &loadgen.QueryTargets{Count: info.ScenarioOptionInt("query-target-count", 5)}
+			executor := &loadgen.GenericExecutor{
+				Setup:    targets.Start,
+				Execute:  func(ctx context.Context, run *loadgen.Run) error { return targets.Query(ctx, run, nil) },
+				Teardown: targets.Terminate,
+			}
+			return executor.Run(ctx, info)
+		}),
+	})
+}
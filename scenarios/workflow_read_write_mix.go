@@ -0,0 +1,30 @@
+package scenarios
+
+import (
+	"context"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	mix := &loadgen.ReadWriteMix{}
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Starts a pool of long-lived kitchen sink workflows once, then each iteration either queries " +
+			"(reads) or signals (writes) one of them, chosen reproducibly from the seed, replacing workflows " +
+			"of the pool that closed. Latencies are recorded in the omes_read_write_mix_read_latency and " +
+			"omes_read_write_mix_write_latency metrics. Additional options: read-write-pool-size (default 10), " +
+			"read-weight (default 1), write-weight (default 1).",
+		Executor: &loadgen.GenericExecutor{
+			Setup: func(ctx context.Context, info *loadgen.ScenarioInfo) error {
+				mix.PoolSize = info.ScenarioOptionInt("read-write-pool-size", 10)
+				mix.ReadWeight = float64(info.ScenarioOptionInt("read-weight", 1))
+				mix.WriteWeight = float64(info.ScenarioOptionInt("write-weight", 1))
+				return mix.Start(ctx, info)
+			},
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				_, err := mix.Execute(ctx, run)
+				return err
+			},
+		},
+	})
+}
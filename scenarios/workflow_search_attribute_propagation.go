@@ -0,0 +1,36 @@
+package scenarios
+
+import (
+	"context"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration starts a workflow with the OmesRunId search attribute set to the run ID and " +
+			"its iteration in the memo, then polls visibility until the workflow is found by its search " +
+			"attribute with the memo. The delay until it was is recorded in the " +
+			"omes_search_attribute_propagation_latency metric. Requires the OmesRunId Keyword search attribute " +
+			"to be registered. Additional options: visibility-timeout (default 1m).",
+		Executor: &loadgen.GenericExecutor{
+			Setup: func(ctx context.Context, info *loadgen.ScenarioInfo) error {
+				return info.CheckSearchAttributesRegistered(ctx,
+					map[string]interface{}{loadgen.RunIDSearchAttribute: info.RunID})
+			},
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				options := run.StartWorkflowOptions()
+				options.SearchAttributes = map[string]interface{}{loadgen.RunIDSearchAttribute: run.RunID}
+				options.Memo = map[string]interface{}{"iteration": run.Iteration}
+				input := &kitchensink.WorkflowInput{
+					InitialActions: []*kitchensink.ActionSet{kitchensink.EmptyResultActionSet()},
+				}
+				_, err := run.StartWithVisibleAttributes(ctx, options,
+					run.ScenarioOptionDuration("visibility-timeout", time.Minute), "kitchenSink", input)
+				return err
+			},
+		},
+	})
+}
@@ -0,0 +1,15 @@
+package scenarios
+
+import (
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration is a session: it starts a kitchen sink workflow, updates it " +
+			"operations-per-session times (default 5) one after the other, then signals it to complete. Sessions " +
+			"are the unit of iterations and concurrency. Operation latency is recorded in the " +
+			"omes_session_operation_latency metric and summarized in the run report.",
+		Executor: &loadgen.SessionExecutor{},
+	})
+}
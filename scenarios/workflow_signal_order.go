@@ -0,0 +1,24 @@
+package scenarios
+
+import (
+	"context"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration sends a workflow a numbered sequence of signals one after the other, then " +
+			"queries the order the workflow observed them in and fails with the workflow ID if it differs. " +
+			"Violations are counted in the omes_signal_order_violations metric and the time until all signals " +
+			"were observed is recorded in the omes_signal_order_latency metric. Requires the Go worker. " +
+			"Additional options: signal-count (default 10), signal-order-timeout (default 1m).",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				return run.ExecuteSignalOrderWorkflow(ctx, run.ScenarioOptionInt("signal-count", 10),
+					run.ScenarioOptionDuration("signal-order-timeout", time.Minute))
+			},
+		},
+	})
+}
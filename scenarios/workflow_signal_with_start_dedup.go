@@ -0,0 +1,22 @@
+package scenarios
+
+import (
+	"context"
+	"errors"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration signal-with-starts the same kitchen sink workflow, concurrently up to " +
+			"max-concurrent, then the run verifies that a single workflow was created and received every signal. " +
+			"Latency is recorded in the omes_signal_with_start_latency metric. The workflow is left running.",
+		Executor: loadgen.ExecutorFunc(func(ctx context.Context, info loadgen.ScenarioInfo) error {
+			dedup := &loadgen.SignalWithStartDedup{}
+			executor := &loadgen.GenericExecutor{Execute: dedup.Signal}
+			err := executor.Run(ctx, info)
+			return errors.Join(err, dedup.Verify(ctx, &info))
+		}),
+	})
+}
@@ -0,0 +1,27 @@
+package scenarios
+
+import (
+	"context"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration runs a kitchen sink workflow with timer-count (default 1) concurrent timers " +
+			"of timer-duration (default 1s), then records how late each fired, from history timestamps, in the " +
+			"omes_timer_drift metric. The run logs the p99 drift.",
+		Executor: loadgen.ExecutorFunc(func(ctx context.Context, info loadgen.ScenarioInfo) error {
+			accuracy := &loadgen.TimerAccuracy{
+				Duration: info.ScenarioOptionDuration("timer-duration", time.Second),
+				Timers:   info.ScenarioOptionInt("timer-count", 1),
+			}
+			executor := &loadgen.GenericExecutor{Execute: accuracy.Execute}
+			err := executor.Run(ctx, info)
+			summary := accuracy.Summary()
+			info.Logger.Infof("Timer drift: p50 %v, p99 %v, max %v", summary.P50, summary.P99, summary.Max)
+			return err
+		}),
+	})
+}
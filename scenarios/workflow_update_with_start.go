@@ -0,0 +1,28 @@
+package scenarios
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/temporalio/omes/loadgen"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration sends an update to one of update-with-start-workflows (default 10) kitchen sink " +
+			"workflows, round robin, starting it if not running, and awaits the update result. Latency is recorded in " +
+			"the omes_update_with_start_latency metric, tagged by whether the workflow was created or already running. " +
+			"The workflows are left running.",
+		Executor: &loadgen.GenericExecutor{
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				workflows := run.ScenarioOptionInt("update-with-start-workflows", 10)
+				if workflows <= 0 {
+					return fmt.Errorf("update-with-start-workflows must be positive")
+				}
+				id := fmt.Sprintf("%supdate-with-start-%d", run.WorkflowIDPrefix(), (run.Iteration-1)%workflows)
+				_, err := run.UpdateWithStart(ctx, loadgen.UpdateWithStart{WorkflowID: id}, nil)
+				return err
+			},
+		},
+	})
+}
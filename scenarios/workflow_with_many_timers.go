@@ -0,0 +1,31 @@
+package scenarios
+
+import (
+	"context"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration executes a single workflow that runs a number of concurrent timers, then " +
+			"completes. Additional options: timer-count (default 10), sleep-duration (default 1s).",
+		Executor: loadgen.KitchenSinkExecutor{
+			TestInput: &kitchensink.TestInput{
+				WorkflowInput: &kitchensink.WorkflowInput{},
+			},
+			PrepareTestInput: func(ctx context.Context, opts loadgen.ScenarioInfo, params *kitchensink.TestInput) error {
+				timers := opts.ScenarioOptionInt("timer-count", 10)
+				sleep := opts.ScenarioOptionDuration("sleep-duration", time.Second)
+				opts.Logger.Infof("Preparing to run with %v concurrent timer(s) of %v", timers, sleep)
+				params.WorkflowInput.InitialActions = []*kitchensink.ActionSet{
+					kitchensink.TimersActionSet(timers, sleep),
+					kitchensink.EmptyResultActionSet(),
+				}
+				return nil
+			},
+		},
+	})
+}
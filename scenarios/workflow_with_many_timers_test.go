@@ -0,0 +1,40 @@
+package scenarios
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/omes/loadgen"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/sdk/client"
+)
+
+func TestWorkflowWithManyTimers(t *testing.T) {
+	var inputs []*kitchensink.WorkflowInput
+	fake := &loadgen.FakeClient{
+		OnExecuteWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, workflow interface{},
+			args ...interface{}) (client.WorkflowRun, error) {
+			inputs = append(inputs, args[0].(*kitchensink.WorkflowInput))
+			return &loadgen.FakeWorkflowRun{ID: options.ID, Delay: 50 * time.Millisecond}, nil
+		},
+	}
+	info := loadgen.NewTestScenarioInfo(fake, loadgen.RunConfiguration{Iterations: 2, MaxConcurrent: 1})
+	info.ScenarioOptions = map[string]string{"timer-count": "4", "sleep-duration": "250ms"}
+
+	start := time.Now()
+	require.NoError(t, loadgen.GetScenario("workflow_with_many_timers").Executor.Run(context.Background(), info))
+	// Each iteration waits for its workflow to complete
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	require.Len(t, inputs, 2)
+	actionSets := inputs[0].InitialActions
+	require.Len(t, actionSets, 2)
+	require.True(t, actionSets[0].Concurrent)
+	require.Len(t, actionSets[0].Actions, 4)
+	for _, action := range actionSets[0].Actions {
+		require.Equal(t, uint64(250), action.GetTimer().GetMilliseconds())
+	}
+	require.NotNil(t, actionSets[1].Actions[0].GetReturnResult())
+}
@@ -0,0 +1,51 @@
+package scenarios
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+	"github.com/temporalio/omes/loadgen/kitchensink"
+	"go.temporal.io/api/common/v1"
+)
+
+func init() {
+	loadgen.MustRegisterScenario(loadgen.Scenario{
+		Description: "Each iteration starts a workflow with a start delay that completes immediately, and verifies " +
+			"it did not complete before the delay elapsed. Lateness beyond the delay is recorded in the " +
+			"omes_start_delay_lateness metric. Fails before the first iteration if the server does not support " +
+			"start delay. Additional options: start-delay (default 5s).",
+		Executor: &loadgen.GenericExecutor{
+			Setup: func(ctx context.Context, info *loadgen.ScenarioInfo) error {
+				return info.CheckStartDelaySupported(ctx)
+			},
+			Execute: func(ctx context.Context, run *loadgen.Run) error {
+				delay := run.ScenarioOptionDuration("start-delay", 5*time.Second)
+				options := run.DefaultKitchenSinkWorkflowOptions()
+				options.StartOptions = run.StartWorkflowOptions(loadgen.WithStartDelay(delay))
+				options.Params = &kitchensink.TestInput{
+					WorkflowInput: &kitchensink.WorkflowInput{
+						InitialActions: []*kitchensink.ActionSet{{
+							Actions: []*kitchensink.Action{{
+								Variant: &kitchensink.Action_ReturnResult{
+									ReturnResult: &kitchensink.ReturnResultAction{ReturnThis: &common.Payload{}},
+								},
+							}},
+						}},
+					},
+				}
+				start := time.Now()
+				if err := run.ExecuteKitchenSinkWorkflow(ctx, &options); err != nil {
+					return err
+				}
+				elapsed := time.Since(start)
+				if elapsed < delay {
+					return fmt.Errorf("workflow completed after %v which is before start delay of %v", elapsed, delay)
+				}
+				run.RecordTimer("omes_start_delay_lateness", nil, elapsed-delay)
+				return nil
+			},
+		},
+	})
+}
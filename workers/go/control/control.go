@@ -0,0 +1,37 @@
+package control
+
+import (
+	"context"
+	"time"
+
+	"github.com/temporalio/omes/loadgen"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ControlWorkflow handles the control signals sent by a scenario to this worker process, then
+// completes. It runs on the control task queue of this process (see loadgen.ControlTaskQueue), and
+// handles signals by local activities, so they affect this process.
+func ControlWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithLocalActivityOptions(ctx, workflow.LocalActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+	})
+	flushCh := workflow.GetSignalChannel(ctx, loadgen.FlushStickyCacheSignal)
+	flushCh.Receive(ctx, nil)
+	for {
+		workflow.GetLogger(ctx).Info("Flushing sticky workflow cache")
+		if err := workflow.ExecuteLocalActivity(ctx, FlushStickyCache).Get(ctx, nil); err != nil {
+			return err
+		}
+		// Flushes requested meanwhile are handled before completing
+		if !flushCh.ReceiveAsync(nil) {
+			return nil
+		}
+	}
+}
+
+// FlushStickyCache purges the sticky workflow cache of this worker process.
+func FlushStickyCache(_ context.Context) error {
+	worker.PurgeStickyWorkflowCache()
+	return nil
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/temporalio/omes/loadgen/kitchensink"
 	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
@@ -22,6 +26,8 @@ func KitchenSinkWorkflow(ctx workflow.Context, params *kitchensink.WorkflowInput
 	state := KSWorkflowState{
 		workflowState: &kitchensink.WorkflowState{},
 	}
+	// Signal and update action sets being handled
+	handling := 0
 	queryErr := workflow.SetQueryHandler(ctx, "report_state",
 		func(input interface{}) (*kitchensink.WorkflowState, error) {
 			return state.workflowState, nil
@@ -32,6 +38,8 @@ func KitchenSinkWorkflow(ctx workflow.Context, params *kitchensink.WorkflowInput
 
 	updateErr := workflow.SetUpdateHandlerWithOptions(ctx, "do_actions_update",
 		func(ctx workflow.Context, actions *kitchensink.DoActionsUpdate) (rval interface{}, err error) {
+			handling++
+			defer func() { handling-- }()
 			rval, err = state.handleActionSet(ctx, actions.GetDoActions())
 			if rval == nil {
 				rval = &state.workflowState
@@ -49,6 +57,43 @@ func KitchenSinkWorkflow(ctx workflow.Context, params *kitchensink.WorkflowInput
 		return nil, updateErr
 	}
 
+	// Count the distinct children results of a fan-in were received from
+	fanInChan := workflow.GetSignalChannel(ctx, kitchensink.FanInSignalName)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		received := map[int]bool{}
+		for {
+			var payload *common.Payload
+			fanInChan.Receive(ctx, &payload)
+			result, err := kitchensink.DecodeFanInResult(payload)
+			if err != nil {
+				workflow.GetLogger(ctx).Warn("Ignoring invalid fan-in result", "error", err)
+				continue
+			}
+			received[result.Child] = true
+			if state.workflowState.Kvs == nil {
+				state.workflowState.Kvs = map[string]string{}
+			}
+			state.workflowState.Kvs[kitchensink.FanInReceivedKey] = strconv.Itoa(len(received))
+		}
+	})
+
+	// Record the order ordered signals were received in
+	orderedChan := workflow.GetSignalChannel(ctx, kitchensink.OrderedSignalName)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		for {
+			var seq int
+			orderedChan.Receive(ctx, &seq)
+			if state.workflowState.Kvs == nil {
+				state.workflowState.Kvs = map[string]string{}
+			}
+			if received := state.workflowState.Kvs[kitchensink.OrderedSignalsKey]; received != "" {
+				state.workflowState.Kvs[kitchensink.OrderedSignalsKey] = received + "," + strconv.Itoa(seq)
+			} else {
+				state.workflowState.Kvs[kitchensink.OrderedSignalsKey] = strconv.Itoa(seq)
+			}
+		}
+	})
+
 	// Handle initial set
 	if params != nil && params.InitialActions != nil {
 		for _, actionSet := range params.InitialActions {
@@ -70,20 +115,40 @@ func KitchenSinkWorkflow(ctx workflow.Context, params *kitchensink.WorkflowInput
 			if actionSet == nil {
 				actionSet = sigActions.GetDoActions()
 			}
+			handling++
 			workflow.Go(ctx, func(ctx workflow.Context) {
 				ret, err := state.handleActionSet(ctx, actionSet)
+				handling--
 				if ret != nil || err != nil {
 					retOrErrChan.Send(ctx, ReturnOrErr{ret, err})
 				}
 			})
 		}
 	})
-	for {
-		var retOrErr ReturnOrErr
-		retOrErrChan.Receive(ctx, &retOrErr)
+
+	// Long-lived workflows driven by signals, like entities, continue as new once the server
+	// suggests it, between handlers and unless state reported by queries would be lost
+	continueAsNew, settleContinueAsNew := workflow.NewFuture(ctx)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		_ = workflow.Await(ctx, func() bool {
+			return workflow.GetInfo(ctx).GetContinueAsNewSuggested() && handling == 0 &&
+				signalActionsChan.Len() == 0 && len(state.workflowState.Kvs) == 0
+		})
+		settleContinueAsNew.Set(nil, nil)
+	})
+
+	var retOrErr ReturnOrErr
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(retOrErrChan, func(c workflow.ReceiveChannel, more bool) {
+		c.Receive(ctx, &retOrErr)
 		workflow.GetLogger(ctx).Info("Finishing workflow", "retOrErr", retOrErr)
-		return retOrErr.retme, retOrErr.err
-	}
+	})
+	selector.AddFuture(continueAsNew, func(workflow.Future) {
+		workflow.GetLogger(ctx).Info("Continuing workflow as new")
+		retOrErr.err = workflow.NewContinueAsNewError(ctx, "kitchenSink", &kitchensink.WorkflowInput{})
+	})
+	selector.Select(ctx)
+	return retOrErr.retme, retOrErr.err
 }
 
 func (ws *KSWorkflowState) handleActionSet(
@@ -148,6 +213,12 @@ func (ws *KSWorkflowState) handleAction(
 		if child.WorkflowType != "" {
 			childType = child.WorkflowType
 		}
+		if child.ParentClosePolicy != kitchensink.ParentClosePolicy_PARENT_CLOSE_POLICY_UNSPECIFIED {
+			// The kitchen sink policies have the same values as the API's
+			options := workflow.GetChildWorkflowOptions(ctx)
+			options.ParentClosePolicy = enums.ParentClosePolicy(child.ParentClosePolicy)
+			ctx = workflow.WithChildOptions(ctx, options)
+		}
 		err := withAwaitableChoiceCustom(ctx, func(ctx workflow.Context) workflow.ChildWorkflowFuture {
 			return workflow.ExecuteChildWorkflow(ctx, childType, child.GetInput()[0])
 		}, child.AwaitableChoice,
@@ -159,6 +230,15 @@ func (ws *KSWorkflowState) handleAction(
 			},
 		)
 		return nil, err
+	} else if signal := action.GetSendSignal(); signal != nil {
+		// Signals take a single argument in the Go SDK
+		var arg interface{}
+		if len(signal.GetArgs()) > 0 {
+			arg = signal.GetArgs()[0]
+		}
+		return nil, withAwaitableChoice(ctx, func(ctx workflow.Context) workflow.Future {
+			return workflow.SignalExternalWorkflow(ctx, signal.WorkflowId, signal.RunId, signal.SignalName, arg)
+		}, signal.AwaitableChoice)
 	} else if patch := action.GetSetPatchMarker(); patch != nil {
 		if workflow.GetVersion(ctx, patch.GetPatchId(), workflow.DefaultVersion, 1) == 1 {
 			return ws.handleAction(ctx, patch.GetInnerAction())
@@ -201,6 +281,16 @@ func launchActivity(ctx workflow.Context, act *kitchensink.ExecuteActivityAction
 	if delay := act.GetDelay(); delay != nil {
 		actType = "delay"
 		args = append(args, delay.AsDuration())
+	} else if generic := act.GetGeneric(); generic != nil {
+		actType = generic.GetType()
+		// Decoded so the activity receives them as it would from any other caller
+		for i, payload := range generic.GetArguments() {
+			var arg interface{}
+			if err := converter.GetDefaultDataConverter().FromPayload(payload, &arg); err != nil {
+				return fmt.Errorf("failed decoding argument %v of activity %v: %w", i, actType, err)
+			}
+			args = append(args, arg)
+		}
 	}
 	if act.GetIsLocal() != nil {
 		opts := workflow.LocalActivityOptions{
@@ -304,6 +394,37 @@ func Delay(_ context.Context, delayFor time.Duration) error {
 	return nil
 }
 
+// Heartbeat heartbeats with the number of heartbeats so far every interval of the input for its
+// duration, then returns the number of heartbeats.
+func Heartbeat(ctx context.Context, input kitchensink.HeartbeatActivityInput) (int, error) {
+	ticker := time.NewTicker(input.Interval)
+	defer ticker.Stop()
+	done := time.After(input.Duration)
+	heartbeats := 0
+	for {
+		select {
+		case <-done:
+			return heartbeats, nil
+		case <-ctx.Done():
+			return heartbeats, ctx.Err()
+		case <-ticker.C:
+			heartbeats++
+			activity.RecordHeartbeat(ctx, heartbeats)
+		}
+	}
+}
+
+// FailAttempts fails the first attempts of the input with a retryable error, then returns the
+// attempt it completed on.
+func FailAttempts(ctx context.Context, input kitchensink.FailingActivityInput) (int, error) {
+	attempt := int(activity.GetInfo(ctx).Attempt)
+	if attempt <= input.Failures {
+		return 0, temporal.NewApplicationError(
+			fmt.Sprintf("failing attempt %v of %v", attempt, input.Failures), "FailAttempts")
+	}
+	return attempt, nil
+}
+
 func convertFromPBRetryPolicy(retryPolicy *common.RetryPolicy) *temporal.RetryPolicy {
 	if retryPolicy == nil {
 		return nil
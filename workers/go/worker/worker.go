@@ -2,9 +2,12 @@ package worker
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/temporalio/omes/cmd/cmdoptions"
+	"github.com/temporalio/omes/loadgen"
+	"github.com/temporalio/omes/workers/go/control"
 	"github.com/temporalio/omes/workers/go/kitchensink"
 	"github.com/temporalio/omes/workers/go/throughputstress"
 	"go.temporal.io/sdk/activity"
@@ -44,7 +47,7 @@ func (a *App) Run(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	if err := runWorkers(client, taskQueues, a.workerOptions); err != nil {
+	if err := runWorkers(client, a.taskQueue, taskQueues, a.workerOptions); err != nil {
 		a.logger.Fatalf("Fatal worker error: %v", err)
 	}
 	if err := metrics.Shutdown(cmd.Context()); err != nil {
@@ -52,8 +55,20 @@ func (a *App) Run(cmd *cobra.Command, args []string) {
 	}
 }
 
-func runWorkers(client client.Client, taskQueues []string, options cmdoptions.WorkerOptions) error {
-	errCh := make(chan error, len(taskQueues))
+func runWorkers(client client.Client, taskQueue string, taskQueues []string, options cmdoptions.WorkerOptions) error {
+	// The identity the workers of this process poll with, addressing its control workflows
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	identity := fmt.Sprintf("%d@%v", os.Getpid(), hostname)
+
+	errCh := make(chan error, len(taskQueues)+1)
+	go func() {
+		w := worker.New(client, loadgen.ControlTaskQueue(taskQueue, identity), worker.Options{Identity: identity})
+		w.RegisterWorkflowWithOptions(control.ControlWorkflow, workflow.RegisterOptions{Name: loadgen.ControlWorkflowType})
+		errCh <- w.Run(worker.InterruptCh())
+	}()
 	tpsActivities := throughputstress.Activities{
 		Client: client,
 	}
@@ -65,17 +80,24 @@ func runWorkers(client client.Client, taskQueues []string, options cmdoptions.Wo
 				MaxConcurrentWorkflowTaskExecutionSize: options.MaxConcurrentWorkflowTasks,
 				MaxConcurrentActivityTaskPollers:       options.MaxConcurrentActivityPollers,
 				MaxConcurrentWorkflowTaskPollers:       options.MaxConcurrentWorkflowPollers,
+				BuildID:                                options.BuildID,
+				UseBuildIDForVersioning:                options.BuildID != "",
+				TaskQueueActivitiesPerSecond:           options.TaskQueueActivitiesPerSecond,
+				StickyScheduleToStartTimeout:           options.StickyScheduleToStartTimeout,
+				Identity:                               identity,
 			})
 			w.RegisterWorkflowWithOptions(kitchensink.KitchenSinkWorkflow, workflow.RegisterOptions{Name: "kitchenSink"})
 			w.RegisterActivityWithOptions(kitchensink.Noop, activity.RegisterOptions{Name: "noop"})
 			w.RegisterActivityWithOptions(kitchensink.Delay, activity.RegisterOptions{Name: "delay"})
+			w.RegisterActivityWithOptions(kitchensink.Heartbeat, activity.RegisterOptions{Name: "heartbeat"})
+			w.RegisterActivityWithOptions(kitchensink.FailAttempts, activity.RegisterOptions{Name: "fail_attempts"})
 			w.RegisterWorkflowWithOptions(throughputstress.ThroughputStressWorkflow, workflow.RegisterOptions{Name: "throughputStress"})
 			w.RegisterWorkflow(throughputstress.ThroughputStressChild)
 			w.RegisterActivity(&tpsActivities)
 			errCh <- w.Run(worker.InterruptCh())
 		}()
 	}
-	for range taskQueues {
+	for i := 0; i < len(taskQueues)+1; i++ {
 		if err := <-errCh; err != nil {
 			return err
 		}
@@ -120,6 +120,11 @@ public class Main implements Runnable {
       description = "Max concurrent workflow tasks")
   private int maxConcurrentWorkflowTasks;
 
+  @CommandLine.Option(
+      names = "--task-queue-activities-per-second",
+      description = "Server-side activity dispatch rate limit per task queue")
+  private double taskQueueActivitiesPerSecond;
+
   @Override
   public void run() {
     // Configure TLS
@@ -219,6 +224,9 @@ public class Main implements Runnable {
     // Activity options
     workerOptions.setMaxConcurrentActivityTaskPollers(maxConcurrentActivityPollers);
     workerOptions.setMaxConcurrentActivityExecutionSize(maxConcurrentActivities);
+    if (taskQueueActivitiesPerSecond > 0) {
+      workerOptions.setMaxTaskQueueActivitiesPerSecond(taskQueueActivitiesPerSecond);
+    }
     // Start all workers, throwing on first exception
     for (String taskQueue : taskQueues) {
       Worker worker = workerFactory.newWorker(taskQueue, workerOptions.build());
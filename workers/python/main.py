@@ -70,6 +70,11 @@ async def run():
         type=int,
         help="Max concurrent workflow tasks",
     )
+    parser.add_argument(
+        "--task-queue-activities-per-second",
+        type=float,
+        help="Server-side activity dispatch rate limit per task queue",
+    )
     # Log arguments
     parser.add_argument(
         "--log-level", default="info", help="(debug info warn error panic fatal)"
@@ -190,6 +195,10 @@ async def run():
         worker_kwargs[
             "max_concurrent_workflow_tasks"
         ] = args.max_concurrent_workflow_tasks
+    if args.task_queue_activities_per_second is not None:
+        worker_kwargs[
+            "max_task_queue_activities_per_second"
+        ] = args.task_queue_activities_per_second
 
     # Start all workers, throwing on first exception
     workers = [